@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
+	"github.com/jparrill/bobo-desk-pet/pkg/claude"
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/history"
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+	"github.com/jparrill/bobo-desk-pet/pkg/quota"
+)
+
+// askResult is the output of "bobo ask --output json"
+type askResult struct {
+	Question     string     `json:"question"`
+	Answer       string     `json:"answer"`
+	Intent       string     `json:"intent,omitempty"`
+	Sources      []string   `json:"sources,omitempty"`
+	Card         *card.Card `json:"card,omitempty"`
+	InputTokens  int        `json:"input_tokens"`
+	OutputTokens int        `json:"output_tokens"`
+	Cost         float64    `json:"cost"`
+	LatencyMs    int64      `json:"latency_ms"`
+	Variant      string     `json:"variant,omitempty"`
+}
+
+// runAsk sends one question to Claude and prints the answer
+func runAsk(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("ask", flag.ContinueOnError)
+	output := fs.String("output", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkOutputFormat(*output); err != nil {
+		return err
+	}
+	question := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if question == "" {
+		return fmt.Errorf("usage: bobo ask [--output text|json] <question>")
+	}
+
+	ctx := context.Background()
+	client := claude.NewSmartClient(cfg.VertexAI)
+	if guard := quota.NewGuard(cfg.Quota); guard != nil {
+		store, err := memory.Open(cfg.Memory.Dir)
+		if err != nil {
+			return fmt.Errorf("failed to open memory store: %w", err)
+		}
+		if err := guard.SetStore(store); err != nil {
+			return fmt.Errorf("failed to restore quota usage: %w", err)
+		}
+		client.SetQuota(guard)
+	}
+	if err := client.Initialize(ctx); err != nil {
+		return fmt.Errorf("failed to initialize Claude client: %w", err)
+	}
+
+	start := time.Now()
+	answer, err := client.Ask(ctx, []claude.Message{{Role: "user", Content: question}})
+	if err != nil {
+		return err
+	}
+	latency := time.Since(start)
+	cost := answer.Usage.Cost(cfg.VertexAI.InputTokenPrice, cfg.VertexAI.OutputTokenPrice)
+
+	// Keep one-shot questions in the conversation log and usage reports
+	if cfg.History.Enabled {
+		store, err := history.NewStore(cfg.History.Dir)
+		if err == nil {
+			err = store.Append(&history.Interaction{
+				Transcription: question,
+				Response:      answer.Text,
+				Sources:       answer.Sources,
+				Intent:        answer.Intent,
+				InputTokens:   answer.Usage.InputTokens,
+				OutputTokens:  answer.Usage.OutputTokens,
+				Cost:          cost,
+				LatencyMs:     latency.Milliseconds(),
+				Variant:       answer.Variant,
+			})
+		}
+		if err != nil {
+			slog.Warn("Failed to record interaction", "error", err)
+		}
+	}
+
+	if *output == "json" {
+		return printJSON(askResult{
+			Question:     question,
+			Answer:       answer.Text,
+			Intent:       answer.Intent,
+			Sources:      answer.Sources,
+			Card:         answer.Card,
+			InputTokens:  answer.Usage.InputTokens,
+			OutputTokens: answer.Usage.OutputTokens,
+			Cost:         cost,
+			LatencyMs:    latency.Milliseconds(),
+			Variant:      answer.Variant,
+		})
+	}
+
+	fmt.Println(answer.Text)
+	answer.Card.Render(os.Stdout)
+	for _, source := range answer.Sources {
+		fmt.Printf("  - %s\n", source)
+	}
+	return nil
+}
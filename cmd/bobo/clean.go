@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/recordings"
+)
+
+// runClean removes the recordings the retention policy no longer keeps
+func runClean(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("clean", flag.ContinueOnError)
+	var (
+		keep   = fs.String("keep", cfg.Recordings.Retention, "Retention to apply: an age (7d), a count (50), all or none")
+		dryRun = fs.Bool("dry-run", false, "List what would be removed without removing it")
+		output = fs.String("output", "text", "Output format: text or json")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkOutputFormat(*output); err != nil {
+		return err
+	}
+
+	store, err := recordings.NewStore(&config.RecordingsConfig{Dir: cfg.Recordings.Dir, Retention: *keep})
+	if err != nil {
+		return err
+	}
+	report, err := store.Clean(*dryRun)
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		return printJSON(report)
+	}
+
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+		for _, path := range report.Removed {
+			fmt.Println(path)
+		}
+	}
+	fmt.Printf("%s %d recordings (%.1f MB), kept %d\n", verb, len(report.Removed), float64(report.Bytes)/1e6, report.Kept)
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// runCommand dispatches one-shot subcommands such as "bobo history export"
+func runCommand(cfg *config.Config, configFile string, args []string) error {
+	switch args[0] {
+	case "history":
+		return runHistoryCommand(cfg, args[1:])
+	case "ask":
+		return runAsk(cfg, args[1:])
+	case "transcribe":
+		return runTranscribe(cfg, args[1:])
+	case "process":
+		return runProcess(cfg, args[1:])
+	case "status":
+		return runStatus(cfg, args[1:])
+	case "skills":
+		return runSkillsCommand(cfg, args[1:])
+	case "clean":
+		return runClean(cfg, args[1:])
+	case "data":
+		return runDataCommand(cfg, args[1:])
+	case "telemetry":
+		return runTelemetryCommand(cfg, args[1:])
+	case "models":
+		return runModelsCommand(cfg, configFile, args[1:])
+	case "serve":
+		return runServe(cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q (available: ask, transcribe, process, status, history, skills, clean, data, telemetry, models, serve)", args[0])
+	}
+}
+
+// checkOutputFormat validates the --output flag of a subcommand
+func checkOutputFormat(format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown output format %q (available: text, json)", format)
+	}
+	return nil
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
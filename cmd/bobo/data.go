@@ -0,0 +1,222 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/skills"
+)
+
+// dataLocation is a file or directory where Bobo keeps personal data, and
+// the name it gets in an export
+type dataLocation struct {
+	name string
+	path string
+}
+
+// dataLocations lists every local store Bobo writes: the conversation log,
+// skill memory and preferences, saved notes, recordings, the learned wake
+// word profile, the captions transcript, the stores of "bobo serve" tenants
+// and the private data of installed skills
+func dataLocations(cfg *config.Config) ([]dataLocation, error) {
+	locations := []dataLocation{
+		{name: "history", path: cfg.History.Dir},
+		{name: "memory", path: cfg.Memory.Dir},
+		{name: "notes", path: cfg.Notes.Dir},
+		{name: "tenants", path: cfg.Serve.Dir},
+		{name: "recordings", path: cfg.Recordings.Dir},
+		{name: filepath.Base(cfg.WakeWord.StateFile), path: cfg.WakeWord.StateFile},
+		{name: filepath.Base(cfg.Telemetry.File), path: cfg.Telemetry.File},
+	}
+	if cfg.Captions.File != "" {
+		locations = append(locations, dataLocation{name: filepath.Base(cfg.Captions.File), path: cfg.Captions.File})
+	}
+
+	dirs, err := skills.DataDirs(cfg.Skills.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		name := filepath.Base(filepath.Dir(dir))
+		locations = append(locations, dataLocation{name: "skills/" + name, path: dir})
+	}
+	return locations, nil
+}
+
+// runDataCommand handles "bobo data <subcommand>"
+func runDataCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: bobo data <export|wipe> [flags]")
+	}
+
+	switch args[0] {
+	case "export":
+		return runDataExport(cfg, args[1:])
+	case "wipe":
+		return runDataWipe(cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown data command %q (available: export, wipe)", args[0])
+	}
+}
+
+// runDataExport writes everything Bobo stores locally to a zip archive
+func runDataExport(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("data export", flag.ContinueOnError)
+	output := fs.String("output", "bobo-data-"+time.Now().Format("20060102")+".zip", "Archive to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	locations, err := dataLocations(cfg)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	var files int
+	var bytes int64
+	for _, location := range locations {
+		err := walkData(location.path, func(path, rel string, info os.FileInfo) error {
+			name := location.name
+			if rel != "." {
+				name = filepath.ToSlash(filepath.Join(location.name, rel))
+			}
+			if err := addToZip(archive, path, name, info); err != nil {
+				return err
+			}
+			files++
+			bytes += info.Size()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+
+	fmt.Printf("Exported %d files (%.1f MB) to %s\n", files, float64(bytes)/1e6, *output)
+	return nil
+}
+
+// addToZip copies the file at path into the archive as name
+func addToZip(archive *zip.Writer, path, name string, info os.FileInfo) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", path, err)
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	w, err := archive.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", path, err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", path, err)
+	}
+	return nil
+}
+
+// runDataWipe permanently deletes everything Bobo stores locally, after
+// asking for confirmation
+func runDataWipe(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("data wipe", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "Don't ask for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	locations, err := dataLocations(cfg)
+	if err != nil {
+		return err
+	}
+
+	var found []dataLocation
+	for _, location := range locations {
+		var files int
+		var bytes int64
+		err := walkData(location.path, func(_, _ string, info os.FileInfo) error {
+			files++
+			bytes += info.Size()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(location.path); err == nil {
+			found = append(found, location)
+			fmt.Printf("%-20s %s (%d files, %.1f MB)\n", location.name, location.path, files, float64(bytes)/1e6)
+		}
+	}
+	if len(found) == 0 {
+		fmt.Println("Nothing to wipe")
+		return nil
+	}
+
+	if !*yes {
+		fmt.Print("\nThis permanently deletes everything above. Type \"wipe\" to confirm: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "wipe" {
+			fmt.Println("Cancelled, nothing was deleted")
+			return nil
+		}
+	}
+
+	for _, location := range found {
+		if err := os.RemoveAll(location.path); err != nil {
+			return fmt.Errorf("failed to wipe %s: %w", location.name, err)
+		}
+	}
+	fmt.Printf("Wiped %d data stores\n", len(found))
+	return nil
+}
+
+// walkData calls fn for every regular file under root (or root itself when
+// it is a file), with its path relative to root; a missing root is empty
+func walkData(root string, fn func(path, rel string, info os.FileInfo) error) error {
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		return fn(path, rel, info)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/history"
+)
+
+// runHistoryCommand handles "bobo history <subcommand>"
+func runHistoryCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: bobo history <export|summary|stats> [flags]")
+	}
+
+	switch args[0] {
+	case "export":
+		return runHistoryExport(cfg, args[1:])
+	case "summary":
+		return runHistorySummary(cfg, args[1:])
+	case "stats":
+		return runHistoryStats(cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown history command %q (available: export, summary, stats)", args[0])
+	}
+}
+
+// runHistoryExport writes the conversation log in the requested format
+func runHistoryExport(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("history export", flag.ContinueOnError)
+	var (
+		format         = fs.String("format", "markdown", "Export format: markdown, json or html")
+		since          = fs.String("since", "", "Only include interactions newer than this (e.g. 7d, 2w, 12h)")
+		output         = fs.String("output", "", "Write to this file instead of stdout")
+		includeAudio   = fs.Bool("audio", false, "Include links to the recorded audio")
+		includeSources = fs.Bool("sources", true, "Include search sources used for the answers")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	sinceTime, err := history.ParseSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	store, err := history.NewStore(cfg.History.Dir)
+	if err != nil {
+		return err
+	}
+
+	interactions, err := store.Since(sinceTime)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return history.Export(w, interactions, history.ExportOptions{
+		Format:         *format,
+		IncludeAudio:   *includeAudio,
+		IncludeSources: *includeSources,
+	})
+}
+
+// runHistorySummary prints and saves the usage summary for a day
+func runHistorySummary(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("history summary", flag.ContinueOnError)
+	date := fs.String("date", "", "Day to summarize (YYYY-MM-DD, defaults to today)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	day := time.Now()
+	if *date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date %q: %w", *date, err)
+		}
+		day = parsed
+	}
+
+	store, err := history.NewStore(cfg.History.Dir)
+	if err != nil {
+		return err
+	}
+
+	summary, err := store.Summarize(day)
+	if err != nil {
+		return err
+	}
+
+	path, err := store.WriteSummary(summary)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(summary.Markdown())
+	fmt.Printf("\nSaved to %s\n", path)
+	return nil
+}
+
+// runHistoryStats prints aggregated answer feedback per intent or experiment variant
+func runHistoryStats(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("history stats", flag.ContinueOnError)
+	var (
+		since   = fs.String("since", "30d", "Only include interactions newer than this (e.g. 7d, 2w, 12h)")
+		groupBy = fs.String("by", "intent", "Group results by: intent or variant")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	sinceTime, err := history.ParseSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	store, err := history.NewStore(cfg.History.Dir)
+	if err != nil {
+		return err
+	}
+
+	stats, err := store.Feedback(sinceTime, *groupBy)
+	if err != nil {
+		return err
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("No interactions recorded in this period.")
+		return nil
+	}
+
+	fmt.Printf("%-16s %8s %6s %6s %10s %10s %10s\n", strings.ToUpper(*groupBy), "ANSWERS", "👍", "👎", "APPROVAL", "AVG TOKENS", "AVG TIME")
+	for _, s := range stats {
+		approval := "-"
+		if rated := s.Positive + s.Negative; rated > 0 {
+			approval = fmt.Sprintf("%.0f%%", float64(s.Positive)/float64(rated)*100)
+		}
+		fmt.Printf("%-16s %8d %6d %6d %10s %10d %10s\n",
+			s.Group, s.Interactions, s.Positive, s.Negative, approval, s.AvgOutputTokens, s.AvgLatency.Round(time.Millisecond))
+	}
+	return nil
+}
@@ -10,6 +10,9 @@ import (
 	"syscall"
 
 	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/reporting"
+	"github.com/jparrill/bobo-desk-pet/pkg/telemetry"
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
 	"github.com/jparrill/bobo-desk-pet/pkg/voice"
 )
 
@@ -20,6 +23,8 @@ func main() {
 		configFile = flag.String("config", ".env", "Configuration file path")
 		verbose    = flag.Bool("v", false, "Enable verbose logging")
 		showVersion = flag.Bool("version", false, "Show version and exit")
+		stdin       = flag.Bool("stdin", false, "Answer questions read from stdin, one per line (default when stdin is not a terminal)")
+		output      = flag.String("output", "text", "Answer format for --stdin: text or json")
 	)
 	flag.Parse()
 
@@ -34,7 +39,21 @@ func main() {
 		logLevel = slog.LevelDebug
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	// Answer questions from stdin when it is a pipe or a file
+	scripted := *stdin
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 && flag.NArg() == 0 {
+		scripted = true
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q (available: text, json)\n", *output)
+		os.Exit(2)
+	}
+	// Keep stdout for the answers of scripts and subcommands
+	if scripted || flag.NArg() > 0 {
+		voice.Console.SetOutput(os.Stderr)
+	}
+
+	logger := slog.New(slog.NewTextHandler(voice.Console, &slog.HandlerOptions{
 		Level: logLevel,
 	}))
 	slog.SetDefault(logger)
@@ -45,6 +64,27 @@ func main() {
 		slog.Error("Failed to load configuration", "error", err)
 		os.Exit(1)
 	}
+	textutil.SetPreviewLength(cfg.Log.PreviewChars)
+
+	// Report errors and crashes if the user opted in
+	reporter, err := reporting.New(cfg.Log.ErrorReportDSN, version)
+	if err != nil {
+		slog.Warn("Error reporting disabled", "error", err)
+	}
+	slog.SetDefault(slog.New(reporter.Handler(logger.Handler())))
+	defer reporter.Flush()
+	defer reporter.Recover()
+
+	// Run a one-shot subcommand instead of the interactive assistant
+	if flag.NArg() > 0 {
+		if err := runCommand(cfg, *configFile, flag.Args()); err != nil {
+			slog.Error("Command failed", "command", flag.Arg(0), "error", err)
+			reporter.Flush()
+			os.Exit(1)
+		}
+		reporter.Flush()
+		os.Exit(0)
+	}
 
 	slog.Info("🤖 Bobo - Your AI Voice Assistant", "version", version)
 	slog.Info("Configuration loaded",
@@ -65,18 +105,40 @@ func main() {
 	voiceInterface, err := voice.New(cfg)
 	if err != nil {
 		slog.Error("Failed to initialize voice interface", "error", err)
+		reporter.Flush()
 		os.Exit(1)
 	}
 
+	voiceInterface.SetScripted(scripted)
+
+	// Count feature usage if the user opted in to telemetry
+	if cfg.Telemetry.Enabled {
+		collector, err := telemetry.NewCollector(cfg.Telemetry, version)
+		if err != nil {
+			slog.Warn("Telemetry disabled", "error", err)
+		} else {
+			voiceInterface.SetTelemetry(collector)
+			go collector.Run(ctx)
+		}
+	}
+
 	// Initialize the voice interface
 	if err := voiceInterface.Initialize(ctx); err != nil {
 		slog.Error("Failed to initialize voice interface", "error", err)
+		reporter.Flush()
 		os.Exit(1)
 	}
 
 	// Start the main interaction loop in a goroutine
 	go func() {
-		if err := voiceInterface.Run(ctx); err != nil {
+		defer reporter.Recover()
+		run := voiceInterface.Run
+		if scripted {
+			run = func(ctx context.Context) error {
+				return voiceInterface.RunScript(ctx, os.Stdin, os.Stdout, *output == "json")
+			}
+		}
+		if err := run(ctx); err != nil {
 			slog.Error("Voice interface error", "error", err)
 		}
 		// Always cancel context when Run() exits (error or quit)
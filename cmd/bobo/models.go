@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/models"
+)
+
+// runModelsCommand handles "bobo models <subcommand>"
+func runModelsCommand(cfg *config.Config, configFile string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: bobo models <list|download|verify|use> [model]")
+	}
+
+	// Models are kept next to the configured one
+	manager := models.NewManager(filepath.Dir(cfg.Voice.WhisperModelPath))
+	switch args[0] {
+	case "list":
+		return runModelsList(cfg, manager, args[1:])
+	case "download", "verify", "use":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: bobo models %s <model>", args[0])
+		}
+		model, err := models.Find(args[1])
+		if err != nil {
+			return err
+		}
+		switch args[0] {
+		case "download":
+			_, err = downloadModel(manager, model)
+			return err
+		case "verify":
+			if err := manager.Verify(model); err != nil {
+				return err
+			}
+			fmt.Printf("✅ %s is intact\n", model.Name)
+			return nil
+		default:
+			return useModel(manager, model, configFile)
+		}
+	default:
+		return fmt.Errorf("unknown models command %q (available: list, download, verify, use)", args[0])
+	}
+}
+
+// runModelsList prints the catalog and which models are downloaded
+func runModelsList(cfg *config.Config, manager *models.Manager, args []string) error {
+	fs := flag.NewFlagSet("models list", flag.ContinueOnError)
+	output := fs.String("output", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkOutputFormat(*output); err != nil {
+		return err
+	}
+
+	statuses := manager.List()
+	if *output == "json" {
+		return printJSON(statuses)
+	}
+
+	fmt.Printf("%-8s %-9s %-11s %s\n", "MODEL", "SIZE", "STATE", "PATH")
+	for _, status := range statuses {
+		state := "-"
+		switch {
+		case filepath.Clean(status.Path) == filepath.Clean(cfg.Voice.WhisperModelPath):
+			state = "in use"
+		case status.Installed:
+			state = "downloaded"
+		}
+		fmt.Printf("%-8s %-9s %-11s %s\n", status.Name, status.Size, state, status.Path)
+	}
+	return nil
+}
+
+// downloadModel downloads a model, showing the progress
+func downloadModel(manager *models.Manager, model models.Model) (string, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("⬇️  Downloading %s (%s)...\n", model.Name, model.Size)
+	lastPercent := -1
+	path, err := manager.Download(ctx, model, func(done, total int64) {
+		if total <= 0 {
+			return
+		}
+		if percent := int(done * 100 / total); percent != lastPercent {
+			lastPercent = percent
+			fmt.Printf("\r   %3d%%", percent)
+		}
+	})
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("✅ %s saved to %s\n", model.Name, path)
+	return path, nil
+}
+
+// useModel makes model the one Bobo transcribes with, downloading it first
+// if needed
+func useModel(manager *models.Manager, model models.Model, configFile string) error {
+	path := manager.Path(model)
+	if _, err := os.Stat(path); err != nil {
+		if path, err = downloadModel(manager, model); err != nil {
+			return err
+		}
+	}
+	if err := config.SetEnvValue(configFile, "WHISPER_CPP_MODEL", path); err != nil {
+		return err
+	}
+	fmt.Printf("🎙️  Bobo now transcribes with %s (WHISPER_CPP_MODEL in %s)\n", model.Name, configFile)
+	return nil
+}
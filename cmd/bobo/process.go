@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/voice"
+)
+
+// runProcess answers an existing recording as if it had just been said,
+// speaking the answer
+func runProcess(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("process", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bobo process <file.wav>")
+	}
+
+	ctx := context.Background()
+	v, err := voice.New(cfg)
+	if err != nil {
+		return err
+	}
+	if err := v.Initialize(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if err := v.Shutdown(); err != nil {
+			slog.Warn("Error during shutdown", "error", err)
+		}
+	}()
+
+	return v.ProcessFile(ctx, fs.Arg(0))
+}
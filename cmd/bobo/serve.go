@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/server"
+)
+
+// runServe answers questions over HTTP for the tenants in SERVE_TENANTS_FILE
+// until interrupted
+func runServe(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", cfg.Serve.Listen, "Address to serve the API on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg.Serve.Listen = *listen
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv, err := server.New(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	return srv.Run(ctx)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/skills"
+)
+
+// runSkillsCommand handles "bobo skills <subcommand>"
+func runSkillsCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: bobo skills <list|install|remove|enable|disable|update> [args]")
+	}
+
+	installer := skills.NewInstaller(cfg.Skills.Dir, cfg.Skills.IndexURL)
+	switch args[0] {
+	case "list":
+		return runSkillsList(installer, args[1:])
+	case "install":
+		return runSkillsInstall(installer, args[1:])
+	case "update":
+		return runSkillsUpdate(installer, args[1:])
+	case "remove", "enable", "disable":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: bobo skills %s <name>", args[0])
+		}
+		var err error
+		switch args[0] {
+		case "remove":
+			err = installer.Remove(args[1])
+		default:
+			err = installer.SetEnabled(args[1], args[0] == "enable")
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Skill %s %sd\n", args[1], args[0])
+		return nil
+	default:
+		return fmt.Errorf("unknown skills command %q (available: list, install, remove, enable, disable, update)", args[0])
+	}
+}
+
+// runSkillsList prints the installed skills
+func runSkillsList(installer *skills.Installer, args []string) error {
+	fs := flag.NewFlagSet("skills list", flag.ContinueOnError)
+	output := fs.String("output", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkOutputFormat(*output); err != nil {
+		return err
+	}
+
+	installed, err := installer.List()
+	if err != nil {
+		return err
+	}
+	if *output == "json" {
+		return printJSON(installed)
+	}
+
+	if len(installed) == 0 {
+		fmt.Println("No skills installed.")
+		return nil
+	}
+
+	names := make([]string, 0, len(installed))
+	for name := range installed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%-20s %-10s %-8s %-7s %s\n", "NAME", "VERSION", "STATE", "PINNED", "PERMISSIONS")
+	for _, name := range names {
+		skill := installed[name]
+		state := map[bool]string{true: "enabled", false: "disabled"}[skill.Enabled]
+		pinned := map[bool]string{true: "yes", false: "no"}[skill.Pinned]
+		permissions := strings.Join(skill.Permissions, ", ")
+		if permissions == "" {
+			permissions = "-"
+		}
+		fmt.Printf("%-20s %-10s %-8s %-7s %s\n", name, skill.Version, state, pinned, permissions)
+	}
+	return nil
+}
+
+// runSkillsInstall installs a skill from the registry, an archive or a directory
+func runSkillsInstall(installer *skills.Installer, args []string) error {
+	fs := flag.NewFlagSet("skills install", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "Approve the permissions the skill requests without asking")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bobo skills install [--yes] <name[@version]|url|archive.tar.gz|directory>")
+	}
+	return installSkill(installer, fs.Arg(0), nil, *yes)
+}
+
+// runSkillsUpdate reinstalls the latest registry version of unpinned skills
+func runSkillsUpdate(installer *skills.Installer, args []string) error {
+	fs := flag.NewFlagSet("skills update", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "Approve new permissions requested by updated skills without asking")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	names := fs.Args()
+	if len(names) == 0 {
+		var err error
+		if names, err = installer.Updatable(); err != nil {
+			return err
+		}
+	}
+	if len(names) == 0 {
+		fmt.Println("No skills to update.")
+		return nil
+	}
+
+	installed, err := installer.List()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		skill, ok := installed[name]
+		if ok && skill.Pinned {
+			fmt.Printf("Skipping %s, pinned to %s\n", name, skill.Version)
+			continue
+		}
+		var approved []string
+		if ok {
+			approved = skill.Permissions
+		}
+		if err := installSkill(installer, name, approved, *yes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installSkill fetches and installs one skill, asking the user to approve
+// the permissions it requests beyond the already approved ones; "name@version"
+// pins it
+func installSkill(installer *skills.Installer, source string, approved []string, yes bool) error {
+	manifest, staged, err := installer.Fetch(context.Background(), source)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s", manifest.Name, manifest.Version)
+	if manifest.Description != "" {
+		fmt.Printf(" - %s", manifest.Description)
+	}
+	fmt.Println()
+
+	if missing := skills.NewPolicy(approved).Missing(manifest.Permissions); len(missing) > 0 && !yes {
+		fmt.Println("This skill wants to:")
+		for _, permission := range missing {
+			fmt.Printf("  %-13s %s\n", permission, skills.PermissionDescriptions[skills.Permission(permission)])
+		}
+		if !confirm("Approve? [y/N] ") {
+			installer.Discard(staged)
+			return fmt.Errorf("permissions for %s not approved (use --yes to approve them without asking)", manifest.Name)
+		}
+	}
+
+	pinned := skills.IsRegistrySource(source) && strings.Contains(source, "@")
+	if err := installer.Install(manifest, staged, source, pinned); err != nil {
+		installer.Discard(staged)
+		return err
+	}
+	fmt.Printf("Installed %s %s\n", manifest.Name, manifest.Version)
+	return nil
+}
+
+// confirm asks a yes/no question on stdin; anything but yes is a no
+func confirm(question string) bool {
+	fmt.Print(question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "s", "si", "sí":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/history"
+	"github.com/jparrill/bobo-desk-pet/pkg/voice"
+)
+
+// statusResult is the output of "bobo status --output json"
+type statusResult struct {
+	Version           string                `json:"version"`
+	Project           string                `json:"project"`
+	Model             string                `json:"model"`
+	SpeechRecognition string                `json:"speech_recognition"`
+	TTS               bool                  `json:"tts"`
+	WebSearch         bool                  `json:"web_search"`
+	History           bool                  `json:"history"`
+	Today             *history.DailySummary `json:"today,omitempty"`
+}
+
+// runStatus prints the configured engines and today's usage
+func runStatus(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	output := fs.String("output", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkOutputFormat(*output); err != nil {
+		return err
+	}
+
+	status := statusResult{
+		Version:           version,
+		Project:           cfg.VertexAI.ProjectID,
+		Model:             cfg.VertexAI.Model,
+		SpeechRecognition: voice.TranscriberName(cfg),
+		TTS:               cfg.TTS.Enabled,
+		WebSearch:         cfg.VertexAI.EnableAutoSearch,
+		History:           cfg.History.Enabled,
+	}
+	if cfg.History.Enabled {
+		store, err := history.NewStore(cfg.History.Dir)
+		if err != nil {
+			return err
+		}
+		if status.Today, err = store.Summarize(time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if *output == "json" {
+		return printJSON(status)
+	}
+
+	onOff := map[bool]string{true: "on", false: "off"}
+	fmt.Printf("Bobo v%s\n", status.Version)
+	fmt.Printf("Model:              %s (project %s)\n", status.Model, status.Project)
+	fmt.Printf("Speech recognition: %s\n", status.SpeechRecognition)
+	fmt.Printf("Text-to-speech:     %s\n", onOff[status.TTS])
+	fmt.Printf("Web search:         %s\n", onOff[status.WebSearch])
+	fmt.Printf("History:            %s\n", onOff[status.History])
+	if today := status.Today; today != nil {
+		fmt.Printf("Today:              %d interactions, %d tokens, $%.4f, %s average latency\n",
+			today.Interactions, today.InputTokens+today.OutputTokens, today.Cost, today.AverageLatency.Round(time.Millisecond))
+	}
+	return nil
+}
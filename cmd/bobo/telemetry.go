@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/telemetry"
+)
+
+// runTelemetryCommand handles "bobo telemetry <subcommand>"
+func runTelemetryCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: bobo telemetry <show|send|reset>")
+	}
+
+	collector, err := telemetry.NewCollector(cfg.Telemetry, version)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "show":
+		return runTelemetryShow(cfg, collector)
+	case "send":
+		if !cfg.Telemetry.Enabled {
+			return fmt.Errorf("telemetry is off; set TELEMETRY=true to opt in")
+		}
+		if err := collector.Send(context.Background()); err != nil {
+			return err
+		}
+		fmt.Printf("📊 Usage report sent to %s\n", cfg.Telemetry.URL)
+		return nil
+	case "reset":
+		if err := collector.Reset(); err != nil {
+			return err
+		}
+		fmt.Println("🧹 Usage counts cleared")
+		return nil
+	default:
+		return fmt.Errorf("unknown telemetry command %q (available: show, send, reset)", args[0])
+	}
+}
+
+// runTelemetryShow prints whether telemetry is on and the exact report the
+// next send would post
+func runTelemetryShow(cfg *config.Config, collector *telemetry.Collector) error {
+	switch {
+	case !cfg.Telemetry.Enabled:
+		fmt.Println("Telemetry is off (TELEMETRY=false): nothing is counted or sent.")
+	case cfg.Telemetry.URL == "":
+		fmt.Println("Telemetry counts usage on this machine only (TELEMETRY_URL is not set).")
+	default:
+		fmt.Printf("Telemetry is on: this report is sent to %s every %d hours.\n", cfg.Telemetry.URL, cfg.Telemetry.IntervalHours)
+	}
+	fmt.Println()
+	return printJSON(collector.Report())
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/voice"
+)
+
+// transcribeResult is the output of "bobo transcribe --output json"
+type transcribeResult struct {
+	File      string           `json:"file"`
+	Language  string           `json:"language"`
+	Engine    string           `json:"engine"`
+	Text      string           `json:"text"`
+	Words     []transcribeWord `json:"words,omitempty"`
+	Turns     []transcribeTurn `json:"turns,omitempty"`
+	LatencyMs int64            `json:"latency_ms"`
+}
+
+// transcribeTurn is what a speaker said, with "bobo transcribe --speakers"
+type transcribeTurn struct {
+	Speaker int     `json:"speaker"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+}
+
+// transcribeWord is a word of "bobo transcribe --words", timed in seconds
+type transcribeWord struct {
+	Word       string  `json:"word"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Confidence float64 `json:"confidence"`
+}
+
+// runTranscribe prints the transcription of an audio file
+func runTranscribe(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("transcribe", flag.ContinueOnError)
+	var (
+		output   = fs.String("output", "text", "Output format: text or json")
+		language = fs.String("language", "es", "Language spoken in the recording")
+		words    = fs.Bool("words", false, "Include when each word was said")
+		speakers = fs.Bool("speakers", false, "Label who said what (diarization)")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkOutputFormat(*output); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bobo transcribe [--output text|json] [--language es] [--words|--speakers] <file.wav>")
+	}
+	if *words && *speakers {
+		return fmt.Errorf("--words and --speakers can't be combined")
+	}
+
+	transcriber, err := voice.NewTranscriber(cfg)
+	if err != nil {
+		return err
+	}
+	if closer, ok := transcriber.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	start := time.Now()
+	var text string
+	var timed []voice.Word
+	var turns []voice.Turn
+	if *speakers {
+		speakerTranscriber, ok := voice.TranscriberAs[voice.SpeakerTranscriber](transcriber)
+		if !ok {
+			return fmt.Errorf("%s can't tell speakers apart (whisper.cpp with a tinydiarize model and Deepgram can)", voice.TranscriberName(cfg))
+		}
+		turns, err = speakerTranscriber.TranscribeSpeakers(context.Background(), fs.Arg(0), *language)
+		if err != nil {
+			return fmt.Errorf("transcription failed: %w", err)
+		}
+		var texts []string
+		for _, turn := range turns {
+			texts = append(texts, turn.Text)
+		}
+		text = strings.Join(texts, " ")
+	} else if *words {
+		wordTranscriber, ok := voice.TranscriberAs[voice.WordTranscriber](transcriber)
+		if !ok {
+			return fmt.Errorf("%s can't time words (whisper.cpp, whisper-lib, Deepgram and Google can)", voice.TranscriberName(cfg))
+		}
+		result, err := wordTranscriber.TranscribeWords(context.Background(), fs.Arg(0), *language)
+		if err != nil {
+			return fmt.Errorf("transcription failed: %w", err)
+		}
+		text, timed = result.Text, result.Words
+	} else {
+		text, err = transcriber.Transcribe(context.Background(), fs.Arg(0), *language)
+		if err != nil {
+			return fmt.Errorf("transcription failed: %w", err)
+		}
+	}
+	latency := time.Since(start)
+
+	if *output == "json" {
+		result := transcribeResult{
+			File:      fs.Arg(0),
+			Language:  *language,
+			Engine:    voice.TranscriberName(cfg),
+			Text:      text,
+			LatencyMs: latency.Milliseconds(),
+		}
+		for _, turn := range turns {
+			result.Turns = append(result.Turns, transcribeTurn{
+				Speaker: turn.Speaker,
+				Start:   turn.Start.Seconds(),
+				End:     turn.End.Seconds(),
+				Text:    turn.Text,
+			})
+		}
+		for _, word := range timed {
+			result.Words = append(result.Words, transcribeWord{
+				Word:       word.Text,
+				Start:      word.Start.Seconds(),
+				End:        word.End.Seconds(),
+				Confidence: word.Confidence,
+			})
+		}
+		return printJSON(result)
+	}
+
+	if turns != nil {
+		for _, turn := range turns {
+			fmt.Printf("[%7.2fs] Speaker %d: %s\n", turn.Start.Seconds(), turn.Speaker, turn.Text)
+		}
+		return nil
+	}
+	fmt.Println(text)
+	for _, word := range timed {
+		fmt.Printf("%7.2fs %7.2fs  %-20s %3.0f%%\n", word.Start.Seconds(), word.End.Seconds(), word.Text, word.Confidence*100)
+	}
+	return nil
+}
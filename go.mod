@@ -4,10 +4,12 @@ go 1.25.5
 
 require (
 	github.com/chzyer/readline v1.5.1
+	github.com/tetratelabs/wazero v1.10.0
+	go.starlark.net v0.0.0-20260908191801-89a6a09411d5
 	golang.org/x/oauth2 v0.35.0
 )
 
 require (
 	cloud.google.com/go/compute/metadata v0.3.0 // indirect
-	golang.org/x/sys v0.0.0-20220310020820-b874c991c1a5 // indirect
+	golang.org/x/sys v0.42.0 // indirect
 )
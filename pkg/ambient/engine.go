@@ -0,0 +1,157 @@
+// Package ambient implements idle "presence" behaviors that make the desk pet
+// feel alive between interactions
+package ambient
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// Kind identifies the type of idle behavior
+type Kind string
+
+const (
+	// KindFace is a purely visual ASCII face animation
+	KindFace Kind = "face"
+	// KindRemark is a short idle comment
+	KindRemark Kind = "remark"
+	// KindBreak suggests the user takes a break
+	KindBreak Kind = "break"
+)
+
+// Behavior is an idle action the interface should render
+type Behavior struct {
+	Kind Kind
+	Face string
+	Text string
+}
+
+var faces = []string{
+	"(•‿•)",
+	"(-‿-) zZ",
+	"\\(•o•)/  *stretch*",
+	"(◕‿◕✿)",
+	"(¬‿¬)",
+	"(°o°)  *yawn*",
+}
+
+var remarks = []string{
+	"¿Sabías que los pulpos tienen tres corazones?",
+	"Aquí sigo, por si me necesitas.",
+	"Qué tranquilo está esto...",
+	"Un vaso de agua nunca viene mal.",
+	"Si necesitas algo, solo tienes que pedírmelo.",
+}
+
+// Engine emits idle behaviors after periods of inactivity
+type Engine struct {
+	config *config.AmbientConfig
+	rand   *rand.Rand
+
+	mu           sync.Mutex
+	lastActivity time.Time
+	lastBehavior time.Time
+	sessionStart time.Time
+	paused       bool
+}
+
+// NewEngine creates an idle behavior engine
+func NewEngine(cfg *config.AmbientConfig) *Engine {
+	now := time.Now()
+	return &Engine{
+		config:       cfg,
+		rand:         rand.New(rand.NewSource(now.UnixNano())),
+		lastActivity: now,
+		sessionStart: now,
+	}
+}
+
+// Touch records user activity, resetting the idle timer
+func (e *Engine) Touch() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	// A long idle gap counts as a break, so the work session restarts
+	if now.Sub(e.lastActivity) >= e.breakEvery()/2 {
+		e.sessionStart = now
+	}
+	e.lastActivity = now
+}
+
+// SetPaused suspends or resumes idle behaviors (e.g. while recording)
+func (e *Engine) SetPaused(paused bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.paused = paused
+}
+
+// Run checks for idle conditions until ctx is cancelled, sending behaviors to emit
+func (e *Engine) Run(ctx context.Context, emit func(Behavior)) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if behavior, ok := e.next(now); ok {
+				emit(behavior)
+			}
+		}
+	}
+}
+
+// next decides whether a behavior is due at now
+func (e *Engine) next(now time.Time) (Behavior, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.paused {
+		return Behavior{}, false
+	}
+
+	// Suggest a break after a long stretch of continuous use
+	if e.breakEvery() > 0 && now.Sub(e.sessionStart) >= e.breakEvery() && now.Sub(e.lastActivity) < e.idleAfter() {
+		e.sessionStart = now
+		e.lastBehavior = now
+		return Behavior{
+			Kind: KindBreak,
+			Face: "(•‿•)ノ",
+			Text: "Llevamos un buen rato. ¿Qué tal si te levantas y estiras un poco?",
+		}, true
+	}
+
+	if now.Sub(e.lastActivity) < e.idleAfter() || now.Sub(e.lastBehavior) < e.interval() {
+		return Behavior{}, false
+	}
+
+	// Jitter so behaviors don't feel mechanical
+	if e.rand.Intn(3) != 0 {
+		return Behavior{}, false
+	}
+	e.lastBehavior = now
+
+	face := faces[e.rand.Intn(len(faces))]
+	if e.rand.Intn(2) == 0 {
+		return Behavior{Kind: KindFace, Face: face}, true
+	}
+	return Behavior{Kind: KindRemark, Face: face, Text: remarks[e.rand.Intn(len(remarks))]}, true
+}
+
+func (e *Engine) idleAfter() time.Duration {
+	return time.Duration(e.config.IdleMinutes) * time.Minute
+}
+
+func (e *Engine) interval() time.Duration {
+	return time.Duration(e.config.IntervalMinutes) * time.Minute
+}
+
+func (e *Engine) breakEvery() time.Duration {
+	return time.Duration(e.config.BreakMinutes) * time.Minute
+}
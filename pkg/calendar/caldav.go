@@ -0,0 +1,100 @@
+// Package calendar writes reminders created by voice to the user's CalDAV
+// calendar (Nextcloud, iCloud, Fastmail, Radicale, ...)
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/skills"
+)
+
+// eventDuration is the length given to reminder events
+const eventDuration = 15 * time.Minute
+
+// CalDAV stores reminders as events in a CalDAV calendar collection
+type CalDAV struct {
+	config *config.CalendarConfig
+	client *http.Client
+}
+
+// NewCalDAV creates a CalDAV writer for the configured calendar URL
+func NewCalDAV(cfg *config.CalendarConfig) *CalDAV {
+	return &CalDAV{
+		config: cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WriteReminder implements skills.CalendarWriter by uploading the reminder as
+// an event with an alarm at its start
+func (c *CalDAV) WriteReminder(ctx context.Context, reminder skills.Reminder) error {
+	url := strings.TrimRight(c.config.CalDAVURL, "/") + "/" + reminder.ID + ".ics"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, strings.NewReader(icsEvent(reminder, time.Now())))
+	if err != nil {
+		return fmt.Errorf("invalid calendar URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	req.Header.Set("If-None-Match", "*")
+	if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach calendar: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("calendar returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// icsEvent renders a reminder as an iCalendar VEVENT with a display alarm,
+// repeating like the reminder
+func icsEvent(reminder skills.Reminder, now time.Time) string {
+	const stamp = "20060102T150405Z"
+	start := reminder.At.UTC()
+	summary := escapeText(reminder.Text)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Bobo//Desk Pet//EN",
+		"BEGIN:VEVENT",
+		"UID:" + reminder.ID + "@bobo",
+		"DTSTAMP:" + now.UTC().Format(stamp),
+		"DTSTART:" + start.Format(stamp),
+		"DTEND:" + start.Add(eventDuration).Format(stamp),
+		"SUMMARY:" + summary,
+	}
+	if reminder.RRule != "" {
+		rule := reminder.RRule
+		if recurrence, err := skills.ParseRRule(rule); err == nil {
+			rule = recurrence.CalendarRule(start)
+		}
+		lines = append(lines, "RRULE:"+rule)
+	}
+	lines = append(lines,
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:"+summary,
+		"TRIGGER:-PT0M",
+		"END:VALARM",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// escapeText escapes characters with a special meaning in iCalendar text values
+func escapeText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
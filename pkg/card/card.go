@@ -0,0 +1,73 @@
+// Package card describes structured results (forecasts, prices, lists) that
+// are shown on screen alongside a short spoken answer
+package card
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Card is a titled table; a list is a table with a single column
+type Card struct {
+	Title   string     `json:"title"`
+	Columns []string   `json:"columns,omitempty"`
+	Rows    [][]string `json:"rows"`
+}
+
+// New creates a card with the given title and column headers
+func New(title string, columns ...string) *Card {
+	return &Card{Title: title, Columns: columns}
+}
+
+// Add appends a row
+func (c *Card) Add(cells ...string) {
+	c.Rows = append(c.Rows, cells)
+}
+
+// Render writes the card as an aligned text table
+func (c *Card) Render(w io.Writer) {
+	if c == nil || len(c.Rows) == 0 {
+		return
+	}
+
+	widths := make([]int, len(c.Columns))
+	measure := func(cells []string) {
+		for i, cell := range cells {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	measure(c.Columns)
+	for _, row := range c.Rows {
+		measure(row)
+	}
+
+	line := func(cells []string) string {
+		padded := make([]string, len(cells))
+		for i, cell := range cells {
+			padded[i] = cell
+			if i < len(cells)-1 {
+				padded[i] += strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			}
+		}
+		return strings.TrimRight("  │ "+strings.Join(padded, "  "), " ")
+	}
+
+	fmt.Fprintf(w, "\n  ┌ %s\n", c.Title)
+	if len(c.Columns) > 0 {
+		fmt.Fprintln(w, line(c.Columns))
+		total := 0
+		for _, width := range widths {
+			total += width + 2
+		}
+		fmt.Fprintf(w, "  ├%s\n", strings.Repeat("─", total))
+	}
+	for _, row := range c.Rows {
+		fmt.Fprintln(w, line(row))
+	}
+	fmt.Fprintln(w, "  └")
+}
@@ -0,0 +1,37 @@
+package claude
+
+// EstimateTokens approximates the token count of text (about 4 characters per token)
+func EstimateTokens(text string) int {
+	return (len([]rune(text)) + 3) / 4
+}
+
+// Estimate predicts the worst-case usage of Ask for messages: the prompt with
+// a full-length reply, plus the search and verification rounds that may follow
+func (s *SmartClient) Estimate(messages []Message) Usage {
+	system := s.config.SystemPrompt
+	if s.personaPrompt != "" {
+		system = s.personaPrompt
+	}
+
+	input := EstimateTokens(system)
+	for _, message := range messages {
+		input += EstimateTokens(message.Content)
+	}
+
+	usage := Usage{InputTokens: input, OutputTokens: s.config.MaxTokens}
+	if s.autoSearchEnabled && s.needsWebSearch("", messages) {
+		// The search round resends the conversation with the first reply and the results
+		usage.Add(&Usage{
+			InputTokens:  input + s.config.MaxTokens + s.searchContextBudget(),
+			OutputTokens: s.config.MaxTokens,
+		})
+		if s.config.VerifyAnswers {
+			// The verification pass sends the results and the answer once more
+			usage.Add(&Usage{
+				InputTokens:  EstimateTokens(verifySystemPrompt) + s.searchContextBudget() + s.config.MaxTokens,
+				OutputTokens: s.config.MaxTokens,
+			})
+		}
+	}
+	return usage
+}
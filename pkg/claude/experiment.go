@@ -0,0 +1,41 @@
+package claude
+
+import (
+	"sync"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// Experiment alternates between two request variants so they can be compared
+type Experiment struct {
+	name     string
+	variants [2]*Overrides
+	next     int
+	mu       sync.Mutex
+}
+
+// NewExperiment creates an A/B experiment, returning nil when disabled
+func NewExperiment(cfg *config.ExperimentConfig) *Experiment {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	return &Experiment{
+		name: cfg.Name,
+		variants: [2]*Overrides{
+			{Model: cfg.A.Model, SystemPrompt: cfg.A.SystemPrompt},
+			{Model: cfg.B.Model, SystemPrompt: cfg.B.SystemPrompt},
+		},
+	}
+}
+
+// Next returns the tag and overrides for the next interaction, alternating A and B
+func (e *Experiment) Next() (string, *Overrides) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	index := e.next
+	e.next = (e.next + 1) % len(e.variants)
+
+	return e.name + "/" + string(rune('A'+index)), e.variants[index]
+}
@@ -0,0 +1,271 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SearchLocale holds the vocabulary of one language used to decide when to
+// search the web and how to build the search query
+type SearchLocale struct {
+	Language string `json:"language"`
+	// Triggers are phrases in Claude's reply meaning it lacks current information
+	Triggers []string `json:"triggers"`
+	// Indicators are words in the user's question asking for current information
+	Indicators []string `json:"indicators"`
+	// LocationPrepositions introduce a place ("en Madrid", "in Madrid")
+	LocationPrepositions []string `json:"location_prepositions"`
+	// LocationConnectors may join the words of a place name ("Santiago de Compostela")
+	LocationConnectors []string `json:"location_connectors"`
+	// PlaceNouns are generic places skipped before the name ("el centro de Madrid")
+	PlaceNouns []string `json:"place_nouns"`
+	// LocationStopwords end a place written in lowercase ("madrid hoy")
+	LocationStopwords []string        `json:"location_stopwords"`
+	Categories        []QueryCategory `json:"categories"`
+	// Fallback is the query for other questions; {message} is the question
+	Fallback string `json:"fallback"`
+}
+
+// QueryCategory turns questions about one topic into a search query
+type QueryCategory struct {
+	Intent   string      `json:"intent"`
+	Keywords []string    `json:"keywords"`
+	Rules    []QueryRule `json:"rules,omitempty"`
+	Query    string      `json:"query"`
+	// LocationQuery is used instead of Query when a place is found; {location} is the place
+	LocationQuery string `json:"location_query,omitempty"`
+	// Requires lists slots the question must have ("location"); Clarify asks for them
+	Requires []string `json:"requires,omitempty"`
+	Clarify  string   `json:"clarify,omitempty"`
+}
+
+// QueryRule is a more specific query for questions mentioning one of Match
+// and, when set, one of Also
+type QueryRule struct {
+	Match []string `json:"match"`
+	Also  []string `json:"also,omitempty"`
+	Query string   `json:"query"`
+}
+
+// builtinLocales are always available and can be overridden from the locales file
+var builtinLocales = []SearchLocale{
+	{
+		Language: "es",
+		Triggers: []string{
+			`no tengo acceso a informaci[oó]n actual`,
+			`no tengo acceso a (?:datos|informaci[oó]n) en tiempo real`,
+			`no tengo acceso a internet`,
+			`no puedo acceder a internet`,
+			`no puedo consultar`,
+			`informaci[oó]n en tiempo real`,
+			`informaci[oó]n actualizada`,
+			`datos actualizados`,
+		},
+		Indicators:           []string{"hoy", "ahora", "actual", "reciente", "último", "ultimo", "tiempo", "noticias", "precio"},
+		LocationPrepositions: []string{"en", "de"},
+		LocationConnectors:   []string{"de", "del", "la", "las", "los", "el"},
+		PlaceNouns:           []string{"centro", "zona", "barrio", "afueras", "alrededores", "ciudad", "provincia", "pueblo", "norte", "sur", "oeste"},
+		LocationStopwords: []string{
+			"hoy", "mañana", "ahora", "ayer", "esta", "este", "estos", "estas", "hace", "hará", "va", "para", "por",
+			"que", "qué", "y", "o", "con", "durante", "semana", "fin", "tarde", "noche", "pasado", "lloverá", "llueve",
+			"está", "estará", "es", "será", "son", "a", "al",
+		},
+		Categories: []QueryCategory{
+			{
+				Intent:        "weather",
+				Keywords:      []string{"tiempo", "clima", "temperatura", "lloverá", "llover", "llueve", "lluvia", "paraguas", "previsión", "pronóstico"},
+				Query:         "el tiempo hoy",
+				LocationQuery: "el tiempo hoy en {location}",
+				Requires:      []string{"location"},
+				Clarify:       "¿De qué ciudad quieres saber el tiempo?",
+			},
+			{
+				Intent:   "sports",
+				Keywords: []string{"real madrid", "partido", "resultado", "fútbol", "futbol", "liga"},
+				Rules: []QueryRule{
+					{Match: []string{"real madrid"}, Also: []string{"último", "ultimo", "ayer"}, Query: "resultado del último partido del Real Madrid"},
+					{Match: []string{"real madrid"}, Query: "noticias del Real Madrid hoy"},
+				},
+				Query: "resultados de fútbol hoy en España",
+			},
+			{
+				Intent:   "news",
+				Keywords: []string{"noticias", "novedades", "titulares"},
+				Query:    "últimas noticias de hoy",
+			},
+			{
+				Intent:   "finance",
+				Keywords: []string{"precio", "bitcoin", "ethereum", "dogecoin", "solana", "cripto", "bolsa", "cotización", "cotizacion"},
+				Rules: []QueryRule{
+					{Match: []string{"bitcoin"}, Query: "precio del bitcoin hoy"},
+				},
+				Query: "mercados financieros hoy",
+			},
+		},
+		Fallback: "{message}",
+	},
+	{
+		Language: "en",
+		Triggers: []string{
+			`I don't have access to current information`,
+			`I cannot provide real-time information`,
+			`I don't have access to weather data`,
+			`real-time weather information`,
+			`I don't have access to internet`,
+			`updated data`,
+			`I don't have access to real-time`,
+			`I don't have access to current`,
+			`I cannot access current`,
+			`I don't have internet access`,
+			`real-time information`,
+			`current information`,
+			`up-to-date information`,
+		},
+		Indicators:           []string{"today", "now", "current", "recent", "latest", "weather", "news", "price"},
+		LocationPrepositions: []string{"in", "for", "at"},
+		LocationConnectors:   []string{"of", "the", "upon"},
+		PlaceNouns:           []string{"center", "centre", "downtown", "area", "city", "town", "outskirts", "province", "north", "south", "east", "west"},
+		LocationStopwords: []string{
+			"today", "tomorrow", "now", "tonight", "this", "next", "will", "is", "be", "for", "and", "or", "right",
+			"like", "at", "on", "during", "weekend", "week", "going", "gonna", "please", "i",
+		},
+		Categories: []QueryCategory{
+			{
+				Intent:        "weather",
+				Keywords:      []string{"weather", "forecast", "temperature", "rain", "umbrella"},
+				Query:         "weather today",
+				LocationQuery: "weather today {location}",
+				Requires:      []string{"location"},
+				Clarify:       "Which city do you want the weather for?",
+			},
+			{
+				Intent:   "sports",
+				Keywords: []string{"real madrid", "match", "football", "soccer"},
+				Rules: []QueryRule{
+					{Match: []string{"real madrid"}, Also: []string{"last", "recent", "yesterday"}, Query: "Real Madrid latest match result today"},
+					{Match: []string{"real madrid"}, Query: "Real Madrid news today"},
+				},
+				Query: "football results today Spain",
+			},
+			{
+				Intent:   "news",
+				Keywords: []string{"news", "headlines"},
+				Query:    "latest news today",
+			},
+			{
+				Intent:   "finance",
+				Keywords: []string{"price", "bitcoin", "ethereum", "dogecoin", "solana", "crypto", "stock"},
+				Rules: []QueryRule{
+					{Match: []string{"bitcoin"}, Query: "Bitcoin price today"},
+				},
+				Query: "financial markets today",
+			},
+		},
+		Fallback: "current information {message}",
+	},
+}
+
+// loadSearchLocales returns the built-in locales merged with the JSON array in
+// path, with the preferred language first
+func loadSearchLocales(path, preferred string) ([]*SearchLocale, error) {
+	byLanguage := make(map[string]*SearchLocale)
+	for i := range builtinLocales {
+		locale := builtinLocales[i]
+		byLanguage[locale.Language] = &locale
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading search locales file %s: %w", path, err)
+		}
+		if err == nil {
+			var custom []SearchLocale
+			if err := json.Unmarshal(data, &custom); err != nil {
+				return nil, fmt.Errorf("invalid search locales file %s: %w", path, err)
+			}
+			for i := range custom {
+				locale := custom[i]
+				if locale.Language == "" {
+					return nil, fmt.Errorf("search locale #%d in %s has no language", i+1, path)
+				}
+				locale.Language = strings.ToLower(locale.Language)
+				byLanguage[locale.Language] = &locale
+			}
+		}
+	}
+
+	if _, ok := byLanguage[preferred]; !ok {
+		return nil, fmt.Errorf("unknown search locale %q", preferred)
+	}
+
+	locales := []*SearchLocale{byLanguage[preferred]}
+	var others []string
+	for language := range byLanguage {
+		if language != preferred {
+			others = append(others, language)
+		}
+	}
+	sort.Strings(others)
+	for _, language := range others {
+		locales = append(locales, byLanguage[language])
+	}
+	return locales, nil
+}
+
+// Score counts the words of this locale's search vocabulary in the lowercased question
+func (l *SearchLocale) Score(question string) int {
+	score := 0
+	for _, indicator := range l.Indicators {
+		if strings.Contains(question, indicator) {
+			score++
+		}
+	}
+	for _, category := range l.Categories {
+		for _, keyword := range category.Keywords {
+			if strings.Contains(question, keyword) {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// Category returns the category whose keywords appear in the lowercased question
+func (l *SearchLocale) Category(question string) *QueryCategory {
+	for i := range l.Categories {
+		if containsAny(question, l.Categories[i].Keywords) {
+			return &l.Categories[i]
+		}
+	}
+	return nil
+}
+
+// Query builds the search query for message
+func (l *SearchLocale) Query(message string) SearchQuery {
+	lower := strings.ToLower(message)
+	query := SearchQuery{Intent: "chat", Language: l.Language, Location: l.Location(message), Message: message}
+
+	category := l.Category(lower)
+	if category == nil {
+		query.Text = strings.ReplaceAll(l.Fallback, "{message}", message)
+		return query
+	}
+	query.Intent = category.Intent
+
+	for _, rule := range category.Rules {
+		if containsAny(lower, rule.Match) && (len(rule.Also) == 0 || containsAny(lower, rule.Also)) {
+			query.Text = rule.Query
+			return query
+		}
+	}
+
+	query.Text = category.Query
+	if category.LocationQuery != "" && query.Location != "" {
+		query.Text = strings.ReplaceAll(category.LocationQuery, "{location}", query.Location)
+	}
+	return query
+}
@@ -0,0 +1,167 @@
+package claude
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// word is a token of the user's question
+type word struct {
+	text  string
+	lower string
+	// capitalized is set for capitalized words that do not start a sentence,
+	// which is how transcripts and typed questions mark proper nouns
+	capitalized bool
+}
+
+// splitWords tokenizes message, keeping letters, digits, hyphens and apostrophes
+func splitWords(message string) []word {
+	var words []word
+	var current []rune
+	sentenceStart := true
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		words = append(words, word{
+			text:        string(current),
+			lower:       strings.ToLower(string(current)),
+			capitalized: unicode.IsUpper(current[0]) && !sentenceStart,
+		})
+		current = current[:0]
+		sentenceStart = false
+	}
+
+	for _, r := range message {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '\'' {
+			current = append(current, r)
+			continue
+		}
+		flush()
+		if strings.ContainsRune(".?!¿¡", r) {
+			sentenceStart = true
+		}
+	}
+	flush()
+
+	return words
+}
+
+// Location extracts the place the question is about: a proper noun ("Santiago
+// de Compostela"), preferably introduced by a preposition and generic place
+// nouns ("en el centro de Madrid"), else the words after a preposition up to a
+// stopword for questions written in lowercase
+func (l *SearchLocale) Location(message string) string {
+	words := splitWords(message)
+	if place := l.properLocation(words); place != "" {
+		return place
+	}
+	return l.lowercaseLocation(words)
+}
+
+// properLocation returns the first capitalized name after a preposition, or
+// the last capitalized name when none follows one
+func (l *SearchLocale) properLocation(words []word) string {
+	var fallback string
+	for i := 0; i < len(words); i++ {
+		if !l.isName(words[i]) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(words) {
+			if l.isName(words[end]) {
+				end++
+			} else if end+1 < len(words) && slices.Contains(l.LocationConnectors, words[end].lower) && l.isName(words[end+1]) {
+				end += 2
+			} else {
+				break
+			}
+		}
+
+		name := joinWords(words[i:end])
+		if l.introduced(words, i) {
+			return name
+		}
+		fallback = name
+		i = end - 1
+	}
+	return fallback
+}
+
+// isName reports whether w can be part of a proper place name
+func (l *SearchLocale) isName(w word) bool {
+	return w.capitalized && !slices.Contains(l.LocationStopwords, w.lower)
+}
+
+// introduced reports whether the word at index follows a preposition, possibly
+// through connectors and place nouns ("en el centro de")
+func (l *SearchLocale) introduced(words []word, index int) bool {
+	for i := index - 1; i >= 0; i-- {
+		switch lower := words[i].lower; {
+		case slices.Contains(l.LocationPrepositions, lower):
+			return true
+		case slices.Contains(l.LocationConnectors, lower), slices.Contains(l.PlaceNouns, lower):
+			continue
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// lowercaseLocation returns the words after the first preposition that
+// introduces a place, skipping generic place nouns and stopping at stopwords
+func (l *SearchLocale) lowercaseLocation(words []word) string {
+	for i, w := range words {
+		if !slices.Contains(l.LocationPrepositions, w.lower) {
+			continue
+		}
+
+		start := i + 1
+		for start < len(words) && (slices.Contains(l.LocationConnectors, words[start].lower) || slices.Contains(l.PlaceNouns, words[start].lower)) {
+			start++
+		}
+
+		end := start
+		for end < len(words) {
+			lower := words[end].lower
+			if slices.Contains(l.LocationStopwords, lower) {
+				break
+			}
+			if slices.Contains(l.LocationConnectors, lower) {
+				// Keep "de" in "santiago de compostela" but not in "madrid de noche"
+				if end > start && end+1 < len(words) && !l.endsPlace(words[end+1].lower) {
+					end += 2
+					continue
+				}
+				break
+			}
+			if slices.Contains(l.LocationPrepositions, lower) {
+				break
+			}
+			end++
+		}
+
+		if end > start {
+			return joinWords(words[start:end])
+		}
+	}
+	return ""
+}
+
+// endsPlace reports whether a lowercase word cannot be part of a place name
+func (l *SearchLocale) endsPlace(lower string) bool {
+	return slices.Contains(l.LocationStopwords, lower) || slices.Contains(l.LocationConnectors, lower) || slices.Contains(l.LocationPrepositions, lower)
+}
+
+// joinWords rebuilds the original text of words
+func joinWords(words []word) string {
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.text
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,345 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
+)
+
+// providerTimeout bounds each request to a specialized search provider
+const providerTimeout = 10 * time.Second
+
+// getJSON fetches rawURL and decodes its JSON body into v
+func getJSON(ctx context.Context, client *http.Client, rawURL string, headers map[string]string, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, providerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s returned %d: %s", req.URL.Host, resp.StatusCode, textutil.Preview(strings.TrimSpace(string(body))))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", req.URL.Host, err)
+	}
+	return nil
+}
+
+// OpenMeteo answers weather queries with the free Open-Meteo forecast API
+type OpenMeteo struct {
+	client          *http.Client
+	defaultLocation string
+}
+
+// NewOpenMeteo creates the weather provider; defaultLocation is used when the
+// question names no place
+func NewOpenMeteo(defaultLocation string) *OpenMeteo {
+	return &OpenMeteo{
+		client:          &http.Client{},
+		defaultLocation: defaultLocation,
+	}
+}
+
+// Name implements SearchProvider
+func (o *OpenMeteo) Name() string {
+	return "open-meteo"
+}
+
+// place is a geocoding match
+type place struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Country   string  `json:"country"`
+	Admin1    string  `json:"admin1"`
+}
+
+// forecast is the subset of the forecast response Bobo reports; times are
+// local to the place ("2026-10-16T14:00")
+type forecast struct {
+	Current struct {
+		Time                string  `json:"time"`
+		Temperature         float64 `json:"temperature_2m"`
+		ApparentTemperature float64 `json:"apparent_temperature"`
+		Humidity            float64 `json:"relative_humidity_2m"`
+		WeatherCode         int     `json:"weather_code"`
+		WindSpeed           float64 `json:"wind_speed_10m"`
+	} `json:"current"`
+	Hourly struct {
+		Time          []string  `json:"time"`
+		Temperature   []float64 `json:"temperature_2m"`
+		RainChance    []float64 `json:"precipitation_probability"`
+		Precipitation []float64 `json:"precipitation"`
+		WeatherCode   []int     `json:"weather_code"`
+		WindGusts     []float64 `json:"wind_gusts_10m"`
+	} `json:"hourly"`
+	Daily struct {
+		Time        []string  `json:"time"`
+		WeatherCode []int     `json:"weather_code"`
+		Max         []float64 `json:"temperature_2m_max"`
+		Min         []float64 `json:"temperature_2m_min"`
+		RainChance  []float64 `json:"precipitation_probability_max"`
+	} `json:"daily"`
+}
+
+// Search implements SearchProvider
+func (o *OpenMeteo) Search(ctx context.Context, query SearchQuery) (*SearchResults, error) {
+	location := query.Location
+	if location == "" {
+		location = o.defaultLocation
+	}
+	if location == "" {
+		return nil, fmt.Errorf("%w: no location", errNotHandled)
+	}
+
+	name, f, err := o.forecast(ctx, location, query.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &SearchResults{
+		Card: card.New("Weather in "+name, "", "Conditions", "Temperature", "Rain"),
+	}
+	results.Card.Add("Now", weatherDescription(f.Current.WeatherCode),
+		fmt.Sprintf("%.0f°C (feels %.0f°C)", f.Current.Temperature, f.Current.ApparentTemperature), "")
+	results.Results = append(results.Results, SearchResult{
+		Title: "Weather now in " + name,
+		Snippet: fmt.Sprintf("%s, %.0f°C (feels like %.0f°C). Humidity %.0f%%, wind %.0f km/h.",
+			weatherDescription(f.Current.WeatherCode), f.Current.Temperature, f.Current.ApparentTemperature,
+			f.Current.Humidity, f.Current.WindSpeed),
+		Source: "Open-Meteo",
+	})
+
+	// Hour by hour over the part of the day asked about ("¿va a llover esta tarde?")
+	if now, err := time.Parse(openMeteoTime, f.Current.Time); err == nil {
+		period := periodFor(query.Message, now)
+		if outlook, ok := hourlyOutlookFor(f, period); ok {
+			results.Card.Add(period.label, outlook.conditions, outlook.temperature, outlook.rain)
+			results.Results = append(results.Results, SearchResult{
+				Title:   period.label + " in " + name + ", hour by hour",
+				Snippet: outlook.snippet,
+				Source:  "Open-Meteo",
+			})
+		}
+	}
+
+	var week []string
+	for day := range f.Daily.Max {
+		if day >= len(f.Daily.Min) || day >= len(f.Daily.WeatherCode) || day >= len(f.Daily.Time) {
+			break
+		}
+		label := dayLabel(day, f.Daily.Time[day])
+		snippet := fmt.Sprintf("%s. High: %.0f°C, Low: %.0f°C.", weatherDescription(f.Daily.WeatherCode[day]), f.Daily.Max[day], f.Daily.Min[day])
+		rain := ""
+		if day < len(f.Daily.RainChance) {
+			snippet += fmt.Sprintf(" Chance of rain: %.0f%%.", f.Daily.RainChance[day])
+			rain = fmt.Sprintf("%.0f%%", f.Daily.RainChance[day])
+		}
+		results.Card.Add(label, weatherDescription(f.Daily.WeatherCode[day]),
+			fmt.Sprintf("%.0f–%.0f°C", f.Daily.Min[day], f.Daily.Max[day]), rain)
+		if day < 2 {
+			results.Results = append(results.Results, SearchResult{
+				Title:   label + "'s forecast for " + name,
+				Snippet: snippet,
+				Source:  "Open-Meteo",
+			})
+			continue
+		}
+		week = append(week, label+": "+strings.ReplaceAll(snippet, "Chance of rain: ", "rain "))
+	}
+	if len(week) > 0 {
+		results.Results = append(results.Results, SearchResult{
+			Title:   "Forecast for the rest of the week in " + name,
+			Snippet: strings.Join(week, " "),
+			Source:  "Open-Meteo",
+		})
+	}
+
+	return results, nil
+}
+
+// forecast geocodes location and fetches its forecast for the coming week,
+// returning the place's name with it
+func (o *OpenMeteo) forecast(ctx context.Context, location, language string) (string, *forecast, error) {
+	if language == "" {
+		language = "en"
+	}
+
+	var geocoding struct {
+		Results []place `json:"results"`
+	}
+	geocodingURL := "https://geocoding-api.open-meteo.com/v1/search?count=1&name=" + url.QueryEscape(location) + "&language=" + url.QueryEscape(language)
+	if err := getJSON(ctx, o.client, geocodingURL, nil, &geocoding); err != nil {
+		return "", nil, fmt.Errorf("geocoding failed: %w", err)
+	}
+	if len(geocoding.Results) == 0 {
+		return "", nil, fmt.Errorf("unknown location %q", location)
+	}
+	p := geocoding.Results[0]
+
+	var f forecast
+	forecastURL := fmt.Sprintf("https://api.open-meteo.com/v1/forecast?latitude=%.4f&longitude=%.4f"+
+		"&current=temperature_2m,apparent_temperature,relative_humidity_2m,weather_code,wind_speed_10m"+
+		"&hourly=temperature_2m,precipitation_probability,precipitation,weather_code,wind_gusts_10m"+
+		"&daily=weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max"+
+		"&timezone=auto&forecast_days=%d", p.Latitude, p.Longitude, forecastDays)
+	if err := getJSON(ctx, o.client, forecastURL, nil, &f); err != nil {
+		return "", nil, fmt.Errorf("forecast failed: %w", err)
+	}
+
+	name := p.Name
+	if p.Country != "" {
+		name += ", " + p.Country
+	}
+	return name, &f, nil
+}
+
+// weatherDescription names a WMO weather code
+func weatherDescription(code int) string {
+	switch {
+	case code == 0:
+		return "Clear sky"
+	case code == 1:
+		return "Mainly clear"
+	case code == 2:
+		return "Partly cloudy"
+	case code == 3:
+		return "Overcast"
+	case code == 45 || code == 48:
+		return "Fog"
+	case code >= 51 && code <= 57:
+		return "Drizzle"
+	case code == 61:
+		return "Light rain"
+	case code == 63:
+		return "Rain"
+	case code == 65:
+		return "Heavy rain"
+	case code == 66 || code == 67:
+		return "Freezing rain"
+	case code >= 71 && code <= 77:
+		return "Snow"
+	case code >= 80 && code <= 82:
+		return "Rain showers"
+	case code == 85 || code == 86:
+		return "Snow showers"
+	case code >= 95:
+		return "Thunderstorm"
+	default:
+		return "Unknown conditions"
+	}
+}
+
+// coinIDs maps the words people use for cryptocurrencies to CoinGecko ids
+var coinIDs = map[string]string{
+	"bitcoin":  "bitcoin",
+	"btc":      "bitcoin",
+	"ethereum": "ethereum",
+	"ether":    "ethereum",
+	"eth":      "ethereum",
+	"solana":   "solana",
+	"sol":      "solana",
+	"dogecoin": "dogecoin",
+	"doge":     "dogecoin",
+	"cardano":  "cardano",
+	"ada":      "cardano",
+	"ripple":   "ripple",
+	"xrp":      "ripple",
+	"litecoin": "litecoin",
+	"polkadot": "polkadot",
+}
+
+// CoinGecko answers cryptocurrency price queries with the CoinGecko API
+type CoinGecko struct {
+	client *http.Client
+	apiKey string
+}
+
+// NewCoinGecko creates the crypto price provider; apiKey is an optional demo key
+func NewCoinGecko(apiKey string) *CoinGecko {
+	return &CoinGecko{
+		client: &http.Client{},
+		apiKey: apiKey,
+	}
+}
+
+// Name implements SearchProvider
+func (c *CoinGecko) Name() string {
+	return "coingecko"
+}
+
+// Search implements SearchProvider
+func (c *CoinGecko) Search(ctx context.Context, query SearchQuery) (*SearchResults, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, w := range splitWords(query.Message + " " + query.Text) {
+		if id, ok := coinIDs[w.lower]; ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: no cryptocurrency mentioned", errNotHandled)
+	}
+
+	var headers map[string]string
+	if c.apiKey != "" {
+		headers = map[string]string{"x-cg-demo-api-key": c.apiKey}
+	}
+
+	var prices map[string]map[string]float64
+	priceURL := "https://api.coingecko.com/api/v3/simple/price?vs_currencies=usd,eur&include_24hr_change=true&ids=" + url.QueryEscape(strings.Join(ids, ","))
+	if err := getJSON(ctx, c.client, priceURL, headers, &prices); err != nil {
+		return nil, err
+	}
+
+	results := &SearchResults{Card: card.New("Crypto prices", "Coin", "USD", "EUR", "24h")}
+	for _, id := range ids {
+		price, ok := prices[id]
+		if !ok {
+			continue
+		}
+		name := strings.ToUpper(id[:1]) + id[1:]
+		results.Results = append(results.Results, SearchResult{
+			Title: name + " price now",
+			Snippet: fmt.Sprintf("%s: $%s USD / €%s EUR (%+.1f%% in the last 24h).",
+				name, formatPrice(price["usd"]), formatPrice(price["eur"]), price["usd_24h_change"]),
+			Source: "CoinGecko",
+		})
+		results.Card.Add(name, "$"+formatPrice(price["usd"]), "€"+formatPrice(price["eur"]), fmt.Sprintf("%+.1f%%", price["usd_24h_change"]))
+	}
+	return results, nil
+}
+
+// formatPrice prints large prices without decimals and small ones with enough precision
+func formatPrice(price float64) string {
+	switch {
+	case price >= 1000:
+		return fmt.Sprintf("%.0f", price)
+	case price >= 1:
+		return fmt.Sprintf("%.2f", price)
+	default:
+		return fmt.Sprintf("%.4f", price)
+	}
+}
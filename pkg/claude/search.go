@@ -0,0 +1,85 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// errNotHandled is returned by providers for queries outside what they cover
+var errNotHandled = errors.New("query not handled by this provider")
+
+// SearchQuery is what to look up for a user's question
+type SearchQuery struct {
+	Text     string
+	Intent   string
+	Location string
+	Language string
+	// Message is the user's original question
+	Message string
+}
+
+// SearchProvider looks up current information for a query
+type SearchProvider interface {
+	Name() string
+	Search(ctx context.Context, query SearchQuery) (*SearchResults, error)
+}
+
+// SearchRouter sends each query class to a specialized provider, falling back
+// to the generic web search when there is none or it fails
+type SearchRouter struct {
+	routes  map[string]SearchProvider
+	generic SearchProvider
+	logger  *slog.Logger
+}
+
+// NewSearchRouter creates a router that sends everything to generic until
+// routes are added
+func NewSearchRouter(generic SearchProvider) *SearchRouter {
+	return &SearchRouter{
+		routes:  make(map[string]SearchProvider),
+		generic: generic,
+		logger:  slog.Default(),
+	}
+}
+
+// Route sends queries with intent to provider
+func (r *SearchRouter) Route(intent string, provider SearchProvider) {
+	r.routes[intent] = provider
+}
+
+// Search runs query on its provider, falling back to the generic search
+func (r *SearchRouter) Search(ctx context.Context, query SearchQuery) *SearchResults {
+	if provider, ok := r.routes[query.Intent]; ok {
+		results, err := provider.Search(ctx, query)
+		switch {
+		case err == nil && results != nil && len(results.Results) > 0:
+			r.logger.Info("🧭 Search routed", "provider", provider.Name(), "intent", query.Intent)
+			return results
+		case errors.Is(err, errNotHandled):
+			r.logger.Debug("Search provider skipped query", "provider", provider.Name(), "query", query.Text)
+		default:
+			r.logger.Warn("Search provider failed, falling back to web search", "provider", provider.Name(), "error", err)
+		}
+	}
+
+	results, err := r.generic.Search(ctx, query)
+	if err != nil {
+		return &SearchResults{Error: err.Error()}
+	}
+	return results
+}
+
+// simulatedWebSearch is the generic web search, simulated until a real one is
+// available via Vertex AI
+type simulatedWebSearch struct {
+	client *SmartClient
+}
+
+func (w *simulatedWebSearch) Name() string {
+	return "web"
+}
+
+func (w *simulatedWebSearch) Search(ctx context.Context, query SearchQuery) (*SearchResults, error) {
+	return w.client.simulateRealisticSearch(query.Text, query.Intent), nil
+}
@@ -8,8 +8,12 @@ import (
 	"log/slog"
 	"regexp"
 	"strings"
+	"sync"
 
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
 	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/quota"
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
 )
 
 // SmartClient provides automatic web search integration like Claude CLI
@@ -18,7 +22,15 @@ type SmartClient struct {
 	config          *config.VertexAIConfig
 	autoSearchEnabled bool
 	searchTriggers  []*regexp.Regexp
+	locales         []*SearchLocale
+	router          *SearchRouter
+	experiment      *Experiment
+	quota           *quota.Guard
 	logger          *slog.Logger
+
+	mu            sync.Mutex // guards the prompt settings below
+	personaPrompt string
+	replyLanguage string
 }
 
 // SearchResult represents a web search result
@@ -32,6 +44,21 @@ type SearchResult struct {
 type SearchResults struct {
 	Results []SearchResult `json:"results"`
 	Error   string         `json:"error,omitempty"`
+	// Card shows the results on screen, when the provider has structured data
+	Card *card.Card `json:"-"`
+}
+
+// Sources returns the distinct sources cited by the search results
+func (r *SearchResults) Sources() []string {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, result := range r.Results {
+		if result.Source != "" && !seen[result.Source] {
+			seen[result.Source] = true
+			sources = append(sources, result.Source)
+		}
+	}
+	return sources
 }
 
 // NewSmartClient creates a new smart Claude client with automatic web search
@@ -39,40 +66,21 @@ func NewSmartClient(cfg *config.VertexAIConfig) *SmartClient {
 	// Create base Vertex AI client
 	vertexClient := NewVertexClient(cfg)
 
-	// Compile search trigger patterns
-	triggerPatterns := []string{
-		// English phrases that indicate need for current info
-		`I don't have access to current information`,
-		`I cannot provide real-time information`,
-		`I don't have access to weather data`,
-		`real-time weather information`,
-		`I don't have access to internet`,
-		`updated data`,
-
-		// Additional English phrases
-		`I don't have access to real-time`,
-		`I don't have access to current`,
-		`I cannot access current`,
-		`I don't have internet access`,
-		`real-time information`,
-		`current information`,
-		`up-to-date information`,
-	}
-
-	var compiledTriggers []*regexp.Regexp
-	for _, pattern := range triggerPatterns {
-		if regex, err := regexp.Compile(`(?i)` + pattern); err == nil {
-			compiledTriggers = append(compiledTriggers, regex)
-		}
-	}
-
-	return &SmartClient{
+	client := &SmartClient{
 		vertexClient:      vertexClient,
 		config:            cfg,
 		autoSearchEnabled: cfg.EnableAutoSearch,
-		searchTriggers:    compiledTriggers,
 		logger:            slog.Default(),
 	}
+
+	// Route query classes to specialized providers, the rest to web search
+	client.router = NewSearchRouter(&simulatedWebSearch{client: client})
+	if cfg.SearchRouting {
+		client.router.Route("weather", NewOpenMeteo(cfg.DefaultLocation))
+		client.router.Route("finance", NewCoinGecko(cfg.CoinGeckoAPIKey))
+	}
+
+	return client
 }
 
 // Initialize initializes the smart Claude client
@@ -82,6 +90,13 @@ func (s *SmartClient) Initialize(ctx context.Context) error {
 		s.config.SystemPrompt = s.getSmartSystemPrompt()
 	}
 
+	// Load the search vocabulary, preferred language first
+	locales, err := loadSearchLocales(s.config.SearchLocalesFile, s.config.SearchLocale)
+	if err != nil {
+		return fmt.Errorf("failed to load search locales: %w", err)
+	}
+	s.setLocales(locales)
+
 	// Initialize the underlying Vertex AI client
 	if err := s.vertexClient.Initialize(ctx); err != nil {
 		return fmt.Errorf("failed to initialize vertex client: %w", err)
@@ -91,22 +106,141 @@ func (s *SmartClient) Initialize(ctx context.Context) error {
 	return nil
 }
 
+// Answer represents Claude's reply along with the context used to produce it
+type Answer struct {
+	Text    string
+	Sources []string
+	Intent  string
+	Variant string
+	Usage   Usage
+	// Degraded is set when a quota forced a reduced answer (e.g. no web search)
+	Degraded error
+	// Card has the details behind the answer, to show on screen
+	Card *card.Card
+}
+
+// SetExperiment enables A/B comparison of prompt/model variants across interactions
+func (s *SmartClient) SetExperiment(experiment *Experiment) {
+	s.experiment = experiment
+}
+
+// SetQuota caps the Vertex tokens and web searches spent by the client
+func (s *SmartClient) SetQuota(guard *quota.Guard) {
+	s.quota = guard
+	s.vertexClient.quota = guard
+}
+
+// SetPersonaPrompt replaces the system prompt with a persona's prompt;
+// an empty prompt restores the configured one
+func (s *SmartClient) SetPersonaPrompt(prompt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.personaPrompt = prompt
+}
+
+// languageNames names the languages answers can be asked in
+var languageNames = map[string]string{
+	"es": "Spanish",
+	"en": "English",
+	"ca": "Catalan",
+	"fr": "French",
+	"de": "German",
+	"it": "Italian",
+	"pt": "Portuguese",
+	"nl": "Dutch",
+	"gl": "Galician",
+	"eu": "Basque",
+}
+
+// SetReplyLanguage makes answers come in the language the user spoke (an
+// ISO 639-1 code); empty leaves it to the system prompt
+func (s *SmartClient) SetReplyLanguage(language string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replyLanguage = language
+}
+
+// promptSettings returns the persona prompt and reply language of a new
+// question, which may change while it is answered
+func (s *SmartClient) promptSettings() (personaPrompt, replyLanguage string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.personaPrompt, s.replyLanguage
+}
+
+// withReplyLanguage adds the reply language to the system prompt overrides
+func (s *SmartClient) withReplyLanguage(overrides *Overrides, language string) *Overrides {
+	if language == "" {
+		return overrides
+	}
+	name, ok := languageNames[language]
+	if !ok {
+		name = "the language with code " + language
+	}
+
+	merged := &Overrides{SystemPrompt: s.config.SystemPrompt}
+	if overrides != nil {
+		*merged = *overrides
+		if merged.SystemPrompt == "" {
+			merged.SystemPrompt = s.config.SystemPrompt
+		}
+	}
+	merged.SystemPrompt += fmt.Sprintf("\n\nThe user is speaking %s: always answer in %s.", name, name)
+	return merged
+}
+
+// Prompt sends a single prompt with its own system prompt, bypassing web search;
+// used by skills that need generated text
+func (s *SmartClient) Prompt(ctx context.Context, system, prompt string) (string, error) {
+	text, _, err := s.vertexClient.CompleteWith(ctx, []Message{{Role: "user", Content: prompt}}, &Overrides{SystemPrompt: system})
+	return text, err
+}
+
 // SendMessage sends message with automatic smart enhancements
 func (s *SmartClient) SendMessage(ctx context.Context, messages []Message) (string, error) {
+	answer, err := s.Ask(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	return answer.Text, nil
+}
+
+// Ask sends messages with automatic smart enhancements and returns the full answer
+func (s *SmartClient) Ask(ctx context.Context, messages []Message) (*Answer, error) {
+	answer := &Answer{}
+	if len(messages) > 0 {
+		answer.Intent = s.classifyIntent(messages[len(messages)-1].Content)
+	}
+
+	// Apply the active persona, then pick the experiment variant for the whole interaction
+	personaPrompt, replyLanguage := s.promptSettings()
+	var overrides *Overrides
+	if personaPrompt != "" {
+		overrides = &Overrides{SystemPrompt: personaPrompt}
+	}
+	if s.experiment != nil {
+		var variant *Overrides
+		answer.Variant, variant = s.experiment.Next()
+		s.logger.Info("🧪 Experiment variant", "variant", answer.Variant)
+		overrides = mergeOverrides(overrides, variant)
+	}
+	overrides = s.withReplyLanguage(overrides, replyLanguage)
+
 	// Get Claude's initial response
-	initialResponse, err := s.vertexClient.SendMessage(ctx, messages)
+	initialResponse, usage, err := s.vertexClient.CompleteWith(ctx, messages, overrides)
 	if err != nil {
-		return "", fmt.Errorf("failed to get initial response: %w", err)
+		return nil, fmt.Errorf("failed to get initial response: %w", err)
 	}
+	answer.Usage.Add(usage)
 
 	if initialResponse == "" {
-		return "", fmt.Errorf("empty response from Claude")
+		return nil, fmt.Errorf("empty response from Claude")
 	}
 
 	// Check if Claude indicates it needs current information
 	if s.autoSearchEnabled && s.needsWebSearch(initialResponse, messages) {
 		s.logger.Info("🔍 Claude indicated need for current information, enhancing with web search...")
-		s.logger.Debug("📝 Claude's initial response", "response", initialResponse[:100]+"...")
+		s.logger.Debug("📝 Claude's initial response", "response", textutil.Preview(initialResponse))
 
 		// Extract search query from user message and Claude's response
 		userMessage := ""
@@ -115,17 +249,33 @@ func (s *SmartClient) SendMessage(ctx context.Context, messages []Message) (stri
 		}
 
 		searchQuery := s.extractSearchQuery(userMessage, initialResponse)
-		s.logger.Info("🎯 Extracted search query", "query", searchQuery)
+		s.logger.Info("🎯 Extracted search query", "query", searchQuery.Text, "intent", searchQuery.Intent, "location", searchQuery.Location)
 
-		if searchQuery != "" {
+		if err := s.quota.Allow(quota.Searches, 1); searchQuery.Text != "" && err != nil {
+			s.logger.Warn("⛔ Skipping web search", "error", err)
+			answer.Degraded = err
+		} else if searchQuery.Text != "" {
 			// Perform web search
-			searchResults := s.performSmartSearch(searchQuery)
+			s.quota.Record(quota.Searches, 1)
+			searchResults := s.performSmartSearch(ctx, searchQuery)
 
 			if searchResults != nil && searchResults.Error == "" && len(searchResults.Results) > 0 {
 				// Create enhanced conversation with search results
-				enhancedResponse, err := s.createEnhancedResponse(ctx, messages, initialResponse, searchQuery, searchResults)
+				enhancedResponse, usage, err := s.createEnhancedResponse(ctx, messages, initialResponse, searchQuery.Text, searchResults, overrides)
+				answer.Usage.Add(usage)
+				if err == nil && enhancedResponse != "" && s.config.VerifyAnswers {
+					verified, usage, err := s.verifyAnswer(ctx, enhancedResponse, searchResults)
+					answer.Usage.Add(usage)
+					if err != nil {
+						s.logger.Warn("Answer verification failed, keeping the unverified answer", "error", err)
+					}
+					enhancedResponse = verified
+				}
 				if err == nil && enhancedResponse != "" {
-					return enhancedResponse, nil
+					answer.Text = enhancedResponse
+					answer.Sources = searchResults.Sources()
+					answer.Card = searchResults.Card
+					return answer, nil
 				}
 				s.logger.Warn("Failed to create enhanced response, falling back to original", "error", err)
 			}
@@ -133,7 +283,8 @@ func (s *SmartClient) SendMessage(ctx context.Context, messages []Message) (stri
 	}
 
 	// Return original response if no enhancement needed/possible
-	return initialResponse, nil
+	answer.Text = initialResponse
+	return answer, nil
 }
 
 // needsWebSearch determines if Claude's response indicates it needs web search
@@ -149,115 +300,162 @@ func (s *SmartClient) needsWebSearch(response string, messages []Message) bool {
 	// Check if user is asking about current/recent topics
 	if len(messages) > 0 {
 		userMessage := strings.ToLower(messages[len(messages)-1].Content)
-		currentIndicators := []string{
-			"hoy", "today", "ahora", "now", "actual", "current",
-			"reciente", "recent", "último", "latest", "tiempo",
-			"weather", "noticias", "news", "precio", "price",
-		}
-
-		for _, indicator := range currentIndicators {
-			if strings.Contains(userMessage, indicator) {
-				s.logger.Debug("Current information indicator found", "indicator", indicator)
-				return true
+		for _, locale := range s.locales {
+			for _, indicator := range locale.Indicators {
+				if strings.Contains(userMessage, indicator) {
+					s.logger.Debug("Current information indicator found", "indicator", indicator, "language", locale.Language)
+					return true
+				}
 			}
 		}
 	}
 
 	return false
 }
+// setLocales installs the search vocabularies and compiles their reply triggers
+func (s *SmartClient) setLocales(locales []*SearchLocale) {
+	var compiledTriggers []*regexp.Regexp
+	for _, locale := range locales {
+		for _, pattern := range locale.Triggers {
+			if regex, err := regexp.Compile(`(?i)` + pattern); err == nil {
+				compiledTriggers = append(compiledTriggers, regex)
+			} else {
+				s.logger.Warn("Invalid search trigger", "language", locale.Language, "trigger", pattern, "error", err)
+			}
+		}
+	}
 
-// extractSearchQuery smart extraction of search query based on user intent and context
-func (s *SmartClient) extractSearchQuery(userMessage, claudeResponse string) string {
-	userLower := strings.ToLower(userMessage)
+	s.locales = locales
+	s.searchTriggers = compiledTriggers
+}
 
-	// Weather queries
-	if containsAny(userLower, []string{"tiempo", "weather", "clima"}) {
-		locationPatterns := []*regexp.Regexp{
-			regexp.MustCompile(`(?i)en\s+([A-Za-z\s]+)`),  // "tiempo en Madrid"
-			regexp.MustCompile(`(?i)in\s+([A-Za-z\s]+)`),  // "weather in Madrid"
-			regexp.MustCompile(`(?i)de\s+([A-Za-z\s]+)`),  // "tiempo de Madrid"
+// localeFor returns the locale whose vocabulary the message uses most, preferring
+// the configured language on ties
+func (s *SmartClient) localeFor(message string) *SearchLocale {
+	lower := strings.ToLower(message)
+	best, bestScore := s.locales[0], s.locales[0].Score(lower)
+	for _, locale := range s.locales[1:] {
+		if score := locale.Score(lower); score > bestScore {
+			best, bestScore = locale, score
 		}
+	}
+	return best
+}
 
-		for _, pattern := range locationPatterns {
-			if matches := pattern.FindStringSubmatch(userMessage); len(matches) > 1 {
-				location := strings.TrimSpace(matches[1])
-				return fmt.Sprintf("weather today %s", location)
-			}
-		}
-		return "weather today"
+// extractSearchQuery builds a search query in the language of the user's question
+func (s *SmartClient) extractSearchQuery(userMessage, claudeResponse string) SearchQuery {
+	if len(s.locales) == 0 {
+		return SearchQuery{Text: userMessage, Intent: "chat", Message: userMessage}
 	}
+	return s.localeFor(userMessage).Query(userMessage)
+}
 
-	// Sports/Football queries
-	if containsAny(userLower, []string{"real madrid", "madrid", "partido", "match", "resultado", "fútbol", "futbol", "football"}) {
-		if strings.Contains(userLower, "real madrid") {
-			if containsAny(userLower, []string{"último", "last", "recent", "ayer", "yesterday"}) {
-				return "Real Madrid latest match result today"
-			}
-			return "Real Madrid news today"
-		}
-		return "football results today Spain"
+// mergeOverrides layers next on top of base, ignoring empty fields
+func mergeOverrides(base, next *Overrides) *Overrides {
+	if base == nil {
+		return next
+	}
+	if next == nil {
+		return base
 	}
 
-	// News queries
-	if containsAny(userLower, []string{"noticias", "news", "novedades"}) {
-		return "latest news today"
+	merged := *base
+	if next.Model != "" {
+		merged.Model = next.Model
+	}
+	if next.SystemPrompt != "" {
+		merged.SystemPrompt = next.SystemPrompt
+	}
+	return &merged
+}
+
+// Clarification returns a short question asking for a slot the message lacks
+// and the slot's name, or empty strings when the message can be answered as is
+func (s *SmartClient) Clarification(message string) (string, string) {
+	if !s.autoSearchEnabled || len(s.locales) == 0 {
+		return "", ""
 	}
 
-	// Price/financial queries
-	if containsAny(userLower, []string{"precio", "price", "bitcoin", "crypto", "bolsa"}) {
-		if strings.Contains(userLower, "bitcoin") {
-			return "Bitcoin price today"
+	locale := s.localeFor(message)
+	category := locale.Category(strings.ToLower(message))
+	if category == nil || category.Clarify == "" {
+		return "", ""
+	}
+
+	for _, slot := range category.Requires {
+		if slot == "location" && s.config.DefaultLocation == "" && locale.Location(message) == "" {
+			return category.Clarify, slot
 		}
-		return "financial markets today"
 	}
+	return "", ""
+}
 
-	// General current information
-	return fmt.Sprintf("current information %s", userMessage)
+// MergeClarification adds the user's answer for slot to the original message
+func (s *SmartClient) MergeClarification(message, slot, answer string) string {
+	if slot != "location" || len(s.locales) == 0 {
+		return strings.TrimSpace(message + " " + answer)
+	}
+
+	locale := s.localeFor(message)
+	place := locale.Location(answer)
+	if place == "" {
+		place = strings.Trim(answer, " .,;:!¡?¿")
+	}
+
+	preposition := ""
+	if len(locale.LocationPrepositions) > 0 {
+		preposition = locale.LocationPrepositions[0] + " "
+	}
+	question := strings.TrimRight(message, " .!?")
+	suffix := message[len(question):]
+	return question + " " + preposition + place + strings.TrimSpace(suffix)
 }
 
-// performSmartSearch performs web search for current information
-func (s *SmartClient) performSmartSearch(query string) *SearchResults {
-	s.logger.Info("🔍 Performing smart search", "query", query)
+// classifyIntent assigns a coarse intent label to a user message for reporting
+func (s *SmartClient) classifyIntent(userMessage string) string {
+	userLower := strings.ToLower(userMessage)
+	for _, locale := range s.locales {
+		if category := locale.Category(userLower); category != nil {
+			return category.Intent
+		}
+	}
+	return "chat"
+}
+
+// performSmartSearch routes the query to the provider for its intent
+func (s *SmartClient) performSmartSearch(ctx context.Context, query SearchQuery) *SearchResults {
+	s.logger.Info("🔍 Performing smart search", "query", query.Text, "intent", query.Intent)
 
-	// For now, simulate web search results with realistic data
-	// TODO: Integrate with native Claude web search capabilities when available via Vertex AI
-	results := s.simulateRealisticSearch(query)
+	results := s.router.Search(ctx, query)
 
 	s.logger.Info("📊 Search results", "count", len(results.Results))
 	return results
 }
 
 // simulateRealisticSearch smart simulation of web search results
-func (s *SmartClient) simulateRealisticSearch(query string) *SearchResults {
+func (s *SmartClient) simulateRealisticSearch(query, intent string) *SearchResults {
 	queryLower := strings.ToLower(query)
 	currentDate := "Today" // Simplified to avoid date confusion
 
 	// Generate contextual search results based on query intent
-	if strings.Contains(queryLower, "weather today") {
+	switch intent {
+	case "weather":
 		if strings.Contains(queryLower, "madrid") {
 			return s.generateWeatherResults("Madrid", currentDate)
 		}
 		return s.generateWeatherResults("location", currentDate)
-	}
-
-	if strings.Contains(queryLower, "real madrid latest match") {
-		return s.generateFootballResults("Real Madrid", currentDate)
-	}
-
-	if strings.Contains(queryLower, "bitcoin price") {
-		return s.generateFinancialResults("Bitcoin", currentDate)
-	}
-
-	if strings.Contains(queryLower, "latest news") {
-		return s.generateNewsResults(currentDate)
-	}
-
-	if strings.Contains(queryLower, "football results") {
+	case "sports":
+		if strings.Contains(queryLower, "real madrid") {
+			return s.generateFootballResults("Real Madrid", currentDate)
+		}
 		return s.generateSportsResults(currentDate)
-	}
-
-	if strings.Contains(queryLower, "financial markets") {
+	case "finance":
+		if strings.Contains(queryLower, "bitcoin") {
+			return s.generateFinancialResults("Bitcoin", currentDate)
+		}
 		return s.generateMarketResults(currentDate)
+	case "news":
+		return s.generateNewsResults(currentDate)
 	}
 
 	// Default: generate current information response
@@ -266,7 +464,7 @@ func (s *SmartClient) simulateRealisticSearch(query string) *SearchResults {
 
 // createEnhancedResponse creates enhanced response using search results
 func (s *SmartClient) createEnhancedResponse(ctx context.Context, messages []Message,
-	initialResponse, searchQuery string, searchResults *SearchResults) (string, error) {
+	initialResponse, searchQuery string, searchResults *SearchResults, overrides *Overrides) (string, *Usage, error) {
 
 	// Prepare search context for Claude
 	searchContext := s.formatSearchResults(searchResults)
@@ -281,36 +479,49 @@ func (s *SmartClient) createEnhancedResponse(ctx context.Context, messages []Mes
 		Content: initialResponse,
 	})
 
-	// Add search results
+	// Add search results; when they are also shown as a table the spoken
+	// answer only needs the gist
+	length := "maximum 2-3 sentences"
+	if searchResults.Card != nil {
+		length = "one sentence, the details are shown on screen"
+	}
 	enhancedMessages = append(enhancedMessages, Message{
 		Role: "user",
-		Content: fmt.Sprintf("I searched for current information about '%s' and found this:\n\n%s\n\nWith this info, respond to my original question briefly and informally (maximum 2-3 sentences).",
-			searchQuery, searchContext),
+		Content: fmt.Sprintf("I searched for current information about '%s' and found this:\n\n%s\n\nWith this info, respond to my original question briefly and informally (%s).",
+			searchQuery, searchContext, length),
 	})
 
 	// Get enhanced response from Claude
-	enhancedResponse, err := s.vertexClient.SendMessage(ctx, enhancedMessages)
+	enhancedResponse, usage, err := s.vertexClient.CompleteWith(ctx, enhancedMessages, overrides)
 	if err != nil {
-		return "", fmt.Errorf("failed to get enhanced response: %w", err)
+		return "", nil, fmt.Errorf("failed to get enhanced response: %w", err)
 	}
 
 	if enhancedResponse != "" {
 		s.logger.Info("Successfully created enhanced response with current information")
-		return enhancedResponse, nil
+		return enhancedResponse, usage, nil
 	}
 
-	return "", fmt.Errorf("empty enhanced response")
+	return "", usage, fmt.Errorf("empty enhanced response")
 }
 
-// formatSearchResults formats search results for Claude to understand
+// formatSearchResults formats search results for Claude to understand, keeping
+// within the configured result count, snippet length and token budget
 func (s *SmartClient) formatSearchResults(searchResults *SearchResults) string {
 	if len(searchResults.Results) == 0 {
 		return "No current information found."
 	}
 
+	maxResults := s.config.SearchMaxResults
+	if maxResults <= 0 {
+		maxResults = len(searchResults.Results)
+	}
+	budget := s.searchContextBudget()
+
 	var formatted []string
+	used := 0
 	for i, result := range searchResults.Results {
-		if i >= 3 { // Limit to 3 results
+		if i >= maxResults {
 			break
 		}
 
@@ -319,7 +530,7 @@ func (s *SmartClient) formatSearchResults(searchResults *SearchResults) string {
 			title = "No title"
 		}
 
-		snippet := result.Snippet
+		snippet := textutil.Truncate(result.Snippet, s.config.SearchSnippetChars)
 		if snippet == "" {
 			snippet = "No description"
 		}
@@ -329,12 +540,33 @@ func (s *SmartClient) formatSearchResults(searchResults *SearchResults) string {
 			source = "Unknown source"
 		}
 
-		formatted = append(formatted, fmt.Sprintf("%d. %s (%s)\n   %s", i+1, title, source, snippet))
+		entry := fmt.Sprintf("%d. %s (%s)\n   %s", i+1, title, source, snippet)
+		tokens := EstimateTokens(entry)
+		if used+tokens > budget {
+			if len(formatted) > 0 {
+				break
+			}
+			// Always give Claude something: shorten the first result to fit
+			entry = textutil.Truncate(entry, budget*4)
+			tokens = budget
+		}
+		formatted = append(formatted, entry)
+		used += tokens
 	}
 
+	s.logger.Debug("Search context", "results", len(formatted), "tokens", used, "budget", budget)
 	return strings.Join(formatted, "\n\n")
 }
 
+// searchContextBudget returns the tokens search results may take in a request:
+// the configured budget, or a fiftieth of the model's context window
+func (s *SmartClient) searchContextBudget() int {
+	if s.config.SearchContextTokens > 0 {
+		return s.config.SearchContextTokens
+	}
+	return max(s.config.ContextWindow/50, 100)
+}
+
 // getSmartSystemPrompt returns the smart system prompt
 func (s *SmartClient) getSmartSystemPrompt() string {
 	return `You are Claude, a friendly AI assistant that responds in an informal, conversational way.
@@ -0,0 +1,54 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
+)
+
+// verifySystemPrompt asks for a fact check of an answer against search results
+const verifySystemPrompt = `You are a strict fact checker for a voice assistant. You get search results
+and an answer written from them. Check every number, date, name and claim in the answer against
+the results. Reply ONLY with a JSON object:
+{"verdict": "ok"} when the answer is supported by the results, or
+{"verdict": "fix", "answer": "..."} with a corrected answer that keeps the original language,
+tone and length and only states what the results support.`
+
+// verification is the fact checker's reply
+type verification struct {
+	Verdict string `json:"verdict"`
+	Answer  string `json:"answer"`
+}
+
+// verifyAnswer checks answer against the search results with a second request,
+// returning a corrected answer when it contradicts them or invents numbers
+func (s *SmartClient) verifyAnswer(ctx context.Context, answer string, searchResults *SearchResults) (string, *Usage, error) {
+	prompt := fmt.Sprintf("Search results:\n\n%s\n\nAnswer:\n%s", s.formatSearchResults(searchResults), answer)
+
+	reply, usage, err := s.vertexClient.CompleteWith(ctx, []Message{{Role: "user", Content: prompt}}, &Overrides{SystemPrompt: verifySystemPrompt})
+	if err != nil {
+		return answer, usage, fmt.Errorf("verification request failed: %w", err)
+	}
+
+	// Tolerate prose or code fences around the JSON object
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end <= start {
+		return answer, usage, fmt.Errorf("verification returned no JSON object")
+	}
+
+	var result verification
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &result); err != nil {
+		return answer, usage, fmt.Errorf("invalid verification: %w", err)
+	}
+
+	if strings.EqualFold(result.Verdict, "fix") && strings.TrimSpace(result.Answer) != "" {
+		s.logger.Info("🔎 Answer corrected by verification", "original", textutil.Preview(answer), "corrected", textutil.Preview(result.Answer))
+		return strings.TrimSpace(result.Answer), usage, nil
+	}
+
+	s.logger.Info("🔎 Answer verified against search results")
+	return answer, usage, nil
+}
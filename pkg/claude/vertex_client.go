@@ -18,6 +18,8 @@ import (
 	"golang.org/x/oauth2/google"
 
 	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/quota"
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
 )
 
 // VertexClient represents a Claude client using Google Cloud Vertex AI
@@ -26,6 +28,7 @@ type VertexClient struct {
 	httpClient  *http.Client
 	credentials *google.Credentials
 	initialized bool
+	quota       *quota.Guard
 	mu          sync.RWMutex
 	logger      *slog.Logger
 }
@@ -63,6 +66,20 @@ type Usage struct {
 	OutputTokens int `json:"output_tokens"`
 }
 
+// Add accumulates another usage report into u
+func (u *Usage) Add(other *Usage) {
+	if other == nil {
+		return
+	}
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+}
+
+// Cost estimates the price in USD given per-million-token prices
+func (u *Usage) Cost(inputPerMTok, outputPerMTok float64) float64 {
+	return float64(u.InputTokens)/1e6*inputPerMTok + float64(u.OutputTokens)/1e6*outputPerMTok
+}
+
 // NewVertexClient creates a new Claude Vertex AI client
 func NewVertexClient(cfg *config.VertexAIConfig) *VertexClient {
 	return &VertexClient{
@@ -127,16 +144,37 @@ func (c *VertexClient) Initialize(ctx context.Context) error {
 
 // SendMessage sends messages to Claude via Vertex AI
 func (c *VertexClient) SendMessage(ctx context.Context, messages []Message) (string, error) {
+	text, _, err := c.Complete(ctx, messages)
+	return text, err
+}
+
+// Overrides replaces configured request settings for a single call
+type Overrides struct {
+	Model        string
+	SystemPrompt string
+}
+
+// Complete sends messages to Claude via Vertex AI and also reports token usage
+func (c *VertexClient) Complete(ctx context.Context, messages []Message) (string, *Usage, error) {
+	return c.CompleteWith(ctx, messages, nil)
+}
+
+// CompleteWith is like Complete but applies per-request overrides when non-nil
+func (c *VertexClient) CompleteWith(ctx context.Context, messages []Message, overrides *Overrides) (string, *Usage, error) {
 	c.mu.RLock()
 	initialized := c.initialized
 	c.mu.RUnlock()
 
 	if !initialized {
 		if err := c.Initialize(ctx); err != nil {
-			return "", fmt.Errorf("failed to initialize client: %w", err)
+			return "", nil, fmt.Errorf("failed to initialize client: %w", err)
 		}
 	}
 
+	if err := c.quota.Allow(quota.VertexTokens, 0); err != nil {
+		return "", nil, err
+	}
+
 	// Build the request
 	request := VertexRequest{
 		AnthropicVersion: "vertex-2023-10-16",
@@ -150,10 +188,20 @@ func (c *VertexClient) SendMessage(ctx context.Context, messages []Message) (str
 		request.System = c.config.SystemPrompt
 	}
 
+	model := c.config.Model
+	if overrides != nil {
+		if overrides.SystemPrompt != "" {
+			request.System = overrides.SystemPrompt
+		}
+		if overrides.Model != "" {
+			model = overrides.Model
+		}
+	}
+
 	// Marshal request to JSON
 	requestBody, err := json.Marshal(request)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Build the URL
@@ -162,7 +210,7 @@ func (c *VertexClient) SendMessage(ctx context.Context, messages []Message) (str
 		c.config.Location,
 		c.config.ProjectID,
 		c.config.Location,
-		c.config.Model,
+		model,
 	)
 
 	c.logger.Debug("Making request to Vertex AI",
@@ -173,7 +221,7 @@ func (c *VertexClient) SendMessage(ctx context.Context, messages []Message) (str
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+		return "", nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -181,14 +229,14 @@ func (c *VertexClient) SendMessage(ctx context.Context, messages []Message) (str
 	// Make the request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("HTTP request failed: %w", err)
+		return "", nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	c.logger.Debug("Received response",
@@ -198,22 +246,28 @@ func (c *VertexClient) SendMessage(ctx context.Context, messages []Message) (str
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API error %d: %s", resp.StatusCode, string(responseBody))
+		return "", nil, fmt.Errorf("API error %d: %s", resp.StatusCode, textutil.Preview(string(responseBody)))
 	}
 
 	// Parse response
 	var vertexResponse VertexResponse
 	if err := json.Unmarshal(responseBody, &vertexResponse); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return "", nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	// Extract text from response
 	text := c.extractTextFromResponse(vertexResponse)
 	if text == "" {
-		return "", fmt.Errorf("no text found in response")
+		return "", nil, fmt.Errorf("no text found in response")
+	}
+
+	usage := vertexResponse.Usage
+	if usage == nil {
+		usage = &Usage{}
 	}
+	c.quota.Record(quota.VertexTokens, usage.InputTokens+usage.OutputTokens)
 
-	return text, nil
+	return text, usage, nil
 }
 
 // extractTextFromResponse extracts text content from Vertex AI response
@@ -0,0 +1,256 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// openMeteoTime is how Open-Meteo writes times, local to the place
+const openMeteoTime = "2006-01-02T15:04"
+
+// forecastDays is how far ahead the forecast goes
+const forecastDays = 7
+
+// Severe weather worth an alert, as Open-Meteo has no warnings of its own:
+// an hour with this much rain (mm), gusts (km/h) or heat (°C)
+const (
+	heavyRainMM    = 10.0
+	strongGustsKMH = 70.0
+	extremeHeatC   = 38.0
+)
+
+// Weather alert kinds
+const (
+	AlertThunderstorm = "thunderstorm"
+	AlertHeavyRain    = "heavy_rain"
+	AlertHeavySnow    = "heavy_snow"
+	AlertWind         = "wind"
+	AlertHeat         = "heat"
+)
+
+// forecastPeriods are the parts of the day questions ask about, checked in
+// order ("mañana por la tarde" before "mañana", "por la mañana" before
+// "mañana"); hours run from-to
+var forecastPeriods = []struct {
+	pattern  *regexp.Regexp
+	label    string
+	tomorrow bool
+	from, to int
+}{
+	{regexp.MustCompile(`(?i)\bmañana por la mañana\b|\btomorrow morning\b`), "Tomorrow morning", true, 6, 12},
+	{regexp.MustCompile(`(?i)\bmañana por la tarde\b|\btomorrow afternoon\b`), "Tomorrow afternoon", true, 12, 20},
+	{regexp.MustCompile(`(?i)\bmañana por la noche\b|\btomorrow (?:evening|night)\b`), "Tomorrow evening", true, 20, 24},
+	{regexp.MustCompile(`(?i)\besta mañana\b|\bpor la mañana\b|\bthis morning\b`), "This morning", false, 6, 12},
+	{regexp.MustCompile(`(?i)\besta tarde\b|\bpor la tarde\b|\bthis afternoon\b`), "This afternoon", false, 12, 20},
+	{regexp.MustCompile(`(?i)\besta noche\b|\bpor la noche\b|\btonight\b|\bthis evening\b`), "Tonight", false, 20, 24},
+	{regexp.MustCompile(`(?i)\bmañana\b|\btomorrow\b`), "Tomorrow", true, 0, 24},
+}
+
+// forecastPeriod is a stretch of hours the forecast is summed up over
+type forecastPeriod struct {
+	label    string
+	from, to time.Time
+}
+
+// hourlyOutlook sums up the hourly forecast over a period
+type hourlyOutlook struct {
+	conditions  string
+	temperature string
+	rain        string
+	snippet     string
+}
+
+// WeatherAlert is severe weather coming up at a place
+type WeatherAlert struct {
+	Kind  string    // AlertThunderstorm, AlertHeavyRain...
+	Start time.Time // the first hour it is expected, local to the place
+	// Text says it in Spanish ("Aviso para Madrid: tormentas hoy a partir de las 17:00.")
+	Text string
+}
+
+// periodFor picks the part of the day message asks about, or the next 12
+// hours from now
+func periodFor(message string, now time.Time) forecastPeriod {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, period := range forecastPeriods {
+		if !period.pattern.MatchString(message) {
+			continue
+		}
+		day := today
+		if period.tomorrow {
+			day = today.AddDate(0, 0, 1)
+		}
+		from := day.Add(time.Duration(period.from) * time.Hour)
+		if from.Before(now) {
+			// What's left of it
+			from = now.Truncate(time.Hour)
+		}
+		return forecastPeriod{label: period.label, from: from, to: day.Add(time.Duration(period.to) * time.Hour)}
+	}
+	from := now.Truncate(time.Hour)
+	return forecastPeriod{label: "Next 12 hours", from: from, to: from.Add(12 * time.Hour)}
+}
+
+// hourlyOutlookFor sums up the forecast hours in period: the worst
+// conditions, the temperature range and the chance and amount of rain; ok is
+// false when the forecast doesn't cover it
+func hourlyOutlookFor(f *forecast, period forecastPeriod) (hourlyOutlook, bool) {
+	hourly := f.Hourly
+	hours, worst := 0, -1
+	minTemp, maxTemp, rainChance, rainAt, precipitation := 0.0, 0.0, -1.0, "", 0.0
+	for i, value := range hourly.Time {
+		at, err := time.Parse(openMeteoTime, value)
+		if err != nil || at.Before(period.from) || !at.Before(period.to) {
+			continue
+		}
+		if i < len(hourly.Temperature) {
+			if hours == 0 || hourly.Temperature[i] < minTemp {
+				minTemp = hourly.Temperature[i]
+			}
+			if hours == 0 || hourly.Temperature[i] > maxTemp {
+				maxTemp = hourly.Temperature[i]
+			}
+		}
+		if i < len(hourly.RainChance) && hourly.RainChance[i] > rainChance {
+			rainChance, rainAt = hourly.RainChance[i], at.Format("15:04")
+		}
+		if i < len(hourly.Precipitation) {
+			precipitation += hourly.Precipitation[i]
+		}
+		if i < len(hourly.WeatherCode) && hourly.WeatherCode[i] > worst {
+			worst = hourly.WeatherCode[i]
+		}
+		hours++
+	}
+	if hours == 0 {
+		return hourlyOutlook{}, false
+	}
+
+	outlook := hourlyOutlook{
+		conditions:  weatherDescription(worst),
+		temperature: fmt.Sprintf("%.0f–%.0f°C", minTemp, maxTemp),
+	}
+	label := period.label
+	if period.to.Sub(period.from) < 24*time.Hour {
+		label += fmt.Sprintf(" (%s–%s)", period.from.Format("15:04"), period.to.Format("15:04"))
+	}
+	snippet := fmt.Sprintf("%s: %s, %s.", label, outlook.conditions, outlook.temperature)
+	if rainChance >= 0 {
+		outlook.rain = fmt.Sprintf("%.0f%%", rainChance)
+		snippet += fmt.Sprintf(" Chance of rain up to %.0f%% (highest at %s)", rainChance, rainAt)
+		if precipitation >= 0.1 {
+			snippet += fmt.Sprintf(", %.1f mm expected", precipitation)
+		}
+		snippet += "."
+	}
+	outlook.snippet = snippet
+	return outlook, true
+}
+
+// dayLabel names the day-th day of the forecast ("Today", "Saturday 18")
+func dayLabel(day int, date string) string {
+	switch day {
+	case 0:
+		return "Today"
+	case 1:
+		return "Tomorrow"
+	}
+	if t, err := time.Parse(time.DateOnly, date); err == nil {
+		return t.Format("Monday 2")
+	}
+	return date
+}
+
+// Alerts returns the severe weather expected at location over the next
+// horizon: thunderstorms, heavy rain or snow, strong gusts and extreme heat,
+// each from the first hour it shows up in the forecast
+func (o *OpenMeteo) Alerts(ctx context.Context, location string, horizon time.Duration) ([]WeatherAlert, error) {
+	name, f, err := o.forecast(ctx, location, "es")
+	if err != nil {
+		return nil, err
+	}
+	now, err := time.Parse(openMeteoTime, f.Current.Time)
+	if err != nil {
+		return nil, fmt.Errorf("invalid forecast time %q: %w", f.Current.Time, err)
+	}
+	place, _, _ := strings.Cut(name, ",")
+
+	hourly := f.Hourly
+	var alerts []WeatherAlert
+	peaks := make(map[string]float64)
+	seen := func(kind string) bool {
+		return slices.ContainsFunc(alerts, func(alert WeatherAlert) bool { return alert.Kind == kind })
+	}
+	for i, value := range hourly.Time {
+		at, err := time.Parse(openMeteoTime, value)
+		if err != nil || at.Before(now.Truncate(time.Hour)) || at.After(now.Add(horizon)) {
+			continue
+		}
+		code := -1
+		if i < len(hourly.WeatherCode) {
+			code = hourly.WeatherCode[i]
+		}
+		var kinds []string
+		if code >= 95 {
+			kinds = append(kinds, AlertThunderstorm)
+		}
+		if code == 65 || code == 82 || (i < len(hourly.Precipitation) && hourly.Precipitation[i] >= heavyRainMM) {
+			kinds = append(kinds, AlertHeavyRain)
+		}
+		if code == 75 || code == 86 {
+			kinds = append(kinds, AlertHeavySnow)
+		}
+		if i < len(hourly.WindGusts) && hourly.WindGusts[i] >= strongGustsKMH {
+			kinds = append(kinds, AlertWind)
+			peaks[AlertWind] = max(peaks[AlertWind], hourly.WindGusts[i])
+		}
+		if i < len(hourly.Temperature) && hourly.Temperature[i] >= extremeHeatC {
+			kinds = append(kinds, AlertHeat)
+			peaks[AlertHeat] = max(peaks[AlertHeat], hourly.Temperature[i])
+		}
+		for _, kind := range kinds {
+			if !seen(kind) {
+				alerts = append(alerts, WeatherAlert{Kind: kind, Start: at})
+			}
+		}
+	}
+
+	for i := range alerts {
+		alerts[i].Text = fmt.Sprintf("Aviso para %s: %s %s.", place, alertDescription(alerts[i].Kind, peaks), spokenStart(alerts[i].Start, now))
+	}
+	return alerts, nil
+}
+
+// alertDescription says what the severe weather is, in Spanish
+func alertDescription(kind string, peaks map[string]float64) string {
+	switch kind {
+	case AlertThunderstorm:
+		return "tormentas"
+	case AlertHeavyRain:
+		return "lluvias fuertes"
+	case AlertHeavySnow:
+		return "nevadas fuertes"
+	case AlertWind:
+		return fmt.Sprintf("rachas de viento de hasta %.0f km/h", peaks[AlertWind])
+	case AlertHeat:
+		return fmt.Sprintf("calor extremo, hasta %.0f°C", peaks[AlertHeat])
+	default:
+		return kind
+	}
+}
+
+// spokenStart says when severe weather starts ("hoy a partir de las 17:00")
+func spokenStart(start, now time.Time) string {
+	day := "hoy"
+	if start.YearDay() != now.YearDay() {
+		day = "mañana"
+	}
+	if !start.After(now) {
+		return "desde ya"
+	}
+	return fmt.Sprintf("%s a partir de las %s", day, start.Format("15:04"))
+}
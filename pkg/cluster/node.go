@@ -0,0 +1,357 @@
+// Package cluster lets several Bobo instances (desk, living room, ...) share
+// their memory and decide which one answers when more than one heard the user
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+)
+
+const (
+	// claimWindow is how close two utterances must be to count as the same one
+	claimWindow = 2 * time.Second
+	// claimSettle gives slightly later peers time to announce their claim
+	claimSettle = 400 * time.Millisecond
+)
+
+// Document is one memory namespace as exchanged between instances
+type Document struct {
+	Namespace string          `json:"namespace"`
+	Modified  time.Time       `json:"modified"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Claim announces that an instance heard an utterance at the given level
+type Claim struct {
+	Instance string    `json:"instance"`
+	LevelDB  float64   `json:"level_db"`
+	Time     time.Time `json:"time"`
+}
+
+// Node serves this instance's memory to peers, pulls theirs and arbitrates
+// which instance answers an utterance
+type Node struct {
+	config *config.SyncConfig
+	memory *memory.Store
+	client *http.Client
+	server *http.Server
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	claims map[string]Claim
+}
+
+// NewNode creates a sync node for the given memory store
+func NewNode(cfg *config.SyncConfig, store *memory.Store) *Node {
+	n := &Node{
+		config: cfg,
+		memory: store,
+		client: &http.Client{Timeout: 5 * time.Second},
+		logger: slog.Default(),
+		claims: make(map[string]Claim),
+	}
+	if cfg.TLSCert != "" {
+		n.client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: n.peerRoots()}}
+	}
+	return n
+}
+
+// peerRoots trusts the system's certificate authorities and our own
+// certificate, so instances sharing a self-signed one reach each other
+func (n *Node) peerRoots() *x509.CertPool {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if pem, err := os.ReadFile(n.config.TLSCert); err != nil || !roots.AppendCertsFromPEM(pem) {
+		n.logger.Warn("Failed to trust SYNC_TLS_CERT for peers", "error", err)
+	}
+	return roots
+}
+
+// Run serves the sync API (if a listen address is configured) and pulls peer
+// memory periodically until ctx is cancelled
+func (n *Node) Run(ctx context.Context) {
+	if n.config.Listen != "" {
+		if err := n.serve(); err != nil {
+			n.logger.Warn("Sync server disabled", "error", err)
+		} else {
+			defer n.server.Shutdown(context.Background())
+		}
+	}
+
+	if len(n.config.Peers) == 0 {
+		<-ctx.Done()
+		return
+	}
+
+	interval := time.Duration(n.config.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, peer := range n.config.Peers {
+			if err := n.pull(ctx, peer); err != nil {
+				n.logger.Debug("Memory sync with peer failed", "peer", peer, "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// serve starts the sync API, over TLS when SYNC_TLS_CERT and SYNC_TLS_KEY
+// are set; memory is personal, so without SYNC_TOKEN it is only served to
+// this machine
+func (n *Node) serve() error {
+	if (n.config.TLSCert == "") != (n.config.TLSKey == "") {
+		return fmt.Errorf("SYNC_TLS_CERT and SYNC_TLS_KEY must be set together")
+	}
+	listener, err := net.Listen("tcp", n.config.Listen)
+	if err != nil {
+		return err
+	}
+	if tcp, ok := listener.Addr().(*net.TCPAddr); n.config.Token == "" && (!ok || !tcp.IP.IsLoopback()) {
+		listener.Close()
+		return fmt.Errorf("SYNC_TOKEN is required to listen on %s", n.config.Listen)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sync/memory", n.authorized(n.handleMemory))
+	mux.HandleFunc("POST /sync/claim", n.authorized(n.handleClaim))
+	n.server = &http.Server{Handler: mux}
+
+	go func() {
+		n.logger.Info("🔗 Sync server listening", "addr", n.config.Listen, "instance", n.config.Instance, "tls", n.config.TLSCert != "")
+		var err error
+		if n.config.TLSCert != "" {
+			err = n.server.ServeTLS(listener, n.config.TLSCert, n.config.TLSKey)
+		} else {
+			err = n.server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			n.logger.Warn("Sync server stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+// pull fetches a peer's memory and keeps every document newer than ours
+func (n *Node) pull(ctx context.Context, peer string) error {
+	req, err := n.newRequest(ctx, http.MethodGet, peer, "/sync/memory", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach peer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("peer returned status %d", resp.StatusCode)
+	}
+
+	var documents []Document
+	if err := json.NewDecoder(resp.Body).Decode(&documents); err != nil {
+		return fmt.Errorf("invalid sync response: %w", err)
+	}
+
+	for _, doc := range documents {
+		if _, modified, err := n.memory.Raw(doc.Namespace); err == nil && !doc.Modified.After(modified) {
+			continue
+		}
+		if err := n.memory.PutRaw(doc.Namespace, doc.Data, doc.Modified); err != nil {
+			n.logger.Warn("Failed to store synced memory", "namespace", doc.Namespace, "error", err)
+			continue
+		}
+		n.logger.Info("🔄 Memory updated from peer", "peer", peer, "namespace", doc.Namespace)
+	}
+
+	return nil
+}
+
+// Claim announces an utterance heard at levelDB and reports whether this
+// instance should answer it, i.e. no peer heard it louder (closer)
+func (n *Node) Claim(ctx context.Context, levelDB float64) bool {
+	if len(n.config.Peers) == 0 {
+		return true
+	}
+
+	own := Claim{Instance: n.config.Instance, LevelDB: levelDB, Time: time.Now()}
+	n.remember(own)
+
+	body, err := json.Marshal(own)
+	if err != nil {
+		return true
+	}
+
+	var wg sync.WaitGroup
+	for _, peer := range n.config.Peers {
+		wg.Add(1)
+		go func(peer string) {
+			defer wg.Done()
+			if reply, err := n.sendClaim(ctx, peer, body); err == nil && reply != nil {
+				n.remember(*reply)
+			}
+		}(peer)
+	}
+	wg.Wait()
+
+	select {
+	case <-ctx.Done():
+		return true
+	case <-time.After(claimSettle):
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, claim := range n.claims {
+		if claim.Instance == own.Instance || claim.Time.Sub(own.Time).Abs() > claimWindow {
+			continue
+		}
+		// Ties go to the alphabetically first instance so exactly one answers
+		if claim.LevelDB > levelDB || (claim.LevelDB == levelDB && claim.Instance < own.Instance) {
+			n.logger.Info("🤫 A closer instance will answer", "instance", claim.Instance, "level_db", claim.LevelDB)
+			return false
+		}
+	}
+	return true
+}
+
+// sendClaim posts our claim to a peer and returns the peer's latest one
+func (n *Node) sendClaim(ctx context.Context, peer string, body []byte) (*Claim, error) {
+	req, err := n.newRequest(ctx, http.MethodPost, peer, "/sync/claim", body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer returned status %d", resp.StatusCode)
+	}
+
+	var claim Claim
+	if err := json.NewDecoder(resp.Body).Decode(&claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// remember stores the latest claim of an instance
+func (n *Node) remember(claim Claim) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.claims[claim.Instance] = claim
+}
+
+// handleMemory returns every memory document
+func (n *Node) handleMemory(w http.ResponseWriter, r *http.Request) {
+	namespaces, err := n.memory.Namespaces()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	documents := []Document{}
+	for _, namespace := range namespaces {
+		data, modified, err := n.memory.Raw(namespace)
+		if err != nil {
+			continue
+		}
+		documents = append(documents, Document{Namespace: namespace, Modified: modified, Data: data})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(documents)
+}
+
+// handleClaim records a peer's claim and answers with ours if it is recent
+func (n *Node) handleClaim(w http.ResponseWriter, r *http.Request) {
+	var claim Claim
+	if err := json.NewDecoder(r.Body).Decode(&claim); err != nil || claim.Instance == "" {
+		http.Error(w, "invalid claim", http.StatusBadRequest)
+		return
+	}
+	n.remember(claim)
+
+	n.mu.Lock()
+	own, ok := n.claims[n.config.Instance]
+	n.mu.Unlock()
+
+	if !ok || time.Since(own.Time) > claimWindow {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(own)
+}
+
+// authorized rejects requests without the shared token (when one is configured)
+func (n *Node) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		expected := "Bearer " + n.config.Token
+		if n.config.Token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// newRequest builds an authenticated request to a peer; peers without a
+// scheme are reached over https when this instance serves TLS itself
+func (n *Node) newRequest(ctx context.Context, method, peer, path string, body []byte) (*http.Request, error) {
+	url := strings.TrimSuffix(peer, "/") + path
+	if !strings.Contains(peer, "://") {
+		scheme := "http://"
+		if n.config.TLSCert != "" {
+			scheme = "https://"
+		}
+		url = scheme + url
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer %q: %w", peer, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if n.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.config.Token)
+	}
+	return req, nil
+}
@@ -12,38 +12,395 @@ import (
 
 // Config holds all configuration for the desk pet application
 type Config struct {
-	VertexAI *VertexAIConfig
-	Voice    *VoiceConfig
-	TTS      *TTSConfig
+	VertexAI   *VertexAIConfig
+	Voice      *VoiceConfig
+	WakeWord   *WakeWordConfig
+	TTS        *TTSConfig
+	History    *HistoryConfig
+	Recordings *RecordingsConfig
+	Experiment *ExperimentConfig
+	Persona    *PersonaConfig
+	Skills     *SkillsConfig
+	Memory     *MemoryConfig
+	Notes      *NotesConfig
+	Ambient    *AmbientConfig
+	Sound      *SoundConfig
+	Bluetooth  *BluetoothConfig
+	Alarm      *AlarmConfig
+	Presence   *PresenceConfig
+	Power      *PowerConfig
+	Sync       *SyncConfig
+	Satellite  *SatelliteConfig
+	Wyoming    *WyomingConfig
+	Intents    *IntentExportConfig
+	Calendar   *CalendarConfig
+	Contacts   *ContactsConfig
+	Twilio     *TwilioConfig
+	Push       *PushConfig
+	Quota      *QuotaConfig
+	Log        *LogConfig
+	Hooks      *HooksConfig
+	Telemetry  *TelemetryConfig
+	Moderation *ModerationConfig
+	Captions   *CaptionsConfig
+	Alerts     *AlertsConfig
+	Serve      *ServeConfig
 }
 
 // VertexAIConfig contains Google Cloud Vertex AI configuration
 type VertexAIConfig struct {
-	ProjectID         string
-	Location          string
-	Model             string
-	MaxTokens         int
-	Temperature       float64
-	SystemPrompt      string
-	EnableAutoSearch  bool
+	ProjectID           string
+	Location            string
+	Model               string
+	MaxTokens           int
+	Temperature         float64
+	SystemPrompt        string
+	EnableAutoSearch    bool
+	SearchLocale        string
+	SearchLocalesFile   string
+	SearchRouting       bool
+	VerifyAnswers       bool
+	SearchMaxResults    int
+	SearchSnippetChars  int
+	SearchContextTokens int
+	ContextWindow       int
+	DefaultLocation     string
+	CoinGeckoAPIKey     string
+	InputTokenPrice     float64
+	OutputTokenPrice    float64
+	ConfirmCostAbove    float64
 }
 
 // VoiceConfig contains voice recognition configuration
 type VoiceConfig struct {
-	UseWhisperCpp     bool
-	WhisperCppPath    string
-	WhisperModelPath  string
-	SampleRate        int
-	Channels          int
-	ChunkSize         int
+	Transcriber          string
+	TranscriberFallback  []string
+	TranscriberTimeouts  []string
+	Language             string
+	UseWhisperCpp        bool
+	WhisperCppPath       string
+	WhisperModelPath     string
+	WhisperServer        bool
+	WhisperServerURL     string
+	WhisperThreads       int
+	WhisperGPU           bool
+	WhisperGPUDevice     int
+	WhisperFlashAttn     bool
+	VoskModelPath        string
+	GoogleSTTModel       string
+	OpenAIAPIKey         string
+	OpenAISTTModel       string
+	DeepgramAPIKey       string
+	DeepgramModel        string
+	SampleRate           int
+	Channels             int
+	InputChannel         int
+	ChunkSize            int
+	AudioBackend         string
+	InputDevice          string
+	OutputDevice         string
+	RepairTranscripts    bool
+	PartialTranscripts   bool
+	WhisperMinConfidence float64
+	WhisperPrompt        string
+	Vocabulary           []string
+	VocabularyFile       string
+	RejectConfidence     float64
+	Diarize              bool
+	WhisperDiarizeModel  string
+	PushToTalk           bool
+	PushToTalkMaxSeconds int
+	FollowUpSeconds      int
+	StreamCapture        bool
+	StreamBufferSeconds  int
+	PreRollMillis        int
+	MuteWhileSpeaking    bool
+	NoiseSuppression     bool
+	NormalizeLevel       bool
+	NormalizeTargetDB    float64
+	TrimSilence          bool
+	SilenceThresholdDB   float64
+	RecordingFormat      string
+	TranscribeChunk      int
+	TranscribeOverlap    float64
+	TranscribeWorkers    int
+}
+
+// WakeWordConfig contains hands-free activation by a spoken phrase; the
+// sensitivity (0-1) differs in quiet and noisy rooms
+type WakeWordConfig struct {
+	Enabled          bool
+	Phrases          []string
+	Sensitivity      float64
+	NoisySensitivity float64
+	NoisyLevelDB     float64
+	Model            string
+	Verify           bool
+	StateFile        string
 }
 
 // TTSConfig contains text-to-speech configuration
 type TTSConfig struct {
-	Enabled    bool
-	Rate       int
-	Volume     float64
-	VoiceID    string
+	Enabled              bool
+	Rate                 int
+	Volume               float64
+	VoiceID              string
+	AnnouncePauseSeconds int
+	Ducking              string
+	DuckingLevel         int
+	SkillVoices          []string
+	LanguageVoices       []string
+}
+
+// HistoryConfig contains conversation history configuration
+type HistoryConfig struct {
+	Enabled   bool
+	Dir       string
+	Retention string
+}
+
+// RecordingsConfig contains where recordings are kept and for how long
+type RecordingsConfig struct {
+	Dir       string
+	Retention string
+}
+
+// ExperimentConfig defines an A/B comparison between two prompt/model variants
+type ExperimentConfig struct {
+	Enabled bool
+	Name    string
+	A       *VariantConfig
+	B       *VariantConfig
+}
+
+// VariantConfig overrides the model and/or system prompt for one experiment arm
+type VariantConfig struct {
+	Model        string
+	SystemPrompt string
+}
+
+// PersonaConfig contains assistant persona configuration
+type PersonaConfig struct {
+	Default string
+	File    string
+}
+
+// SkillsConfig contains local skill configuration
+type SkillsConfig struct {
+	StoryRate     int
+	TutorLanguage string
+	Dir           string
+	IndexURL      string
+	HabitSummary  string // weekly habits summary, "sunday 20:00"; empty disables it
+	ScriptsDir    string // Starlark script skills (*.star) loaded at startup
+}
+
+// MemoryConfig contains the persistent skill memory configuration
+type MemoryConfig struct {
+	Dir string
+}
+
+// NotesConfig contains where "write that down" saves answers
+type NotesConfig struct {
+	Dir string
+}
+
+// AmbientConfig contains idle presence behavior configuration
+type AmbientConfig struct {
+	Enabled         bool
+	IdleMinutes     int
+	IntervalMinutes int
+	BreakMinutes    int
+	Speak           bool
+}
+
+// SoundConfig contains the non-speech sound monitor configuration
+type SoundConfig struct {
+	Enabled         bool
+	Announce        bool
+	WebhookURL      string
+	LoudThresholdDB float64
+	CooldownSeconds int
+}
+
+// AlarmConfig contains the buzzer and speaker that ring reminders even when
+// the TTS engine is unavailable
+type AlarmConfig struct {
+	GPIOPin       int
+	AudioDevice   string
+	RepeatSeconds int
+	MaxMinutes    int
+}
+
+// BluetoothConfig contains headset auto-switching configuration
+type BluetoothConfig struct {
+	Headset     string
+	PollSeconds int
+}
+
+// PresenceConfig contains the sources that tell whether somebody is at the
+// desk, so that listening and idle behaviors pause while nobody is
+type PresenceConfig struct {
+	Bluetooth   string
+	Host        string
+	PIRPin      int
+	PollSeconds int
+	AwayMinutes int
+}
+
+// PowerConfig contains the battery saver used on laptops: below BelowPercent
+// on battery, Bobo switches to lighter settings
+type PowerConfig struct {
+	Enabled        bool
+	BelowPercent   int
+	WhisperModel   string
+	PollMultiplier int
+	PollSeconds    int
+}
+
+// SyncConfig contains multi-instance memory sync configuration
+type SyncConfig struct {
+	Instance        string
+	Listen          string
+	Peers           []string
+	Token           string
+	IntervalSeconds int
+	TLSCert         string
+	TLSKey          string
+}
+
+// SatelliteConfig contains the satellite microphone server configuration
+type SatelliteConfig struct {
+	Listen    string
+	Token     string
+	AllowOpen bool
+	Advertise bool
+	Name      string
+}
+
+// WyomingConfig points Bobo at external Wyoming speech services (e.g. Home
+// Assistant's whisper and piper add-ons) instead of the local engines
+type WyomingConfig struct {
+	ASRURI   string
+	TTSURI   string
+	TTSVoice string
+}
+
+// IntentExportConfig contains the Rhasspy/openHAB intent export targets
+type IntentExportConfig struct {
+	HTTPURL      string
+	MQTTBroker   string
+	MQTTUsername string
+	MQTTPassword string
+	SiteID       string
+}
+
+// CalendarConfig contains the CalDAV calendar that reminders are copied to
+type CalendarConfig struct {
+	CalDAVURL string
+	Username  string
+	Password  string
+}
+
+// ContactsConfig contains the contact sources (local file and/or CardDAV)
+type ContactsConfig struct {
+	File       string
+	CardDAVURL string
+	Username   string
+	Password   string
+}
+
+// TwilioConfig contains the SMS/voice call delivery configuration
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	From       string
+	To         string
+	Language   string
+	CallAlarms bool
+}
+
+// LogConfig contains logging configuration
+type LogConfig struct {
+	PreviewChars   int
+	ErrorReportDSN string // Sentry DSN or report endpoint; empty disables reporting
+}
+
+// HooksConfig contains the user scripts run at each hook point
+type HooksConfig struct {
+	OnTranscript   string
+	BeforeLLM      string
+	AfterLLM       string
+	BeforeSpeak    string
+	TimeoutSeconds int
+}
+
+// ModerationConfig contains the checks spoken text must pass
+type ModerationConfig struct {
+	Enabled      bool
+	Keywords     []string
+	KeywordsFile string
+	Categories   []string // classified by Claude; empty skips the check
+	Replacement  string
+}
+
+// CaptionsConfig contains where live captions of the conversation go
+type CaptionsConfig struct {
+	File   string
+	Listen string
+	// AllowRemote lets clients beyond this machine read the captions
+	AllowRemote bool
+}
+
+// ServeConfig contains the multi-tenant question API of "bobo serve"
+type ServeConfig struct {
+	Listen         string
+	TenantsFile    string
+	Dir            string
+	TLSCert        string
+	TLSKey         string
+	AllowedOrigins []string
+}
+
+// AlertsConfig contains how each kind of alert reaches the user
+type AlertsConfig struct {
+	// Channels lists the channels (speech, flash, desktop, light) of each
+	// alert type (reminder, timer, weather, doorbell, alarm, loud_noise)
+	Channels           map[string][]string
+	HomeAssistantURL   string
+	HomeAssistantToken string
+	Light              string
+	// Weather warns of severe weather coming at DEFAULT_LOCATION
+	Weather bool
+}
+
+// TelemetryConfig contains the opt-in anonymous usage statistics
+type TelemetryConfig struct {
+	Enabled       bool
+	URL           string
+	File          string
+	IntervalHours int
+}
+
+// QuotaConfig caps cloud usage per hour and per day; 0 means unlimited
+type QuotaConfig struct {
+	VertexTokensPerHour int
+	VertexTokensPerDay  int
+	SearchesPerHour     int
+	SearchesPerDay      int
+	TTSCharsPerHour     int
+	TTSCharsPerDay      int
+}
+
+// PushConfig contains the ntfy/Pushover push notification configuration
+type PushConfig struct {
+	NtfyURL       string
+	NtfyToken     string
+	PushoverToken string
+	PushoverUser  string
+	AwayMinutes   int
+	SlowSeconds   int
+	Desktop       bool
 }
 
 // Load reads configuration from environment file and environment variables
@@ -53,29 +410,300 @@ func Load(envFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to load env file: %w", err)
 	}
 
+	// Detected sounds are said out loud unless SOUND_ANNOUNCE turns it off
+	soundAlerts := []string{"speech"}
+	if !getEnvBool("SOUND_ANNOUNCE", true) {
+		soundAlerts = nil
+	}
+
 	config := &Config{
 		VertexAI: &VertexAIConfig{
-			ProjectID:         getEnvString("ANTHROPIC_VERTEX_PROJECT_ID", "your-gcp-project-id"),
-			Location:          getEnvString("CLOUD_ML_REGION", "us-east5"),
-			Model:             getEnvString("ANTHROPIC_MODEL", "claude-sonnet-4@20250514"),
-			MaxTokens:         getEnvInt("MAX_TOKENS", 1000),
-			Temperature:       getEnvFloat("TEMPERATURE", 0.7),
-			SystemPrompt:      getEnvString("SYSTEM_PROMPT", ""),
-			EnableAutoSearch:  getEnvBool("ENABLE_AUTO_SEARCH", true),
+			ProjectID:           getEnvString("ANTHROPIC_VERTEX_PROJECT_ID", "your-gcp-project-id"),
+			Location:            getEnvString("CLOUD_ML_REGION", "us-east5"),
+			Model:               getEnvString("ANTHROPIC_MODEL", "claude-sonnet-4@20250514"),
+			MaxTokens:           getEnvInt("MAX_TOKENS", 1000),
+			Temperature:         getEnvFloat("TEMPERATURE", 0.7),
+			SystemPrompt:        getEnvString("SYSTEM_PROMPT", ""),
+			EnableAutoSearch:    getEnvBool("ENABLE_AUTO_SEARCH", true),
+			SearchLocale:        strings.ToLower(getEnvString("SEARCH_LOCALE", "es")),
+			SearchLocalesFile:   getEnvString("SEARCH_LOCALES_FILE", "./search_locales.json"),
+			SearchRouting:       getEnvBool("SEARCH_ROUTING", false),
+			VerifyAnswers:       getEnvBool("VERIFY_ANSWERS", false),
+			SearchMaxResults:    getEnvInt("SEARCH_MAX_RESULTS", 3),
+			SearchSnippetChars:  getEnvInt("SEARCH_SNIPPET_CHARS", 400),
+			SearchContextTokens: getEnvInt("SEARCH_CONTEXT_TOKENS", 0),
+			ContextWindow:       getEnvInt("MODEL_CONTEXT_WINDOW", 200000),
+			DefaultLocation:     getEnvString("DEFAULT_LOCATION", ""),
+			CoinGeckoAPIKey:     getEnvString("COINGECKO_API_KEY", ""),
+			InputTokenPrice:     getEnvFloat("INPUT_TOKEN_PRICE", 3.0),
+			OutputTokenPrice:    getEnvFloat("OUTPUT_TOKEN_PRICE", 15.0),
+			ConfirmCostAbove:    getEnvFloat("CONFIRM_COST_ABOVE", 0),
 		},
 		Voice: &VoiceConfig{
-			UseWhisperCpp:     getEnvBool("USE_WHISPER_CPP", true),
-			WhisperCppPath:    getEnvString("WHISPER_CPP_PATH", "./work/repos/whisper.cpp/build/bin/whisper-cli"),
-			WhisperModelPath:  getEnvString("WHISPER_CPP_MODEL", "./work/repos/whisper.cpp/models/ggml-small.bin"),
-			SampleRate:        getEnvInt("SAMPLE_RATE", 22050),
-			Channels:          getEnvInt("CHANNELS", 1),
-			ChunkSize:         getEnvInt("CHUNK_SIZE", 2048),
+			Transcriber:          getEnvString("TRANSCRIBER", ""),
+			TranscriberFallback:  getEnvList("TRANSCRIBER_FALLBACK"),
+			TranscriberTimeouts:  getEnvList("TRANSCRIBER_TIMEOUTS"),
+			Language:             getEnvString("TRANSCRIPTION_LANGUAGE", "es"),
+			UseWhisperCpp:        getEnvBool("USE_WHISPER_CPP", true),
+			WhisperCppPath:       getEnvString("WHISPER_CPP_PATH", "./work/repos/whisper.cpp/build/bin/whisper-cli"),
+			WhisperModelPath:     getEnvString("WHISPER_CPP_MODEL", "./work/repos/whisper.cpp/models/ggml-small.bin"),
+			WhisperServer:        getEnvBool("WHISPER_SERVER", false),
+			WhisperServerURL:     getEnvString("WHISPER_SERVER_URL", ""),
+			WhisperThreads:       getEnvInt("WHISPER_THREADS", 4),
+			WhisperGPU:           getEnvBool("WHISPER_GPU", true),
+			WhisperGPUDevice:     getEnvInt("WHISPER_GPU_DEVICE", 0),
+			WhisperFlashAttn:     getEnvBool("WHISPER_FLASH_ATTN", false),
+			VoskModelPath:        getEnvString("VOSK_MODEL", "./work/models/vosk-model-small-es-0.42"),
+			GoogleSTTModel:       getEnvString("GOOGLE_STT_MODEL", "latest_short"),
+			OpenAIAPIKey:         getEnvString("OPENAI_API_KEY", ""),
+			OpenAISTTModel:       getEnvString("OPENAI_STT_MODEL", "whisper-1"),
+			DeepgramAPIKey:       getEnvString("DEEPGRAM_API_KEY", ""),
+			DeepgramModel:        getEnvString("DEEPGRAM_MODEL", "nova-2"),
+			SampleRate:           getEnvInt("SAMPLE_RATE", 22050),
+			Channels:             getEnvInt("CHANNELS", 1),
+			InputChannel:         getEnvInt("INPUT_CHANNEL", 0),
+			ChunkSize:            getEnvInt("CHUNK_SIZE", 2048),
+			AudioBackend:         getEnvString("AUDIO_BACKEND", "auto"),
+			InputDevice:          getEnvString("AUDIO_INPUT_DEVICE", ""),
+			OutputDevice:         getEnvString("AUDIO_OUTPUT_DEVICE", ""),
+			RepairTranscripts:    getEnvBool("REPAIR_TRANSCRIPTS", true),
+			PartialTranscripts:   getEnvBool("PARTIAL_TRANSCRIPTS", false),
+			WhisperMinConfidence: getEnvFloat("WHISPER_MIN_CONFIDENCE", 0.4),
+			WhisperPrompt:        getEnvString("WHISPER_PROMPT", ""),
+			Vocabulary:           getEnvList("VOCABULARY"),
+			VocabularyFile:       getEnvString("VOCABULARY_FILE", ""),
+			RejectConfidence:     getEnvFloat("REJECT_CONFIDENCE", 0.5),
+			Diarize:              getEnvBool("DIARIZE", false),
+			WhisperDiarizeModel:  getEnvString("WHISPER_DIARIZE_MODEL", ""),
+			PushToTalk:           getEnvBool("PUSH_TO_TALK", false),
+			PushToTalkMaxSeconds: getEnvInt("PUSH_TO_TALK_MAX_SECONDS", 30),
+			FollowUpSeconds:      getEnvInt("FOLLOW_UP_SECONDS", 0),
+			StreamCapture:        getEnvBool("STREAM_CAPTURE", false),
+			StreamBufferSeconds:  getEnvInt("STREAM_BUFFER_SECONDS", 30),
+			PreRollMillis:        getEnvInt("PRE_ROLL_MS", 0),
+			MuteWhileSpeaking:    getEnvBool("MUTE_WHILE_SPEAKING", true),
+			NoiseSuppression:     getEnvBool("NOISE_SUPPRESSION", false),
+			NormalizeLevel:       getEnvBool("NORMALIZE_LEVEL", true),
+			NormalizeTargetDB:    getEnvFloat("NORMALIZE_TARGET_DB", -20),
+			TrimSilence:          getEnvBool("TRIM_SILENCE", true),
+			SilenceThresholdDB:   getEnvFloat("SILENCE_THRESHOLD_DB", -50),
+			RecordingFormat:      strings.ToLower(getEnvString("RECORDING_FORMAT", "wav")),
+			TranscribeChunk:      getEnvInt("TRANSCRIBE_CHUNK_SECONDS", 0),
+			TranscribeOverlap:    getEnvFloat("TRANSCRIBE_CHUNK_OVERLAP", 1),
+			TranscribeWorkers:    getEnvInt("TRANSCRIBE_WORKERS", 3),
+		},
+		WakeWord: &WakeWordConfig{
+			Enabled:          getEnvBool("WAKE_WORD", false),
+			Phrases:          getEnvList("WAKE_WORD_PHRASES"),
+			Sensitivity:      getEnvFloat("WAKE_WORD_SENSITIVITY", 0.5),
+			NoisySensitivity: getEnvFloat("WAKE_WORD_NOISY_SENSITIVITY", 0.3),
+			NoisyLevelDB:     getEnvFloat("WAKE_WORD_NOISY_LEVEL_DB", -45),
+			Model:            getEnvString("WAKE_WORD_MODEL", ""),
+			Verify:           getEnvBool("WAKE_WORD_VERIFY", true),
+			StateFile:        getEnvString("WAKE_WORD_STATE_FILE", "./work/wake_word.json"),
 		},
 		TTS: &TTSConfig{
-			Enabled:    !getEnvBool("TTS_DISABLED", false),
-			Rate:       getEnvInt("TTS_RATE", 160),
-			Volume:     getEnvFloat("TTS_VOLUME", 0.9),
-			VoiceID:    getEnvString("TTS_VOICE_ID", ""),
+			Enabled:              !getEnvBool("TTS_DISABLED", false),
+			Rate:                 getEnvInt("TTS_RATE", 160),
+			Volume:               getEnvFloat("TTS_VOLUME", 0.9),
+			VoiceID:              getEnvString("TTS_VOICE_ID", ""),
+			AnnouncePauseSeconds: getEnvInt("ANNOUNCE_PAUSE_SECONDS", 3),
+			Ducking:              getEnvString("DUCKING", "off"),
+			DuckingLevel:         getEnvInt("DUCKING_LEVEL", 30),
+			SkillVoices:          getEnvList("SKILL_VOICES"),
+			LanguageVoices:       getEnvList("LANGUAGE_VOICES"),
+		},
+		History: &HistoryConfig{
+			Enabled:   getEnvBool("HISTORY_ENABLED", true),
+			Dir:       getEnvString("HISTORY_DIR", "./work/history"),
+			Retention: getEnvString("HISTORY_RETENTION", "all"),
+		},
+		Recordings: &RecordingsConfig{
+			Dir:       getEnvString("RECORDINGS_DIR", "./work/temp"),
+			Retention: getEnvString("RECORDING_RETENTION", "7d"),
+		},
+		Experiment: &ExperimentConfig{
+			Enabled: getEnvBool("EXPERIMENT_ENABLED", false),
+			Name:    getEnvString("EXPERIMENT_NAME", "default"),
+			A: &VariantConfig{
+				Model:        getEnvString("EXPERIMENT_A_MODEL", ""),
+				SystemPrompt: getEnvString("EXPERIMENT_A_PROMPT", ""),
+			},
+			B: &VariantConfig{
+				Model:        getEnvString("EXPERIMENT_B_MODEL", ""),
+				SystemPrompt: getEnvString("EXPERIMENT_B_PROMPT", ""),
+			},
+		},
+		Persona: &PersonaConfig{
+			Default: getEnvString("PERSONA", "bobo"),
+			File:    getEnvString("PERSONAS_FILE", "./personas.json"),
+		},
+		Skills: &SkillsConfig{
+			StoryRate:     getEnvInt("STORY_TTS_RATE", 130),
+			TutorLanguage: getEnvString("TUTOR_LANGUAGE", "English"),
+			Dir:           getEnvString("SKILLS_DIR", "./work/skills"),
+			IndexURL:      getEnvString("SKILLS_INDEX_URL", ""),
+			HabitSummary:  getEnvString("HABIT_SUMMARY", "sunday 20:00"),
+			ScriptsDir:    getEnvString("SCRIPTS_DIR", "./work/scripts"),
+		},
+		Memory: &MemoryConfig{
+			Dir: getEnvString("MEMORY_DIR", "./work/memory"),
+		},
+		Notes: &NotesConfig{
+			Dir: getEnvString("NOTES_DIR", "./work/notes"),
+		},
+		Ambient: &AmbientConfig{
+			Enabled:         getEnvBool("AMBIENT_ENABLED", false),
+			IdleMinutes:     getEnvInt("AMBIENT_IDLE_MINUTES", 10),
+			IntervalMinutes: getEnvInt("AMBIENT_INTERVAL_MINUTES", 15),
+			BreakMinutes:    getEnvInt("AMBIENT_BREAK_MINUTES", 50),
+			Speak:           getEnvBool("AMBIENT_SPEAK", false),
+		},
+		Sound: &SoundConfig{
+			Enabled:         getEnvBool("SOUND_MONITOR_ENABLED", false),
+			Announce:        getEnvBool("SOUND_ANNOUNCE", true),
+			WebhookURL:      getEnvString("SOUND_WEBHOOK_URL", ""),
+			LoudThresholdDB: getEnvFloat("SOUND_LOUD_THRESHOLD_DB", -10),
+			CooldownSeconds: getEnvInt("SOUND_COOLDOWN_SECONDS", 60),
+		},
+		Bluetooth: &BluetoothConfig{
+			Headset:     getEnvString("BLUETOOTH_HEADSET", ""),
+			PollSeconds: getEnvInt("BLUETOOTH_POLL_SECONDS", 5),
+		},
+		Alarm: &AlarmConfig{
+			GPIOPin:       getEnvInt("ALARM_GPIO_PIN", -1),
+			AudioDevice:   getEnvString("ALARM_AUDIO_DEVICE", ""),
+			RepeatSeconds: getEnvInt("ALARM_REPEAT_SECONDS", 20),
+			MaxMinutes:    getEnvInt("ALARM_MAX_MINUTES", 10),
+		},
+		Presence: &PresenceConfig{
+			Bluetooth:   getEnvString("PRESENCE_BLUETOOTH", ""),
+			Host:        getEnvString("PRESENCE_HOST", ""),
+			PIRPin:      getEnvInt("PRESENCE_PIR_PIN", -1),
+			PollSeconds: getEnvInt("PRESENCE_POLL_SECONDS", 10),
+			AwayMinutes: getEnvInt("PRESENCE_AWAY_MINUTES", 5),
+		},
+		Power: &PowerConfig{
+			Enabled:        getEnvBool("POWER_SAVER", false),
+			BelowPercent:   getEnvInt("POWER_SAVER_BELOW_PERCENT", 20),
+			WhisperModel:   getEnvString("POWER_SAVER_WHISPER_MODEL", ""),
+			PollMultiplier: getEnvInt("POWER_SAVER_POLL_MULTIPLIER", 3),
+			PollSeconds:    getEnvInt("POWER_POLL_SECONDS", 60),
+		},
+		Sync: &SyncConfig{
+			Instance:        getEnvString("SYNC_INSTANCE", hostname()),
+			Listen:          getEnvString("SYNC_LISTEN", ""),
+			Peers:           getEnvList("SYNC_PEERS"),
+			Token:           getEnvString("SYNC_TOKEN", ""),
+			IntervalSeconds: getEnvInt("SYNC_INTERVAL_SECONDS", 30),
+			TLSCert:         getEnvString("SYNC_TLS_CERT", ""),
+			TLSKey:          getEnvString("SYNC_TLS_KEY", ""),
+		},
+		Satellite: &SatelliteConfig{
+			Listen:    getEnvString("SATELLITE_LISTEN", ""),
+			Token:     getEnvString("SATELLITE_TOKEN", ""),
+			AllowOpen: getEnvBool("SATELLITE_ALLOW_UNAUTHENTICATED", false),
+			Advertise: getEnvBool("SATELLITE_ADVERTISE", false),
+			Name:      getEnvString("SATELLITE_NAME", "Bobo "+hostname()),
+		},
+		Wyoming: &WyomingConfig{
+			ASRURI:   getEnvString("WYOMING_ASR_URI", ""),
+			TTSURI:   getEnvString("WYOMING_TTS_URI", ""),
+			TTSVoice: getEnvString("WYOMING_TTS_VOICE", ""),
+		},
+		Intents: &IntentExportConfig{
+			HTTPURL:      getEnvString("INTENT_HTTP_URL", ""),
+			MQTTBroker:   getEnvString("INTENT_MQTT_BROKER", ""),
+			MQTTUsername: getEnvString("INTENT_MQTT_USERNAME", ""),
+			MQTTPassword: getEnvString("INTENT_MQTT_PASSWORD", ""),
+			SiteID:       getEnvString("INTENT_SITE_ID", hostname()),
+		},
+		Calendar: &CalendarConfig{
+			CalDAVURL: getEnvString("CALDAV_URL", ""),
+			Username:  getEnvString("CALDAV_USERNAME", ""),
+			Password:  getEnvString("CALDAV_PASSWORD", ""),
+		},
+		Contacts: &ContactsConfig{
+			File:       getEnvString("CONTACTS_FILE", "./contacts.vcf"),
+			CardDAVURL: getEnvString("CARDDAV_URL", ""),
+			Username:   getEnvString("CARDDAV_USERNAME", ""),
+			Password:   getEnvString("CARDDAV_PASSWORD", ""),
+		},
+		Twilio: &TwilioConfig{
+			AccountSID: getEnvString("TWILIO_ACCOUNT_SID", ""),
+			AuthToken:  getEnvString("TWILIO_AUTH_TOKEN", ""),
+			From:       getEnvString("TWILIO_FROM", ""),
+			To:         getEnvString("TWILIO_TO", ""),
+			Language:   getEnvString("TWILIO_LANGUAGE", "es-ES"),
+			CallAlarms: getEnvBool("TWILIO_CALL_ALARMS", false),
+		},
+		Push: &PushConfig{
+			NtfyURL:       getEnvString("NTFY_URL", ""),
+			NtfyToken:     getEnvString("NTFY_TOKEN", ""),
+			PushoverToken: getEnvString("PUSHOVER_TOKEN", ""),
+			PushoverUser:  getEnvString("PUSHOVER_USER", ""),
+			AwayMinutes:   getEnvInt("PUSH_AWAY_MINUTES", 5),
+			SlowSeconds:   getEnvInt("PUSH_SLOW_SECONDS", 30),
+			Desktop:       getEnvBool("DESKTOP_NOTIFICATIONS", false),
+		},
+		Quota: &QuotaConfig{
+			VertexTokensPerHour: getEnvInt("QUOTA_VERTEX_TOKENS_PER_HOUR", 0),
+			VertexTokensPerDay:  getEnvInt("QUOTA_VERTEX_TOKENS_PER_DAY", 0),
+			SearchesPerHour:     getEnvInt("QUOTA_SEARCHES_PER_HOUR", 0),
+			SearchesPerDay:      getEnvInt("QUOTA_SEARCHES_PER_DAY", 0),
+			TTSCharsPerHour:     getEnvInt("QUOTA_TTS_CHARS_PER_HOUR", 0),
+			TTSCharsPerDay:      getEnvInt("QUOTA_TTS_CHARS_PER_DAY", 0),
+		},
+		Log: &LogConfig{
+			PreviewChars:   getEnvInt("LOG_PREVIEW_CHARS", 100),
+			ErrorReportDSN: getEnvString("ERROR_REPORT_DSN", ""),
+		},
+		Hooks: &HooksConfig{
+			OnTranscript:   getEnvString("HOOK_ON_TRANSCRIPT", ""),
+			BeforeLLM:      getEnvString("HOOK_BEFORE_LLM", ""),
+			AfterLLM:       getEnvString("HOOK_AFTER_LLM", ""),
+			BeforeSpeak:    getEnvString("HOOK_BEFORE_SPEAK", ""),
+			TimeoutSeconds: getEnvInt("HOOK_TIMEOUT_SECONDS", 5),
+		},
+		Moderation: &ModerationConfig{
+			Enabled:      getEnvBool("MODERATION", false),
+			Keywords:     getEnvList("MODERATION_KEYWORDS"),
+			KeywordsFile: getEnvString("MODERATION_KEYWORDS_FILE", ""),
+			Categories:   getEnvListDefault("MODERATION_CATEGORIES", []string{"sexual", "violence", "hate", "self-harm", "drugs", "profanity"}),
+			Replacement:  getEnvString("MODERATION_REPLACEMENT", "Prefiero no hablar de eso."),
+		},
+		Captions: &CaptionsConfig{
+			File:        getEnvString("CAPTIONS_FILE", ""),
+			Listen:      getEnvString("CAPTIONS_LISTEN", ""),
+			AllowRemote: getEnvBool("CAPTIONS_ALLOW_REMOTE", false),
+		},
+		Serve: &ServeConfig{
+			Listen:         getEnvString("SERVE_LISTEN", "localhost:8080"),
+			TenantsFile:    getEnvString("SERVE_TENANTS_FILE", "./tenants.json"),
+			Dir:            getEnvString("SERVE_DIR", "./work/tenants"),
+			TLSCert:        getEnvString("SERVE_TLS_CERT", ""),
+			TLSKey:         getEnvString("SERVE_TLS_KEY", ""),
+			AllowedOrigins: getEnvList("SERVE_ALLOWED_ORIGINS"),
+		},
+		Alerts: &AlertsConfig{
+			Channels: map[string][]string{
+				"reminder":   getEnvListDefault("ALERTS_REMINDER", []string{"speech"}),
+				"timer":      getEnvListDefault("ALERTS_TIMER", []string{"speech"}),
+				"weather":    getEnvListDefault("ALERTS_WEATHER", []string{"speech"}),
+				"doorbell":   getEnvListDefault("ALERTS_DOORBELL", soundAlerts),
+				"alarm":      getEnvListDefault("ALERTS_ALARM", soundAlerts),
+				"loud_noise": getEnvListDefault("ALERTS_LOUD_NOISE", soundAlerts),
+			},
+			HomeAssistantURL:   getEnvString("HOME_ASSISTANT_URL", "http://homeassistant.local:8123"),
+			HomeAssistantToken: getEnvString("HOME_ASSISTANT_TOKEN", ""),
+			Light:              getEnvString("ALERT_LIGHT", ""),
+			Weather:            getEnvBool("WEATHER_ALERTS", false),
+		},
+		Telemetry: &TelemetryConfig{
+			Enabled:       getEnvBool("TELEMETRY", false),
+			URL:           getEnvString("TELEMETRY_URL", ""),
+			File:          getEnvString("TELEMETRY_FILE", "./work/telemetry.json"),
+			IntervalHours: getEnvInt("TELEMETRY_INTERVAL_HOURS", 168),
 		},
 	}
 
@@ -136,6 +764,36 @@ func loadEnvFile(filename string) error {
 	return nil
 }
 
+// SetEnvValue saves key=value in the env file, replacing the line that sets
+// key or appending one, so that a setting changed from Bobo survives restarts
+func SetEnvValue(filename, key, value string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading %s: %w", filename, err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	replaced := false
+	for i, line := range lines {
+		name, _, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && strings.TrimSpace(name) == key {
+			lines[i] = key + "=" + value
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, key+"="+value)
+	}
+
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("error writing %s: %w", filename, err)
+	}
+	return nil
+}
+
 // Helper functions for environment variable parsing
 
 func getEnvString(key, defaultValue string) string {
@@ -173,4 +831,32 @@ func getEnvBool(key string, defaultValue bool) bool {
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+// getEnvList splits a comma-separated variable, skipping empty items
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvListDefault is getEnvList with a default for an unset variable; set
+// to empty, it gives an empty list
+func getEnvListDefault(key string, defaultValue []string) []string {
+	if _, ok := os.LookupEnv(key); !ok {
+		return defaultValue
+	}
+	return getEnvList(key)
+}
+
+// hostname returns the machine name, used as the default instance name
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "bobo"
+	}
+	return name
+}
@@ -0,0 +1,192 @@
+// Package contacts resolves the people the user talks about ("Ana", "Marta")
+// to their birthday, phone and email, from a local vCard/JSON file or a
+// CardDAV address book
+package contacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// Contact is one person in the directory
+type Contact struct {
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Birthday string   `json:"birthday,omitempty"` // YYYY-MM-DD or --MM-DD when the year is unknown
+	Phones   []string `json:"phones,omitempty"`
+	Emails   []string `json:"emails,omitempty"`
+}
+
+// NextBirthday returns the next occurrence of the contact's birthday on or
+// after the day of now, and the age they turn (0 when the year is unknown)
+func (c *Contact) NextBirthday(now time.Time) (time.Time, int, bool) {
+	year, month, day, ok := parseBirthday(c.Birthday)
+	if !ok {
+		return time.Time{}, 0, false
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := time.Date(now.Year(), time.Month(month), day, 0, 0, 0, 0, now.Location())
+	if next.Before(today) {
+		next = next.AddDate(1, 0, 0)
+	}
+
+	age := 0
+	if year > 0 {
+		age = next.Year() - year
+	}
+	return next, age, true
+}
+
+// Directory holds the known contacts
+type Directory struct {
+	config *config.ContactsConfig
+	logger *slog.Logger
+
+	mu       sync.RWMutex
+	contacts []Contact
+}
+
+// NewDirectory creates an empty directory for the configured sources
+func NewDirectory(cfg *config.ContactsConfig) *Directory {
+	return &Directory{
+		config: cfg,
+		logger: slog.Default(),
+	}
+}
+
+// Load (re)reads the contacts from the configured file and CardDAV address book
+func (d *Directory) Load(ctx context.Context) error {
+	var contacts []Contact
+
+	if d.config.File != "" {
+		fromFile, err := loadFile(d.config.File)
+		if err != nil {
+			return err
+		}
+		contacts = append(contacts, fromFile...)
+	}
+
+	if d.config.CardDAVURL != "" {
+		fromServer, err := fetchCardDAV(ctx, d.config)
+		if err != nil {
+			return err
+		}
+		contacts = append(contacts, fromServer...)
+	}
+
+	d.mu.Lock()
+	d.contacts = contacts
+	d.mu.Unlock()
+
+	d.logger.Info("📇 Contacts loaded", "count", len(contacts))
+	return nil
+}
+
+// Len returns the number of known contacts
+func (d *Directory) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.contacts)
+}
+
+// Find resolves a spoken name to a contact: an exact full name or alias wins,
+// then a unique first name
+func (d *Directory) Find(name string) (*Contact, bool) {
+	wanted := fold(name)
+	if wanted == "" {
+		return nil, false
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for i := range d.contacts {
+		contact := &d.contacts[i]
+		if fold(contact.Name) == wanted {
+			return contact, true
+		}
+		for _, alias := range contact.Aliases {
+			if fold(alias) == wanted {
+				return contact, true
+			}
+		}
+	}
+
+	var match *Contact
+	for i := range d.contacts {
+		first, _, _ := strings.Cut(fold(d.contacts[i].Name), " ")
+		if first == wanted {
+			if match != nil {
+				return nil, false // ambiguous
+			}
+			match = &d.contacts[i]
+		}
+	}
+	return match, match != nil
+}
+
+// loadFile reads contacts from a .vcf (vCard) or .json file
+func loadFile(path string) ([]Contact, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contacts file: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var contacts []Contact
+		if err := json.Unmarshal(data, &contacts); err != nil {
+			return nil, fmt.Errorf("invalid contacts file %s: %w", path, err)
+		}
+		return contacts, nil
+	}
+	return parseVCards(string(data)), nil
+}
+
+// parseBirthday accepts YYYY-MM-DD, YYYYMMDD and --MM-DD / --MMDD
+func parseBirthday(value string) (year, month, day int, ok bool) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), "-", "")
+	if len(value) > 8 {
+		value = value[:8] // drop a time part
+	}
+
+	var err error
+	switch len(value) {
+	case 8:
+		_, err = fmt.Sscanf(value, "%04d%02d%02d", &year, &month, &day)
+	case 4:
+		_, err = fmt.Sscanf(value, "%02d%02d", &month, &day)
+	default:
+		return 0, 0, 0, false
+	}
+	if err != nil || month < 1 || month > 12 || day < 1 || day > 31 {
+		return 0, 0, 0, false
+	}
+	return year, month, day, true
+}
+
+// fold lowercases, strips accents and collapses spaces so spoken names match
+func fold(text string) string {
+	replacer := strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n", "ç", "c")
+	text = replacer.Replace(strings.ToLower(text))
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}
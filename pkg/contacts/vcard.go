@@ -0,0 +1,134 @@
+package contacts
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// addressbookQuery asks a CardDAV server for every vCard in the collection
+const addressbookQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:addressbook-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:carddav">
+  <D:prop><C:address-data/></D:prop>
+</C:addressbook-query>`
+
+// multistatus is the subset of a WebDAV REPORT response we read
+type multistatus struct {
+	Responses []struct {
+		AddressData string `xml:"propstat>prop>address-data"`
+	} `xml:"response"`
+}
+
+// fetchCardDAV downloads the address book with an addressbook-query REPORT
+func fetchCardDAV(ctx context.Context, cfg *config.ContactsConfig) ([]Contact, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "REPORT", cfg.CardDAVURL, strings.NewReader(addressbookQuery))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CardDAV URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+	if cfg.Username != "" {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach CardDAV server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CardDAV server returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CardDAV response: %w", err)
+	}
+
+	var result multistatus
+	if err := xml.NewDecoder(bytes.NewReader(body)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid CardDAV response: %w", err)
+	}
+
+	var contacts []Contact
+	for _, response := range result.Responses {
+		contacts = append(contacts, parseVCards(response.AddressData)...)
+	}
+	return contacts, nil
+}
+
+// parseVCards extracts the contacts from one or more vCards (FN, NICKNAME,
+// BDAY, TEL and EMAIL properties)
+func parseVCards(data string) []Contact {
+	// Unfold continuation lines (RFC 6350 3.2)
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.NewReplacer("\n ", "", "\n\t", "").Replace(data)
+
+	var contacts []Contact
+	var current *Contact
+	for _, line := range strings.Split(data, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Drop parameters (TEL;TYPE=cell) and groups (item1.EMAIL)
+		name, _, _ = strings.Cut(strings.ToUpper(name), ";")
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		value = unescapeValue(strings.TrimSpace(value))
+
+		switch name {
+		case "BEGIN":
+			if strings.EqualFold(value, "VCARD") {
+				current = &Contact{}
+			}
+		case "END":
+			if current != nil && current.Name != "" {
+				contacts = append(contacts, *current)
+			}
+			current = nil
+		case "FN":
+			if current != nil {
+				current.Name = value
+			}
+		case "NICKNAME":
+			if current != nil {
+				for _, alias := range strings.Split(value, ",") {
+					if alias = strings.TrimSpace(alias); alias != "" {
+						current.Aliases = append(current.Aliases, alias)
+					}
+				}
+			}
+		case "BDAY":
+			if current != nil {
+				current.Birthday = value
+			}
+		case "TEL":
+			if current != nil && value != "" {
+				current.Phones = append(current.Phones, strings.TrimPrefix(value, "tel:"))
+			}
+		case "EMAIL":
+			if current != nil && value != "" {
+				current.Emails = append(current.Emails, value)
+			}
+		}
+	}
+	return contacts
+}
+
+// unescapeValue undoes vCard text escaping
+func unescapeValue(value string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\\`, `\`).Replace(value)
+}
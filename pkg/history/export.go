@@ -0,0 +1,115 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// ExportOptions controls what gets included in an export
+type ExportOptions struct {
+	Format         string
+	IncludeAudio   bool
+	IncludeSources bool
+}
+
+// Export writes interactions to w in the requested format (markdown, json or html)
+func Export(w io.Writer, interactions []Interaction, opts ExportOptions) error {
+	// Drop optional fields up front so every format behaves the same
+	prepared := make([]Interaction, len(interactions))
+	for i, interaction := range interactions {
+		if !opts.IncludeAudio {
+			interaction.AudioFile = ""
+		}
+		if !opts.IncludeSources {
+			interaction.Sources = nil
+		}
+		prepared[i] = interaction
+	}
+
+	switch strings.ToLower(opts.Format) {
+	case "", "markdown", "md":
+		return exportMarkdown(w, prepared)
+	case "json":
+		return exportJSON(w, prepared)
+	case "html":
+		return exportHTML(w, prepared)
+	default:
+		return fmt.Errorf("unsupported export format: %s (use markdown, json or html)", opts.Format)
+	}
+}
+
+// exportMarkdown writes a journal-style markdown document grouped by day
+func exportMarkdown(w io.Writer, interactions []Interaction) error {
+	var b strings.Builder
+	b.WriteString("# Bobo Conversation Log\n")
+
+	currentDay := ""
+	for _, interaction := range interactions {
+		day := interaction.Timestamp.Format("Monday, 2 January 2006")
+		if day != currentDay {
+			currentDay = day
+			fmt.Fprintf(&b, "\n## %s\n", day)
+		}
+
+		fmt.Fprintf(&b, "\n### %s\n\n", interaction.Timestamp.Format("15:04:05"))
+		fmt.Fprintf(&b, "**You:** %s\n\n", interaction.Transcription)
+		fmt.Fprintf(&b, "**Bobo:** %s\n", interaction.Response)
+
+		if interaction.AudioFile != "" {
+			fmt.Fprintf(&b, "\n🎧 [Recording](%s)\n", interaction.AudioFile)
+		}
+		if len(interaction.Sources) > 0 {
+			b.WriteString("\n*Sources:*\n")
+			for _, source := range interaction.Sources {
+				fmt.Fprintf(&b, "- %s\n", source)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// exportJSON writes interactions as an indented JSON array
+func exportJSON(w io.Writer, interactions []Interaction) error {
+	if interactions == nil {
+		interactions = []Interaction{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(interactions)
+}
+
+var htmlTemplate = template.Must(template.New("history").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Bobo Conversation Log</title>
+<style>
+body { font-family: sans-serif; max-width: 48em; margin: 2em auto; color: #222; }
+.entry { border-bottom: 1px solid #ddd; padding: 1em 0; }
+.time { color: #888; font-size: 0.85em; }
+.you { font-weight: bold; }
+.sources { font-size: 0.85em; color: #555; }
+</style>
+</head>
+<body>
+<h1>Bobo Conversation Log</h1>
+{{range .}}<div class="entry">
+<div class="time">{{.Timestamp.Format "2006-01-02 15:04:05"}}</div>
+<p class="you">🗣️ {{.Transcription}}</p>
+<p>🤖 {{.Response}}</p>
+{{if .AudioFile}}<audio controls src="{{.AudioFile}}"></audio>
+{{end}}{{if .Sources}}<ul class="sources">{{range .Sources}}<li>{{.}}</li>{{end}}</ul>
+{{end}}</div>
+{{end}}</body>
+</html>
+`))
+
+// exportHTML writes a standalone HTML page
+func exportHTML(w io.Writer, interactions []Interaction) error {
+	return htmlTemplate.Execute(w, interactions)
+}
@@ -0,0 +1,149 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transcript retention policies besides an age
+const (
+	RetainAll       = "all"
+	RetainSummaries = "summaries" // transcripts go once their day is summarized
+)
+
+// CheckRetention validates a transcript retention policy: all, summaries or
+// an age such as 30d
+func CheckRetention(retention string) error {
+	if retention == RetainAll || retention == RetainSummaries {
+		return nil
+	}
+	if _, err := ParseSince(retention, time.Now()); err != nil || retention == "" {
+		return fmt.Errorf("invalid history retention %q (use all, summaries or an age such as 30d)", retention)
+	}
+	return nil
+}
+
+// RetentionCutoff is the time before which retention drops transcripts
+// (zero when they are all kept)
+func RetentionCutoff(retention string, now time.Time) time.Time {
+	switch retention {
+	case RetainAll:
+		return time.Time{}
+	case RetainSummaries:
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	}
+	cutoff, _ := ParseSince(retention, now)
+	return cutoff
+}
+
+// Prune removes the interactions recorded before the cutoff, writing the
+// summary of every day they cover first so the usage reports survive; it
+// returns the removed interactions
+func (s *Store) Prune(before time.Time) ([]Interaction, error) {
+	s.mu.Lock()
+	interactions, err := s.readSince(time.Time{})
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]time.Time)
+	for _, interaction := range interactions {
+		if interaction.Timestamp.Before(before) {
+			days[interaction.Timestamp.Format("2006-01-02")] = interaction.Timestamp
+		}
+	}
+	if len(days) == 0 {
+		return nil, nil
+	}
+	for date, day := range days {
+		if _, err := os.Stat(filepath.Join(s.dir, "summaries", date+".md")); err == nil {
+			continue
+		}
+		summary, err := s.Summarize(day)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.WriteSummary(summary); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.remove(func(interaction Interaction) bool {
+		return interaction.Timestamp.Before(before)
+	})
+}
+
+// Forget removes the interactions recorded at or after since, without a
+// trace, returning them
+func (s *Store) Forget(since time.Time) ([]Interaction, error) {
+	return s.remove(func(interaction Interaction) bool {
+		return !interaction.Timestamp.Before(since)
+	})
+}
+
+// remove rewrites the log without the interactions drop selects
+func (s *Store) remove(drop func(Interaction) bool) ([]Interaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interactions, err := s.readSince(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	var kept, removed []Interaction
+	for _, interaction := range interactions {
+		if drop(interaction) {
+			removed = append(removed, interaction)
+		} else {
+			kept = append(kept, interaction)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	return removed, s.rewrite(kept)
+}
+
+// forgetPattern recognizes "olvida los últimos 10 minutos" / "forget the last hour"
+var forgetPattern = regexp.MustCompile(`(?i)\b(?:olvida|borra|forget|erase|delete)\b.*?\s(?:[úu]ltim[oa]s?|last|past)\s+(?:(\d+|\pL+)\s+)?(minutos?|horas?|minutes?|hours?)\b`)
+
+// spokenNumbers are the amounts said in words
+var spokenNumbers = map[string]int{
+	"un": 1, "una": 1, "a": 1, "an": 1, "one": 1,
+	"dos": 2, "two": 2, "tres": 3, "three": 3, "cinco": 5, "five": 5,
+	"diez": 10, "ten": 10, "quince": 15, "fifteen": 15,
+	"veinte": 20, "twenty": 20, "treinta": 30, "thirty": 30,
+}
+
+// ParseForget recognizes a request to forget the recent conversation,
+// returning how far back to forget
+func ParseForget(transcription string) (time.Duration, bool) {
+	match := forgetPattern.FindStringSubmatch(transcription)
+	if match == nil {
+		return 0, false
+	}
+
+	amount := 1
+	if match[1] != "" {
+		var err error
+		if amount, err = strconv.Atoi(match[1]); err != nil {
+			var ok bool
+			if amount, ok = spokenNumbers[strings.ToLower(match[1])]; !ok {
+				return 0, false
+			}
+		}
+	}
+
+	unit := time.Minute
+	if strings.HasPrefix(strings.ToLower(match[2]), "h") {
+		unit = time.Hour
+	}
+	return time.Duration(amount) * unit, amount > 0
+}
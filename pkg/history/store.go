@@ -0,0 +1,216 @@
+// Package history persists conversation interactions so they can be reviewed,
+// exported and summarized later
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileName is the append-only log holding one interaction per line
+const fileName = "interactions.jsonl"
+
+// Interaction represents a single question/answer exchange with Bobo
+type Interaction struct {
+	ID            string    `json:"id"`
+	Timestamp     time.Time `json:"timestamp"`
+	Transcription string    `json:"transcription"`
+	Response      string    `json:"response"`
+	AudioFile     string    `json:"audio_file,omitempty"`
+	Sources       []string  `json:"sources,omitempty"`
+	Intent        string    `json:"intent,omitempty"`
+	InputTokens   int       `json:"input_tokens,omitempty"`
+	OutputTokens  int       `json:"output_tokens,omitempty"`
+	Cost          float64   `json:"cost,omitempty"`
+	LatencyMs     int64     `json:"latency_ms,omitempty"`
+	Feedback      string    `json:"feedback,omitempty"`
+	Variant       string    `json:"variant,omitempty"`
+}
+
+// Feedback values users can attach to an interaction
+const (
+	FeedbackPositive = "positive"
+	FeedbackNegative = "negative"
+)
+
+// Store is a JSONL-backed interaction log
+type Store struct {
+	dir  string
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore creates a history store in the given directory
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	return &Store{
+		dir:  dir,
+		path: filepath.Join(dir, fileName),
+	}, nil
+}
+
+// Append writes an interaction to the log, filling in ID and timestamp if missing
+func (s *Store) Append(interaction *Interaction) error {
+	if interaction.Timestamp.IsZero() {
+		interaction.Timestamp = time.Now()
+	}
+	if interaction.ID == "" {
+		interaction.ID = interaction.Timestamp.Format("20060102T150405.000000000")
+	}
+
+	data, err := json.Marshal(interaction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal interaction: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write interaction: %w", err)
+	}
+
+	return nil
+}
+
+// Since returns all interactions recorded at or after the given time, oldest first
+func (s *Store) Since(since time.Time) ([]Interaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.readSince(since)
+}
+
+// SetFeedback tags the interaction with the given ID with user feedback
+func (s *Store) SetFeedback(id, feedback string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interactions, err := s.readSince(time.Time{})
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range interactions {
+		if interactions[i].ID == id {
+			interactions[i].Feedback = feedback
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("interaction %s not found", id)
+	}
+
+	return s.rewrite(interactions)
+}
+
+// rewrite atomically replaces the log with the given interactions
+func (s *Store) rewrite(interactions []Interaction) error {
+	tmpPath := s.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create history file: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	for _, interaction := range interactions {
+		data, err := json.Marshal(interaction)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to marshal interaction: %w", err)
+		}
+		writer.Write(append(data, '\n'))
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	return os.Rename(tmpPath, s.path)
+}
+
+// readSince reads the log; callers must hold s.mu
+func (s *Store) readSince(since time.Time) ([]Interaction, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	var interactions []Interaction
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(line, &interaction); err != nil {
+			return nil, fmt.Errorf("invalid history entry at line %d: %w", lineNum, err)
+		}
+
+		if !interaction.Timestamp.Before(since) {
+			interactions = append(interactions, interaction)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history file: %w", err)
+	}
+
+	return interactions, nil
+}
+
+// ParseSince parses relative periods like "7d", "2w" or "36h" into an absolute
+// time; periods must be positive
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if digits := strings.IndexFunc(value, func(r rune) bool { return r < '0' || r > '9' }); digits > 0 {
+		if amount, err := strconv.Atoi(value[:digits]); err == nil && amount > 0 {
+			switch value[digits:] {
+			case "d":
+				return now.AddDate(0, 0, -amount), nil
+			case "w":
+				return now.AddDate(0, 0, -7*amount), nil
+			}
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return time.Time{}, fmt.Errorf("invalid period %q (use e.g. 7d, 2w, 12h)", value)
+	}
+	return now.Add(-duration), nil
+}
@@ -0,0 +1,207 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IntentCount is the number of interactions for a given intent
+type IntentCount struct {
+	Intent string `json:"intent"`
+	Count  int    `json:"count"`
+}
+
+// DailySummary aggregates one day of interactions
+type DailySummary struct {
+	Date           time.Time     `json:"date"`
+	Interactions   int           `json:"interactions"`
+	TopIntents     []IntentCount `json:"top_intents"`
+	InputTokens    int           `json:"input_tokens"`
+	OutputTokens   int           `json:"output_tokens"`
+	Cost           float64       `json:"cost"`
+	AverageLatency time.Duration `json:"average_latency"`
+	Positive       int           `json:"positive_feedback"`
+	Negative       int           `json:"negative_feedback"`
+}
+
+// FeedbackStats aggregates user feedback for one group of interactions
+type FeedbackStats struct {
+	Group           string        `json:"group"`
+	Interactions    int           `json:"interactions"`
+	Positive        int           `json:"positive"`
+	Negative        int           `json:"negative"`
+	AvgOutputTokens int           `json:"avg_output_tokens"`
+	AvgLatency      time.Duration `json:"avg_latency"`
+}
+
+// Summarize builds the summary for the calendar day containing day
+func (s *Store) Summarize(day time.Time) (*DailySummary, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	interactions, err := s.Since(start)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &DailySummary{Date: start}
+	intents := make(map[string]int)
+	var totalLatency int64
+
+	for _, interaction := range interactions {
+		if !interaction.Timestamp.Before(end) {
+			continue
+		}
+
+		summary.Interactions++
+		summary.InputTokens += interaction.InputTokens
+		summary.OutputTokens += interaction.OutputTokens
+		summary.Cost += interaction.Cost
+		totalLatency += interaction.LatencyMs
+
+		switch interaction.Feedback {
+		case FeedbackPositive:
+			summary.Positive++
+		case FeedbackNegative:
+			summary.Negative++
+		}
+
+		intent := interaction.Intent
+		if intent == "" {
+			intent = "chat"
+		}
+		intents[intent]++
+	}
+
+	if summary.Interactions > 0 {
+		summary.AverageLatency = time.Duration(totalLatency/int64(summary.Interactions)) * time.Millisecond
+	}
+
+	for intent, count := range intents {
+		summary.TopIntents = append(summary.TopIntents, IntentCount{Intent: intent, Count: count})
+	}
+	sort.Slice(summary.TopIntents, func(i, j int) bool {
+		if summary.TopIntents[i].Count != summary.TopIntents[j].Count {
+			return summary.TopIntents[i].Count > summary.TopIntents[j].Count
+		}
+		return summary.TopIntents[i].Intent < summary.TopIntents[j].Intent
+	})
+	if len(summary.TopIntents) > 3 {
+		summary.TopIntents = summary.TopIntents[:3]
+	}
+
+	return summary, nil
+}
+
+// Feedback aggregates feedback for interactions since the given time, grouped
+// by "intent" or by experiment "variant"
+func (s *Store) Feedback(since time.Time, groupBy string) ([]FeedbackStats, error) {
+	interactions, err := s.Since(since)
+	if err != nil {
+		return nil, err
+	}
+
+	byGroup := make(map[string]*FeedbackStats)
+	outputTokens := make(map[string]int)
+	latency := make(map[string]int64)
+
+	for _, interaction := range interactions {
+		var group string
+		switch groupBy {
+		case "variant":
+			group = interaction.Variant
+			if group == "" {
+				continue
+			}
+		default:
+			group = interaction.Intent
+			if group == "" {
+				group = "chat"
+			}
+		}
+
+		stats, ok := byGroup[group]
+		if !ok {
+			stats = &FeedbackStats{Group: group}
+			byGroup[group] = stats
+		}
+
+		stats.Interactions++
+		outputTokens[group] += interaction.OutputTokens
+		latency[group] += interaction.LatencyMs
+
+		switch interaction.Feedback {
+		case FeedbackPositive:
+			stats.Positive++
+		case FeedbackNegative:
+			stats.Negative++
+		}
+	}
+
+	var result []FeedbackStats
+	for group, stats := range byGroup {
+		stats.AvgOutputTokens = outputTokens[group] / stats.Interactions
+		stats.AvgLatency = time.Duration(latency[group]/int64(stats.Interactions)) * time.Millisecond
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Group < result[j].Group
+	})
+
+	return result, nil
+}
+
+// WriteSummary saves the summary as markdown under the history directory and returns its path
+func (s *Store) WriteSummary(summary *DailySummary) (string, error) {
+	dir := filepath.Join(s.dir, "summaries")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create summaries directory: %w", err)
+	}
+
+	path := filepath.Join(dir, summary.Date.Format("2006-01-02")+".md")
+	if err := os.WriteFile(path, []byte(summary.Markdown()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write summary: %w", err)
+	}
+
+	return path, nil
+}
+
+// Markdown renders the summary as a markdown report
+func (d *DailySummary) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Bobo Daily Summary - %s\n\n", d.Date.Format("2006-01-02"))
+	fmt.Fprintf(&b, "- **Interactions:** %d\n", d.Interactions)
+	fmt.Fprintf(&b, "- **Tokens:** %d in / %d out\n", d.InputTokens, d.OutputTokens)
+	fmt.Fprintf(&b, "- **Estimated cost:** $%.4f\n", d.Cost)
+	fmt.Fprintf(&b, "- **Average latency:** %s\n", d.AverageLatency.Round(time.Millisecond))
+	fmt.Fprintf(&b, "- **Feedback:** %d 👍 / %d 👎\n", d.Positive, d.Negative)
+
+	if len(d.TopIntents) > 0 {
+		b.WriteString("\n## Top intents\n\n")
+		for _, intent := range d.TopIntents {
+			fmt.Fprintf(&b, "- %s: %d\n", intent.Intent, intent.Count)
+		}
+	}
+
+	return b.String()
+}
+
+// Spoken renders a short conversational version of the summary for TTS
+func (d *DailySummary) Spoken() string {
+	if d.Interactions == 0 {
+		return "Hoy todavía no hemos hablado. ¡Cuéntame algo!"
+	}
+
+	text := fmt.Sprintf("Hoy hemos hablado %d veces", d.Interactions)
+	if len(d.TopIntents) > 0 {
+		text += fmt.Sprintf(", sobre todo de %s", d.TopIntents[0].Intent)
+	}
+	text += fmt.Sprintf(". He tardado de media %.1f segundos en responder y hemos gastado unos %.2f dólares.",
+		d.AverageLatency.Seconds(), d.Cost)
+
+	return text
+}
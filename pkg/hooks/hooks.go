@@ -0,0 +1,151 @@
+// Package hooks lets plugins and user scripts inspect, rewrite or veto what
+// Bobo heard, asks Claude, gets back and is about to say
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
+)
+
+// Point is a place in the pipeline where hooks run
+type Point string
+
+// Hook points, in the order an interaction goes through them
+const (
+	OnTranscript Point = "on_transcript"
+	BeforeLLM    Point = "before_llm"
+	AfterLLM     Point = "after_llm"
+	BeforeSpeak  Point = "before_speak"
+)
+
+// Event is what a hook receives; it may change Text or set Veto to stop the
+// text from going any further
+type Event struct {
+	Hook Point  `json:"hook"`
+	Text string `json:"text"`
+	// Question is the user's request, for hooks that see Claude's answer
+	Question string `json:"question,omitempty"`
+	Veto     bool   `json:"veto,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Func is a hook; returning an error leaves the event as it was
+type Func func(ctx context.Context, event *Event) error
+
+// Runner runs the hooks registered for each point
+type Runner struct {
+	hooks  map[Point][]Func
+	logger *slog.Logger
+}
+
+// NewRunner creates a runner with the exec hooks configured in cfg
+func NewRunner(cfg *config.HooksConfig) *Runner {
+	r := &Runner{
+		hooks:  make(map[Point][]Func),
+		logger: slog.Default(),
+	}
+
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	for point, command := range map[Point]string{
+		OnTranscript: cfg.OnTranscript,
+		BeforeLLM:    cfg.BeforeLLM,
+		AfterLLM:     cfg.AfterLLM,
+		BeforeSpeak:  cfg.BeforeSpeak,
+	} {
+		if command != "" {
+			r.Register(point, Exec(command, timeout))
+		}
+	}
+	return r
+}
+
+// Len returns the number of registered hooks
+func (r *Runner) Len() int {
+	if r == nil {
+		return 0
+	}
+	n := 0
+	for _, fns := range r.hooks {
+		n += len(fns)
+	}
+	return n
+}
+
+// Register adds a hook at point, run after the ones already there
+func (r *Runner) Register(point Point, fn Func) {
+	r.hooks[point] = append(r.hooks[point], fn)
+}
+
+// Run passes text through the hooks at point, returning the text to use and
+// false when a hook vetoed it. A nil runner returns text unchanged
+func (r *Runner) Run(ctx context.Context, point Point, text, question string) (string, bool) {
+	if r == nil {
+		return text, true
+	}
+
+	for _, fn := range r.hooks[point] {
+		event := &Event{Hook: point, Text: text, Question: question}
+		if err := fn(ctx, event); err != nil {
+			r.logger.Warn("Hook failed, ignoring it", "hook", point, "error", err)
+			continue
+		}
+		if event.Veto {
+			r.logger.Info("🪝 Hook vetoed text", "hook", point, "text", textutil.Preview(text), "reason", event.Reason)
+			return "", false
+		}
+		if event.Text != text {
+			r.logger.Debug("Hook rewrote text", "hook", point, "from", textutil.Preview(text), "to", textutil.Preview(event.Text))
+			text = event.Text
+		}
+	}
+	return text, true
+}
+
+// Exec creates a hook that runs command with sh, sending the event as JSON on
+// stdin and reading the changed event as JSON from stdout; no output keeps the
+// event unchanged
+func Exec(command string, timeout time.Duration) Func {
+	return func(ctx context.Context, event *Event) error {
+		input, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w: %s", command, err, textutil.Preview(strings.TrimSpace(stderr.String())))
+		}
+
+		output := bytes.TrimSpace(stdout.Bytes())
+		if len(output) == 0 {
+			return nil
+		}
+
+		changed := *event
+		if err := json.Unmarshal(output, &changed); err != nil {
+			return fmt.Errorf("%s returned invalid JSON: %w", command, err)
+		}
+		changed.Hook = event.Hook
+		*event = changed
+		return nil
+	}
+}
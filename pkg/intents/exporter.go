@@ -0,0 +1,187 @@
+// Package intents publishes the intents Bobo recognizes as Rhasspy/Hermes
+// compatible JSON events, so openHAB rules, Node-RED flows or any other
+// Rhasspy-based automation can react to Bobo's voice front end
+package intents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// Intent is a recognized user request
+type Intent struct {
+	Name  string
+	Slots map[string]string
+	Text  string
+}
+
+// rhasspyEntity is one slot in Rhasspy's intent JSON
+type rhasspyEntity struct {
+	Entity   string `json:"entity"`
+	Value    string `json:"value"`
+	RawValue string `json:"raw_value"`
+}
+
+// rhasspyIntent is Rhasspy's HTTP intent JSON (as posted to its intent handlers)
+type rhasspyIntent struct {
+	Text   string `json:"text"`
+	Intent struct {
+		Name       string  `json:"name"`
+		Confidence float64 `json:"confidence"`
+	} `json:"intent"`
+	Entities []rhasspyEntity   `json:"entities"`
+	Slots    map[string]string `json:"slots"`
+	RawText  string            `json:"raw_text"`
+	SiteID   string            `json:"site_id"`
+}
+
+// hermesSlot is one slot in a Hermes MQTT intent message
+type hermesSlot struct {
+	Entity   string `json:"entity"`
+	SlotName string `json:"slotName"`
+	RawValue string `json:"rawValue"`
+	Value    struct {
+		Kind  string `json:"kind"`
+		Value string `json:"value"`
+	} `json:"value"`
+	Confidence float64 `json:"confidence"`
+}
+
+// hermesIntent is the payload of hermes/intent/<name> MQTT messages
+type hermesIntent struct {
+	Input  string `json:"input"`
+	Intent struct {
+		IntentName      string  `json:"intentName"`
+		ConfidenceScore float64 `json:"confidenceScore"`
+	} `json:"intent"`
+	Slots     []hermesSlot `json:"slots"`
+	SiteID    string       `json:"siteId"`
+	SessionID string       `json:"sessionId"`
+}
+
+// Exporter sends recognized intents to an HTTP endpoint and/or an MQTT broker
+type Exporter struct {
+	config *config.IntentExportConfig
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewExporter creates an exporter for the configured targets
+func NewExporter(cfg *config.IntentExportConfig) *Exporter {
+	return &Exporter{
+		config: cfg,
+		client: &http.Client{Timeout: 5 * time.Second},
+		logger: slog.Default(),
+	}
+}
+
+// Publish sends the intent to every configured target in the background
+func (e *Exporter) Publish(intent Intent) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if e.config.HTTPURL != "" {
+			if err := e.postHTTP(ctx, intent); err != nil {
+				e.logger.Warn("Intent HTTP export failed", "intent", intent.Name, "error", err)
+			}
+		}
+		if e.config.MQTTBroker != "" {
+			if err := e.publishMQTT(ctx, intent); err != nil {
+				e.logger.Warn("Intent MQTT export failed", "intent", intent.Name, "error", err)
+			}
+		}
+	}()
+}
+
+// postHTTP posts the intent as Rhasspy intent JSON
+func (e *Exporter) postHTTP(ctx context.Context, intent Intent) error {
+	body, err := json.Marshal(e.rhasspy(intent))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.HTTPURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// publishMQTT publishes the intent as a Hermes message on hermes/intent/<name>
+func (e *Exporter) publishMQTT(ctx context.Context, intent Intent) error {
+	body, err := json.Marshal(e.hermes(intent))
+	if err != nil {
+		return err
+	}
+	return publishMQTT(ctx, e.config.MQTTBroker, "bobo-"+e.config.SiteID,
+		e.config.MQTTUsername, e.config.MQTTPassword, "hermes/intent/"+intent.Name, body)
+}
+
+// rhasspy converts an intent to Rhasspy's JSON format
+func (e *Exporter) rhasspy(intent Intent) *rhasspyIntent {
+	out := &rhasspyIntent{
+		Text:     intent.Text,
+		Entities: []rhasspyEntity{},
+		Slots:    map[string]string{},
+		RawText:  intent.Text,
+		SiteID:   e.config.SiteID,
+	}
+	out.Intent.Name = intent.Name
+	out.Intent.Confidence = 1
+
+	for _, name := range slotNames(intent.Slots) {
+		value := intent.Slots[name]
+		out.Entities = append(out.Entities, rhasspyEntity{Entity: name, Value: value, RawValue: value})
+		out.Slots[name] = value
+	}
+	return out
+}
+
+// hermes converts an intent to a Hermes MQTT message
+func (e *Exporter) hermes(intent Intent) *hermesIntent {
+	out := &hermesIntent{
+		Input:     intent.Text,
+		Slots:     []hermesSlot{},
+		SiteID:    e.config.SiteID,
+		SessionID: fmt.Sprintf("bobo-%d", time.Now().UnixNano()),
+	}
+	out.Intent.IntentName = intent.Name
+	out.Intent.ConfidenceScore = 1
+
+	for _, name := range slotNames(intent.Slots) {
+		slot := hermesSlot{Entity: name, SlotName: name, RawValue: intent.Slots[name], Confidence: 1}
+		slot.Value.Kind = "Unknown"
+		slot.Value.Value = intent.Slots[name]
+		out.Slots = append(out.Slots, slot)
+	}
+	return out
+}
+
+// slotNames returns the slot names in a stable order
+func slotNames(slots map[string]string) []string {
+	names := make([]string, 0, len(slots))
+	for name := range slots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
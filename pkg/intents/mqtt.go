@@ -0,0 +1,88 @@
+package intents
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// MQTT 3.1.1 control packet types used by the publisher
+const (
+	mqttConnect    = 0x10
+	mqttConnAck    = 0x20
+	mqttPublish    = 0x30
+	mqttDisconnect = 0xe0
+)
+
+// publishMQTT connects to broker, publishes payload to topic with QoS 0 and
+// disconnects; intents are rare enough that a connection per event is fine
+func publishMQTT(ctx context.Context, broker, clientID, username, password, topic string, payload []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", broker)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	// CONNECT: protocol name, level 4, flags, keep alive, then the payload fields
+	var flags byte = 0x02 // clean session
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, 0, 0, 60)
+	body = appendString(body, clientID)
+	if username != "" {
+		flags |= 0x80
+		body = appendString(body, username)
+		if password != "" {
+			flags |= 0x40
+			body = appendString(body, password)
+		}
+	}
+	body[7] = flags
+	if _, err := conn.Write(packet(mqttConnect, body)); err != nil {
+		return fmt.Errorf("failed to send MQTT connect: %w", err)
+	}
+
+	// CONNACK: fixed header, session present flag, return code
+	ack := make([]byte, 4)
+	if _, err := io.ReadFull(bufio.NewReader(conn), ack); err != nil {
+		return fmt.Errorf("failed to read MQTT connack: %w", err)
+	}
+	if ack[0] != mqttConnAck || ack[3] != 0 {
+		return fmt.Errorf("MQTT broker refused connection (code %d)", ack[3])
+	}
+
+	if _, err := conn.Write(packet(mqttPublish, append(appendString(nil, topic), payload...))); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+
+	_, err = conn.Write([]byte{mqttDisconnect, 0})
+	return err
+}
+
+// packet prefixes body with the fixed header and variable-length remaining size
+func packet(kind byte, body []byte) []byte {
+	out := []byte{kind}
+	size := len(body)
+	for {
+		digit := byte(size % 128)
+		size /= 128
+		if size > 0 {
+			digit |= 0x80
+		}
+		out = append(out, digit)
+		if size == 0 {
+			break
+		}
+	}
+	return append(out, body...)
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(buf []byte, s string) []byte {
+	buf = append(buf, byte(len(s)>>8), byte(len(s)))
+	return append(buf, s...)
+}
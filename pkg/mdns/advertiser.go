@@ -0,0 +1,293 @@
+// Package mdns advertises Bobo's services on the local network with
+// multicast DNS service discovery (DNS-SD), so that satellites and Home
+// Assistant find them without an IP address to type in
+package mdns
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// groupAddr is the IPv4 multicast DNS group
+const groupAddr = "224.0.0.251:5353"
+
+// recordTTL is how long, in seconds, resolvers may cache the records
+const recordTTL = 120
+
+// DNS record types and classes used by DNS-SD
+const (
+	typeA      = 1
+	typePTR    = 12
+	typeTXT    = 16
+	typeSRV    = 33
+	typeANY    = 255
+	classIN    = 1
+	cacheFlush = 0x8000 // records only this host answers for
+)
+
+// servicesName lists the service types on the network (DNS-SD browsing)
+const servicesName = "_services._dns-sd._udp.local."
+
+// errMalformed marks a packet that isn't valid DNS
+var errMalformed = errors.New("malformed DNS message")
+
+// Service is one service to advertise, such as the satellite server
+type Service struct {
+	// Instance is the name shown to users, such as "Bobo desk"
+	Instance string
+	// Type is the DNS-SD service type, such as "_wyoming._tcp"
+	Type string
+	// Port is the TCP port the service listens on
+	Port int
+	// TXT holds "key=value" service attributes
+	TXT []string
+}
+
+// Advertiser answers multicast DNS queries for a service
+type Advertiser struct {
+	service Service
+	host    string // this machine's name, such as "desk.local."
+	logger  *slog.Logger
+}
+
+// NewAdvertiser creates an advertiser for service on this machine
+func NewAdvertiser(service Service) *Advertiser {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "bobo"
+	}
+	host, _, _ = strings.Cut(host, ".")
+
+	return &Advertiser{
+		service: service,
+		host:    host + ".local.",
+		logger:  slog.Default(),
+	}
+}
+
+// Run announces the service and answers queries for it until ctx is
+// cancelled, when it tells the network the service is gone
+func (a *Advertiser) Run(ctx context.Context) error {
+	group, err := net.ResolveUDPAddr("udp4", groupAddr)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the mDNS group: %w", err)
+	}
+	conn, err := net.ListenMulticastUDP("udp4", nil, group)
+	if err != nil {
+		return fmt.Errorf("failed to join the mDNS group: %w", err)
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// Announce twice, a second apart, as RFC 6762 recommends
+		a.send(conn, group, recordTTL)
+		select {
+		case <-time.After(time.Second):
+			a.send(conn, group, recordTTL)
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		select {
+		case <-ctx.Done():
+			a.send(conn, group, 0) // goodbye
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	a.logger.Info("📣 Advertising on the local network", "service", a.service.Type, "instance", a.service.Instance, "port", a.service.Port)
+	buf := make([]byte, 9000)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("mDNS read failed: %w", err)
+		}
+		if a.asked(buf[:n]) {
+			a.send(conn, group, recordTTL)
+		}
+	}
+}
+
+// send multicasts the service records with the given TTL
+func (a *Advertiser) send(conn *net.UDPConn, group *net.UDPAddr, ttl uint32) {
+	if _, err := conn.WriteToUDP(a.response(ttl), group); err != nil {
+		a.logger.Debug("mDNS announcement failed", "error", err)
+	}
+}
+
+// serviceName is the FQDN of the service type, such as "_wyoming._tcp.local."
+func (a *Advertiser) serviceName() string {
+	return a.service.Type + ".local."
+}
+
+// asked reports whether msg is a query for any of the service's records
+func (a *Advertiser) asked(msg []byte) bool {
+	if len(msg) < 12 || binary.BigEndian.Uint16(msg[2:])&0x8000 != 0 {
+		return false // too short, or a response
+	}
+
+	off := 12
+	for range binary.BigEndian.Uint16(msg[4:]) {
+		name, next, err := readName(msg, off)
+		if err != nil || next+4 > len(msg) {
+			return false
+		}
+		qtype := binary.BigEndian.Uint16(msg[next:])
+		off = next + 4
+
+		switch {
+		case strings.EqualFold(name, a.serviceName()), strings.EqualFold(name, servicesName):
+			if qtype == typePTR || qtype == typeANY {
+				return true
+			}
+		case strings.EqualFold(name, a.instanceName()):
+			if qtype == typeSRV || qtype == typeTXT || qtype == typeANY {
+				return true
+			}
+		case strings.EqualFold(name, a.host):
+			if qtype == typeA || qtype == typeANY {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// instanceName is the FQDN of the service instance, for comparisons
+func (a *Advertiser) instanceName() string {
+	return a.service.Instance + "." + a.serviceName()
+}
+
+// response builds the answer with every record of the service
+func (a *Advertiser) response(ttl uint32) []byte {
+	service := splitName(a.serviceName())
+	instance := append([]string{a.service.Instance}, service...)
+	host := splitName(a.host)
+
+	var records [][]byte
+	records = append(records,
+		record(splitName(servicesName), typePTR, classIN, ttl, encodeName(service)),
+		record(service, typePTR, classIN, ttl, encodeName(instance)),
+	)
+
+	srv := make([]byte, 6)
+	binary.BigEndian.PutUint16(srv[4:], uint16(a.service.Port))
+	records = append(records, record(instance, typeSRV, classIN|cacheFlush, ttl, append(srv, encodeName(host)...)))
+
+	var txt []byte
+	for _, entry := range a.service.TXT {
+		txt = append(txt, byte(min(len(entry), 255)))
+		txt = append(txt, entry[:min(len(entry), 255)]...)
+	}
+	if len(txt) == 0 {
+		txt = []byte{0}
+	}
+	records = append(records, record(instance, typeTXT, classIN|cacheFlush, ttl, txt))
+
+	for _, ip := range localIPv4() {
+		records = append(records, record(host, typeA, classIN|cacheFlush, ttl, ip))
+	}
+
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[2:], 0x8400) // response, authoritative
+	binary.BigEndian.PutUint16(msg[6:], uint16(len(records)))
+	for _, r := range records {
+		msg = append(msg, r...)
+	}
+	return msg
+}
+
+// record encodes one resource record
+func record(name []string, rtype, class uint16, ttl uint32, data []byte) []byte {
+	out := encodeName(name)
+	fixed := make([]byte, 10)
+	binary.BigEndian.PutUint16(fixed[0:], rtype)
+	binary.BigEndian.PutUint16(fixed[2:], class)
+	binary.BigEndian.PutUint32(fixed[4:], ttl)
+	binary.BigEndian.PutUint16(fixed[8:], uint16(len(data)))
+	return append(append(out, fixed...), data...)
+}
+
+// splitName splits a dotted FQDN into its labels
+func splitName(name string) []string {
+	return strings.Split(strings.TrimSuffix(name, "."), ".")
+}
+
+// encodeName encodes labels as a DNS name, without compression; a label may
+// contain dots or spaces, as instance names do
+func encodeName(labels []string) []byte {
+	var out []byte
+	for _, label := range labels {
+		label = label[:min(len(label), 63)]
+		out = append(out, byte(len(label)))
+		out = append(out, label...)
+	}
+	return append(out, 0)
+}
+
+// readName decodes the possibly compressed name at off, returning it as a
+// dotted FQDN and the offset after it
+func readName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errMalformed
+		}
+		length := int(msg[off])
+		switch {
+		case length == 0:
+			if end < 0 {
+				end = off + 1
+			}
+			return strings.Join(labels, ".") + ".", end, nil
+		case length&0xC0 == 0xC0:
+			if off+1 >= len(msg) || jumps > 16 {
+				return "", 0, errMalformed
+			}
+			if end < 0 {
+				end = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3FFF)
+			jumps++
+		default:
+			if off+1+length > len(msg) {
+				return "", 0, errMalformed
+			}
+			labels = append(labels, string(msg[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
+
+// localIPv4 returns this machine's non-loopback IPv4 addresses
+func localIPv4() [][]byte {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips [][]byte
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		if ip := ipNet.IP.To4(); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,157 @@
+// Package memory provides a small persistent document store shared by skills
+// (vocabulary, flashcards, countdowns, preferences, ...)
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// validNamespace keeps namespaces safe to use as file names
+var validNamespace = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Store persists one JSON document per namespace in a directory
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open creates (if needed) and opens a memory store in dir
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create memory directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir returns the directory backing the store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Load decodes the namespace document into v; a missing document leaves v untouched
+func (s *Store) Load(namespace string, v any) error {
+	path, err := s.path(namespace)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read memory %s: %w", namespace, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid memory %s: %w", namespace, err)
+	}
+	return nil
+}
+
+// Save atomically replaces the namespace document with v
+func (s *Store) Save(namespace string, v any) error {
+	path, err := s.path(namespace)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal memory %s: %w", namespace, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write memory %s: %w", namespace, err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Namespaces returns the sorted names of all stored documents
+func (s *Store) Namespaces() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memory: %w", err)
+	}
+
+	var namespaces []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if ok && !entry.IsDir() && validNamespace.MatchString(name) {
+			namespaces = append(namespaces, name)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// Raw returns the encoded namespace document and when it was last written
+func (s *Store) Raw(namespace string) ([]byte, time.Time, error) {
+	path, err := s.path(namespace)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read memory %s: %w", namespace, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read memory %s: %w", namespace, err)
+	}
+	return data, info.ModTime(), nil
+}
+
+// PutRaw replaces the namespace document with already encoded JSON, keeping
+// the given modification time so replicas agree on which copy is newest
+func (s *Store) PutRaw(namespace string, data []byte, modified time.Time) error {
+	path, err := s.path(namespace)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("invalid memory %s: not JSON", namespace)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write memory %s: %w", namespace, err)
+	}
+	if err := os.Chtimes(tmpPath, modified, modified); err != nil {
+		return fmt.Errorf("failed to write memory %s: %w", namespace, err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// path returns the file backing a namespace
+func (s *Store) path(namespace string) (string, error) {
+	if !validNamespace.MatchString(namespace) {
+		return "", fmt.Errorf("invalid memory namespace %q", namespace)
+	}
+	return filepath.Join(s.dir, namespace+".json"), nil
+}
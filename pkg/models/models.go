@@ -0,0 +1,171 @@
+// Package models downloads, verifies and lists the whisper.cpp models Bobo
+// can transcribe with
+package models
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// downloadURL is where whisper.cpp publishes its ggml models
+const downloadURL = "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/"
+
+// Model is a whisper.cpp model that can be downloaded
+type Model struct {
+	Name string `json:"name"`
+	Size string `json:"size"`
+	// SHA1 is the checksum whisper.cpp publishes for the file
+	SHA1 string `json:"sha1"`
+}
+
+// File is the model's file name
+func (m Model) File() string {
+	return "ggml-" + m.Name + ".bin"
+}
+
+// Catalog lists the multilingual models, from fastest to most accurate
+var Catalog = []Model{
+	{Name: "tiny", Size: "75 MiB", SHA1: "bd577a113a864445d4c299885e0cb97d4ba92b5f"},
+	{Name: "base", Size: "142 MiB", SHA1: "465707469ff3a37a2b9b8d8f89f2f99de7299dac"},
+	{Name: "small", Size: "466 MiB", SHA1: "55356645c2b361a969dfd0ef2c5a50d530afd8d5"},
+	{Name: "medium", Size: "1.5 GiB", SHA1: "fd9727b6e1217c2f614f9b698455c4ffd82463b4"},
+}
+
+// Find returns the catalog model called name ("small" or "ggml-small.bin")
+func Find(name string) (Model, error) {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "ggml-"), ".bin")
+	for _, model := range Catalog {
+		if model.Name == name {
+			return model, nil
+		}
+	}
+	names := make([]string, len(Catalog))
+	for i, model := range Catalog {
+		names[i] = model.Name
+	}
+	return Model{}, fmt.Errorf("unknown model %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Status is a catalog model and whether it has been downloaded
+type Status struct {
+	Model
+	Path      string `json:"path"`
+	Installed bool   `json:"installed"`
+}
+
+// Manager keeps the models in a directory
+type Manager struct {
+	dir    string
+	client *http.Client
+}
+
+// NewManager creates a manager for the models in dir
+func NewManager(dir string) *Manager {
+	return &Manager{dir: dir, client: &http.Client{}}
+}
+
+// Path is where model is kept
+func (m *Manager) Path(model Model) string {
+	return filepath.Join(m.dir, model.File())
+}
+
+// List reports which catalog models are downloaded
+func (m *Manager) List() []Status {
+	statuses := make([]Status, 0, len(Catalog))
+	for _, model := range Catalog {
+		path := m.Path(model)
+		_, err := os.Stat(path)
+		statuses = append(statuses, Status{Model: model, Path: path, Installed: err == nil})
+	}
+	return statuses
+}
+
+// Download fetches model, checks it against its published checksum and
+// returns where it was saved; progress is called as it downloads, with a
+// total of -1 when the size is unknown
+func (m *Manager) Download(ctx context.Context, model Model, progress func(done, total int64)) (string, error) {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", m.dir, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL+model.File(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", model.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: status %d", model.Name, resp.StatusCode)
+	}
+
+	// Download next to the model and only replace it once verified
+	path := m.Path(model)
+	partial := path + ".part"
+	file, err := os.Create(partial)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", partial, err)
+	}
+	defer os.Remove(partial)
+
+	hash := sha1.New()
+	counter := &progressWriter{total: resp.ContentLength, progress: progress}
+	_, err = io.Copy(io.MultiWriter(file, hash, counter), resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", model.Name, err)
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != model.SHA1 {
+		return "", fmt.Errorf("downloaded %s is corrupt (SHA-1 %s, expected %s)", model.Name, sum, model.SHA1)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		return "", fmt.Errorf("failed to save %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// Verify checks a downloaded model against its published checksum
+func (m *Manager) Verify(model Model) error {
+	file, err := os.Open(m.Path(model))
+	if err != nil {
+		return fmt.Errorf("%s is not downloaded: %w", model.Name, err)
+	}
+	defer file.Close()
+
+	hash := sha1.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to read %s: %w", model.Name, err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != model.SHA1 {
+		return fmt.Errorf("%s is corrupt (SHA-1 %s, expected %s); download it again", model.Name, sum, model.SHA1)
+	}
+	return nil
+}
+
+// progressWriter counts the bytes written through it for a progress callback
+type progressWriter struct {
+	done     int64
+	total    int64
+	progress func(done, total int64)
+}
+
+// Write implements io.Writer
+func (p *progressWriter) Write(data []byte) (int, error) {
+	p.done += int64(len(data))
+	if p.progress != nil {
+		p.progress(p.done, p.total)
+	}
+	return len(data), nil
+}
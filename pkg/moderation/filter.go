@@ -0,0 +1,191 @@
+// Package moderation keeps Bobo from saying what it shouldn't in a shared
+// office or a kid's room: everything it is about to say is checked against
+// keyword lists and, optionally, classified by Claude into safety categories
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/hooks"
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
+)
+
+// classifySystemPrompt asks for the safety categories a text falls into
+const classifySystemPrompt = `You are the content moderator of a voice assistant that children and
+coworkers may hear. You get a text it is about to say and a list of categories. Reply ONLY with a
+JSON object listing the categories the text clearly falls into: {"flagged": ["category", ...]},
+or {"flagged": []} when it falls into none. Mentioning a topic in a neutral, age-appropriate way
+(news about a war, a cooking knife) does not count.`
+
+// maxVerdicts is how many classified texts are remembered, since canned
+// phrases are said again and again
+const maxVerdicts = 256
+
+// Classifier sends a prompt to the language model
+type Classifier interface {
+	Prompt(ctx context.Context, system, prompt string) (string, error)
+}
+
+// classification is the moderator's reply
+type classification struct {
+	Flagged []string `json:"flagged"`
+}
+
+// Filter decides whether a text may be said
+type Filter struct {
+	config     *config.ModerationConfig
+	keywords   map[string]*regexp.Regexp
+	classifier Classifier
+	logger     *slog.Logger
+
+	mu       sync.Mutex
+	verdicts map[string]string // text → reason it was flagged, "" if allowed
+}
+
+// NewFilter creates a filter with the configured keywords; classifier may be
+// nil to check keywords only
+func NewFilter(cfg *config.ModerationConfig, classifier Classifier) (*Filter, error) {
+	f := &Filter{
+		config:     cfg,
+		keywords:   make(map[string]*regexp.Regexp),
+		classifier: classifier,
+		logger:     slog.Default(),
+		verdicts:   make(map[string]string),
+	}
+
+	keywords := cfg.Keywords
+	if cfg.KeywordsFile != "" {
+		fromFile, err := readKeywords(cfg.KeywordsFile)
+		if err != nil {
+			return nil, err
+		}
+		keywords = append(keywords, fromFile...)
+	}
+	for _, keyword := range keywords {
+		// Whole words only, with accented letters counted as letters
+		pattern, err := regexp.Compile(`(?i)(^|[^\p{L}\p{N}])` + regexp.QuoteMeta(keyword) + `([^\p{L}\p{N}]|$)`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid moderation keyword %q: %w", keyword, err)
+		}
+		f.keywords[keyword] = pattern
+	}
+	return f, nil
+}
+
+// readKeywords reads one keyword or phrase per line, skipping blank lines
+// and # comments
+func readKeywords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var keywords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			keywords = append(keywords, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return keywords, nil
+}
+
+// Check returns why text must not be said, or "" when it may; if Claude
+// can't be asked about the categories, text is held back as if flagged
+func (f *Filter) Check(ctx context.Context, text string) string {
+	for keyword, pattern := range f.keywords {
+		if pattern.MatchString(text) {
+			return "keyword: " + keyword
+		}
+	}
+	if f.classifier == nil || len(f.config.Categories) == 0 || strings.TrimSpace(text) == "" {
+		return ""
+	}
+
+	f.mu.Lock()
+	reason, known := f.verdicts[text]
+	f.mu.Unlock()
+	if known {
+		return reason
+	}
+
+	flagged, err := f.classify(ctx, text)
+	if err != nil {
+		// Unchecked text may be what the categories are there to stop
+		f.logger.Warn("Moderation check failed, holding the answer back", "error", err)
+		return "unchecked: " + err.Error()
+	}
+	if len(flagged) > 0 {
+		reason = "category: " + strings.Join(flagged, ", ")
+	}
+
+	f.mu.Lock()
+	if len(f.verdicts) >= maxVerdicts {
+		clear(f.verdicts)
+	}
+	f.verdicts[text] = reason
+	f.mu.Unlock()
+	return reason
+}
+
+// classify asks Claude which of the configured categories text falls into
+func (f *Filter) classify(ctx context.Context, text string) ([]string, error) {
+	prompt := fmt.Sprintf("Categories: %s\n\nText:\n%s", strings.Join(f.config.Categories, ", "), text)
+	reply, err := f.classifier.Prompt(ctx, classifySystemPrompt, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("moderation request failed: %w", err)
+	}
+
+	// Tolerate prose or code fences around the JSON object
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("moderation returned no JSON object")
+	}
+	var result classification
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("invalid moderation reply: %w", err)
+	}
+
+	// Only the categories asked about count
+	var flagged []string
+	for _, category := range result.Flagged {
+		for _, configured := range f.config.Categories {
+			if strings.EqualFold(strings.TrimSpace(category), configured) {
+				flagged = append(flagged, configured)
+				break
+			}
+		}
+	}
+	return flagged, nil
+}
+
+// Hook is a before_speak hook that replaces text that must not be said with
+// MODERATION_REPLACEMENT, or silences it when that is empty
+func (f *Filter) Hook(ctx context.Context, event *hooks.Event) error {
+	reason := f.Check(ctx, event.Text)
+	if reason == "" {
+		return nil
+	}
+	f.logger.Warn("🚫 Answer moderated", "reason", reason, "text", textutil.Preview(event.Text))
+	if f.config.Replacement == "" {
+		event.Veto = true
+		event.Reason = "moderated (" + reason + ")"
+		return nil
+	}
+	event.Text = f.config.Replacement
+	return nil
+}
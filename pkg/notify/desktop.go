@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Desktop shows native desktop notifications, with notify-send on Linux and
+// the notification center on macOS
+type Desktop struct {
+	command string
+}
+
+// NewDesktop finds the notification command of the platform
+func NewDesktop() (*Desktop, error) {
+	command := "notify-send"
+	if runtime.GOOS == "darwin" {
+		command = "osascript"
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("%s not found", command)
+	}
+	return &Desktop{command: command}, nil
+}
+
+// Send shows a notification
+func (d *Desktop) Send(ctx context.Context, title, message string) error {
+	var cmd *exec.Cmd
+	if d.command == "osascript" {
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		cmd = exec.CommandContext(ctx, d.command, "-e", script)
+	} else {
+		cmd = exec.CommandContext(ctx, d.command, "--app-name=Bobo", title, message)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", d.command, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// appleScriptString quotes text as an AppleScript string literal
+func appleScriptString(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	return `"` + strings.ReplaceAll(text, `"`, `\"`) + `"`
+}
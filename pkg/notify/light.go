@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// Light blinks a smart bulb through Home Assistant
+type Light struct {
+	config *config.AlertsConfig
+	client *http.Client
+}
+
+// NewLight creates a notifier for the bulb ALERT_LIGHT names
+func NewLight(cfg *config.AlertsConfig) (*Light, error) {
+	if cfg.HomeAssistantURL == "" || cfg.HomeAssistantToken == "" {
+		return nil, fmt.Errorf("HOME_ASSISTANT_URL and HOME_ASSISTANT_TOKEN are needed to blink %s", cfg.Light)
+	}
+	return &Light{
+		config: cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Flash makes the bulb blink for a few seconds, leaving it as it was
+func (l *Light) Flash(ctx context.Context) error {
+	body, err := json.Marshal(map[string]string{
+		"entity_id": l.config.Light,
+		"flash":     "long",
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(l.config.HomeAssistantURL, "/") + "/api/services/light/turn_on"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+l.config.HomeAssistantToken)
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Home Assistant returned status %d", resp.StatusCode)
+	}
+	return nil
+}
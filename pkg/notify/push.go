@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// pushoverAPI is Pushover's message endpoint
+const pushoverAPI = "https://api.pushover.net/1/messages.json"
+
+// Push sends push notifications through ntfy and/or Pushover
+type Push struct {
+	config *config.PushConfig
+	client *http.Client
+}
+
+// NewPush creates a push notifier for the configured services
+func NewPush(cfg *config.PushConfig) *Push {
+	return &Push{
+		config: cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Enabled reports whether any push service is configured
+func (p *Push) Enabled() bool {
+	return p.config.NtfyURL != "" || (p.config.PushoverToken != "" && p.config.PushoverUser != "")
+}
+
+// Send delivers a notification to every configured service
+func (p *Push) Send(ctx context.Context, title, message string) error {
+	var errs []error
+	if p.config.NtfyURL != "" {
+		if err := p.sendNtfy(ctx, title, message); err != nil {
+			errs = append(errs, fmt.Errorf("ntfy: %w", err))
+		}
+	}
+	if p.config.PushoverToken != "" && p.config.PushoverUser != "" {
+		if err := p.sendPushover(ctx, title, message); err != nil {
+			errs = append(errs, fmt.Errorf("pushover: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendNtfy publishes to an ntfy topic URL (ntfy.sh or self-hosted)
+func (p *Push) sendNtfy(ctx context.Context, title, message string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.NtfyURL, strings.NewReader(message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", title)
+	req.Header.Set("Tags", "robot")
+	if p.config.NtfyToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.NtfyToken)
+	}
+	return p.do(req)
+}
+
+// sendPushover sends a Pushover message
+func (p *Push) sendPushover(ctx context.Context, title, message string) error {
+	form := url.Values{}
+	form.Set("token", p.config.PushoverToken)
+	form.Set("user", p.config.PushoverUser)
+	form.Set("title", title)
+	form.Set("message", message)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pushoverAPI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return p.do(req)
+}
+
+// do sends a request and checks the response status
+func (p *Push) do(req *http.Request) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Package notify delivers messages to the user's phone when they are away
+// from the desk
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// twilioAPI is the base URL of Twilio's REST API
+const twilioAPI = "https://api.twilio.com/2010-04-01/Accounts/"
+
+// Twilio sends SMS messages and places voice calls through Twilio
+type Twilio struct {
+	config *config.TwilioConfig
+	client *http.Client
+}
+
+// NewTwilio creates a Twilio sender for the configured account
+func NewTwilio(cfg *config.TwilioConfig) *Twilio {
+	return &Twilio{
+		config: cfg,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// DefaultRecipient returns the user's own phone number
+func (t *Twilio) DefaultRecipient() string {
+	return t.config.To
+}
+
+// SendSMS texts body to the phone number to
+func (t *Twilio) SendSMS(ctx context.Context, to, body string) error {
+	form := url.Values{}
+	form.Set("From", t.config.From)
+	form.Set("To", to)
+	form.Set("Body", body)
+	return t.post(ctx, "Messages.json", form)
+}
+
+// Call phones to and reads message aloud twice
+func (t *Twilio) Call(ctx context.Context, to, message string) error {
+	say := fmt.Sprintf(`<Say language="%s">%s</Say>`, t.config.Language, xmlEscape(message))
+	form := url.Values{}
+	form.Set("From", t.config.From)
+	form.Set("To", to)
+	form.Set("Twiml", "<Response>"+say+`<Pause length="1"/>`+say+"</Response>")
+	return t.post(ctx, "Calls.json", form)
+}
+
+// post calls a Twilio API resource with the account credentials
+func (t *Twilio) post(ctx context.Context, resource string, form url.Values) error {
+	endpoint := twilioAPI + url.PathEscape(t.config.AccountSID) + "/" + resource
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.config.AccountSID, t.config.AuthToken)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("Twilio returned status %d: %s", resp.StatusCode, apiErr.Message)
+	}
+	return nil
+}
+
+// xmlEscape escapes text for use inside TwiML
+func xmlEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(text)
+}
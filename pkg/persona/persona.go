@@ -0,0 +1,209 @@
+// Package persona provides named assistant personalities with their own
+// prompt, voice and wake-word name
+package persona
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Persona describes how the assistant talks and sounds
+type Persona struct {
+	Name         string   `json:"name"`
+	WakeWord     string   `json:"wake_word"`
+	Aliases      []string `json:"aliases,omitempty"`
+	SystemPrompt string   `json:"system_prompt"`
+	VoiceID      string   `json:"voice_id,omitempty"`
+	Rate         int      `json:"rate,omitempty"`
+}
+
+// builtins are always available and can be overridden from the personas file
+var builtins = []Persona{
+	{
+		Name:     "bobo",
+		WakeWord: "Bobo",
+		Aliases:  []string{"bobo", "normal", "default"},
+		// Empty prompt keeps the configured/smart system prompt
+	},
+	{
+		Name:     "butler",
+		WakeWord: "Jeeves",
+		Aliases:  []string{"butler", "mayordomo", "jeeves"},
+		SystemPrompt: `You are Jeeves, an impeccably polite English butler serving as a desk assistant.
+Address the user formally ("sir" or "madam", or "señor"/"señora" in Spanish), stay calm and discreet,
+and keep answers SHORT (2-3 sentences max). Answer in the user's language.`,
+		Rate: 150,
+	},
+	{
+		Name:     "pirate",
+		WakeWord: "Barbanegra",
+		Aliases:  []string{"pirate", "pirata", "barbanegra"},
+		SystemPrompt: `You are Barbanegra, a cheerful pirate who lives on the user's desk.
+Talk like a pirate ("¡Arrr!", "grumete", "ahoy, matey"), be playful but still give correct answers,
+and keep answers SHORT (2-3 sentences max). Answer in the user's language.`,
+		Rate: 170,
+	},
+	{
+		Name:     "kids",
+		WakeWord: "Bobo",
+		Aliases:  []string{"kids", "child", "niño", "niños", "niña", "niñas", "peques"},
+		SystemPrompt: `You are Bobo, a friendly desk pet talking with a young child (4-8 years old).
+Use very simple words and short sentences, be warm, patient and encouraging, never scary or rude,
+and avoid adult topics (gently change the subject if asked). Keep answers to 1-2 sentences.
+Answer in the child's language.`,
+		Rate: 130,
+	},
+}
+
+// Registry holds the available personas and tracks the active one
+type Registry struct {
+	personas map[string]*Persona
+	active   *Persona
+	mu       sync.RWMutex
+}
+
+// NewRegistry creates a registry with the built-in personas plus any defined in
+// the JSON file at path, activating defaultName
+func NewRegistry(path, defaultName string) (*Registry, error) {
+	r := &Registry{personas: make(map[string]*Persona)}
+
+	for i := range builtins {
+		p := builtins[i]
+		r.personas[p.Name] = &p
+	}
+
+	if path != "" {
+		if err := r.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if defaultName == "" {
+		defaultName = "bobo"
+	}
+	active := r.Find(defaultName)
+	if active == nil {
+		return nil, fmt.Errorf("unknown default persona %q (available: %s)", defaultName, strings.Join(r.Names(), ", "))
+	}
+	r.active = active
+
+	return r, nil
+}
+
+// loadFile reads custom personas from a JSON array
+func (r *Registry) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		// The personas file is optional
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading personas file %s: %w", path, err)
+	}
+
+	var personas []Persona
+	if err := json.Unmarshal(data, &personas); err != nil {
+		return fmt.Errorf("invalid personas file %s: %w", path, err)
+	}
+
+	for i := range personas {
+		p := personas[i]
+		if p.Name == "" {
+			return fmt.Errorf("persona #%d in %s has no name", i+1, path)
+		}
+		p.Name = strings.ToLower(p.Name)
+		if p.WakeWord == "" {
+			p.WakeWord = p.Name
+		}
+		r.personas[p.Name] = &p
+	}
+
+	return nil
+}
+
+// Find looks a persona up by name, alias or wake word (case-insensitive)
+func (r *Registry) Find(name string) *Persona {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if p, ok := r.personas[name]; ok {
+		return p
+	}
+	for _, p := range r.personas {
+		if strings.ToLower(p.WakeWord) == name {
+			return p
+		}
+		for _, alias := range p.Aliases {
+			if alias == name {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// Names returns the sorted names of all personas
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.personas))
+	for name := range r.personas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Active returns the currently selected persona
+func (r *Registry) Active() *Persona {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active
+}
+
+// Activate switches the current persona by name, alias or wake word
+func (r *Registry) Activate(name string) (*Persona, error) {
+	p := r.Find(name)
+	if p == nil {
+		return nil, fmt.Errorf("unknown persona %q", name)
+	}
+
+	r.mu.Lock()
+	r.active = p
+	r.mu.Unlock()
+
+	return p, nil
+}
+
+// switchPrefixes introduce a persona change request
+var switchPrefixes = []string{
+	"talk to me as the ", "talk to me as ", "talk like the ", "talk like a ", "switch to the ", "switch to ",
+	"habla como el ", "habla como la ", "habla como un ", "habla como una ", "habla como ",
+	"háblame como el ", "háblame como un ", "háblame como ", "cambia a ", "cambia al ",
+	"modo ", "activa el modo ",
+}
+
+// ParseSwitch extracts the requested persona name from utterances like
+// "talk to me as the butler" or "habla como un pirata"
+func ParseSwitch(transcription string) (string, bool) {
+	text := strings.ToLower(strings.Trim(strings.TrimSpace(transcription), ".!¡?¿"))
+
+	for _, prefix := range switchPrefixes {
+		if idx := strings.Index(text, prefix); idx >= 0 {
+			name := strings.TrimSpace(text[idx+len(prefix):])
+			if name == "" {
+				return "", false
+			}
+			// Use only the first word ("pirata por favor" -> "pirata")
+			return strings.Fields(name)[0], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,183 @@
+// Package quota caps how much cloud usage (Vertex tokens, web searches, remote
+// TTS characters) Bobo spends per hour and per day
+package quota
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+)
+
+// Resource identifies a capped kind of cloud usage
+type Resource string
+
+const (
+	VertexTokens  Resource = "vertex_tokens"
+	Searches      Resource = "searches"
+	TTSCharacters Resource = "tts_characters"
+)
+
+// memoryNamespace persists the counters so daily caps survive restarts
+const memoryNamespace = "quota"
+
+// Limit caps a resource per hour and per day; 0 means unlimited
+type Limit struct {
+	PerHour int
+	PerDay  int
+}
+
+// ExceededError reports a resource whose cap has been reached
+type ExceededError struct {
+	Resource Resource
+	Window   string // "hour" or "day"
+	Limit    int
+	Reset    time.Time
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s quota reached (%d per %s, resets at %s)", e.Resource, e.Limit, e.Window, e.Reset.Format("15:04"))
+}
+
+// counter holds the usage of a resource in the current hour and day
+type counter struct {
+	Hour     time.Time `json:"hour"`
+	HourUsed int       `json:"hour_used"`
+	Day      time.Time `json:"day"`
+	DayUsed  int       `json:"day_used"`
+}
+
+// roll resets the windows that have ended
+func (c *counter) roll(now time.Time) {
+	hour := now.Truncate(time.Hour)
+	if !c.Hour.Equal(hour) {
+		c.Hour, c.HourUsed = hour, 0
+	}
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !c.Day.Equal(day) {
+		c.Day, c.DayUsed = day, 0
+	}
+}
+
+// Guard tracks usage against the configured caps; a nil Guard allows everything
+type Guard struct {
+	limits   map[Resource]Limit
+	counters map[Resource]*counter
+	store    *memory.Store
+	now      func() time.Time
+	mu       sync.Mutex
+	logger   *slog.Logger
+}
+
+// NewGuard creates a guard, returning nil when no cap is configured
+func NewGuard(cfg *config.QuotaConfig) *Guard {
+	limits := map[Resource]Limit{
+		VertexTokens:  {PerHour: cfg.VertexTokensPerHour, PerDay: cfg.VertexTokensPerDay},
+		Searches:      {PerHour: cfg.SearchesPerHour, PerDay: cfg.SearchesPerDay},
+		TTSCharacters: {PerHour: cfg.TTSCharsPerHour, PerDay: cfg.TTSCharsPerDay},
+	}
+	for resource, limit := range limits {
+		if limit.PerHour <= 0 && limit.PerDay <= 0 {
+			delete(limits, resource)
+		}
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+
+	return &Guard{
+		limits:   limits,
+		counters: make(map[Resource]*counter),
+		now:      time.Now,
+		logger:   slog.Default(),
+	}
+}
+
+// SetStore persists usage in store and restores what was recorded earlier
+func (g *Guard) SetStore(store *memory.Store) error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.store = store
+	if err := store.Load(memoryNamespace, &g.counters); err != nil {
+		return err
+	}
+	if g.counters == nil {
+		g.counters = make(map[Resource]*counter)
+	}
+	return nil
+}
+
+// Limited reports whether resource has a cap
+func (g *Guard) Limited(resource Resource) bool {
+	if g == nil {
+		return false
+	}
+	_, ok := g.limits[resource]
+	return ok
+}
+
+// Allow checks whether amount more of resource fits in the caps, returning an
+// *ExceededError when it does not
+func (g *Guard) Allow(resource Resource, amount int) error {
+	if g == nil {
+		return nil
+	}
+	limit, ok := g.limits[resource]
+	if !ok {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c := g.counter(resource)
+	if limit.PerHour > 0 && (c.HourUsed >= limit.PerHour || c.HourUsed+amount > limit.PerHour) {
+		return &ExceededError{Resource: resource, Window: "hour", Limit: limit.PerHour, Reset: c.Hour.Add(time.Hour)}
+	}
+	if limit.PerDay > 0 && (c.DayUsed >= limit.PerDay || c.DayUsed+amount > limit.PerDay) {
+		return &ExceededError{Resource: resource, Window: "day", Limit: limit.PerDay, Reset: c.Day.AddDate(0, 0, 1)}
+	}
+	return nil
+}
+
+// Record adds amount to the usage of resource
+func (g *Guard) Record(resource Resource, amount int) {
+	if g == nil || amount <= 0 {
+		return
+	}
+	if _, ok := g.limits[resource]; !ok {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c := g.counter(resource)
+	c.HourUsed += amount
+	c.DayUsed += amount
+
+	if g.store != nil {
+		if err := g.store.Save(memoryNamespace, g.counters); err != nil {
+			g.logger.Warn("Failed to save quota usage", "error", err)
+		}
+	}
+}
+
+// counter returns the current counter for resource; callers hold g.mu
+func (g *Guard) counter(resource Resource) *counter {
+	c, ok := g.counters[resource]
+	if !ok {
+		c = &counter{}
+		g.counters[resource] = c
+	}
+	c.roll(g.now())
+	return c
+}
@@ -0,0 +1,223 @@
+// Package recordings manages the audio Bobo records: one directory per
+// session, and a retention policy deciding how long recordings are kept
+package recordings
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/history"
+)
+
+// sessionLayout names the session directories, after when Bobo started
+const sessionLayout = "20060102_150405"
+
+// audioExtensions are the files a clean-up may remove
+var audioExtensions = map[string]bool{".wav": true, ".flac": true, ".ogg": true, ".mp3": true}
+
+// Retention policies besides a count or an age
+const (
+	KeepAll  = "all"
+	KeepNone = "none"
+)
+
+// Store hands out recording paths in the session directory and enforces
+// the retention policy
+type Store struct {
+	dir       string
+	retention string
+	started   time.Time
+	logger    *slog.Logger
+
+	mu      sync.Mutex
+	session string // created on first use
+}
+
+// CleanReport is what a clean-up removed, or would remove on a dry run
+type CleanReport struct {
+	Removed []string `json:"removed"`
+	Bytes   int64    `json:"bytes"`
+	Kept    int      `json:"kept"`
+}
+
+// NewStore creates a store for the recordings under cfg.Dir
+func NewStore(cfg *config.RecordingsConfig) (*Store, error) {
+	if err := checkRetention(cfg.Retention); err != nil {
+		return nil, err
+	}
+	dir, err := filepath.Abs(cfg.Dir)
+	if err != nil {
+		dir = cfg.Dir
+	}
+	return &Store{
+		dir:       dir,
+		retention: cfg.Retention,
+		started:   time.Now(),
+		logger:    slog.Default(),
+	}, nil
+}
+
+// checkRetention validates a policy: all, none, a number of recordings (50)
+// or an age (7d, 2w, 12h)
+func checkRetention(retention string) error {
+	switch retention {
+	case KeepAll, KeepNone:
+		return nil
+	}
+	if count, err := strconv.Atoi(retention); err == nil {
+		if count < 0 {
+			return fmt.Errorf("invalid recording retention %q: negative count", retention)
+		}
+		return nil
+	}
+	if _, err := history.ParseSince(retention, time.Now()); err != nil || retention == "" {
+		return fmt.Errorf("invalid recording retention %q (use all, none, a count such as 50 or an age such as 7d)", retention)
+	}
+	return nil
+}
+
+// SessionDir returns the directory of this session's recordings, creating it
+func (s *Store) SessionDir() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.session != "" {
+		return s.session, nil
+	}
+	session := filepath.Join(s.dir, s.started.Format(sessionLayout))
+	if err := os.MkdirAll(session, 0755); err != nil {
+		return "", fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+	s.session = session
+	return session, nil
+}
+
+// NewPath names a new WAV recording in the session directory, such as
+// "desk_pet_recording_20240101_120000.000.wav"; without a store, or when the
+// directory can't be created, it falls back to the system temp directory
+func (s *Store) NewPath(prefix string) string {
+	name := fmt.Sprintf("%s_%s.wav", prefix, time.Now().Format("20060102_150405.000"))
+	if s == nil {
+		return filepath.Join(os.TempDir(), name)
+	}
+
+	dir, err := s.SessionDir()
+	if err != nil {
+		s.logger.Warn("Using the system temp directory for recordings", "error", err)
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, name)
+}
+
+// Clean removes the recordings the retention policy no longer keeps, and
+// the empty directories of past sessions; with dryRun it only reports them.
+// Only the session directories are looked at, so other files kept in
+// RECORDINGS_DIR are never removed
+func (s *Store) Clean(dryRun bool) (*CleanReport, error) {
+	if s == nil {
+		return &CleanReport{}, nil
+	}
+
+	type recording struct {
+		path     string
+		size     int64
+		modified time.Time
+	}
+	var found []recording
+	var dirs []string
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if path == s.dir {
+			return nil
+		}
+		if filepath.Dir(path) == s.dir && !isSession(entry) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			dirs = append(dirs, path)
+			return nil
+		}
+		if !audioExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		found = append(found, recording{path: path, size: info.Size(), modified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	// Newest first, so that a count keeps the latest ones
+	sort.Slice(found, func(i, j int) bool { return found[i].modified.After(found[j].modified) })
+
+	report := &CleanReport{}
+	for i, rec := range found {
+		if s.keeps(i, rec.modified) {
+			report.Kept++
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(rec.path); err != nil && !os.IsNotExist(err) {
+				return report, fmt.Errorf("failed to remove recording: %w", err)
+			}
+		}
+		report.Removed = append(report.Removed, rec.path)
+		report.Bytes += rec.size
+	}
+
+	// Deepest first, so that nested empty directories go too
+	if !dryRun {
+		s.mu.Lock()
+		session := s.session
+		s.mu.Unlock()
+		for i := len(dirs) - 1; i >= 0; i-- {
+			if dirs[i] != session {
+				os.Remove(dirs[i]) // fails, as intended, while not empty
+			}
+		}
+	}
+	return report, nil
+}
+
+// isSession reports whether entry is the directory of a session
+func isSession(entry fs.DirEntry) bool {
+	_, err := time.Parse(sessionLayout, entry.Name())
+	return entry.IsDir() && err == nil
+}
+
+// keeps reports whether the retention policy keeps the index-th newest
+// recording, last modified at modified
+func (s *Store) keeps(index int, modified time.Time) bool {
+	switch s.retention {
+	case KeepAll:
+		return true
+	case KeepNone:
+		return false
+	}
+	if count, err := strconv.Atoi(s.retention); err == nil {
+		return index < count
+	}
+	cutoff, _ := history.ParseSince(s.retention, time.Now())
+	return !modified.Before(cutoff)
+}
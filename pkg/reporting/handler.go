@@ -0,0 +1,56 @@
+package reporting
+
+import (
+	"context"
+	"log/slog"
+)
+
+// handler passes log records on to another handler, reporting those at
+// error level
+type handler struct {
+	next     slog.Handler
+	reporter *Reporter
+	attrs    []slog.Attr
+}
+
+// Handler wraps next so that every error logged through it is reported
+func (r *Reporter) Handler(next slog.Handler) slog.Handler {
+	if r == nil {
+		return next
+	}
+	return &handler{next: next, reporter: r}
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelError || h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelError {
+		attrs := make(map[string]string, len(h.attrs)+record.NumAttrs())
+		for _, attr := range h.attrs {
+			attrs[attr.Key] = attr.Value.String()
+		}
+		record.Attrs(func(attr slog.Attr) bool {
+			attrs[attr.Key] = attr.Value.String()
+			return true
+		})
+		h.reporter.Report("error", record.Message, attrs)
+	}
+	if !h.next.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{
+		next:     h.next.WithAttrs(attrs),
+		reporter: h.reporter,
+		attrs:    append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...),
+	}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), reporter: h.reporter, attrs: h.attrs}
+}
@@ -0,0 +1,210 @@
+// Package reporting sends errors and crashes to Sentry, or a self-hosted
+// endpoint, for users who opt in; personal data is scrubbed before anything
+// leaves the machine
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxReportsPerHour caps the reports sent, so a failure loop can't flood
+// the endpoint
+const maxReportsPerHour = 20
+
+// flushTimeout is how long Flush waits for reports still being sent
+const flushTimeout = 5 * time.Second
+
+// event is a report in the Sentry event format, which self-hosted
+// endpoints receive as is
+type event struct {
+	EventID   string                       `json:"event_id"`
+	Timestamp string                       `json:"timestamp"`
+	Level     string                       `json:"level"`
+	Platform  string                       `json:"platform"`
+	Release   string                       `json:"release"`
+	Message   string                       `json:"message"`
+	Extra     map[string]string            `json:"extra,omitempty"`
+	Contexts  map[string]map[string]string `json:"contexts"`
+}
+
+// Reporter sends error reports in the background
+type Reporter struct {
+	endpoint string
+	auth     string // X-Sentry-Auth header, empty for a plain endpoint
+	release  string
+	client   *http.Client
+	logger   *slog.Logger
+	pending  sync.WaitGroup
+
+	mu     sync.Mutex
+	seen   map[string]bool // messages already reported
+	window time.Time       // start of the current rate limit hour
+	sent   int
+}
+
+// New creates a reporter for dsn, which is either a Sentry DSN
+// (https://<key>@<host>/<project>) or the URL of an endpoint that accepts
+// the JSON events; an empty dsn disables reporting and returns nil
+func New(dsn, version string) (*Reporter, error) {
+	if dsn == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(dsn)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("invalid error report DSN")
+	}
+
+	r := &Reporter{
+		endpoint: dsn,
+		release:  "bobo@" + version,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   slog.Default(),
+		seen:     make(map[string]bool),
+	}
+	if key := u.User.Username(); key != "" {
+		project := path.Base(u.Path)
+		prefix := strings.TrimSuffix(path.Dir(u.Path), "/")
+		r.endpoint = fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project)
+		r.auth = fmt.Sprintf("Sentry sentry_version=7, sentry_client=bobo/%s, sentry_key=%s", version, key)
+	}
+	return r, nil
+}
+
+// Report sends a scrubbed report of message with the given attributes; it
+// returns at once, and does nothing on a nil reporter
+func (r *Reporter) Report(level, message string, attrs map[string]string) {
+	if r == nil {
+		return
+	}
+	message = Scrub(message)
+	if !r.allow(message) {
+		return
+	}
+
+	extra := make(map[string]string, len(attrs))
+	for key, value := range attrs {
+		extra[key] = scrubAttr(key, value)
+	}
+	ev := event{
+		EventID:   eventID(),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Level:     level,
+		Platform:  "go",
+		Release:   r.release,
+		Message:   message,
+		Extra:     extra,
+		Contexts: map[string]map[string]string{
+			"os":      {"name": runtime.GOOS},
+			"device":  {"arch": runtime.GOARCH},
+			"runtime": {"name": "go", "version": runtime.Version()},
+		},
+	}
+
+	r.pending.Add(1)
+	go func() {
+		defer r.pending.Done()
+		if err := r.send(ev); err != nil {
+			r.logger.Debug("Error report failed", "error", err)
+		}
+	}()
+}
+
+// allow reports whether message may be sent: each message once per run,
+// and no more than maxReportsPerHour
+func (r *Reporter) allow(message string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.seen[message] {
+		return false
+	}
+	if time.Since(r.window) > time.Hour {
+		r.window = time.Now()
+		r.sent = 0
+	}
+	if r.sent >= maxReportsPerHour {
+		return false
+	}
+	r.seen[message] = true
+	r.sent++
+	return true
+}
+
+// send posts one event
+func (r *Reporter) send(ev event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.auth != "" {
+		req.Header.Set("X-Sentry-Auth", r.auth)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("report rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Flush waits, for a few seconds at most, for reports still being sent;
+// call it before the program exits
+func (r *Reporter) Flush() {
+	if r == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		r.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(flushTimeout):
+	}
+}
+
+// Recover reports a panic with its stack and panics again; defer it at the
+// top of main and of long-running goroutines
+func (r *Reporter) Recover() {
+	if r == nil {
+		return
+	}
+	if p := recover(); p != nil {
+		r.Report("fatal", fmt.Sprintf("panic: %v", p), map[string]string{"stack": string(debug.Stack())})
+		r.Flush()
+		panic(p)
+	}
+}
+
+// eventID returns a random 32-character hex event ID
+func eventID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
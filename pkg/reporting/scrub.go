@@ -0,0 +1,57 @@
+package reporting
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redacted replaces personal data in reports
+const redacted = "[redacted]"
+
+// sensitiveKeys are log attributes that carry what the user said or heard,
+// or who they talk to; they are never reported
+var sensitiveKeys = map[string]bool{
+	"transcription": true, "text": true, "question": true, "answer": true,
+	"response": true, "reply": true, "prompt": true, "query": true,
+	"phrase": true, "phrases": true, "contact": true, "email": true,
+	"phone": true, "to": true, "from": true, "title": true, "summary": true,
+	"output": true, "stderr": true, "dropped": true, "user": true,
+}
+
+// scrubPatterns replace personal data in messages and attributes, in order
+var scrubPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// Quoted text is usually something the user said or typed
+	{regexp.MustCompile(`"(?:[^"\\]|\\.)*"`), `"` + redacted + `"`},
+	{regexp.MustCompile(`«[^»]*»`), `«` + redacted + `»`},
+	// Credentials in URLs, headers and key=value pairs
+	{regexp.MustCompile(`(?i)(https?://)[^/\s:@]+(?::[^/\s@]*)?@`), `${1}`},
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+\S+`), `$1 ` + redacted},
+	{regexp.MustCompile(`(?i)\b(token|key|secret|password|passwd|auth|sid|dsn)(=|:\s*)\S+`), `$1$2` + redacted},
+	{regexp.MustCompile(`(?i)(https?://[^\s?#]+)\?\S*`), `$1?` + redacted},
+	// Contact details and addresses
+	{regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`), redacted},
+	{regexp.MustCompile(`\+?\d[\d ().-]{7,}\d`), redacted},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), redacted},
+	// The user name in home directories
+	{regexp.MustCompile(`(/home/|/Users/|\\Users\\)[^/\\\s]+`), `$1` + redacted},
+}
+
+// Scrub removes personal data from text: quoted speech, credentials, email
+// addresses, phone numbers, IP addresses and user names in paths
+func Scrub(text string) string {
+	for _, scrub := range scrubPatterns {
+		text = scrub.pattern.ReplaceAllString(text, scrub.replacement)
+	}
+	return text
+}
+
+// scrubAttr returns the value of a log attribute as it may be reported
+func scrubAttr(key, value string) string {
+	if sensitiveKeys[strings.ToLower(key)] {
+		return redacted
+	}
+	return Scrub(value)
+}
@@ -0,0 +1,374 @@
+// Package server answers text questions over HTTP for several people at
+// once ("bobo serve"): each API key is a tenant with its own conversation
+// log, memory, quotas and allowed skills
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
+	"github.com/jparrill/bobo-desk-pet/pkg/claude"
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/history"
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+	"github.com/jparrill/bobo-desk-pet/pkg/quota"
+	"github.com/jparrill/bobo-desk-pet/pkg/skills"
+)
+
+// maxRequestBody caps the JSON body of a question
+const maxRequestBody = 64 << 10
+
+// corsMaxAge is how long browsers may cache a preflight answer, in seconds
+const corsMaxAge = "600"
+
+// askRequest is the body of POST /v1/ask
+type askRequest struct {
+	Text string `json:"text"`
+}
+
+// askResponse is the reply to POST /v1/ask
+type askResponse struct {
+	Answer  string     `json:"answer"`
+	Skill   string     `json:"skill,omitempty"`
+	Intent  string     `json:"intent,omitempty"`
+	Sources []string   `json:"sources,omitempty"`
+	Card    *card.Card `json:"card,omitempty"`
+}
+
+// errorResponse is the body of every failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// tenantKey is the request context key of the authenticated tenant
+type tenantKey struct{}
+
+// spokenKey is the context key collecting what script skills bobo.speak
+type spokenKey struct{}
+
+// tenantSession is a tenant's state: everything it stores is kept apart from the
+// other tenants, and its questions are answered one at a time
+type tenantSession struct {
+	tenant  Tenant
+	vertex  *config.VertexAIConfig
+	client  *claude.SmartClient
+	history *history.Store
+	skills  *skills.Registry
+
+	mu sync.Mutex
+}
+
+// Server is the multi-tenant question API
+type Server struct {
+	config   *config.Config
+	sessions []*tenantSession
+	logger   *slog.Logger
+}
+
+// New creates the server for the tenants in SERVE_TENANTS_FILE, each with
+// its stores under SERVE_DIR/<tenant>
+func New(ctx context.Context, cfg *config.Config) (*Server, error) {
+	tenants, err := LoadTenants(cfg.Serve.TenantsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Installed skills are loaded once and offered to the tenants allowed them
+	installed, err := skills.NewInstaller(cfg.Skills.Dir, cfg.Skills.IndexURL).Load()
+	if err != nil {
+		slog.Warn("Failed to load installed skills", "error", err)
+	}
+
+	s := &Server{config: cfg, logger: slog.Default()}
+	for _, tenant := range tenants {
+		session, err := s.newSession(ctx, tenant, installed)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant.Name, err)
+		}
+		s.sessions = append(s.sessions, session)
+	}
+	return s, nil
+}
+
+// newSession opens the tenant's stores and builds its skills and Claude client
+func (s *Server) newSession(ctx context.Context, tenant Tenant, installed []*skills.ExternalSkill) (*tenantSession, error) {
+	dir := filepath.Join(s.config.Serve.Dir, tenant.Name)
+	store, err := memory.Open(filepath.Join(dir, "memory"))
+	if err != nil {
+		return nil, err
+	}
+	log, err := history.NewStore(filepath.Join(dir, "history"))
+	if err != nil {
+		return nil, err
+	}
+
+	client := claude.NewSmartClient(s.config.VertexAI)
+	if guard := quota.NewGuard(tenant.quotaConfig(s.config.Quota)); guard != nil {
+		if err := guard.SetStore(store); err != nil {
+			return nil, fmt.Errorf("failed to restore quota usage: %w", err)
+		}
+		client.SetQuota(guard)
+	}
+	if err := client.Initialize(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize Claude client: %w", err)
+	}
+
+	// Only the skills that answer in text; timers and reminders need someone
+	// at the desk to hear them go off
+	available := []skills.Skill{
+		skills.NewTutorSkill(client, store, s.config.Skills.TutorLanguage),
+		skills.NewFlashcardsSkill(client, store),
+		skills.NewStorySkill(client, s.config.Skills.StoryRate),
+		skills.NewCountdownSkill(store),
+	}
+	for _, skill := range installed {
+		available = append(available, skill)
+	}
+	scripts, err := skills.LoadScripts(s.config.Skills.ScriptsDir, &skills.ScriptHost{
+		Completer: client,
+		Speak:     collectSpoken,
+		Store:     store,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to load script skills", "tenant", tenant.Name, "error", err)
+	}
+	for _, skill := range scripts {
+		available = append(available, skill)
+	}
+
+	registry := skills.NewRegistry()
+	for _, skill := range available {
+		if tenant.allows(skill.Name()) {
+			registry.Register(skill)
+		}
+	}
+
+	return &tenantSession{
+		tenant:  tenant,
+		vertex:  s.config.VertexAI,
+		client:  client,
+		history: log,
+		skills:  registry,
+	}, nil
+}
+
+// Run serves the API on SERVE_LISTEN until ctx is cancelled, over TLS when
+// SERVE_TLS_CERT and SERVE_TLS_KEY are set; API keys aren't sent in clear
+// text, so without TLS it only listens on this machine
+func (s *Server) Run(ctx context.Context) error {
+	cfg := s.config.Serve
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		return fmt.Errorf("SERVE_TLS_CERT and SERVE_TLS_KEY must be set together")
+	}
+	listener, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfg.Listen, err)
+	}
+	if tcp, ok := listener.Addr().(*net.TCPAddr); cfg.TLSCert == "" && (!ok || !tcp.IP.IsLoopback()) {
+		listener.Close()
+		return fmt.Errorf("SERVE_TLS_CERT and SERVE_TLS_KEY are required to listen on %s", cfg.Listen)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/ask", s.authorized(s.handleAsk))
+	mux.HandleFunc("GET /v1/history", s.authorized(s.handleHistory))
+	server := &http.Server{Handler: s.cors(mux), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	s.logger.Info("🌐 Serving the question API", "addr", listener.Addr().String(), "tenants", len(s.sessions), "tls", cfg.TLSCert != "")
+	if cfg.TLSCert != "" {
+		err = server.ServeTLS(listener, cfg.TLSCert, cfg.TLSKey)
+	} else {
+		err = server.Serve(listener)
+	}
+	if err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// cors lets the web pages of SERVE_ALLOWED_ORIGINS call the API, answering
+// their preflight requests before authentication
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !s.allowsOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowsOrigin reports whether SERVE_ALLOWED_ORIGINS lists origin, or "*"
+func (s *Server) allowsOrigin(origin string) bool {
+	for _, allowed := range s.config.Serve.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorized finds the tenant of the request's API key, rejecting requests
+// without a known one
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		// Compare against every key so that timing doesn't tell which matched
+		var found *tenantSession
+		for _, session := range s.sessions {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(session.tenant.APIKey)) == 1 {
+				found = session
+			}
+		}
+		if found == nil {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), tenantKey{}, found)))
+	}
+}
+
+// handleAsk answers a question with the tenant's skills or Claude
+func (s *Server) handleAsk(w http.ResponseWriter, r *http.Request) {
+	session := r.Context().Value(tenantKey{}).(*tenantSession)
+
+	var req askRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request: " + err.Error()})
+		return
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "text is required"})
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	reply, err := session.answer(r.Context(), text)
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		s.logger.Warn("Failed to answer", "tenant", session.tenant.Name, "error", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to answer"})
+		return
+	}
+	writeJSON(w, http.StatusOK, reply)
+}
+
+// handleHistory lists the tenant's conversation log, ?since=7d by default
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	session := r.Context().Value(tenantKey{}).(*tenantSession)
+
+	period := r.URL.Query().Get("since")
+	if period == "" {
+		period = "7d"
+	}
+	since, err := history.ParseSince(period, time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	interactions, err := session.history.Since(since)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	if interactions == nil {
+		interactions = []history.Interaction{}
+	}
+	writeJSON(w, http.StatusOK, interactions)
+}
+
+// answer runs the first of the tenant's skills that matches, or asks Claude,
+// and records the exchange in the tenant's log
+func (s *tenantSession) answer(ctx context.Context, text string) (*askResponse, error) {
+	start := time.Now()
+	reply := &askResponse{}
+	interaction := &history.Interaction{Transcription: text}
+
+	if skill, req := s.skills.Match(text); skill != nil {
+		var spoken []string
+		result, err := skill.Handle(context.WithValue(ctx, spokenKey{}, &spoken), req)
+		if err != nil {
+			return nil, fmt.Errorf("skill %s failed: %w", skill.Name(), err)
+		}
+		parts := spoken
+		if result != nil && result.Text != "" {
+			parts = append(parts, result.Text)
+			reply.Card = result.Card
+		}
+		reply.Answer = strings.Join(parts, " ")
+		reply.Skill = skill.Name()
+		interaction.Intent = skill.Name()
+	} else {
+		answer, err := s.client.Ask(ctx, []claude.Message{{Role: "user", Content: text}})
+		if err != nil {
+			return nil, err
+		}
+		reply.Answer = answer.Text
+		reply.Intent = answer.Intent
+		reply.Sources = answer.Sources
+		reply.Card = answer.Card
+		interaction.Intent = answer.Intent
+		interaction.Sources = answer.Sources
+		interaction.InputTokens = answer.Usage.InputTokens
+		interaction.OutputTokens = answer.Usage.OutputTokens
+		interaction.Cost = answer.Usage.Cost(s.vertex.InputTokenPrice, s.vertex.OutputTokenPrice)
+	}
+
+	interaction.Response = reply.Answer
+	interaction.LatencyMs = time.Since(start).Milliseconds()
+	if err := s.history.Append(interaction); err != nil {
+		slog.Warn("Failed to record interaction", "tenant", s.tenant.Name, "error", err)
+	}
+	return reply, nil
+}
+
+// collectSpoken adds what a script skill says with bobo.speak to the answer
+func collectSpoken(ctx context.Context, text string) {
+	if spoken, ok := ctx.Value(spokenKey{}).(*[]string); ok {
+		*spoken = append(*spoken, text)
+	}
+}
+
+// writeJSON sends v with the status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
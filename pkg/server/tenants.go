@@ -0,0 +1,91 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// minAPIKeyLength rejects keys short enough to guess
+const minAPIKeyLength = 16
+
+// tenantName keeps tenant names safe to use as directory names
+var tenantName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Tenant is one API key of the server with what it may use
+type Tenant struct {
+	Name   string `json:"name"`
+	APIKey string `json:"api_key"`
+	// Skills are the names of the skills the tenant may use; omitted allows
+	// every skill, an empty list none (Claude answers only)
+	Skills []string `json:"skills,omitempty"`
+	// Quota caps the tenant's usage; unset caps fall back to QUOTA_*
+	Quota TenantQuota `json:"quota"`
+}
+
+// TenantQuota is the per-tenant override of the QUOTA_* caps
+type TenantQuota struct {
+	TokensPerHour   int `json:"tokens_per_hour,omitempty"`
+	TokensPerDay    int `json:"tokens_per_day,omitempty"`
+	SearchesPerHour int `json:"searches_per_hour,omitempty"`
+	SearchesPerDay  int `json:"searches_per_day,omitempty"`
+}
+
+// quotaConfig merges the tenant's caps over the global ones
+func (t *Tenant) quotaConfig(global *config.QuotaConfig) *config.QuotaConfig {
+	cfg := *global
+	override := func(value *int, tenant int) {
+		if tenant > 0 {
+			*value = tenant
+		}
+	}
+	override(&cfg.VertexTokensPerHour, t.Quota.TokensPerHour)
+	override(&cfg.VertexTokensPerDay, t.Quota.TokensPerDay)
+	override(&cfg.SearchesPerHour, t.Quota.SearchesPerHour)
+	override(&cfg.SearchesPerDay, t.Quota.SearchesPerDay)
+	return &cfg
+}
+
+// allows reports whether the tenant may use the named skill
+func (t *Tenant) allows(skill string) bool {
+	return t.Skills == nil || slices.Contains(t.Skills, skill)
+}
+
+// LoadTenants reads the tenants file, a JSON array of tenants
+func LoadTenants(path string) ([]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var tenants []Tenant
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("invalid tenants file: %w", err)
+	}
+	if len(tenants) == 0 {
+		return nil, fmt.Errorf("no tenants in %s", path)
+	}
+
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, tenant := range tenants {
+		if !tenantName.MatchString(tenant.Name) {
+			return nil, fmt.Errorf("invalid tenant name %q (lowercase letters, digits, - and _)", tenant.Name)
+		}
+		if len(tenant.APIKey) < minAPIKeyLength {
+			return nil, fmt.Errorf("tenant %s needs an api_key of at least %d characters", tenant.Name, minAPIKeyLength)
+		}
+		if names[tenant.Name] {
+			return nil, fmt.Errorf("tenant %s is listed twice", tenant.Name)
+		}
+		if keys[tenant.APIKey] {
+			return nil, fmt.Errorf("tenant %s shares its api_key with another tenant", tenant.Name)
+		}
+		names[tenant.Name], keys[tenant.APIKey] = true, true
+	}
+	return tenants, nil
+}
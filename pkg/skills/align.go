@@ -0,0 +1,109 @@
+package skills
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WordScore is the alignment outcome for one expected word
+type WordScore struct {
+	Expected string
+	Heard    string
+	Score    float64
+}
+
+// AlignScore aligns the heard words against the expected words and returns an
+// overall similarity between 0 and 1 plus the per-word breakdown
+func AlignScore(expected, heard string) (float64, []WordScore) {
+	exp := normalizeWords(expected)
+	got := normalizeWords(heard)
+	if len(exp) == 0 {
+		return 0, nil
+	}
+
+	// Word-level edit distance where substitution cost is 1 - character similarity
+	rows, cols := len(exp)+1, len(got)+1
+	cost := make([][]float64, rows)
+	for i := range cost {
+		cost[i] = make([]float64, cols)
+		cost[i][0] = float64(i)
+	}
+	for j := 0; j < cols; j++ {
+		cost[0][j] = float64(j)
+	}
+
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			substitute := cost[i-1][j-1] + (1 - wordSimilarity(exp[i-1], got[j-1]))
+			remove := cost[i-1][j] + 1
+			insert := cost[i][j-1] + 1
+			cost[i][j] = min(substitute, remove, insert)
+		}
+	}
+
+	// Walk back to recover which heard word lined up with each expected word
+	scores := make([]WordScore, len(exp))
+	i, j := len(exp), len(got)
+	for i > 0 {
+		switch {
+		case j > 0 && cost[i][j] == cost[i-1][j-1]+(1-wordSimilarity(exp[i-1], got[j-1])):
+			scores[i-1] = WordScore{Expected: exp[i-1], Heard: got[j-1], Score: wordSimilarity(exp[i-1], got[j-1])}
+			i--
+			j--
+		case j > 0 && cost[i][j] == cost[i][j-1]+1:
+			j--
+		default:
+			scores[i-1] = WordScore{Expected: exp[i-1]}
+			i--
+		}
+	}
+
+	longest := max(len(exp), len(got))
+	overall := 1 - cost[len(exp)][len(got)]/float64(longest)
+	if overall < 0 {
+		overall = 0
+	}
+	return overall, scores
+}
+
+// wordSimilarity returns 1 - normalized Levenshtein distance between two words
+func wordSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein computes the character edit distance
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := prev[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+			curr[j] = min(substitution, prev[j]+1, curr[j-1]+1)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// normalizeWords lowercases and strips punctuation
+func normalizeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
@@ -0,0 +1,108 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/contacts"
+)
+
+// contactQuestions map a question about someone to the detail it asks for
+var contactQuestions = []struct {
+	detail  string
+	pattern *regexp.Regexp
+}{
+	{"birthday", regexp.MustCompile(`(?i)(?:cu[aá]ndo es el cumplea[nñ]os de|cu[aá]ndo cumple(?: a[nñ]os)?)\s+(.+?)[?.!]*$`)},
+	{"birthday", regexp.MustCompile(`(?i)when(?: is|'s) (.+?)(?:'s)? birthday[?.!]*$`)},
+	{"phone", regexp.MustCompile(`(?i)(?:cu[aá]l es el (?:tel[eé]fono|n[uú]mero)(?: de tel[eé]fono)? de|tel[eé]fono de)\s+(.+?)[?.!]*$`)},
+	{"phone", regexp.MustCompile(`(?i)what(?: is|'s) (.+?)'s (?:phone|number|phone number)[?.!]*$`)},
+	{"email", regexp.MustCompile(`(?i)(?:cu[aá]l es el (?:correo|email|e-mail)(?: electr[oó]nico)? de|correo de)\s+(.+?)[?.!]*$`)},
+	{"email", regexp.MustCompile(`(?i)what(?: is|'s) (.+?)'s (?:email|e-mail|email address)[?.!]*$`)},
+}
+
+// ContactsSkill answers questions about the people in the user's contacts
+type ContactsSkill struct {
+	directory *contacts.Directory
+	now       func() time.Time
+}
+
+// NewContactsSkill creates the contacts skill
+func NewContactsSkill(directory *contacts.Directory) *ContactsSkill {
+	return &ContactsSkill{
+		directory: directory,
+		now:       time.Now,
+	}
+}
+
+// Name implements Skill
+func (c *ContactsSkill) Name() string {
+	return "contacts"
+}
+
+// Match implements Skill
+func (c *ContactsSkill) Match(utterance string) (*Request, bool) {
+	utterance = strings.TrimSpace(utterance)
+	for _, question := range contactQuestions {
+		if matches := question.pattern.FindStringSubmatch(utterance); matches != nil {
+			return &Request{Slots: map[string]string{
+				"detail": question.detail,
+				"name":   strings.TrimSpace(matches[1]),
+			}}, true
+		}
+	}
+	return nil, false
+}
+
+// Handle implements Skill
+func (c *ContactsSkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	name := req.Slot("name", "")
+	contact, ok := c.directory.Find(name)
+	if !ok {
+		return &Result{Text: fmt.Sprintf("No encuentro a %s en tus contactos.", name)}, nil
+	}
+
+	switch req.Slot("detail", "") {
+	case "birthday":
+		next, age, ok := contact.NextBirthday(c.now())
+		if !ok {
+			return &Result{Text: fmt.Sprintf("No sé cuándo es el cumpleaños de %s.", contact.Name)}, nil
+		}
+		text := fmt.Sprintf("El cumpleaños de %s es el %d de %s", contact.Name, next.Day(), spanishMonths[next.Month()-1])
+		if days := int(next.Sub(c.today()).Hours() / 24); days == 0 {
+			text = fmt.Sprintf("¡El cumpleaños de %s es hoy", contact.Name)
+		} else if days <= 30 {
+			text += fmt.Sprintf(", dentro de %d días", days)
+		}
+		if age > 0 {
+			text += fmt.Sprintf(" y cumple %d", age)
+		}
+		return &Result{Text: text + "."}, nil
+
+	case "phone":
+		if len(contact.Phones) == 0 {
+			return &Result{Text: fmt.Sprintf("No tengo el teléfono de %s.", contact.Name)}, nil
+		}
+		return &Result{Text: fmt.Sprintf("El teléfono de %s es %s.", contact.Name, contact.Phones[0])}, nil
+
+	default:
+		if len(contact.Emails) == 0 {
+			return &Result{Text: fmt.Sprintf("No tengo el correo de %s.", contact.Name)}, nil
+		}
+		return &Result{Text: fmt.Sprintf("El correo de %s es %s.", contact.Name, contact.Emails[0])}, nil
+	}
+}
+
+// today returns the start of the current day
+func (c *ContactsSkill) today() time.Time {
+	now := c.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// spanishMonths names the months for spoken dates
+var spanishMonths = []string{
+	"enero", "febrero", "marzo", "abril", "mayo", "junio",
+	"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+}
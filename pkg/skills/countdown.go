@@ -0,0 +1,408 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+)
+
+// countdownsNamespace is where countdowns are kept in the memory store
+const countdownsNamespace = "countdowns"
+
+// countdownAnnounceHour is the earliest hour milestones are announced at, so
+// crossing one at midnight waits for the morning
+const countdownAnnounceHour = 9
+
+// countdownMilestones are the days left that are announced, largest first
+var countdownMilestones = []int{100, 50, 30, 14, 7, 3, 1, 0}
+
+var (
+	countdownQueryPattern  = regexp.MustCompile(`(?i)(?:cu[aá]nto (?:queda|falta)|cu[aá]ntos d[ií]as (?:quedan|faltan)|how many days (?:are )?(?:left )?(?:until|till|to|before)|how long (?:until|till|before))\s+(?:para\s+|hasta\s+)?(.+?)[.!?]*$`)
+	countdownAddPattern    = regexp.MustCompile(`(?i)(?:(?:a[nñ]ade|crea|pon)(?:me)?(?: una)? cuenta atr[aá]s (?:para|de|hasta)|(?:start |add )?(?:a )?count ?down (?:to|for|until))\s+(.+?)[.!?]*$`)
+	countdownListPattern   = regexp.MustCompile(`(?i)(?:mis cuentas atr[aá]s|qu[eé] cuentas atr[aá]s|my countdowns|list (?:my )?countdowns)`)
+	countdownRemovePattern = regexp.MustCompile(`(?i)(?:(?:borra|quita|elimina) la cuenta atr[aá]s (?:de|para|hasta)|(?:delete|remove) (?:the |my )?countdown (?:to|for|until))\s+(.+?)[.!?]*$`)
+
+	spokenMonth          = `(enero|febrero|marzo|abril|mayo|junio|julio|agosto|septiembre|setiembre|octubre|noviembre|diciembre|january|february|march|april|may|june|july|august|september|october|november|december)`
+	spanishDatePattern   = regexp.MustCompile(`(?i)(?:\b(?:el|que es el|es el)\s+)?\b(\d{1,2})\s+de\s+` + spokenMonth + `(?:\s+(?:de|del)\s+(\d{4}))?\b`)
+	englishDatePattern   = regexp.MustCompile(`(?i)(?:\b(?:on|is on|is)\s+)?\b` + spokenMonth + `\s+(\d{1,2})(?:st|nd|rd|th)?(?:,?\s+(\d{4}))?\b`)
+	englishOfDatePattern = regexp.MustCompile(`(?i)(?:\b(?:on|is on|is)\s+)?(?:\bthe\s+)?\b(\d{1,2})(?:st|nd|rd|th)?\s+of\s+` + spokenMonth + `(?:,?\s+(\d{4}))?\b`)
+	numericDatePattern   = regexp.MustCompile(`(?i)(?:\b(?:el|on)\s+)?\b(\d{1,2})/(\d{1,2})(?:/(\d{4}))?\b`)
+	nameArticles         = regexp.MustCompile(`(?i)^(?:el|la|los|las|mi|mis|my|the|our|nuestro|nuestra|nuestras|nuestros)\s+`)
+)
+
+// spokenMonths maps month names in Spanish and English
+var spokenMonths = map[string]time.Month{
+	"enero": time.January, "febrero": time.February, "marzo": time.March, "abril": time.April,
+	"mayo": time.May, "junio": time.June, "julio": time.July, "agosto": time.August,
+	"septiembre": time.September, "setiembre": time.September, "octubre": time.October,
+	"noviembre": time.November, "diciembre": time.December,
+	"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+	"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+	"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+}
+
+// monthNames are the months as Bobo says them
+var monthNames = []string{"", "enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"}
+
+// Countdown is a named event counted down to
+type Countdown struct {
+	Name string    `json:"name"`
+	Date time.Time `json:"date"`
+	// Announced is the last milestone announced, in days left
+	Announced int `json:"announced"`
+}
+
+// Milestone is a countdown that reached a milestone and what to announce
+type Milestone struct {
+	Name string
+	Text string
+}
+
+// countdownList is the persisted set of countdowns
+type countdownList struct {
+	Countdowns []Countdown `json:"countdowns"`
+}
+
+// CountdownSkill keeps named countdowns to events ("¿cuánto queda para las
+// vacaciones?") and announces them at milestones
+type CountdownSkill struct {
+	store  *memory.Store
+	now    func() time.Time
+	logger *slog.Logger
+	mu     sync.Mutex
+}
+
+// NewCountdownSkill creates the countdown skill
+func NewCountdownSkill(store *memory.Store) *CountdownSkill {
+	return &CountdownSkill{
+		store:  store,
+		now:    time.Now,
+		logger: slog.Default(),
+	}
+}
+
+// Name implements Skill
+func (c *CountdownSkill) Name() string {
+	return "countdown"
+}
+
+// Match implements Skill
+func (c *CountdownSkill) Match(utterance string) (*Request, bool) {
+	utterance = strings.TrimSpace(utterance)
+	if countdownListPattern.MatchString(utterance) {
+		return &Request{Slots: map[string]string{"action": "list"}}, true
+	}
+	if matches := countdownRemovePattern.FindStringSubmatch(utterance); matches != nil {
+		return &Request{Slots: map[string]string{"action": "remove", "name": matches[1]}}, true
+	}
+
+	action, event := "", ""
+	if matches := countdownAddPattern.FindStringSubmatch(utterance); matches != nil {
+		action, event = "add", matches[1]
+	} else if matches := countdownQueryPattern.FindStringSubmatch(utterance); matches != nil {
+		action, event = "query", matches[1]
+	} else {
+		return nil, false
+	}
+
+	name, date, ok := parseEventDate(event, c.now())
+	slots := map[string]string{"action": action, "name": name}
+	if ok {
+		slots["date"] = date.Format(time.DateOnly)
+	} else if action == "query" {
+		// "¿Cuánto queda para que acabe el partido?" is for Claude unless
+		// there's a countdown by that name
+		c.mu.Lock()
+		list, err := c.load()
+		c.mu.Unlock()
+		if err != nil || findCountdown(list.Countdowns, name) < 0 {
+			return nil, false
+		}
+	}
+	return &Request{Slots: slots}, true
+}
+
+// Handle implements Skill
+func (c *CountdownSkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	list, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	name := req.Slot("name", "")
+
+	switch req.Slot("action", "") {
+	case "list":
+		return listCountdowns(list.Countdowns, now), nil
+	case "remove":
+		i := findCountdown(list.Countdowns, name)
+		if i < 0 {
+			return &Result{Text: fmt.Sprintf("No tengo ninguna cuenta atrás para %s.", spokenEvent(name))}, nil
+		}
+		removed := list.Countdowns[i]
+		list.Countdowns = append(list.Countdowns[:i], list.Countdowns[i+1:]...)
+		if err := c.store.Save(countdownsNamespace, list); err != nil {
+			return nil, err
+		}
+		return &Result{Text: fmt.Sprintf("Vale, he borrado la cuenta atrás para %s.", spokenEvent(removed.Name))}, nil
+	}
+
+	// A date saves the countdown, or moves it; without one it must exist
+	if value := req.Slot("date", ""); value != "" {
+		date, err := time.ParseInLocation(time.DateOnly, value, now.Location())
+		if err != nil {
+			return nil, fmt.Errorf("invalid countdown date %q: %w", value, err)
+		}
+		if name == "" {
+			return &Result{Text: "¿Para qué es la cuenta atrás? Prueba con \"cuánto queda para mi viaje el 3 de marzo\"."}, nil
+		}
+		countdown := Countdown{Name: name, Date: date, Announced: nextMilestone(daysUntil(date, now))}
+		if i := findCountdown(list.Countdowns, name); i >= 0 {
+			list.Countdowns[i] = countdown
+		} else {
+			list.Countdowns = append(list.Countdowns, countdown)
+		}
+		if err := c.store.Save(countdownsNamespace, list); err != nil {
+			return nil, err
+		}
+		return &Result{Text: describeCountdown(countdown, now) + " Te avisaré cuando se acerque."}, nil
+	}
+
+	i := findCountdown(list.Countdowns, name)
+	if i < 0 {
+		return &Result{Text: fmt.Sprintf("No sé cuándo es %s. Dímelo con la fecha, por ejemplo \"cuánto queda para %s el 3 de marzo\".", spokenEvent(name), name)}, nil
+	}
+	return &Result{Text: describeCountdown(list.Countdowns[i], now)}, nil
+}
+
+// Milestones returns the countdowns that just reached a milestone (30 days
+// left, a week, tomorrow, today...) to be announced
+func (c *CountdownSkill) Milestones(now time.Time) []Milestone {
+	if now.Hour() < countdownAnnounceHour {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list, err := c.load()
+	if err != nil {
+		c.logger.Warn("Failed to load countdowns", "error", err)
+		return nil
+	}
+
+	var announcements []Milestone
+	for i, countdown := range list.Countdowns {
+		days := daysUntil(countdown.Date, now)
+		if days < 0 {
+			continue
+		}
+		if milestone := nextMilestone(days); milestone < countdown.Announced {
+			list.Countdowns[i].Announced = milestone
+			announcements = append(announcements, Milestone{Name: countdown.Name, Text: describeCountdown(countdown, now)})
+		}
+	}
+	if len(announcements) > 0 {
+		if err := c.store.Save(countdownsNamespace, list); err != nil {
+			c.logger.Warn("Failed to save countdowns", "error", err)
+		}
+	}
+	return announcements
+}
+
+// load reads the countdowns from the memory store
+func (c *CountdownSkill) load() (*countdownList, error) {
+	list := &countdownList{}
+	if err := c.store.Load(countdownsNamespace, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// listCountdowns reads out the upcoming countdowns, soonest first
+func listCountdowns(countdowns []Countdown, now time.Time) *Result {
+	sort.Slice(countdowns, func(i, j int) bool { return countdowns[i].Date.Before(countdowns[j].Date) })
+	var parts []string
+	table := card.New("Cuentas atrás", "Fecha", "Evento", "Días")
+	for _, countdown := range countdowns {
+		days := daysUntil(countdown.Date, now)
+		if days < 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s, %s", spokenEvent(countdown.Name), daysLeft(days)))
+		table.Add(countdown.Date.Format("02/01/2006"), countdown.Name, strconv.Itoa(days))
+	}
+	if len(parts) == 0 {
+		return &Result{Text: "No tienes cuentas atrás. Prueba con \"cuánto queda para mis vacaciones el 1 de agosto\"."}
+	}
+	return &Result{Text: fmt.Sprintf("Tienes %d: %s.", len(parts), strings.Join(parts, "; ")), Card: table}
+}
+
+// describeCountdown says how long is left for a countdown
+func describeCountdown(countdown Countdown, now time.Time) string {
+	event := spokenEvent(countdown.Name)
+	date := fmt.Sprintf("el %s %d de %s", dayNames[countdown.Date.Weekday()], countdown.Date.Day(), monthNames[countdown.Date.Month()])
+	switch days := daysUntil(countdown.Date, now); {
+	case days == 0:
+		return fmt.Sprintf("¡Es hoy: %s!", event)
+	case days == 1:
+		return fmt.Sprintf("¡Es mañana: %s!", event)
+	case days < 0:
+		return fmt.Sprintf("%s fue hace %d días, %s.", capitalize(event), -days, date)
+	default:
+		return fmt.Sprintf("Quedan %d días para %s, %s.", days, event, date)
+	}
+}
+
+// daysLeft says how many days are left ("mañana", "en 12 días")
+func daysLeft(days int) string {
+	switch days {
+	case 0:
+		return "hoy"
+	case 1:
+		return "mañana"
+	default:
+		return fmt.Sprintf("en %d días", days)
+	}
+}
+
+// spokenEvent turns the user's words about an event into Bobo's ("mi
+// viaje" is "tu viaje")
+func spokenEvent(name string) string {
+	lower := strings.ToLower(name)
+	for mine, yours := range map[string]string{"mi ": "tu ", "mis ": "tus ", "my ": "your "} {
+		if strings.HasPrefix(lower, mine) {
+			return yours + name[len(mine):]
+		}
+	}
+	return name
+}
+
+// capitalize upper-cases the first letter of a sentence
+func capitalize(text string) string {
+	if text == "" {
+		return text
+	}
+	runes := []rune(text)
+	return strings.ToUpper(string(runes[0])) + string(runes[1:])
+}
+
+// findCountdown returns the index of the countdown called name, ignoring
+// articles and possessives ("mi viaje" finds "el viaje"), or -1
+func findCountdown(countdowns []Countdown, name string) int {
+	key := nameKey(name)
+	if key == "" {
+		return -1
+	}
+	for i, countdown := range countdowns {
+		if nameKey(countdown.Name) == key {
+			return i
+		}
+	}
+	// "las vacaciones de verano" still finds "vacaciones"
+	for i, countdown := range countdowns {
+		if other := nameKey(countdown.Name); strings.Contains(key, other) || strings.Contains(other, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// nameKey is the name of a countdown or timer without articles or
+// possessives
+func nameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(nameArticles.ReplaceAllString(strings.TrimSpace(name), "")))
+}
+
+// daysUntil counts the calendar days from now to date
+func daysUntil(date, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, now.Location())
+	// Round over daylight saving changes
+	return int((day.Sub(today).Hours() + 12) / 24)
+}
+
+// nextMilestone is the smallest milestone not below days, or one past the
+// largest so that it is still announced
+func nextMilestone(days int) int {
+	milestone := countdownMilestones[0] + 1
+	for _, m := range countdownMilestones {
+		if days <= m {
+			milestone = m
+		}
+	}
+	return milestone
+}
+
+// parseEventDate splits "mi viaje el 3 de marzo" into the event's name and
+// its date, the next one with that day if no year is said; ok is false
+// without a date
+func parseEventDate(text string, now time.Time) (name string, date time.Time, ok bool) {
+	day, month, year := 0, time.Month(0), 0
+	var match []int
+	if m := spanishDatePattern.FindStringSubmatchIndex(text); m != nil {
+		day, _ = strconv.Atoi(text[m[2]:m[3]])
+		month = spokenMonths[strings.ToLower(text[m[4]:m[5]])]
+		if m[6] >= 0 {
+			year, _ = strconv.Atoi(text[m[6]:m[7]])
+		}
+		match = m
+	} else if m := englishDatePattern.FindStringSubmatchIndex(text); m != nil {
+		month = spokenMonths[strings.ToLower(text[m[2]:m[3]])]
+		day, _ = strconv.Atoi(text[m[4]:m[5]])
+		if m[6] >= 0 {
+			year, _ = strconv.Atoi(text[m[6]:m[7]])
+		}
+		match = m
+	} else if m := englishOfDatePattern.FindStringSubmatchIndex(text); m != nil {
+		day, _ = strconv.Atoi(text[m[2]:m[3]])
+		month = spokenMonths[strings.ToLower(text[m[4]:m[5]])]
+		if m[6] >= 0 {
+			year, _ = strconv.Atoi(text[m[6]:m[7]])
+		}
+		match = m
+	} else if m := numericDatePattern.FindStringSubmatchIndex(text); m != nil {
+		day, _ = strconv.Atoi(text[m[2]:m[3]])
+		number, _ := strconv.Atoi(text[m[4]:m[5]])
+		month = time.Month(number)
+		if m[6] >= 0 {
+			year, _ = strconv.Atoi(text[m[6]:m[7]])
+		}
+		match = m
+	}
+
+	name = text
+	if match != nil {
+		name = text[:match[0]] + " " + text[match[1]:]
+	}
+	// "las vacaciones, que empiezan el..." is called "las vacaciones"
+	name, _, _ = strings.Cut(name, ",")
+	name = strings.Trim(strings.Join(strings.Fields(name), " "), " .!?¿¡")
+
+	if match == nil || month < time.January || month > time.December || day < 1 || day > 31 {
+		return name, time.Time{}, false
+	}
+	if year == 0 {
+		year = now.Year()
+		if time.Date(year, month, day, 0, 0, 0, 0, now.Location()).Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())) {
+			year++
+		}
+	}
+	date = time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	if date.Day() != day {
+		// 31 de febrero
+		return name, time.Time{}, false
+	}
+	return name, date, true
+}
@@ -0,0 +1,208 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
+)
+
+// ManifestFile is the name of the manifest at the root of an installed skill
+const ManifestFile = "skill.json"
+
+// externalTimeout bounds a single run of an external skill
+const externalTimeout = 30 * time.Second
+
+// Manifest describes an installable skill
+type Manifest struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+	// Command runs the skill, relative to the skill directory. It gets the
+	// request as JSON on stdin and prints the result as JSON on stdout
+	Command string `json:"command,omitempty"`
+	// Wasm is a WASI module, relative to the skill directory, run instead of
+	// Command with the same stdin/stdout protocol
+	Wasm string `json:"wasm,omitempty"`
+	// Patterns are regular expressions matched against utterances; named
+	// groups become request slots
+	Patterns []string `json:"patterns"`
+	// Permissions are the capabilities the skill needs, approved by the user
+	// on install (see Permission)
+	Permissions []string `json:"permissions,omitempty"`
+	// VoiceID and SpeechRate are the voice every answer of the skill is
+	// spoken with, which needs the audio permission
+	VoiceID    string `json:"voice_id,omitempty"`
+	SpeechRate int    `json:"speech_rate,omitempty"`
+}
+
+// namePattern restricts skill names to safe directory names
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ReadManifest loads and validates the manifest of the skill in dir
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skill manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid skill manifest: %w", err)
+	}
+	if !namePattern.MatchString(manifest.Name) {
+		return nil, fmt.Errorf("invalid skill name %q", manifest.Name)
+	}
+	if (manifest.Command == "") == (manifest.Wasm == "") {
+		return nil, fmt.Errorf("skill %s needs either a command or a wasm module", manifest.Name)
+	}
+	if manifest.Wasm != "" && !filepath.IsLocal(manifest.Wasm) {
+		return nil, fmt.Errorf("skill %s wasm module %q must be inside the skill directory", manifest.Name, manifest.Wasm)
+	}
+	if len(manifest.Patterns) == 0 {
+		return nil, fmt.Errorf("skill %s has no patterns", manifest.Name)
+	}
+	for _, pattern := range manifest.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("skill %s has an invalid pattern: %w", manifest.Name, err)
+		}
+	}
+	if err := validatePermissions(manifest.Permissions); err != nil {
+		return nil, fmt.Errorf("skill %s: %w", manifest.Name, err)
+	}
+	if manifest.SpeechRate < 0 {
+		return nil, fmt.Errorf("skill %s has an invalid speech rate %d", manifest.Name, manifest.SpeechRate)
+	}
+	return &manifest, nil
+}
+
+// externalRequest is what an external skill gets on stdin
+type externalRequest struct {
+	Utterance string            `json:"utterance"`
+	Slots     map[string]string `json:"slots"`
+}
+
+// externalResult is what an external skill prints on stdout
+type externalResult struct {
+	Text       string     `json:"text"`
+	SpeechRate int        `json:"speech_rate,omitempty"`
+	VoiceID    string     `json:"voice_id,omitempty"`
+	Card       *card.Card `json:"card,omitempty"`
+}
+
+// ExternalSkill is an installed skill run as a separate program, restricted
+// to the permissions the user approved
+type ExternalSkill struct {
+	manifest *Manifest
+	dir      string
+	patterns []*regexp.Regexp
+	policy   *Policy
+	module   wasmModule
+}
+
+// NewExternalSkill loads the installed skill in dir with the approved permissions
+func NewExternalSkill(dir string, approved []string) (*ExternalSkill, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	skill := &ExternalSkill{manifest: manifest, dir: dir, policy: NewPolicy(approved)}
+	for _, pattern := range manifest.Patterns {
+		skill.patterns = append(skill.patterns, regexp.MustCompile(pattern))
+	}
+	return skill, nil
+}
+
+// Manifest returns the skill's manifest
+func (s *ExternalSkill) Manifest() *Manifest {
+	return s.manifest
+}
+
+// Policy implements Scoped
+func (s *ExternalSkill) Policy() *Policy {
+	return s.policy
+}
+
+// Name implements Skill
+func (s *ExternalSkill) Name() string {
+	return s.manifest.Name
+}
+
+// Voice implements Voiced
+func (s *ExternalSkill) Voice() (string, int) {
+	if !s.policy.Allows(PermissionAudio) {
+		return "", 0
+	}
+	return s.manifest.VoiceID, s.manifest.SpeechRate
+}
+
+// Match implements Skill
+func (s *ExternalSkill) Match(utterance string) (*Request, bool) {
+	for _, pattern := range s.patterns {
+		matches := pattern.FindStringSubmatch(utterance)
+		if matches == nil {
+			continue
+		}
+
+		slots := make(map[string]string)
+		for i, name := range pattern.SubexpNames() {
+			if name != "" && matches[i] != "" {
+				slots[name] = strings.TrimSpace(matches[i])
+			}
+		}
+		return &Request{Slots: slots}, true
+	}
+	return nil, false
+}
+
+// Handle implements Skill
+func (s *ExternalSkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	// The manifest may have been edited since the permissions were approved
+	if missing := s.policy.Missing(s.manifest.Permissions); len(missing) > 0 {
+		return nil, fmt.Errorf("skill %s needs permissions that were not approved (%s), reinstall it to approve them", s.manifest.Name, strings.Join(missing, ", "))
+	}
+
+	input, err := json.Marshal(externalRequest{Utterance: req.Utterance, Slots: req.Slots})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, externalTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	if s.manifest.Wasm != "" {
+		err = s.runWasm(ctx, input, &stdout, &stderr)
+	} else {
+		var cmd *exec.Cmd
+		if cmd, err = s.policy.command(ctx, s.manifest, s.dir); err != nil {
+			return nil, fmt.Errorf("skill %s: %w", s.manifest.Name, err)
+		}
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err = s.policy.run(cmd, s.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("skill %s failed: %w: %s", s.manifest.Name, err, textutil.Preview(strings.TrimSpace(stderr.String())))
+	}
+
+	var result externalResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("skill %s returned invalid JSON: %w", s.manifest.Name, err)
+	}
+	if !s.policy.Allows(PermissionAudio) {
+		result.SpeechRate, result.VoiceID = 0, ""
+	}
+	return &Result{Text: result.Text, SpeechRate: result.SpeechRate, VoiceID: result.VoiceID, Card: result.Card}, nil
+}
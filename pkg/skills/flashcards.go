@@ -0,0 +1,260 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+)
+
+// flashcardsNamespace is where decks are kept in the memory store
+const flashcardsNamespace = "flashcards"
+
+// maxQuizCards limits how many cards one quiz session asks
+const maxQuizCards = 10
+
+var (
+	quizStartPattern = regexp.MustCompile(`(?i)(?:quiz me on|quiz me about|preg[uú]ntame (?:sobre|de)|hazme preguntas (?:sobre|de)|repasar|repaso de|flashcards? (?:de|of|on))\s+(.+?)[.!?]*$`)
+	quizStopPattern  = regexp.MustCompile(`(?i)^(?:stop|para|basta|terminar|salir)\b|\b(?:stop|end|termina|para) (?:the )?(?:quiz|repaso)`)
+	dontKnowPattern  = regexp.MustCompile(`(?i)\b(?:no (?:lo )?s[eé]|i don'?t know|pass|paso|ni idea)\b`)
+)
+
+// flashcardDecks is the persisted set of decks keyed by topic
+type flashcardDecks struct {
+	Decks map[string][]Card `json:"decks"`
+}
+
+// FlashcardsSkill runs spaced-repetition quizzes: Bobo asks, listens and grades
+type FlashcardsSkill struct {
+	completer Completer
+	store     *memory.Store
+
+	mu      sync.Mutex
+	topic   string
+	queue   []int
+	correct int
+	asked   int
+}
+
+// NewFlashcardsSkill creates the flashcards skill
+func NewFlashcardsSkill(completer Completer, store *memory.Store) *FlashcardsSkill {
+	return &FlashcardsSkill{
+		completer: completer,
+		store:     store,
+	}
+}
+
+// Name implements Skill
+func (f *FlashcardsSkill) Name() string {
+	return "flashcards"
+}
+
+// Engaged implements Engager: a quiz is in progress
+func (f *FlashcardsSkill) Engaged() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.topic != ""
+}
+
+// Match implements Skill
+func (f *FlashcardsSkill) Match(utterance string) (*Request, bool) {
+	if f.Engaged() {
+		action := "answer"
+		if quizStopPattern.MatchString(strings.TrimSpace(utterance)) {
+			action = "stop"
+		}
+		return &Request{Slots: map[string]string{"action": action}}, true
+	}
+
+	matches := quizStartPattern.FindStringSubmatch(strings.TrimSpace(utterance))
+	if matches == nil {
+		return nil, false
+	}
+	return &Request{Slots: map[string]string{"action": "start", "topic": strings.ToLower(strings.TrimSpace(matches[1]))}}, true
+}
+
+// Handle implements Skill
+func (f *FlashcardsSkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	switch req.Slot("action", "") {
+	case "start":
+		return f.start(ctx, req.Slot("topic", ""))
+	case "stop":
+		return f.finish("Vale, dejamos el repaso aquí."), nil
+	default:
+		return f.answer(req.Utterance)
+	}
+}
+
+// start loads (or generates) the deck and asks the first due card
+func (f *FlashcardsSkill) start(ctx context.Context, topic string) (*Result, error) {
+	decks, err := f.loadDecks()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decks.Decks[topic]) == 0 {
+		cards, err := f.generateDeck(ctx, topic)
+		if err != nil {
+			return nil, err
+		}
+		decks.Decks[topic] = cards
+		if err := f.store.Save(flashcardsNamespace, decks); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	var queue []int
+	for i, card := range decks.Decks[topic] {
+		if card.IsDue(now) {
+			queue = append(queue, i)
+		}
+		if len(queue) == maxQuizCards {
+			break
+		}
+	}
+
+	if len(queue) == 0 {
+		next := decks.Decks[topic][0].Due
+		for _, card := range decks.Decks[topic] {
+			if card.Due.Before(next) {
+				next = card.Due
+			}
+		}
+		return &Result{Text: fmt.Sprintf("¡Estás al día con %s! La próxima tarjeta toca el %s.", topic, next.Format("02/01"))}, nil
+	}
+
+	f.mu.Lock()
+	f.topic = topic
+	f.queue = queue
+	f.correct = 0
+	f.asked = 0
+	f.mu.Unlock()
+
+	first := decks.Decks[topic][queue[0]]
+	return &Result{Text: fmt.Sprintf("Vamos con %d tarjetas de %s. Primera: %s", len(queue), topic, first.Front)}, nil
+}
+
+// answer grades the response to the current card and asks the next one
+func (f *FlashcardsSkill) answer(utterance string) (*Result, error) {
+	decks, err := f.loadDecks()
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	topic := f.topic
+	if len(f.queue) == 0 {
+		f.mu.Unlock()
+		return f.finish(""), nil
+	}
+	index := f.queue[0]
+	f.queue = f.queue[1:]
+	remaining := append([]int(nil), f.queue...)
+	f.mu.Unlock()
+
+	cards := decks.Decks[topic]
+	if index >= len(cards) {
+		return f.finish("El mazo ha cambiado, terminamos aquí."), nil
+	}
+	card := &cards[index]
+
+	quality := 0
+	if !dontKnowPattern.MatchString(utterance) {
+		similarity, _ := AlignScore(card.Back, utterance)
+		quality = gradeAnswer(similarity)
+	}
+	card.Review(quality, time.Now())
+
+	if err := f.store.Save(flashcardsNamespace, decks); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.asked++
+	if quality >= 3 {
+		f.correct++
+	}
+	f.mu.Unlock()
+
+	feedback := "¡Correcto!"
+	if quality < 3 {
+		feedback = fmt.Sprintf("No exactamente, era: %s.", card.Back)
+	} else if quality < 5 {
+		feedback = fmt.Sprintf("¡Bien! Exactamente: %s.", card.Back)
+	}
+
+	if len(remaining) == 0 {
+		return f.finish(feedback), nil
+	}
+
+	next := cards[remaining[0]]
+	return &Result{Text: fmt.Sprintf("%s Siguiente: %s", feedback, next.Front)}, nil
+}
+
+// finish ends the quiz session with a score recap
+func (f *FlashcardsSkill) finish(prefix string) *Result {
+	f.mu.Lock()
+	asked, correct := f.asked, f.correct
+	f.topic = ""
+	f.queue = nil
+	f.mu.Unlock()
+
+	text := strings.TrimSpace(fmt.Sprintf("%s Repaso terminado: %d de %d bien.", prefix, correct, asked))
+	return &Result{Text: text}
+}
+
+// generateDeck asks the language model for a new deck on the topic
+func (f *FlashcardsSkill) generateDeck(ctx context.Context, topic string) ([]Card, error) {
+	system := `You create flashcards for spoken quizzes. Reply ONLY with a JSON array of 12 objects
+{"front": "...", "back": "..."} where "front" is a short question and "back" a short answer
+(1-4 words) that can be said aloud. Use the language of the request for questions.`
+
+	reply, err := f.completer.Prompt(ctx, system, "Topic: "+topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate flashcards: %w", err)
+	}
+
+	// Tolerate prose or code fences around the JSON array
+	start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("flashcard generation returned no JSON array")
+	}
+
+	var raw []struct {
+		Front string `json:"front"`
+		Back  string `json:"back"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("invalid generated flashcards: %w", err)
+	}
+
+	now := time.Now()
+	var cards []Card
+	for _, r := range raw {
+		if r.Front != "" && r.Back != "" {
+			cards = append(cards, NewCard(r.Front, r.Back, now))
+		}
+	}
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("flashcard generation returned no cards")
+	}
+	return cards, nil
+}
+
+// loadDecks reads all decks from the memory store
+func (f *FlashcardsSkill) loadDecks() (*flashcardDecks, error) {
+	decks := &flashcardDecks{}
+	if err := f.store.Load(flashcardsNamespace, decks); err != nil {
+		return nil, err
+	}
+	if decks.Decks == nil {
+		decks.Decks = make(map[string][]Card)
+	}
+	return decks, nil
+}
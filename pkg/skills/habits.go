@@ -0,0 +1,234 @@
+package skills
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// habitSummaryNamespace keeps when the weekly habit summary was last given
+const habitSummaryNamespace = "habit_summary"
+
+// Habits: an occurrence can be marked done for habitDoneWindow after it went
+// off, and the last habitHistory occurrences are kept for streaks
+const (
+	habitDoneWindow = 24 * time.Hour
+	habitHistory    = 60
+)
+
+var (
+	habitDonePattern    = regexp.MustCompile(`(?i)^(?:bobo,?\s+)?¡?(?:hecho|ya est[aá]|ya lo he hecho|lo he hecho|ya lo hice|listo|done|i did it|did it|i'm done)[.!]*$`)
+	habitSummaryPattern = regexp.MustCompile(`(?i)(?:c[oó]mo van mis h[aá]bitos|resumen de (?:mis )?h[aá]bitos|mis rachas|my habits|habit summary|how are my habits)`)
+	stopReminderPattern = regexp.MustCompile(`(?i)^(?:bobo,?\s+)?(?:deja de recordarme|ya no me recuerdes|no me recuerdes m[aá]s|stop reminding me(?: to)?)\s+(?:que\s+|a\s+)?(.+?)[.!?]*$`)
+)
+
+// Occurrence is a time a recurring reminder went off and whether the user
+// said it was done
+type Occurrence struct {
+	At   time.Time `json:"at"`
+	Done bool      `json:"done,omitempty"`
+}
+
+// habitSummaryState is the persisted time of the last weekly summary
+type habitSummaryState struct {
+	Last time.Time `json:"last"`
+}
+
+// Streak is how many occurrences in a row were done; the latest one doesn't
+// break it while it can still be done
+func (r Reminder) Streak() int {
+	occurrences := r.Occurrences
+	if n := len(occurrences); n > 0 && !occurrences[n-1].Done {
+		occurrences = occurrences[:n-1]
+	}
+	streak := 0
+	for i := len(occurrences) - 1; i >= 0 && occurrences[i].Done; i-- {
+		streak++
+	}
+	return streak
+}
+
+// SetWeeklySummary makes WeeklySummary report the habits every week at the
+// given day and time
+func (r *RemindersSkill) SetWeeklySummary(day time.Weekday, hour, minute int) {
+	r.summaryDay, r.summaryHour, r.summaryMinute = day, hour, minute
+	r.summary = true
+}
+
+// WeeklySummary returns the habits summary once it is due this week
+func (r *RemindersSkill) WeeklySummary(now time.Time) (string, bool) {
+	if !r.summary || now.Weekday() != r.summaryDay {
+		return "", false
+	}
+	due := time.Date(now.Year(), now.Month(), now.Day(), r.summaryHour, r.summaryMinute, 0, 0, now.Location())
+	if now.Before(due) {
+		return "", false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var state habitSummaryState
+	if err := r.store.Load(habitSummaryNamespace, &state); err != nil {
+		r.logger.Warn("Failed to load the habit summary state", "error", err)
+		return "", false
+	}
+	if !state.Last.Before(due) {
+		return "", false
+	}
+	list, err := r.load()
+	if err != nil {
+		r.logger.Warn("Failed to load reminders", "error", err)
+		return "", false
+	}
+	text, ok := habitSummary(list.Reminders, now)
+	if !ok {
+		return "", false
+	}
+	state.Last = now
+	if err := r.store.Save(habitSummaryNamespace, state); err != nil {
+		r.logger.Warn("Failed to save the habit summary state", "error", err)
+	}
+	return text, true
+}
+
+// markDone records the habit that went off last as done
+func (r *RemindersSkill) markDone() (*Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	now := r.now()
+	latest := -1
+	for i, reminder := range list.Reminders {
+		n := len(reminder.Occurrences)
+		if reminder.RRule == "" || n == 0 || now.Sub(reminder.Occurrences[n-1].At) > habitDoneWindow {
+			continue
+		}
+		if latest < 0 || reminder.Occurrences[n-1].At.After(lastOccurrence(list.Reminders[latest])) {
+			latest = i
+		}
+	}
+	if latest < 0 {
+		return &Result{Text: "No tengo ningún hábito pendiente de marcar."}, nil
+	}
+
+	habit := &list.Reminders[latest]
+	occurrence := &habit.Occurrences[len(habit.Occurrences)-1]
+	if occurrence.Done {
+		return &Result{Text: fmt.Sprintf("Ya tenía apuntado que has hecho %s.", habit.Text)}, nil
+	}
+	occurrence.Done = true
+	if err := r.store.Save(remindersNamespace, list); err != nil {
+		return nil, err
+	}
+
+	streak := habit.Streak()
+	if streak == 1 {
+		return &Result{Text: fmt.Sprintf("¡Bien! Apuntado que has hecho %s.", habit.Text)}, nil
+	}
+	return &Result{Text: fmt.Sprintf("¡Bien! Llevas %d seguidas con %s.", streak, habit.Text)}, nil
+}
+
+// summarize reads out how the habits went over the last week
+func (r *RemindersSkill) summarize() (*Result, error) {
+	r.mu.Lock()
+	list, err := r.load()
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	text, ok := habitSummary(list.Reminders, r.now())
+	if !ok {
+		return &Result{Text: "No tienes hábitos. Prueba con \"recuérdame regar las plantas cada lunes\"."}, nil
+	}
+	return &Result{Text: text}, nil
+}
+
+// stop removes the reminders whose text contains what
+func (r *RemindersSkill) stop(what string) (*Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	what = strings.ToLower(cleanReminderText(what))
+	var kept []Reminder
+	var removed []string
+	for _, reminder := range list.Reminders {
+		if what != "" && strings.Contains(strings.ToLower(reminder.Text), what) {
+			removed = append(removed, reminder.Text)
+			continue
+		}
+		kept = append(kept, reminder)
+	}
+	if len(removed) == 0 {
+		return &Result{Text: fmt.Sprintf("No tengo ningún recordatorio de %s.", what)}, nil
+	}
+	list.Reminders = kept
+	if err := r.store.Save(remindersNamespace, list); err != nil {
+		return nil, err
+	}
+	return &Result{Text: fmt.Sprintf("Vale, ya no te recordaré %s.", strings.Join(removed, " ni "))}, nil
+}
+
+// habitSummary says how many times each recurring reminder was done in the
+// last week and its streak; ok is false without any
+func habitSummary(reminders []Reminder, now time.Time) (string, bool) {
+	weekAgo := now.AddDate(0, 0, -7)
+	var parts []string
+	for _, reminder := range reminders {
+		if reminder.RRule == "" {
+			continue
+		}
+		done, total := 0, 0
+		for _, occurrence := range reminder.Occurrences {
+			if occurrence.At.After(weekAgo) {
+				total++
+				if occurrence.Done {
+					done++
+				}
+			}
+		}
+		part := fmt.Sprintf("%s, %d de %d", reminder.Text, done, total)
+		if streak := reminder.Streak(); streak > 1 {
+			part += fmt.Sprintf(", con una racha de %d", streak)
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	return "Tus hábitos esta semana: " + strings.Join(parts, "; ") + ".", true
+}
+
+// lastOccurrence is when a recurring reminder last went off
+func lastOccurrence(reminder Reminder) time.Time {
+	if n := len(reminder.Occurrences); n > 0 {
+		return reminder.Occurrences[n-1].At
+	}
+	return time.Time{}
+}
+
+// ParseWeeklyTime reads a day and time of the week such as "sunday 20:00"
+// or "domingo 20:00"
+func ParseWeeklyTime(value string) (time.Weekday, int, int, error) {
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) != 2 {
+		return 0, 0, 0, fmt.Errorf("invalid weekly time %q (use a day and a time, like \"sunday 20:00\")", value)
+	}
+	day, ok := spokenDays[fields[0]]
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid day %q", fields[0])
+	}
+	clock, err := time.Parse("15:04", fields[1])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid time %q", fields[1])
+	}
+	return day, clock.Hour(), clock.Minute(), nil
+}
@@ -0,0 +1,436 @@
+package skills
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// lockFile records the installed skills in the skills directory
+const lockFile = "installed.json"
+
+// maxSkillArchive bounds the size of a downloaded skill archive, and
+// maxSkillFiles that of the files unpacked from any archive
+const (
+	maxSkillArchive = 50 << 20
+	maxSkillFiles   = 200 << 20
+)
+
+// InstalledSkill is an installed skill's entry in the lock file; Permissions
+// are the ones the user approved
+type InstalledSkill struct {
+	Version     string    `json:"version"`
+	Source      string    `json:"source"`
+	Enabled     bool      `json:"enabled"`
+	Pinned      bool      `json:"pinned,omitempty"`
+	Permissions []string  `json:"permissions,omitempty"`
+	Installed   time.Time `json:"installed"`
+}
+
+// indexEntry is a skill in the registry index
+type indexEntry struct {
+	Description string                  `json:"description"`
+	Latest      string                  `json:"latest"`
+	Versions    map[string]indexVersion `json:"versions"`
+}
+
+// indexVersion is where a version of a skill is downloaded from, and the
+// SHA-256 checksum of its archive
+type indexVersion struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// Installer installs, lists and toggles third-party skills in a directory,
+// one subdirectory per skill plus a lock file with versions and state
+type Installer struct {
+	dir      string
+	indexURL string
+	client   *http.Client
+}
+
+// NewInstaller creates an installer for dir; indexURL is the registry used to
+// install skills by name
+func NewInstaller(dir, indexURL string) *Installer {
+	return &Installer{
+		dir:      dir,
+		indexURL: indexURL,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// List returns the installed skills by name
+func (i *Installer) List() (map[string]*InstalledSkill, error) {
+	data, err := os.ReadFile(filepath.Join(i.dir, lockFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]*InstalledSkill{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read installed skills: %w", err)
+	}
+
+	var installed map[string]*InstalledSkill
+	if err := json.Unmarshal(data, &installed); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", lockFile, err)
+	}
+	return installed, nil
+}
+
+// save writes the lock file
+func (i *Installer) save(installed map[string]*InstalledSkill) error {
+	data, err := json.MarshalIndent(installed, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(i.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(i.dir, lockFile), data, 0644)
+}
+
+// Fetch downloads or copies a skill without installing it, returning its
+// manifest and a staging directory to pass to Install. source is a registry
+// name with an optional "@version", a URL or path of a .tar.gz archive, or a
+// local skill directory
+func (i *Installer) Fetch(ctx context.Context, source string) (*Manifest, string, error) {
+	if err := os.MkdirAll(i.dir, 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(i.dir, ".staging-")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	if err := i.fetch(ctx, source, staging); err != nil {
+		os.RemoveAll(staging)
+		return nil, "", err
+	}
+
+	// Archives may wrap the skill in a top-level directory
+	root := staging
+	if entries, err := os.ReadDir(staging); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(staging, entries[0].Name())
+	}
+
+	manifest, err := ReadManifest(root)
+	if err != nil {
+		os.RemoveAll(staging)
+		return nil, "", err
+	}
+	if IsRegistrySource(source) {
+		name, version, _ := strings.Cut(source, "@")
+		if manifest.Name != name || (version != "" && manifest.Version != version) {
+			os.RemoveAll(staging)
+			return nil, "", fmt.Errorf("registry returned %s@%s for %s", manifest.Name, manifest.Version, source)
+		}
+	}
+	return manifest, root, nil
+}
+
+// IsRegistrySource reports whether source names a skill in the registry
+// ("name" or "name@version") rather than an archive or a directory
+func IsRegistrySource(source string) bool {
+	if strings.Contains(source, "://") || strings.HasSuffix(source, ".tar.gz") || strings.HasSuffix(source, ".tgz") {
+		return false
+	}
+	info, err := os.Stat(source)
+	return err != nil || !info.IsDir()
+}
+
+// fetch puts the files of source into dir
+func (i *Installer) fetch(ctx context.Context, source, dir string) error {
+	switch {
+	case strings.HasPrefix(source, "http://"):
+		return fmt.Errorf("skills can only be downloaded over https")
+	case strings.HasPrefix(source, "https://"):
+		return i.download(ctx, source, "", dir)
+	case strings.HasSuffix(source, ".tar.gz") || strings.HasSuffix(source, ".tgz"):
+		file, err := os.Open(source)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return extractTarGz(file, dir)
+	case !IsRegistrySource(source):
+		return os.CopyFS(dir, os.DirFS(source))
+	}
+
+	version, err := i.resolve(ctx, source)
+	if err != nil {
+		return err
+	}
+	return i.download(ctx, version.URL, version.SHA256, dir)
+}
+
+// resolve finds the archive of "name" or "name@version" in the registry index
+func (i *Installer) resolve(ctx context.Context, source string) (*indexVersion, error) {
+	if i.indexURL == "" {
+		return nil, fmt.Errorf("unknown skill %q (set SKILLS_INDEX_URL to install skills by name)", source)
+	}
+	// The index vouches for the archives, so it must come from who it says
+	if !strings.HasPrefix(i.indexURL, "https://") {
+		return nil, fmt.Errorf("SKILLS_INDEX_URL must be an https URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, i.indexURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch skill index: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("skill index returned %d", resp.StatusCode)
+	}
+
+	var index map[string]indexEntry
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return nil, fmt.Errorf("invalid skill index: %w", err)
+	}
+
+	name, version, _ := strings.Cut(source, "@")
+	entry, ok := index[name]
+	if !ok {
+		return nil, fmt.Errorf("skill %q not found in the index", name)
+	}
+	if version == "" {
+		version = entry.Latest
+	}
+	found, ok := entry.Versions[version]
+	if !ok {
+		return nil, fmt.Errorf("skill %s has no version %q", name, version)
+	}
+	if found.SHA256 == "" {
+		return nil, fmt.Errorf("skill %s@%s has no sha256 checksum in the index", name, version)
+	}
+	return &found, nil
+}
+
+// download fetches a .tar.gz archive over https and extracts it into dir,
+// after checking it against checksum (hex SHA-256) when there is one
+func (i *Installer) download(ctx context.Context, url, checksum, dir string) error {
+	if !strings.HasPrefix(url, "https://") {
+		return fmt.Errorf("skill archive %s is not served over https", url)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download skill: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("skill download returned %d", resp.StatusCode)
+	}
+
+	archive, err := io.ReadAll(io.LimitReader(resp.Body, maxSkillArchive+1))
+	if err != nil {
+		return fmt.Errorf("failed to download skill: %w", err)
+	}
+	if len(archive) > maxSkillArchive {
+		return fmt.Errorf("skill archive is larger than %d MB", maxSkillArchive>>20)
+	}
+	if checksum != "" {
+		sum := sha256.Sum256(archive)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), checksum) {
+			return fmt.Errorf("skill archive checksum mismatch: the index has %s, the download is %x", checksum, sum)
+		}
+	}
+	return extractTarGz(bytes.NewReader(archive), dir)
+}
+
+// extractTarGz unpacks regular files and directories of a .tar.gz into dir,
+// refusing entries that would land outside it and archives that unpack to
+// more than maxSkillFiles
+func extractTarGz(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("invalid skill archive: %w", err)
+	}
+	defer gz.Close()
+
+	// One byte over the limit tells a full archive from one cut short
+	unpacked := &io.LimitedReader{R: gz, N: maxSkillFiles + 1}
+	tooLarge := fmt.Errorf("skill archive is larger than %d MB unpacked", maxSkillFiles>>20)
+	tr := tar.NewReader(unpacked)
+	for {
+		header, err := tr.Next()
+		if unpacked.N <= 0 {
+			return tooLarge
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid skill archive: %w", err)
+		}
+
+		if !filepath.IsLocal(header.Name) {
+			return fmt.Errorf("skill archive entry %q escapes the skill directory", header.Name)
+		}
+		target := filepath.Join(dir, header.Name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode)&0755)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, tr)
+			file.Close()
+			if unpacked.N <= 0 {
+				return tooLarge
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Install moves a fetched skill into place and records it, enabled, with
+// the permissions its manifest requests as approved. pinned keeps Update
+// from moving it to another version
+func (i *Installer) Install(manifest *Manifest, staged, source string, pinned bool) error {
+	installed, err := i.List()
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(i.dir, manifest.Name)
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("failed to remove the previous version: %w", err)
+	}
+	if err := os.Rename(staged, target); err != nil {
+		return fmt.Errorf("failed to install skill: %w", err)
+	}
+	i.cleanStaging()
+
+	installed[manifest.Name] = &InstalledSkill{
+		Version:     manifest.Version,
+		Source:      source,
+		Enabled:     true,
+		Pinned:      pinned,
+		Permissions: manifest.Permissions,
+		Installed:   time.Now(),
+	}
+	return i.save(installed)
+}
+
+// Discard removes a fetched skill that the user chose not to install
+func (i *Installer) Discard(staged string) {
+	os.RemoveAll(staged)
+	i.cleanStaging()
+}
+
+// cleanStaging removes leftover staging directories
+func (i *Installer) cleanStaging() {
+	entries, _ := os.ReadDir(i.dir)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".staging-") {
+			os.RemoveAll(filepath.Join(i.dir, entry.Name()))
+		}
+	}
+}
+
+// Remove uninstalls a skill
+func (i *Installer) Remove(name string) error {
+	installed, err := i.List()
+	if err != nil {
+		return err
+	}
+	if _, ok := installed[name]; !ok {
+		return fmt.Errorf("skill %q is not installed", name)
+	}
+
+	if err := os.RemoveAll(filepath.Join(i.dir, name)); err != nil {
+		return fmt.Errorf("failed to remove skill: %w", err)
+	}
+	delete(installed, name)
+	return i.save(installed)
+}
+
+// SetEnabled enables or disables an installed skill
+func (i *Installer) SetEnabled(name string, enabled bool) error {
+	installed, err := i.List()
+	if err != nil {
+		return err
+	}
+	skill, ok := installed[name]
+	if !ok {
+		return fmt.Errorf("skill %q is not installed", name)
+	}
+	skill.Enabled = enabled
+	return i.save(installed)
+}
+
+// Updatable returns the installed skills that come from the registry and
+// are not pinned to a version
+func (i *Installer) Updatable() ([]string, error) {
+	installed, err := i.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name, skill := range installed {
+		if !skill.Pinned && IsRegistrySource(skill.Source) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Load returns the enabled installed skills, skipping (and reporting) the
+// ones that fail to load
+func (i *Installer) Load() ([]*ExternalSkill, error) {
+	installed, err := i.List()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(installed))
+	for name, skill := range installed {
+		if skill.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var loaded []*ExternalSkill
+	var errs []error
+	for _, name := range names {
+		skill, err := NewExternalSkill(filepath.Join(i.dir, name), installed[name].Permissions)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("skill %s: %w", name, err))
+			continue
+		}
+		loaded = append(loaded, skill)
+	}
+	return loaded, errors.Join(errs...)
+}
@@ -0,0 +1,155 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Permission is a capability scope an installed skill can request
+type Permission string
+
+const (
+	// PermissionNetwork lets the skill reach the network
+	PermissionNetwork Permission = "network"
+	// PermissionFilesystem lets the skill see the user's home directory
+	// instead of a private data directory
+	PermissionFilesystem Permission = "filesystem"
+	// PermissionAudio lets the skill change the voice and speech rate of its answers
+	PermissionAudio Permission = "audio"
+	// PermissionHomeControl lets the skill's intents reach home automation
+	// through the intent exporter (Rhasspy, openHAB)
+	PermissionHomeControl Permission = "home-control"
+	// PermissionShell lets the skill's command be a shell command line
+	// instead of a program inside the skill directory
+	PermissionShell Permission = "shell"
+)
+
+// PermissionDescriptions explains each permission when asking for approval
+var PermissionDescriptions = map[Permission]string{
+	PermissionNetwork:     "access the network",
+	PermissionFilesystem:  "read and write files in your home directory",
+	PermissionAudio:       "change the voice and speech rate of its answers",
+	PermissionHomeControl: "send its intents to your home automation",
+	PermissionShell:       "run shell commands",
+}
+
+// dataDir is the private directory, inside the skill directory, used as
+// HOME by skills without the filesystem permission
+const dataDir = ".data"
+
+// Policy is the set of permissions the user approved for a skill, enforced
+// every time it runs. A nil policy allows everything, as for built-in skills
+type Policy struct {
+	granted map[Permission]bool
+}
+
+// NewPolicy creates the policy for the approved permissions
+func NewPolicy(approved []string) *Policy {
+	policy := &Policy{granted: make(map[Permission]bool)}
+	for _, permission := range approved {
+		policy.granted[Permission(permission)] = true
+	}
+	return policy
+}
+
+// Allows reports whether the permission was approved
+func (p *Policy) Allows(permission Permission) bool {
+	return p == nil || p.granted[permission]
+}
+
+// Missing returns the requested permissions that were not approved
+func (p *Policy) Missing(requested []string) []string {
+	var missing []string
+	for _, permission := range requested {
+		if !p.Allows(Permission(permission)) {
+			missing = append(missing, permission)
+		}
+	}
+	return missing
+}
+
+// Scoped is implemented by skills restricted by a permission policy
+type Scoped interface {
+	Policy() *Policy
+}
+
+// validatePermissions rejects unknown permission names
+func validatePermissions(requested []string) error {
+	for _, permission := range requested {
+		if _, ok := PermissionDescriptions[Permission(permission)]; !ok {
+			known := make([]string, 0, len(PermissionDescriptions))
+			for name := range PermissionDescriptions {
+				known = append(known, string(name))
+			}
+			sort.Strings(known)
+			return fmt.Errorf("unknown permission %q (available: %s)", permission, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// passedEnv are the variables of Bobo's environment a skill gets; secrets
+// such as API keys and credentials are never passed
+var passedEnv = []string{"PATH", "LANG", "LC_ALL", "TZ", "USER", "LOGNAME"}
+
+// command builds the command that runs a skill under the policy
+func (p *Policy) command(ctx context.Context, manifest *Manifest, dir string) (*exec.Cmd, error) {
+	var args []string
+	if p.Allows(PermissionShell) {
+		args = []string{"sh", "-c", manifest.Command}
+	} else {
+		program := filepath.Join(dir, manifest.Command)
+		if !filepath.IsLocal(manifest.Command) {
+			return nil, fmt.Errorf("command %q must be a program inside the skill directory without the shell permission", manifest.Command)
+		}
+		args = []string{program}
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	for _, name := range passedEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
+
+	home := filepath.Join(dir, dataDir)
+	if p.Allows(PermissionFilesystem) {
+		home, _ = os.UserHomeDir()
+	} else if err := os.MkdirAll(home, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create skill data directory: %w", err)
+	}
+	cmd.Env = append(cmd.Env, "HOME="+home, "TMPDIR="+home)
+	return cmd, nil
+}
+
+// run runs a skill's command in the sandbox that enforces the policy,
+// refusing to when this system can't
+func (p *Policy) run(cmd *exec.Cmd, dir string) error {
+	var home string
+	if !p.Allows(PermissionFilesystem) {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return fmt.Errorf("failed to find the home directory to keep the skill out of: %w", err)
+		}
+	}
+	if err := sandbox(cmd, !p.Allows(PermissionNetwork), home, dir); err != nil {
+		return err
+	}
+	return cmd.Wait()
+}
+
+// DataDirs returns the private data directories of the skills installed in
+// dir that have written any
+func DataDirs(dir string) ([]string, error) {
+	dirs, err := filepath.Glob(filepath.Join(dir, "*", dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list skill data: %w", err)
+	}
+	return dirs, nil
+}
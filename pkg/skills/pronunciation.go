@@ -0,0 +1,129 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// pronunciationPattern matches "how do I pronounce X", "test my pronunciation of X"
+// or "¿cómo se pronuncia X?"
+var pronunciationPattern = regexp.MustCompile(`(?i)(?:how (?:do (?:i|you)|to) (?:say|pronounce)|pronunciation of|pronounce|c[oó]mo se pronuncia|pronunciaci[oó]n de|practicar la pronunciaci[oó]n de)\s+["'“«]?([^"'”»?¿!]+?)["'”»]?\s*[?.!]*$`)
+
+// PronunciationSkill asks the user to repeat a phrase and scores how close the
+// transcription was to the expected text
+type PronunciationSkill struct {
+	tutor           *TutorSkill
+	defaultLanguage string
+	passScore       float64
+
+	mu       sync.Mutex
+	expected string
+	language *tutorLanguage
+}
+
+// NewPronunciationSkill creates the skill; it follows the tutor's language when the
+// tutor mode is active and uses defaultLanguage otherwise
+func NewPronunciationSkill(tutor *TutorSkill, defaultLanguage string) *PronunciationSkill {
+	return &PronunciationSkill{
+		tutor:           tutor,
+		defaultLanguage: defaultLanguage,
+		passScore:       0.85,
+	}
+}
+
+// Name implements Skill
+func (p *PronunciationSkill) Name() string {
+	return "pronunciation"
+}
+
+// Engaged implements Engager: waiting for the user to repeat the phrase
+func (p *PronunciationSkill) Engaged() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.expected != ""
+}
+
+// TranscriptionLanguage implements LanguageHinter so the attempt is transcribed
+// in the language being practiced
+func (p *PronunciationSkill) TranscriptionLanguage() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.language == nil {
+		return ""
+	}
+	return p.language.Voice
+}
+
+// Match implements Skill
+func (p *PronunciationSkill) Match(utterance string) (*Request, bool) {
+	if p.Engaged() {
+		return &Request{Slots: map[string]string{"action": "score"}}, true
+	}
+
+	matches := pronunciationPattern.FindStringSubmatch(strings.TrimSpace(utterance))
+	if matches == nil {
+		return nil, false
+	}
+	return &Request{Slots: map[string]string{"action": "start", "phrase": strings.TrimSpace(matches[1])}}, true
+}
+
+// Handle implements Skill
+func (p *PronunciationSkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	if req.Slot("action", "") == "score" {
+		return p.score(req.Utterance), nil
+	}
+
+	language := p.tutor.CurrentLanguage()
+	if language == nil {
+		language = findTutorLanguage(p.defaultLanguage)
+	}
+	if language == nil {
+		return nil, fmt.Errorf("unsupported pronunciation language %q", p.defaultLanguage)
+	}
+
+	phrase := req.Slot("phrase", "")
+	p.mu.Lock()
+	p.expected = phrase
+	p.language = language
+	p.mu.Unlock()
+
+	return &Result{
+		Text:       fmt.Sprintf("%s. ... %s", phrase, phrase),
+		VoiceID:    language.Voice,
+		SpeechRate: 120,
+	}, nil
+}
+
+// score compares the attempt with the expected phrase and builds spoken feedback
+func (p *PronunciationSkill) score(attempt string) *Result {
+	p.mu.Lock()
+	expected, language := p.expected, p.language
+	p.expected = ""
+	p.mu.Unlock()
+
+	overall, words := AlignScore(expected, attempt)
+
+	var missed []string
+	for _, word := range words {
+		if word.Score < 0.75 {
+			missed = append(missed, word.Expected)
+		}
+	}
+
+	percent := int(overall*100 + 0.5)
+	var text string
+	switch {
+	case overall >= p.passScore:
+		text = fmt.Sprintf("¡Muy bien! Un %d%% de coincidencia con \"%s\".", percent, expected)
+	case len(missed) > 0:
+		text = fmt.Sprintf("Un %d%%. Practica estas palabras: %s. Escucha otra vez: %s.",
+			percent, strings.Join(missed, ", "), expected)
+	default:
+		text = fmt.Sprintf("Un %d%%. Casi, inténtalo otra vez: %s.", percent, expected)
+	}
+
+	return &Result{Text: text, VoiceID: language.Voice, SpeechRate: 130}
+}
@@ -0,0 +1,255 @@
+package skills
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies, as in iCalendar RRULEs
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+// rruleDays are the iCalendar names of the days of the week
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// spokenDays maps the days of the week as said in Spanish and English
+var spokenDays = map[string]time.Weekday{
+	"lunes": time.Monday, "martes": time.Tuesday, "miércoles": time.Wednesday, "miercoles": time.Wednesday,
+	"jueves": time.Thursday, "viernes": time.Friday, "sábado": time.Saturday, "sabado": time.Saturday,
+	"domingo": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday, "sunday": time.Sunday,
+}
+
+// dayNames are the days of the week as Bobo says them
+var dayNames = []string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"}
+
+var (
+	spokenDay           = `(?:lunes|martes|mi[eé]rcoles|jueves|viernes|s[aá]bados?|domingos?|mondays?|tuesdays?|wednesdays?|thursdays?|fridays?|saturdays?|sundays?)`
+	everyNPattern       = regexp.MustCompile(`(?i)\b(?:cada|every)\s+(\d+)\s+(d[ií]as|semanas|meses|days|weeks|months)\b`)
+	weekdaysPattern     = regexp.MustCompile(`(?i)\b(?:de lunes a viernes|entre semana|on weekdays|every weekday)\b`)
+	everyDayOfWeek      = regexp.MustCompile(`(?i)\b(cada|todos los|todas las|los|every|on)\s+(` + spokenDay + `(?:\s*(?:,|y|and)\s*` + spokenDay + `)*)\b`)
+	spokenDaySeparators = regexp.MustCompile(`(?i)\s*(?:,|\by\b|\band\b)\s*`)
+)
+
+// frequencyPatterns match the plain "every day/week/month" phrases
+var frequencyPatterns = map[string]*regexp.Regexp{
+	FreqDaily:   regexp.MustCompile(`(?i)\b(?:cada d[ií]a|todos los d[ií]as|a diario|diariamente|every day|daily)\b`),
+	FreqWeekly:  regexp.MustCompile(`(?i)\b(?:cada semana|todas las semanas|semanalmente|every week|weekly)\b`),
+	FreqMonthly: regexp.MustCompile(`(?i)\b(?:cada mes|todos los meses|mensualmente|every month|monthly)\b`),
+}
+
+// Recurrence is the subset of iCalendar RRULEs reminders can repeat with
+type Recurrence struct {
+	Freq     string // FreqDaily, FreqWeekly or FreqMonthly
+	Interval int    // every Interval days, weeks or months
+	// ByDay are the days of the week a weekly recurrence falls on; none means
+	// the day it started on
+	ByDay []time.Weekday
+}
+
+// ParseRRule reads an RRULE such as "FREQ=WEEKLY;BYDAY=MO,TH"
+func ParseRRule(rule string) (Recurrence, error) {
+	recurrence := Recurrence{Interval: 1}
+	for _, part := range strings.Split(strings.TrimPrefix(rule, "RRULE:"), ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			recurrence.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return Recurrence{}, fmt.Errorf("invalid RRULE interval %q", value)
+			}
+			recurrence.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				index := slices.Index(rruleDays, strings.ToUpper(day))
+				if index < 0 {
+					return Recurrence{}, fmt.Errorf("unsupported RRULE day %q", day)
+				}
+				recurrence.ByDay = append(recurrence.ByDay, time.Weekday(index))
+			}
+		default:
+			return Recurrence{}, fmt.Errorf("unsupported RRULE part %q", part)
+		}
+	}
+	switch recurrence.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly:
+		return recurrence, nil
+	default:
+		return Recurrence{}, fmt.Errorf("unsupported RRULE frequency %q", recurrence.Freq)
+	}
+}
+
+// String writes the recurrence as an RRULE value
+func (r Recurrence) String() string {
+	rule := "FREQ=" + r.Freq
+	if r.Interval > 1 {
+		rule += ";INTERVAL=" + strconv.Itoa(r.Interval)
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = rruleDays[day]
+		}
+		rule += ";BYDAY=" + strings.Join(days, ",")
+	}
+	return rule
+}
+
+// CalendarRule writes the recurrence as an RRULE for calendars, starting at
+// start: monthly ones from the 29th on fall back to the last day of shorter
+// months, as Next does, where calendars would skip those months
+func (r Recurrence) CalendarRule(start time.Time) string {
+	rule := r.String()
+	if r.Freq == FreqMonthly && start.Day() > 28 {
+		rule += fmt.Sprintf(";BYMONTHDAY=%d,-1;BYSETPOS=1", start.Day())
+	}
+	return rule
+}
+
+// Next returns the first occurrence of a recurrence starting at start that
+// comes after after, at start's time of day
+func (r Recurrence) Next(start, after time.Time) time.Time {
+	interval := max(r.Interval, 1)
+	switch r.Freq {
+	case FreqDaily:
+		// Jump close to after, then step: AddDate keeps the clock across DST
+		skipped := 0
+		if after.After(start) {
+			skipped = int(after.Sub(start).Hours()/24) / interval * interval
+		}
+		next := start.AddDate(0, 0, skipped)
+		for !next.After(after) {
+			next = next.AddDate(0, 0, interval)
+		}
+		return next
+	case FreqMonthly:
+		next := start
+		for months := interval; !next.After(after); months += interval {
+			next = addMonths(start, months)
+		}
+		return next
+	}
+
+	// Weekly: the matching days of every Interval-th week from start's
+	days := r.ByDay
+	if len(days) == 0 {
+		days = []time.Weekday{start.Weekday()}
+	}
+	day := start
+	if after.After(start) {
+		day = time.Date(after.Year(), after.Month(), after.Day(), start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	}
+	firstWeek := weekStart(start)
+	for range 7 * (interval + 1) {
+		weeks := int(weekStart(day).Sub(firstWeek).Hours()/(24*7) + 0.5)
+		if !day.Before(start) && day.After(after) && weeks%interval == 0 && slices.Contains(days, day.Weekday()) {
+			return day
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// addMonths moves t months ahead, to the last day of the month when it is
+// shorter than t's day (January 31 is followed by February 28), where
+// AddDate would overflow into the next one
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
+
+// weekStart is the Monday of t's week, at midnight
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// Describe says how often the recurrence repeats ("cada lunes y jueves")
+func (r Recurrence) Describe() string {
+	interval := max(r.Interval, 1)
+	switch {
+	case r.Freq == FreqDaily && interval == 1:
+		return "cada día"
+	case r.Freq == FreqDaily:
+		return fmt.Sprintf("cada %d días", interval)
+	case r.Freq == FreqMonthly && interval == 1:
+		return "cada mes"
+	case r.Freq == FreqMonthly:
+		return fmt.Sprintf("cada %d meses", interval)
+	}
+
+	if len(r.ByDay) == 0 {
+		if interval > 1 {
+			return fmt.Sprintf("cada %d semanas", interval)
+		}
+		return "cada semana"
+	}
+	prefix := "cada "
+	if interval > 1 {
+		prefix = fmt.Sprintf("cada %d semanas, el ", interval)
+	}
+	if slices.Equal(r.ByDay, []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}) {
+		return "de lunes a viernes"
+	}
+	names := make([]string, len(r.ByDay))
+	for i, day := range r.ByDay {
+		names[i] = dayNames[day]
+	}
+	if len(names) == 1 {
+		return prefix + names[0]
+	}
+	return prefix + strings.Join(names[:len(names)-1], ", ") + " y " + names[len(names)-1]
+}
+
+// parseRecurrence finds how often a reminder should repeat ("cada lunes",
+// "todos los días", "every 2 weeks"), returning the request without it
+func parseRecurrence(request string) (Recurrence, string, bool) {
+	if m := everyNPattern.FindStringSubmatchIndex(request); m != nil {
+		interval, _ := strconv.Atoi(request[m[2]:m[3]])
+		recurrence := Recurrence{Freq: FreqDaily, Interval: max(interval, 1)}
+		switch unit := strings.ToLower(request[m[4]:m[5]]); {
+		case strings.HasPrefix(unit, "sem"), strings.HasPrefix(unit, "week"):
+			recurrence.Freq = FreqWeekly
+		case strings.HasPrefix(unit, "mes"), strings.HasPrefix(unit, "month"):
+			recurrence.Freq = FreqMonthly
+		}
+		return recurrence, request[:m[0]] + " " + request[m[1]:], true
+	}
+	if m := weekdaysPattern.FindStringIndex(request); m != nil {
+		weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		return Recurrence{Freq: FreqWeekly, Interval: 1, ByDay: weekdays}, request[:m[0]] + " " + request[m[1]:], true
+	}
+	// "los lunes" and "on mondays" repeat, "el lunes" and "on monday" are a date
+	if m := everyDayOfWeek.FindStringSubmatchIndex(request); m != nil && (strings.ToLower(request[m[2]:m[3]]) != "on" || strings.HasSuffix(strings.ToLower(strings.Fields(request[m[4]:m[5]])[0]), "s")) {
+		var days []time.Weekday
+		for _, name := range spokenDaySeparators.Split(strings.ToLower(request[m[4]:m[5]]), -1) {
+			day, ok := spokenDays[name]
+			if !ok {
+				day, ok = spokenDays[strings.TrimSuffix(name, "s")]
+			}
+			if ok && !slices.Contains(days, day) {
+				days = append(days, day)
+			}
+		}
+		if len(days) > 0 {
+			slices.SortFunc(days, func(a, b time.Weekday) int { return (int(a)+6)%7 - (int(b)+6)%7 })
+			return Recurrence{Freq: FreqWeekly, Interval: 1, ByDay: days}, request[:m[0]] + " " + request[m[1]:], true
+		}
+	}
+	for _, freq := range []string{FreqDaily, FreqWeekly, FreqMonthly} {
+		if m := frequencyPatterns[freq].FindStringIndex(request); m != nil {
+			return Recurrence{Freq: freq, Interval: 1}, request[:m[0]] + " " + request[m[1]:], true
+		}
+	}
+	return Recurrence{}, request, false
+}
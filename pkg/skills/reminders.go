@@ -0,0 +1,485 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
+	"github.com/jparrill/bobo-desk-pet/pkg/contacts"
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+)
+
+// remindersNamespace is where pending reminders are kept in the memory store
+const remindersNamespace = "reminders"
+
+var (
+	reminderPattern        = regexp.MustCompile(`(?i)^(?:recu[eé]rdame|remind me|pon(?:me)? un recordatorio(?: para)?|(?:a[nñ]ade|apunta|pon) una cita(?: con)?|add an appointment(?: with)?|set a reminder(?: to)?)\s+(.+?)[.!?]*$`)
+	contactReminderPattern = regexp.MustCompile(`(?i)^(?:recu[eé]rdale a|remind|send|m[aá]ndale a|env[ií]ale a)\s+(.+?)\s+(?:que|to|a reminder to|un recordatorio (?:de )?que|un recordatorio para)\s+(.+?)[.!?]*$`)
+	deliveryPattern        = regexp.MustCompile(`(?i)[,\s]*(?:\by\s+|\band\s+)?(?:m[aá]ndame un (?:sms|mensaje)|av[ií]same por (?:sms|mensaje|tel[eé]fono)|por (?:sms|mensaje|tel[eé]fono)|ll[aá]mame|text me|call me|by (?:sms|text|phone)|with a call|urgente|urgent)\b`)
+	listRemindersPattern   = regexp.MustCompile(`(?i)(?:qu[eé] recordatorios|mis recordatorios|qu[eé] citas tengo|my reminders|what reminders|list (?:my )?reminders)`)
+	relativeTimePattern    = regexp.MustCompile(`(?i)\b(?:en|dentro de|in)\s+(\d+|un|una|a|an|media)\s+(minutos?|horas?|minutes?|hours?|hora)\b`)
+	dayPattern             = regexp.MustCompile(`(?i)\b(pasado mañana|mañana|tomorrow|hoy|today)\b`)
+	clockPattern           = regexp.MustCompile(`(?i)\b(?:a las|a la|at)\s+(\d{1,2})(?:[:.](\d{2}))?\s*(am|pm|de la mañana|de la tarde|de la noche)?`)
+	reminderFillerWords    = regexp.MustCompile(`(?i)^(?:que|de|to|about|para)\s+|\s+(?:que|de|to|para)$`)
+)
+
+// Reminder is a pending reminder or appointment
+type Reminder struct {
+	ID   string    `json:"id"`
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+	// Contact is who the reminder is for when it is not the user
+	Contact string `json:"contact,omitempty"`
+	// Phone is the contact's number, used to deliver their reminder
+	Phone string `json:"phone,omitempty"`
+	// Notify asks for phone delivery too ("sms" or "call")
+	Notify string `json:"notify,omitempty"`
+	// RRule repeats the reminder, as an iCalendar RRULE ("FREQ=WEEKLY;BYDAY=MO")
+	RRule string `json:"rrule,omitempty"`
+	// Start is the first occurrence of a recurring reminder (its DTSTART),
+	// which every later one is counted from
+	Start time.Time `json:"start,omitzero"`
+	// Occurrences are the last times a recurring reminder went off
+	Occurrences []Occurrence `json:"occurrences,omitempty"`
+}
+
+// Phone delivery channels for reminders
+const (
+	NotifySMS  = "sms"
+	NotifyCall = "call"
+)
+
+// reminderList is the persisted set of pending reminders
+type reminderList struct {
+	Reminders []Reminder `json:"reminders"`
+}
+
+// CalendarWriter mirrors reminders to an external calendar (e.g. CalDAV) so
+// they also show up on the user's phone
+type CalendarWriter interface {
+	WriteReminder(ctx context.Context, reminder Reminder) error
+}
+
+// RemindersSkill schedules spoken reminders and appointments
+type RemindersSkill struct {
+	store    *memory.Store
+	calendar CalendarWriter
+	contacts *contacts.Directory
+	phone    bool
+	now      func() time.Time
+	logger   *slog.Logger
+	mu       sync.Mutex
+
+	// Weekly habits summary
+	summary       bool
+	summaryDay    time.Weekday
+	summaryHour   int
+	summaryMinute int
+}
+
+// NewRemindersSkill creates the reminders skill; calendar may be nil
+func NewRemindersSkill(store *memory.Store, calendar CalendarWriter) *RemindersSkill {
+	return &RemindersSkill{
+		store:    store,
+		calendar: calendar,
+		now:      time.Now,
+		logger:   slog.Default(),
+	}
+}
+
+// SetContacts lets reminders be addressed to people in the user's contacts
+// ("recuérdale a Marta que compre pan")
+func (r *RemindersSkill) SetContacts(directory *contacts.Directory) {
+	r.contacts = directory
+}
+
+// SetPhoneDelivery enables per-reminder SMS/call delivery ("recuérdame ... y
+// mándame un SMS"); contact reminders are texted to the contact
+func (r *RemindersSkill) SetPhoneDelivery(enabled bool) {
+	r.phone = enabled
+}
+
+// Name implements Skill
+func (r *RemindersSkill) Name() string {
+	return "reminders"
+}
+
+// Match implements Skill
+func (r *RemindersSkill) Match(utterance string) (*Request, bool) {
+	utterance = strings.TrimSpace(utterance)
+	if listRemindersPattern.MatchString(utterance) {
+		return &Request{Slots: map[string]string{"action": "list"}}, true
+	}
+	if habitSummaryPattern.MatchString(utterance) {
+		return &Request{Slots: map[string]string{"action": "summary"}}, true
+	}
+	if habitDonePattern.MatchString(utterance) && r.habitPending() {
+		return &Request{Slots: map[string]string{"action": "done"}}, true
+	}
+	if matches := stopReminderPattern.FindStringSubmatch(utterance); matches != nil {
+		return &Request{Slots: map[string]string{"action": "stop", "text": matches[1]}}, true
+	}
+
+	request, contact := "", ""
+	if matches := reminderPattern.FindStringSubmatch(utterance); matches != nil {
+		request = matches[1]
+	} else if matches := contactReminderPattern.FindStringSubmatch(utterance); matches != nil && r.contacts != nil {
+		contact, request = matches[1], matches[2]
+	} else {
+		return nil, false
+	}
+
+	notify := ""
+	if m := deliveryPattern.FindStringIndex(request); m != nil {
+		notify = NotifySMS
+		if phrase := strings.ToLower(request[m[0]:m[1]]); strings.Contains(phrase, "llam") || strings.Contains(phrase, "call") || strings.Contains(phrase, "tel") || strings.Contains(phrase, "phone") {
+			notify = NotifyCall
+		}
+		request = request[:m[0]] + " " + request[m[1]:]
+	}
+
+	slots := map[string]string{"action": "add"}
+	recurrence, request, recurring := parseRecurrence(request)
+	text, at, ok := parseReminder(request, r.now())
+	if recurring {
+		text, at = firstOccurrence(recurrence, request, r.now())
+		slots["rrule"] = recurrence.String()
+		ok = true
+	}
+	slots["text"] = text
+	if notify != "" && r.phone {
+		slots["notify"] = notify
+	}
+	if contact != "" {
+		slots["contact"] = contact
+	}
+	if ok {
+		slots["at"] = at.Format(time.RFC3339)
+	}
+	return &Request{Slots: slots}, true
+}
+
+// Handle implements Skill
+func (r *RemindersSkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	switch req.Slot("action", "") {
+	case "list":
+		return r.list()
+	case "summary":
+		return r.summarize()
+	case "done":
+		return r.markDone()
+	case "stop":
+		return r.stop(req.Slot("text", ""))
+	}
+
+	at, err := time.Parse(time.RFC3339, req.Slot("at", ""))
+	if err != nil {
+		return &Result{Text: "No he entendido para cuándo. Prueba con \"recuérdame llamar a mamá mañana a las 10\"."}, nil
+	}
+
+	reminder := Reminder{
+		ID:     fmt.Sprintf("bobo-%d", r.now().UnixNano()),
+		Text:   req.Slot("text", "tu recordatorio"),
+		At:     at,
+		Notify: req.Slot("notify", ""),
+		RRule:  req.Slot("rrule", ""),
+	}
+	if reminder.RRule != "" {
+		reminder.Start = at
+	}
+	if name := req.Slot("contact", ""); name != "" {
+		contact, ok := r.contacts.Find(name)
+		if !ok {
+			return &Result{Text: fmt.Sprintf("No encuentro a %s en tus contactos.", name)}, nil
+		}
+		reminder.Contact = contact.Name
+		if len(contact.Phones) > 0 {
+			reminder.Phone = contact.Phones[0]
+		}
+	}
+
+	r.mu.Lock()
+	list, err := r.load()
+	if err == nil {
+		list.Reminders = append(list.Reminders, reminder)
+		err = r.store.Save(remindersNamespace, list)
+	}
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	when := describeTime(at, r.now())
+	if recurrence, err := ParseRRule(reminder.RRule); err == nil {
+		when = fmt.Sprintf("%s a las %s, empezando %s", recurrence.Describe(), at.Format("15:04"), describeDay(at, r.now()))
+	}
+	text := fmt.Sprintf("Hecho, te recordaré %s %s.", reminder.Text, when)
+	if reminder.Contact != "" {
+		text = fmt.Sprintf("Hecho, le recordaré a %s que %s %s.", reminder.Contact, reminder.Text, when)
+	}
+	if reminder.RRule != "" && reminder.Contact == "" {
+		text += " Dime \"hecho\" cuando lo hagas y llevaré la cuenta de tu racha."
+	}
+	switch reminder.Notify {
+	case NotifySMS:
+		text += " Y mandaré un SMS."
+	case NotifyCall:
+		text += " Y llamaré por teléfono."
+	}
+	if r.calendar != nil {
+		if err := r.calendar.WriteReminder(ctx, reminder); err != nil {
+			r.logger.Warn("Failed to write reminder to the calendar", "error", err)
+			text += " No he podido añadirlo a tu calendario."
+		} else {
+			text += " También está en tu calendario."
+		}
+	}
+	return &Result{Text: text}, nil
+}
+
+// Due returns the reminders whose time has come, removing them or, when
+// they repeat, moving them to their next occurrence
+func (r *RemindersSkill) Due(now time.Time) []Reminder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list, err := r.load()
+	if err != nil {
+		r.logger.Warn("Failed to load reminders", "error", err)
+		return nil
+	}
+
+	var due, pending []Reminder
+	for _, reminder := range list.Reminders {
+		if reminder.At.After(now) {
+			pending = append(pending, reminder)
+			continue
+		}
+		due = append(due, reminder)
+		if recurrence, err := ParseRRule(reminder.RRule); err == nil {
+			reminder.Occurrences = append(reminder.Occurrences, Occurrence{At: reminder.At})
+			if len(reminder.Occurrences) > habitHistory {
+				reminder.Occurrences = reminder.Occurrences[len(reminder.Occurrences)-habitHistory:]
+			}
+			// Counted from the start, so the 31st stays the 31st after a
+			// shorter month
+			if reminder.Start.IsZero() {
+				reminder.Start = reminder.At
+			}
+			reminder.At = recurrence.Next(reminder.Start, now)
+			pending = append(pending, reminder)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	list.Reminders = pending
+	if err := r.store.Save(remindersNamespace, list); err != nil {
+		r.logger.Warn("Failed to save reminders", "error", err)
+	}
+	return due
+}
+
+// list reads out the pending reminders
+func (r *RemindersSkill) list() (*Result, error) {
+	r.mu.Lock()
+	list, err := r.load()
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Reminders) == 0 {
+		return &Result{Text: "No tienes recordatorios pendientes."}, nil
+	}
+
+	sort.Slice(list.Reminders, func(i, j int) bool { return list.Reminders[i].At.Before(list.Reminders[j].At) })
+	now := r.now()
+	parts := make([]string, 0, len(list.Reminders))
+	reminders := card.New("Recordatorios", "Cuándo", "Recordatorio", "Para")
+	for _, reminder := range list.Reminders {
+		part := fmt.Sprintf("%s %s", reminder.Text, describeTime(reminder.At, now))
+		if recurrence, err := ParseRRule(reminder.RRule); err == nil {
+			part = fmt.Sprintf("%s %s a las %s", reminder.Text, recurrence.Describe(), reminder.At.Format("15:04"))
+		}
+		if reminder.Contact != "" {
+			part = "para " + reminder.Contact + ", " + part
+		}
+		parts = append(parts, part)
+		reminders.Add(reminder.At.Format("02/01 15:04"), reminder.Text, reminder.Contact)
+	}
+	return &Result{Text: fmt.Sprintf("Tienes %d: %s.", len(parts), strings.Join(parts, "; ")), Card: reminders}, nil
+}
+
+// load reads the pending reminders from the memory store
+func (r *RemindersSkill) load() (*reminderList, error) {
+	list := &reminderList{}
+	if err := r.store.Load(remindersNamespace, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// parseReminder splits a request like "llamar a mamá mañana a las 10" into the
+// reminder text and its time; ok is false when no time was given
+func parseReminder(request string, now time.Time) (text string, at time.Time, ok bool) {
+	text = request
+
+	if m := relativeTimePattern.FindStringSubmatchIndex(text); m != nil {
+		amount := strings.ToLower(text[m[2]:m[3]])
+		unit := strings.ToLower(text[m[4]:m[5]])
+
+		n, err := strconv.Atoi(amount)
+		if err != nil {
+			n = 1
+		}
+		duration := time.Duration(n) * time.Minute
+		if strings.HasPrefix(unit, "h") {
+			duration = time.Duration(n) * time.Hour
+		}
+		if amount == "media" {
+			duration = 30 * time.Minute
+		}
+
+		at = now.Add(duration).Truncate(time.Minute)
+		return cleanReminderText(text[:m[0]] + text[m[1]:]), at, true
+	}
+
+	// The clock goes first so "de la mañana" is not taken for tomorrow
+	hour, minute, suffix := -1, 0, ""
+	if m := clockPattern.FindStringSubmatchIndex(text); m != nil {
+		hour, _ = strconv.Atoi(text[m[2]:m[3]])
+		if m[4] >= 0 {
+			minute, _ = strconv.Atoi(text[m[4]:m[5]])
+		}
+		if m[6] >= 0 {
+			suffix = strings.ToLower(text[m[6]:m[7]])
+		}
+		text = text[:m[0]] + text[m[1]:]
+	}
+
+	day := now
+	dayGiven := false
+	if m := dayPattern.FindStringSubmatchIndex(text); m != nil {
+		switch strings.ToLower(text[m[2]:m[3]]) {
+		case "mañana", "tomorrow":
+			day = now.AddDate(0, 0, 1)
+		case "pasado mañana":
+			day = now.AddDate(0, 0, 2)
+		}
+		dayGiven = true
+		text = text[:m[0]] + text[m[1]:]
+	}
+	text = cleanReminderText(text)
+
+	if hour < 0 {
+		if !dayGiven {
+			return text, time.Time{}, false
+		}
+		// A day without a time: remind in the morning
+		at = time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, now.Location())
+		return text, at, at.After(now)
+	}
+
+	if (suffix == "pm" || suffix == "de la tarde" || suffix == "de la noche") && hour < 12 {
+		hour += 12
+	}
+	if (suffix == "am" || suffix == "de la mañana") && hour == 12 {
+		hour = 0
+	}
+	if hour > 23 || minute > 59 {
+		return text, time.Time{}, false
+	}
+
+	at = time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+	if !dayGiven && !at.After(now) {
+		// "a las 5" said at 14:00 most likely means 17:00, otherwise tomorrow
+		if suffix == "" && hour < 12 && at.Add(12*time.Hour).After(now) {
+			at = at.Add(12 * time.Hour)
+		} else {
+			at = at.AddDate(0, 0, 1)
+		}
+	}
+	return text, at, at.After(now)
+}
+
+// firstOccurrence is the first time a recurring reminder goes off: at the
+// time given in the request, or 9:00, on the first matching day from now
+func firstOccurrence(recurrence Recurrence, request string, now time.Time) (string, time.Time) {
+	// Read from midnight, the time isn't moved to the afternoon or tomorrow
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	text, at, ok := parseReminder(request, today)
+	if !ok {
+		at = today.Add(9 * time.Hour)
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if recurrence.Freq == FreqWeekly && len(recurrence.ByDay) == 0 {
+		recurrence.ByDay = []time.Weekday{start.Weekday()}
+	}
+	// Today counts only if the time is still to come and the day matches
+	return text, recurrence.Next(start, now)
+}
+
+// habitPending reports whether a recurring reminder went off recently
+// enough to be marked done
+func (r *RemindersSkill) habitPending() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list, err := r.load()
+	if err != nil {
+		return false
+	}
+	now := r.now()
+	for _, reminder := range list.Reminders {
+		if reminder.RRule != "" && !lastOccurrence(reminder).IsZero() && now.Sub(lastOccurrence(reminder)) <= habitDoneWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanReminderText trims connecting words left around the reminder text
+func cleanReminderText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	for {
+		cleaned := strings.TrimSpace(reminderFillerWords.ReplaceAllString(text, ""))
+		if cleaned == text {
+			return text
+		}
+		text = cleaned
+	}
+}
+
+// describeDay says which day a reminder is due relative to now
+func describeDay(at, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch days := int(at.Sub(today).Hours() / 24); days {
+	case 0:
+		return "hoy"
+	case 1:
+		return "mañana"
+	default:
+		return fmt.Sprintf("el %s %s", dayNames[at.Weekday()], at.Format("02/01"))
+	}
+}
+
+// describeTime says when a reminder is due relative to now
+func describeTime(at, now time.Time) string {
+	clock := at.Format("15:04")
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch days := int(at.Sub(today).Hours() / 24); days {
+	case 0:
+		return "hoy a las " + clock
+	case 1:
+		return "mañana a las " + clock
+	default:
+		return fmt.Sprintf("el %s a las %s", at.Format("02/01"), clock)
+	}
+}
@@ -0,0 +1,47 @@
+package skills
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sandbox starts cmd under sandbox-exec with a profile that cuts it off the
+// network and, when home is set, from everything under it but the skill
+// directory
+func sandbox(cmd *exec.Cmd, noNetwork bool, home, dir string) error {
+	if !noNetwork && home == "" {
+		return cmd.Start()
+	}
+	sandboxExec, err := exec.LookPath("sandbox-exec")
+	if err != nil {
+		return fmt.Errorf("sandbox-exec not found (%w), approve the skill's permissions to run it", err)
+	}
+
+	profile := []string{"(version 1)", "(allow default)"}
+	if noNetwork {
+		profile = append(profile, "(deny network*)")
+	}
+	if home != "" {
+		// Later rules win, so the skill directory stays reachable
+		home, err := filepath.EvalSymlinks(home)
+		if err == nil {
+			dir, err = filepath.Abs(dir)
+		}
+		if err == nil {
+			dir, err = filepath.EvalSymlinks(dir)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to sandbox the skill: %w", err)
+		}
+		profile = append(profile,
+			fmt.Sprintf("(deny file-read* file-write* (subpath %s))", strconv.Quote(home)),
+			fmt.Sprintf("(allow file-read* file-write* (subpath %s))", strconv.Quote(dir)))
+	}
+
+	cmd.Args = append([]string{"sandbox-exec", "-p", strings.Join(profile, "\n")}, cmd.Args...)
+	cmd.Path = sandboxExec
+	return cmd.Start()
+}
@@ -0,0 +1,194 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// Landlock system calls and flags, from linux/landlock.h
+const (
+	sysLandlockCreateRuleset = 444
+	sysLandlockAddRule       = 445
+	sysLandlockRestrictSelf  = 446
+
+	landlockCreateRulesetVersion = 1
+	landlockRulePathBeneath      = 1
+	prSetNoNewPrivs              = 38
+	oPath                        = 0x200000 // O_PATH, missing from package syscall
+)
+
+// Landlock filesystem rights: those of the first ABI, then the ones added
+// by later ABIs (refer in 2, truncate in 3, ioctl on devices in 5)
+const (
+	landlockAccessFileV1 = 1<<0 | 1<<1 | 1<<2 // execute, write, read
+	landlockAccessFSV1   = 1<<13 - 1
+	landlockAccessRefer  = 1 << 13
+	landlockAccessTrunc  = 1 << 14
+	landlockAccessIoctl  = 1 << 15
+)
+
+// landlockPathBeneath is struct landlock_path_beneath_attr, which the kernel
+// reads as its 12 packed bytes
+type landlockPathBeneath struct {
+	allowedAccess uint64
+	parentFd      int32
+}
+
+// sandbox starts cmd cut off from the network in its own network namespace
+// and, when home is set, unable to reach anything under it but the skill
+// directory, enforced by Landlock; it refuses to start the skill when the
+// kernel can't do either
+func sandbox(cmd *exec.Cmd, noNetwork bool, home, dir string) error {
+	if noNetwork {
+		if !isolateNetwork() {
+			return fmt.Errorf("this system can't cut skills off the network (it needs unprivileged user namespaces), approve the network permission to run it")
+		}
+		unshare, err := exec.LookPath("unshare")
+		if err != nil {
+			return fmt.Errorf("unshare not found: %w", err)
+		}
+		cmd.Args = append([]string{"unshare", "--map-root-user", "--net"}, cmd.Args...)
+		cmd.Path = unshare
+	}
+	if home == "" {
+		return cmd.Start()
+	}
+
+	ruleset, err := landlockRuleset(home, dir)
+	if err != nil {
+		return fmt.Errorf("this system can't keep skills out of your home directory (%w), approve the filesystem permission to run it", err)
+	}
+	defer syscall.Close(ruleset)
+
+	// Landlock restricts the calling thread and the processes it starts, so
+	// the skill is started from a thread of its own, which dies with the
+	// goroutine since it is never unlocked
+	started := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0, 0, 0, 0); errno != 0 {
+			started <- fmt.Errorf("failed to restrict the skill: %w", errno)
+			return
+		}
+		if _, _, errno := syscall.RawSyscall(sysLandlockRestrictSelf, uintptr(ruleset), 0, 0); errno != 0 {
+			started <- fmt.Errorf("failed to restrict the skill: %w", errno)
+			return
+		}
+		started <- cmd.Start()
+	}()
+	return <-started
+}
+
+// landlockRuleset creates a Landlock ruleset allowing everything outside
+// home, and the skill directory inside it
+func landlockRuleset(home, dir string) (int, error) {
+	abi, _, errno := syscall.RawSyscall(sysLandlockCreateRuleset, 0, 0, landlockCreateRulesetVersion)
+	if errno != 0 {
+		return -1, fmt.Errorf("Landlock is not available: %w", errno)
+	}
+	access := uint64(landlockAccessFSV1)
+	files := uint64(landlockAccessFileV1)
+	if abi >= 2 {
+		access |= landlockAccessRefer
+	}
+	if abi >= 3 {
+		access |= landlockAccessTrunc
+		files |= landlockAccessTrunc
+	}
+	if abi >= 5 {
+		access |= landlockAccessIoctl
+		files |= landlockAccessIoctl
+	}
+
+	handled := struct{ handledAccessFS uint64 }{access}
+	fd, _, errno := syscall.RawSyscall(sysLandlockCreateRuleset, uintptr(unsafe.Pointer(&handled)), unsafe.Sizeof(handled), 0)
+	if errno != 0 {
+		return -1, fmt.Errorf("failed to create the Landlock ruleset: %w", errno)
+	}
+	ruleset := int(fd)
+
+	home, err := filepath.EvalSymlinks(home)
+	if err == nil {
+		dir, err = filepath.Abs(dir)
+	}
+	if err == nil {
+		dir, err = filepath.EvalSymlinks(dir)
+	}
+	if err != nil {
+		syscall.Close(ruleset)
+		return -1, err
+	}
+	for _, path := range append(pathsOutside("/", home), dir) {
+		if err := allowPath(ruleset, path, access, files); err != nil {
+			syscall.Close(ruleset)
+			return -1, err
+		}
+	}
+	return ruleset, nil
+}
+
+// pathsOutside lists the entries under root that don't lead into excluded,
+// descending into those that do; symbolic links are left out, as Landlock
+// checks where they lead
+func pathsOutside(root, excluded string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(root, entry.Name())
+		switch {
+		case path == excluded || entry.Type()&os.ModeSymlink != 0:
+		case strings.HasPrefix(excluded, path+string(filepath.Separator)):
+			paths = append(paths, pathsOutside(path, excluded)...)
+		default:
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// allowPath lets the sandboxed skill do anything beneath path
+func allowPath(ruleset int, path string, access, files uint64) error {
+	fd, err := syscall.Open(path, oPath|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		// Gone, or not ours to see: nothing to allow
+		return nil
+	}
+	defer syscall.Close(fd)
+
+	var stat syscall.Stat_t
+	if err := syscall.Fstat(fd, &stat); err != nil {
+		return nil
+	}
+	if stat.Mode&syscall.S_IFMT != syscall.S_IFDIR {
+		access = files
+	}
+	rule := landlockPathBeneath{allowedAccess: access, parentFd: int32(fd)}
+	if _, _, errno := syscall.RawSyscall6(sysLandlockAddRule, uintptr(ruleset), landlockRulePathBeneath, uintptr(unsafe.Pointer(&rule)), 0, 0, 0); errno != 0 {
+		return fmt.Errorf("failed to allow %s: %w", path, errno)
+	}
+	return nil
+}
+
+var (
+	networkIsolationOnce sync.Once
+	networkIsolation     bool
+)
+
+// isolateNetwork reports whether skills can be run without network access
+// in their own network namespace (unprivileged user namespaces)
+func isolateNetwork() bool {
+	networkIsolationOnce.Do(func() {
+		networkIsolation = exec.Command("unshare", "--map-root-user", "--net", "true").Run() == nil
+	})
+	return networkIsolation
+}
@@ -0,0 +1,17 @@
+//go:build !linux && !darwin
+
+package skills
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// sandbox starts cmd; skills can't be sandboxed on this system, so those
+// that weren't approved every permission they could misuse are refused
+func sandbox(cmd *exec.Cmd, noNetwork bool, home, dir string) error {
+	if noNetwork || home != "" {
+		return fmt.Errorf("skills can't be sandboxed on this system, approve the network and filesystem permissions to run it")
+	}
+	return cmd.Start()
+}
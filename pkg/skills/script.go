@@ -0,0 +1,416 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkjson"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+)
+
+// ScriptExtension is the extension of Starlark script skills
+const ScriptExtension = ".star"
+
+const (
+	// scriptTimeout bounds a single run of a script skill
+	scriptTimeout = 30 * time.Second
+	// maxScriptSteps stops scripts stuck in a loop well before the timeout
+	maxScriptSteps = 50_000_000
+	// maxScriptResponse caps the body http.get hands to a script
+	maxScriptResponse = 1 << 20
+)
+
+// contextLocal is the thread-local key holding the request context
+const contextLocal = "context"
+
+// ScriptHost is what script skills reach through the bobo and http modules;
+// there is no other way out of the interpreter (no files, no processes)
+type ScriptHost struct {
+	// Completer answers bobo.ask
+	Completer Completer
+	// Speak says text right away for bobo.speak, before the script's answer
+	Speak func(ctx context.Context, text string)
+	// Store keeps what bobo.store.set saves, in a namespace per script
+	Store *memory.Store
+}
+
+// skillClient fetches http.get for script skills and http_get for WASM skills
+var skillClient = &http.Client{Timeout: 10 * time.Second}
+
+// ScriptSkill is a skill written by the user in Starlark. A script defines
+// handle(utterance, slots), returning the text to say, and either patterns
+// (regular expressions whose named groups become slots) or
+// match(utterance), returning the slots as a dict, True or None
+type ScriptSkill struct {
+	name     string
+	patterns []*regexp.Regexp
+	match    starlark.Callable
+	handle   starlark.Callable
+	host     *ScriptHost
+	logger   *slog.Logger
+	mu       sync.Mutex // serializes bobo.store updates
+}
+
+// LoadScripts loads every script skill in dir; a missing dir has none, and a
+// broken script is skipped and reported without stopping the others
+func LoadScripts(dir string, host *ScriptHost) ([]*ScriptSkill, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+ScriptExtension))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scripts: %w", err)
+	}
+	sort.Strings(paths)
+
+	var loaded []*ScriptSkill
+	var errs []error
+	for _, path := range paths {
+		skill, err := NewScriptSkill(path, host)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("script %s: %w", filepath.Base(path), err))
+			continue
+		}
+		loaded = append(loaded, skill)
+	}
+	return loaded, errors.Join(errs...)
+}
+
+// NewScriptSkill runs the script at path to collect its patterns and functions
+func NewScriptSkill(path string, host *ScriptHost) (*ScriptSkill, error) {
+	name := strings.TrimSuffix(filepath.Base(path), ScriptExtension)
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid script name %q (lowercase letters, digits, - and _)", name)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+	defer cancel()
+
+	s := &ScriptSkill{name: name, host: host, logger: slog.Default()}
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, s.thread(ctx), path, src, s.predeclared())
+	if err != nil {
+		return nil, scriptError(err)
+	}
+
+	handle, ok := globals["handle"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("no handle(utterance, slots) function")
+	}
+	s.handle = handle
+	s.match, _ = globals["match"].(starlark.Callable)
+
+	if list, ok := globals["patterns"].(*starlark.List); ok {
+		for i := range list.Len() {
+			pattern, ok := starlark.AsString(list.Index(i))
+			if !ok {
+				return nil, fmt.Errorf("patterns[%d] is not a string", i)
+			}
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern: %w", err)
+			}
+			s.patterns = append(s.patterns, compiled)
+		}
+	}
+	if s.match == nil && len(s.patterns) == 0 {
+		return nil, fmt.Errorf("no patterns list or match(utterance) function")
+	}
+	return s, nil
+}
+
+// Name implements Skill
+func (s *ScriptSkill) Name() string {
+	return s.name
+}
+
+// Match implements Skill
+func (s *ScriptSkill) Match(utterance string) (*Request, bool) {
+	for _, pattern := range s.patterns {
+		matches := pattern.FindStringSubmatch(utterance)
+		if matches == nil {
+			continue
+		}
+		slots := make(map[string]string)
+		for i, name := range pattern.SubexpNames() {
+			if name != "" && matches[i] != "" {
+				slots[name] = strings.TrimSpace(matches[i])
+			}
+		}
+		return &Request{Slots: slots}, true
+	}
+	if s.match == nil {
+		return nil, false
+	}
+
+	// Matching runs for every utterance, so it gets a much shorter leash
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := starlark.Call(s.thread(ctx), s.match, starlark.Tuple{starlark.String(utterance)}, nil)
+	if err != nil {
+		s.logger.Warn("Script match failed", "script", s.name, "error", scriptError(err))
+		return nil, false
+	}
+	switch result := result.(type) {
+	case *starlark.Dict:
+		slots := make(map[string]string)
+		for _, item := range result.Items() {
+			key, _ := starlark.AsString(item[0])
+			value, ok := starlark.AsString(item[1])
+			if !ok {
+				value = item[1].String()
+			}
+			slots[key] = value
+		}
+		return &Request{Slots: slots}, true
+	default:
+		if result.Truth() {
+			return &Request{Slots: map[string]string{}}, true
+		}
+	}
+	return nil, false
+}
+
+// Handle implements Skill
+func (s *ScriptSkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, scriptTimeout)
+	defer cancel()
+
+	slots := starlark.NewDict(len(req.Slots))
+	for name, value := range req.Slots {
+		slots.SetKey(starlark.String(name), starlark.String(value))
+	}
+	result, err := starlark.Call(s.thread(ctx), s.handle, starlark.Tuple{starlark.String(req.Utterance), slots}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("script %s failed: %w", s.name, scriptError(err))
+	}
+	if result == starlark.None {
+		return &Result{}, nil
+	}
+	text, ok := starlark.AsString(result)
+	if !ok {
+		return nil, fmt.Errorf("script %s returned %s instead of a string", s.name, result.Type())
+	}
+	return &Result{Text: text}, nil
+}
+
+// thread creates an interpreter thread bound to ctx: cancelling ctx stops
+// the script, and so does running too many steps
+func (s *ScriptSkill) thread(ctx context.Context) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: s.name,
+		Print: func(_ *starlark.Thread, msg string) {
+			s.logger.Info("📜 "+msg, "script", s.name)
+		},
+	}
+	thread.SetLocal(contextLocal, ctx)
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+	if done := ctx.Done(); done != nil {
+		go func() {
+			<-done
+			thread.Cancel(ctx.Err().Error())
+		}()
+	}
+	return thread
+}
+
+// predeclared are the globals every script sees: json, http and bobo
+func (s *ScriptSkill) predeclared() starlark.StringDict {
+	store := starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"get": starlark.NewBuiltin("bobo.store.get", s.storeGet),
+		"set": starlark.NewBuiltin("bobo.store.set", s.storeSet),
+	})
+	return starlark.StringDict{
+		"json": starlarkjson.Module,
+		"http": &starlarkstruct.Module{Name: "http", Members: starlark.StringDict{
+			"get": starlark.NewBuiltin("http.get", s.httpGet),
+		}},
+		"bobo": &starlarkstruct.Module{Name: "bobo", Members: starlark.StringDict{
+			"ask":   starlark.NewBuiltin("bobo.ask", s.ask),
+			"speak": starlark.NewBuiltin("bobo.speak", s.speak),
+			"store": store,
+		}},
+	}
+}
+
+// threadContext returns the request context of a running script
+func threadContext(thread *starlark.Thread) context.Context {
+	if ctx, ok := thread.Local(contextLocal).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+// ask implements bobo.ask(prompt, system=""), a question to Claude
+func (s *ScriptSkill) ask(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var prompt, system string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "prompt", &prompt, "system?", &system); err != nil {
+		return nil, err
+	}
+	if s.host == nil || s.host.Completer == nil {
+		return nil, fmt.Errorf("%s: Claude is not available", fn.Name())
+	}
+	if system == "" {
+		system = "You are Bobo, a friendly desk pet. Answer briefly, in plain text that will be read aloud."
+	}
+	answer, err := s.host.Completer.Prompt(threadContext(thread), system, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	return starlark.String(answer), nil
+}
+
+// speak implements bobo.speak(text), said before the script's answer
+func (s *ScriptSkill) speak(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var text string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "text", &text); err != nil {
+		return nil, err
+	}
+	if s.host != nil && s.host.Speak != nil && strings.TrimSpace(text) != "" {
+		s.host.Speak(threadContext(thread), text)
+	}
+	return starlark.None, nil
+}
+
+// httpGet implements http.get(url, headers={}), returning the body of a
+// successful response as a string
+func (s *ScriptSkill) httpGet(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var rawURL string
+	var headers *starlark.Dict
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "url", &rawURL, "headers?", &headers); err != nil {
+		return nil, err
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("%s: %q is not an http(s) URL", fn.Name(), rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(threadContext(thread), http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	if headers != nil {
+		for _, item := range headers.Items() {
+			name, _ := starlark.AsString(item[0])
+			value, _ := starlark.AsString(item[1])
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, err := skillClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s: %s returned status %d", fn.Name(), parsed.Host, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxScriptResponse+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	if len(body) > maxScriptResponse {
+		return nil, fmt.Errorf("%s: response larger than %d bytes", fn.Name(), maxScriptResponse)
+	}
+	return starlark.String(body), nil
+}
+
+// storeNamespace is the memory namespace of the script's bobo.store
+func (s *ScriptSkill) storeNamespace() string {
+	return "script-" + s.name
+}
+
+// loadStore reads the script's saved values
+func (s *ScriptSkill) loadStore() (map[string]json.RawMessage, error) {
+	if s.host == nil || s.host.Store == nil {
+		return nil, fmt.Errorf("memory is not available")
+	}
+	values := make(map[string]json.RawMessage)
+	if err := s.host.Store.Load(s.storeNamespace(), &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// storeGet implements bobo.store.get(key, default=None)
+func (s *ScriptSkill) storeGet(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	var fallback starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "key", &key, "default?", &fallback); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	values, err := s.loadStore()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	value, ok := values[key]
+	if !ok {
+		return fallback, nil
+	}
+	decode := starlarkjson.Module.Members["decode"]
+	return starlark.Call(thread, decode, starlark.Tuple{starlark.String(value)}, nil)
+}
+
+// storeSet implements bobo.store.set(key, value), for any value json.encode
+// accepts; None deletes the key
+func (s *ScriptSkill) storeSet(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	var value starlark.Value
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "key", &key, "value", &value); err != nil {
+		return nil, err
+	}
+
+	var encoded starlark.Value
+	if value != starlark.None {
+		var err error
+		encode := starlarkjson.Module.Members["encode"]
+		if encoded, err = starlark.Call(thread, encode, starlark.Tuple{value}, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values, err := s.loadStore()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	if encoded == nil {
+		delete(values, key)
+	} else {
+		values[key] = json.RawMessage(encoded.(starlark.String))
+	}
+	if err := s.host.Store.Save(s.storeNamespace(), values); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+	return starlark.None, nil
+}
+
+// scriptError adds the Starlark backtrace, which points at the failing line
+func scriptError(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
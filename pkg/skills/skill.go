@@ -0,0 +1,156 @@
+// Package skills provides local voice skills that handle specific requests
+// before (or instead of) a free-form Claude conversation
+package skills
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
+)
+
+// Skill handles a family of voice requests
+type Skill interface {
+	// Name identifies the skill, and is used as the interaction intent
+	Name() string
+	// Match inspects an utterance and returns the extracted request if the skill applies
+	Match(utterance string) (*Request, bool)
+	// Handle executes the request
+	Handle(ctx context.Context, req *Request) (*Result, error)
+}
+
+// Request is a matched utterance with its extracted slots
+type Request struct {
+	Utterance string
+	Slots     map[string]string
+}
+
+// Slot returns a slot value or the fallback when missing
+func (r *Request) Slot(name, fallback string) string {
+	if value, ok := r.Slots[name]; ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Result is what a skill wants said back to the user
+type Result struct {
+	Text string
+	// SpeechRate overrides the TTS rate for this answer when > 0
+	SpeechRate int
+	// VoiceID overrides the TTS voice for this answer when set
+	VoiceID string
+	// Card is shown on screen alongside the spoken answer
+	Card *card.Card
+}
+
+// Engager is implemented by skills that run as a mode and, while engaged,
+// take every utterance before any other skill
+type Engager interface {
+	Engaged() bool
+}
+
+// Voiced is implemented by skills that answer in a voice of their own
+// instead of the persona's
+type Voiced interface {
+	// Voice returns the skill's TTS voice and rate ("" and 0 keep the persona's)
+	Voice() (voiceID string, rate int)
+}
+
+// LanguageHinter is implemented by skills that need the next utterance to be
+// transcribed in a specific language (ISO 639-1 code) while engaged
+type LanguageHinter interface {
+	TranscriptionLanguage() string
+}
+
+// Completer lets skills ask the language model for text
+type Completer interface {
+	Prompt(ctx context.Context, system, prompt string) (string, error)
+}
+
+// Registry holds the enabled skills in match priority order
+type Registry struct {
+	skills []Skill
+	mu     sync.RWMutex
+}
+
+// NewRegistry creates an empty skill registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a skill; earlier skills win when several match
+func (r *Registry) Register(skill Skill) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skills = append(r.skills, skill)
+}
+
+// Match finds the first skill that accepts the utterance
+func (r *Registry) Match(utterance string) (Skill, *Request) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Engaged modes get first pick (latest registered, most specific, first),
+	// then every skill in registration order
+	ordered := make([]Skill, 0, len(r.skills))
+	for i := len(r.skills) - 1; i >= 0; i-- {
+		if engager, ok := r.skills[i].(Engager); ok && engager.Engaged() {
+			ordered = append(ordered, r.skills[i])
+		}
+	}
+	ordered = append(ordered, r.skills...)
+
+	for _, skill := range ordered {
+		if req, ok := skill.Match(utterance); ok {
+			if req.Utterance == "" {
+				req.Utterance = utterance
+			}
+			return skill, req
+		}
+	}
+	return nil, nil
+}
+
+// Engaged reports whether a skill is running as a mode, taking every utterance
+func (r *Registry) Engaged() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, skill := range r.skills {
+		if engager, ok := skill.(Engager); ok && engager.Engaged() {
+			return true
+		}
+	}
+	return false
+}
+
+// TranscriptionLanguage returns the language requested by an engaged skill, if any
+func (r *Registry) TranscriptionLanguage() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Later registrations are more specific (e.g. pronunciation inside tutor mode)
+	for i := len(r.skills) - 1; i >= 0; i-- {
+		engager, engaged := r.skills[i].(Engager)
+		hinter, hints := r.skills[i].(LanguageHinter)
+		if engaged && hints && engager.Engaged() {
+			if language := hinter.TranscriptionLanguage(); language != "" {
+				return language
+			}
+		}
+	}
+	return ""
+}
+
+// Names lists the registered skills
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.skills))
+	for i, skill := range r.skills {
+		names[i] = skill.Name()
+	}
+	return names
+}
@@ -0,0 +1,78 @@
+package skills
+
+import (
+	"math"
+	"time"
+)
+
+// Card is a flashcard with its SM-2 scheduling state
+type Card struct {
+	Front       string    `json:"front"`
+	Back        string    `json:"back"`
+	Easiness    float64   `json:"easiness"`
+	Interval    int       `json:"interval_days"`
+	Repetitions int       `json:"repetitions"`
+	Due         time.Time `json:"due"`
+}
+
+// NewCard creates a card that is due immediately
+func NewCard(front, back string, now time.Time) Card {
+	return Card{
+		Front:    front,
+		Back:     back,
+		Easiness: 2.5,
+		Due:      now,
+	}
+}
+
+// Review applies the SM-2 algorithm for an answer graded 0 (blackout) to 5 (perfect)
+func (c *Card) Review(quality int, now time.Time) {
+	quality = max(0, min(5, quality))
+
+	if quality < 3 {
+		// Failed recall restarts the repetition sequence
+		c.Repetitions = 0
+		c.Interval = 1
+	} else {
+		switch c.Repetitions {
+		case 0:
+			c.Interval = 1
+		case 1:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.Easiness))
+		}
+		c.Repetitions++
+	}
+
+	q := float64(5 - quality)
+	c.Easiness += 0.1 - q*(0.08+q*0.02)
+	if c.Easiness < 1.3 {
+		c.Easiness = 1.3
+	}
+
+	c.Due = now.AddDate(0, 0, c.Interval)
+}
+
+// IsDue reports whether the card should be reviewed at now
+func (c *Card) IsDue(now time.Time) bool {
+	return !c.Due.After(now)
+}
+
+// gradeAnswer maps answer similarity to an SM-2 quality grade
+func gradeAnswer(similarity float64) int {
+	switch {
+	case similarity >= 0.95:
+		return 5
+	case similarity >= 0.8:
+		return 4
+	case similarity >= 0.65:
+		return 3
+	case similarity >= 0.45:
+		return 2
+	case similarity > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,82 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// storyPattern matches "cuéntame un cuento (corto) sobre X" / "tell me a (long) story about X"
+var storyPattern = regexp.MustCompile(`(?i)(?:cu[eé]ntame|tell me|dime)\s+(?:un|una|a|an)\s+(?:(corto|cortito|largo|short|long)\s+)?(?:cuento|historia|story)(?:\s+(corto|cortito|largo|short|long))?(?:\s+(?:sobre|de|about|acerca de)\s+(.+))?`)
+
+// storySystemPrompt keeps stories suitable for young children
+const storySystemPrompt = `You are a gentle storyteller for young children (4-8 years old).
+Use very simple words and short sentences, a warm and happy tone, no violence or scary parts,
+and always end with a kind little lesson. Write plain text only (no titles, lists or markdown),
+in the same language as the request.`
+
+// StorySkill tells short children's stories on request
+type StorySkill struct {
+	completer  Completer
+	speechRate int
+}
+
+// NewStorySkill creates the story skill; speechRate is the gentle TTS rate used to read stories
+func NewStorySkill(completer Completer, speechRate int) *StorySkill {
+	return &StorySkill{
+		completer:  completer,
+		speechRate: speechRate,
+	}
+}
+
+// Name implements Skill
+func (s *StorySkill) Name() string {
+	return "story"
+}
+
+// Match implements Skill
+func (s *StorySkill) Match(utterance string) (*Request, bool) {
+	matches := storyPattern.FindStringSubmatch(utterance)
+	if matches == nil {
+		return nil, false
+	}
+
+	length := matches[1]
+	if length == "" {
+		length = matches[2]
+	}
+
+	return &Request{
+		Slots: map[string]string{
+			"length": strings.ToLower(length),
+			"topic":  strings.TrimSpace(strings.TrimRight(matches[3], ".!?")),
+		},
+	}, true
+}
+
+// Handle implements Skill
+func (s *StorySkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	sentences := 6
+	switch req.Slot("length", "") {
+	case "corto", "cortito", "short":
+		sentences = 3
+	case "largo", "long":
+		sentences = 12
+	}
+
+	topic := req.Slot("topic", "an adventure of a small friendly animal")
+
+	prompt := fmt.Sprintf("Tell a story of about %d sentences about: %s.\nOriginal request: %q",
+		sentences, topic, req.Utterance)
+
+	story, err := s.completer.Prompt(ctx, storySystemPrompt, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate story: %w", err)
+	}
+
+	return &Result{
+		Text:       story,
+		SpeechRate: s.speechRate,
+	}, nil
+}
@@ -0,0 +1,339 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
+)
+
+var (
+	timerWordPattern   = regexp.MustCompile(`(?i)\b(?:(?:el|los|un|mi|the|a|my)\s+)?(?:timers?|temporizador(?:es)?)\b`)
+	listTimersPattern  = regexp.MustCompile(`(?i)(?:qu[eé] temporizadores|mis temporizadores|my timers|what timers|list (?:my |the )?timers)`)
+	cancelTimerPattern = regexp.MustCompile(`(?i)^(?:bobo,?\s+)?(?:cancela|quita|borra|det[eé]n|apaga|para|cancel|stop|remove|delete|clear)\s+(.+?)[.!?]*$`)
+	timerQueryPattern  = regexp.MustCompile(`(?i)^(?:bobo,?\s+)?¿?(?:cu[aá]nto (?:tiempo )?(?:le )?(?:queda|falta)|how (?:long|much time)(?: is)?(?: left| remaining)?|time left)\s*(.*?)[.!?]*$`)
+	// Spelled-out amounts need a whole unit word, or "ah" would be an hour
+	durationPattern    = regexp.MustCompile(`(?i)\b(?:(\d+(?:[.,]\d+)?)\s*(horas?|hours?|h|minutos?|minutes?|mins?|segundos?|seconds?|secs?|segs?|s)|(un|una|medio|media|half an?|an?)\s+(horas?|hours?|minutos?|minutes?|mins?|segundos?|seconds?|secs?|segs?))\b(\s+y\s+medi[oa]|\s+and a half)?`)
+	bareMinutesPattern = regexp.MustCompile(`\b(\d+)\b`)
+	allTimersPattern   = regexp.MustCompile(`(?i)\b(?:todos|all)\b`)
+)
+
+// timerFillers are the words around a timer's name ("pon un temporizador
+// para la pasta", "set a timer for the oven")
+var timerFillers = map[string]bool{
+	"bobo": true, "pon": true, "ponme": true, "crea": true, "inicia": true, "empieza": true,
+	"set": true, "start": true, "un": true, "una": true, "a": true, "an": true,
+	"de": true, "del": true, "para": true, "con": true, "for": true, "of": true, "on": true,
+	"called": true, "llamado": true, "y": true, "and": true, "al": true, "en": true, "in": true,
+}
+
+// Timer is a running countdown, named after what it times ("pasta")
+type Timer struct {
+	Name     string
+	Duration time.Duration
+	Ends     time.Time
+}
+
+// Label says which timer it is ("el temporizador de la pasta", "el
+// temporizador de 8 minutos" when it has no name)
+func (t Timer) Label() string {
+	if t.Name == "" {
+		return "el temporizador de " + spokenDuration(t.Duration)
+	}
+	label := "el temporizador de " + t.Name
+	return strings.Replace(label, " de el ", " del ", 1)
+}
+
+// TimersSkill runs several named timers at once ("timer pasta 8 minutos",
+// "timer horno 25"); they are short, so they are kept in memory only
+type TimersSkill struct {
+	now    func() time.Time
+	mu     sync.Mutex
+	timers []Timer
+}
+
+// NewTimersSkill creates the timers skill
+func NewTimersSkill() *TimersSkill {
+	return &TimersSkill{now: time.Now}
+}
+
+// Name implements Skill
+func (t *TimersSkill) Name() string {
+	return "timers"
+}
+
+// Match implements Skill
+func (t *TimersSkill) Match(utterance string) (*Request, bool) {
+	utterance = strings.TrimSpace(utterance)
+	if listTimersPattern.MatchString(utterance) {
+		return &Request{Slots: map[string]string{"action": "list"}}, true
+	}
+	named := timerWordPattern.MatchString(utterance)
+
+	if matches := cancelTimerPattern.FindStringSubmatch(utterance); matches != nil && named {
+		if allTimersPattern.MatchString(matches[1]) {
+			return &Request{Slots: map[string]string{"action": "cancel_all"}}, true
+		}
+		return &Request{Slots: map[string]string{"action": "cancel", "name": timerName(matches[1])}}, true
+	}
+
+	// "¿Cuánto le queda a la pasta?" is about a timer only if one is running
+	// by that name, or the question says "timer"
+	if matches := timerQueryPattern.FindStringSubmatch(utterance); matches != nil {
+		name := timerName(matches[1])
+		t.mu.Lock()
+		running := len(t.timers) > 0
+		found := findTimer(t.timers, name) >= 0
+		t.mu.Unlock()
+		if named || found || (name == "" && running) {
+			return &Request{Slots: map[string]string{"action": "query", "name": name}}, true
+		}
+		return nil, false
+	}
+
+	if !named {
+		return nil, false
+	}
+	duration, rest := parseTimerDuration(utterance)
+	if duration <= 0 {
+		return nil, false
+	}
+	return &Request{Slots: map[string]string{
+		"action":   "set",
+		"name":     timerName(rest),
+		"duration": duration.String(),
+	}}, true
+}
+
+// Handle implements Skill
+func (t *TimersSkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	name := req.Slot("name", "")
+
+	switch req.Slot("action", "") {
+	case "set":
+		duration, err := time.ParseDuration(req.Slot("duration", ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid timer duration: %w", err)
+		}
+		timer := Timer{Name: name, Duration: duration, Ends: now.Add(duration)}
+		if i := findTimer(t.timers, name); i >= 0 && name != "" {
+			t.timers[i] = timer
+			return &Result{Text: fmt.Sprintf("Vale, he vuelto a poner %s a %s.", timer.Label(), spokenDuration(duration))}, nil
+		}
+		t.timers = append(t.timers, timer)
+		if name == "" {
+			return &Result{Text: fmt.Sprintf("Vale, temporizador de %s en marcha.", spokenDuration(duration))}, nil
+		}
+		return &Result{Text: fmt.Sprintf("Vale, %s: %s.", timer.Label(), spokenDuration(duration))}, nil
+
+	case "list":
+		return listTimers(t.timers, now), nil
+
+	case "cancel_all":
+		if len(t.timers) == 0 {
+			return &Result{Text: "No hay ningún temporizador en marcha."}, nil
+		}
+		t.timers = nil
+		return &Result{Text: "Vale, he cancelado todos los temporizadores."}, nil
+
+	case "cancel":
+		i := t.pick(name)
+		if i < 0 {
+			return &Result{Text: t.notFound(name)}, nil
+		}
+		cancelled := t.timers[i]
+		t.timers = append(t.timers[:i], t.timers[i+1:]...)
+		return &Result{Text: fmt.Sprintf("Vale, he cancelado %s.", cancelled.Label())}, nil
+	}
+
+	if name == "" && len(t.timers) > 1 {
+		return listTimers(t.timers, now), nil
+	}
+	i := t.pick(name)
+	if i < 0 {
+		return &Result{Text: t.notFound(name)}, nil
+	}
+	return &Result{Text: capitalize(timeLeft(t.timers[i], now)) + "."}, nil
+}
+
+// Due returns the timers that have run out, which stop
+func (t *TimersSkill) Due(now time.Time) []Timer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var due, running []Timer
+	for _, timer := range t.timers {
+		if timer.Ends.After(now) {
+			running = append(running, timer)
+		} else {
+			due = append(due, timer)
+		}
+	}
+	t.timers = running
+	return due
+}
+
+// pick finds the timer called name, or the only one running when no name
+// is given; -1 if there is none
+func (t *TimersSkill) pick(name string) int {
+	if name == "" {
+		if len(t.timers) == 1 {
+			return 0
+		}
+		return -1
+	}
+	return findTimer(t.timers, name)
+}
+
+// notFound explains why no timer was picked
+func (t *TimersSkill) notFound(name string) string {
+	switch {
+	case len(t.timers) == 0:
+		return "No hay ningún temporizador en marcha."
+	case name == "":
+		return "Hay varios temporizadores en marcha, ¿cuál?"
+	default:
+		return fmt.Sprintf("No tengo ningún temporizador de %s.", name)
+	}
+}
+
+// listTimers reads out the running timers, the first to end first
+func listTimers(timers []Timer, now time.Time) *Result {
+	if len(timers) == 0 {
+		return &Result{Text: "No hay ningún temporizador en marcha. Prueba con \"timer pasta 8 minutos\"."}
+	}
+	sorted := append([]Timer(nil), timers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Ends.Before(sorted[j].Ends) })
+
+	var parts []string
+	table := card.New("Temporizadores", "Temporizador", "Queda")
+	for _, timer := range sorted {
+		parts = append(parts, timeLeft(timer, now))
+		name := timer.Name
+		if name == "" {
+			name = spokenDuration(timer.Duration)
+		}
+		table.Add(name, timer.Ends.Sub(now).Round(time.Second).String())
+	}
+	return &Result{Text: capitalize(strings.Join(parts, "; ")) + ".", Card: table}
+}
+
+// timeLeft says how long a timer has to go ("al temporizador de la pasta
+// le quedan 3 minutos")
+func timeLeft(timer Timer, now time.Time) string {
+	left := spokenDuration(timer.Ends.Sub(now))
+	verb := "quedan"
+	if strings.HasPrefix(left, "1 ") && !strings.Contains(left, " y ") {
+		verb = "queda"
+	}
+	return fmt.Sprintf("al %s le %s %s", strings.TrimPrefix(timer.Label(), "el "), verb, left)
+}
+
+// findTimer returns the index of the timer called name, ignoring articles,
+// or -1
+func findTimer(timers []Timer, name string) int {
+	key := nameKey(name)
+	if key == "" {
+		return -1
+	}
+	for i, timer := range timers {
+		if nameKey(timer.Name) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseTimerDuration finds how long a timer should run ("8 minutos", "1
+// hora y media"; a bare number is minutes), returning the text without it
+func parseTimerDuration(text string) (time.Duration, string) {
+	var total time.Duration
+	for _, m := range durationPattern.FindAllStringSubmatch(text, -1) {
+		number, unit := m[1]+m[3], m[2]+m[4]
+		amount := 1.0
+		switch number := strings.ToLower(number); {
+		case number == "medio" || number == "media" || strings.HasPrefix(number, "half"):
+			amount = 0.5
+		case number == "un" || number == "una" || number == "a" || number == "an":
+		default:
+			amount, _ = strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+		}
+		if m[5] != "" {
+			amount += 0.5
+		}
+
+		length := time.Minute
+		switch u := strings.ToLower(unit); {
+		case strings.HasPrefix(u, "h"):
+			length = time.Hour
+		case strings.HasPrefix(u, "s"):
+			length = time.Second
+		}
+		total += time.Duration(amount * float64(length))
+	}
+	if total > 0 {
+		return total.Round(time.Second), durationPattern.ReplaceAllString(text, " ")
+	}
+
+	// "timer horno 25"
+	if m := bareMinutesPattern.FindStringIndex(text); m != nil {
+		minutes, _ := strconv.Atoi(text[m[0]:m[1]])
+		return time.Duration(minutes) * time.Minute, text[:m[0]] + " " + text[m[1]:]
+	}
+	return 0, text
+}
+
+// timerName is what is left of a request once the timer word and the
+// words around the name are gone ("pon un timer para la pasta" is "la
+// pasta")
+func timerName(text string) string {
+	words := strings.Fields(strings.Trim(timerWordPattern.ReplaceAllString(text, " "), " .,!?¿¡"))
+	for len(words) > 0 && timerFillers[strings.ToLower(words[0])] {
+		words = words[1:]
+	}
+	for len(words) > 0 && (timerFillers[strings.ToLower(words[len(words)-1])] || nameArticles.MatchString(words[len(words)-1]+" ")) {
+		words = words[:len(words)-1]
+	}
+	return strings.Trim(strings.Join(words, " "), " .,!?¿¡")
+}
+
+// spokenDuration says a duration the way people do ("8 minutos", "1 hora
+// y 5 minutos", "2 minutos y 30 segundos")
+func spokenDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= 10*time.Minute {
+		// Seconds don't matter that far off
+		d = d.Round(time.Minute)
+	}
+	hours, minutes, seconds := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+
+	var parts []string
+	for _, part := range []struct {
+		n              int
+		single, plural string
+	}{{hours, "hora", "horas"}, {minutes, "minuto", "minutos"}, {seconds, "segundo", "segundos"}} {
+		switch {
+		case part.n == 1:
+			parts = append(parts, "1 "+part.single)
+		case part.n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", part.n, part.plural))
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return "0 segundos"
+	case 1:
+		return parts[0]
+	default:
+		return strings.Join(parts[:len(parts)-1], ", ") + " y " + parts[len(parts)-1]
+	}
+}
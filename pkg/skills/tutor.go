@@ -0,0 +1,312 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+)
+
+// tutorNamespace is where practiced vocabulary is kept in the memory store
+const tutorNamespace = "tutor"
+
+// tutorLanguage describes a language the tutor can teach
+type tutorLanguage struct {
+	Name  string
+	Voice string
+	Names []string
+}
+
+var tutorLanguages = []tutorLanguage{
+	{Name: "English", Voice: "en", Names: []string{"english", "inglés", "ingles"}},
+	{Name: "French", Voice: "fr", Names: []string{"french", "francés", "frances"}},
+	{Name: "German", Voice: "de", Names: []string{"german", "alemán", "aleman"}},
+	{Name: "Italian", Voice: "it", Names: []string{"italian", "italiano"}},
+	{Name: "Portuguese", Voice: "pt", Names: []string{"portuguese", "portugués", "portugues"}},
+	{Name: "Spanish", Voice: "es", Names: []string{"spanish", "español", "espanol"}},
+}
+
+var (
+	tutorStartPattern = regexp.MustCompile(`(?i)(?:practi(?:ce|car)|aprender|learn|tutor|clase de|lesson)\s.*?\b(english|inglés|ingles|french|francés|frances|german|alemán|aleman|italian|italiano|portuguese|portugués|portugues|spanish|español|espanol)\b`)
+	tutorStopPattern  = regexp.MustCompile(`(?i)\b(stop|exit|quit|salir|para|terminar|termina|fin)\b.*\b(tutor|class|lesson|practice|clase|práctica|practica)\b|^(stop|salir|terminar)$`)
+	vocabLinePattern  = regexp.MustCompile(`(?im)^\s*VOCAB:\s*(.*)$`)
+)
+
+// tutorVocabulary is the persisted practice record per language
+type tutorVocabulary struct {
+	Languages map[string]*languageProgress `json:"languages"`
+}
+
+type languageProgress struct {
+	Words         map[string]int `json:"words"`
+	Sessions      int            `json:"sessions"`
+	LastPracticed time.Time      `json:"last_practiced"`
+}
+
+// TutorSkill is a conversation mode that only speaks the target language,
+// corrects the user's sentences and tracks practiced vocabulary
+type TutorSkill struct {
+	completer       Completer
+	store           *memory.Store
+	defaultLanguage string
+
+	mu       sync.Mutex
+	language *tutorLanguage
+	turns    []string
+	session  map[string]bool
+}
+
+// NewTutorSkill creates the tutor; defaultLanguage is used when the request names none
+func NewTutorSkill(completer Completer, store *memory.Store, defaultLanguage string) *TutorSkill {
+	return &TutorSkill{
+		completer:       completer,
+		store:           store,
+		defaultLanguage: defaultLanguage,
+	}
+}
+
+// Name implements Skill
+func (t *TutorSkill) Name() string {
+	return "tutor"
+}
+
+// Engaged implements Engager
+func (t *TutorSkill) Engaged() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.language != nil
+}
+
+// CurrentLanguage returns the language being practiced, or nil when not engaged
+func (t *TutorSkill) CurrentLanguage() *tutorLanguage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.language
+}
+
+// TranscriptionLanguage implements LanguageHinter
+func (t *TutorSkill) TranscriptionLanguage() string {
+	if language := t.CurrentLanguage(); language != nil {
+		return language.Voice
+	}
+	return ""
+}
+
+// Match implements Skill
+func (t *TutorSkill) Match(utterance string) (*Request, bool) {
+	if t.Engaged() {
+		// Let pronunciation drills run inside the tutor mode
+		if pronunciationPattern.MatchString(strings.TrimSpace(utterance)) {
+			return nil, false
+		}
+
+		action := "converse"
+		if tutorStopPattern.MatchString(strings.TrimSpace(utterance)) {
+			action = "stop"
+		}
+		return &Request{Slots: map[string]string{"action": action}}, true
+	}
+
+	matches := tutorStartPattern.FindStringSubmatch(utterance)
+	if matches == nil {
+		return nil, false
+	}
+	return &Request{Slots: map[string]string{"action": "start", "language": strings.ToLower(matches[1])}}, true
+}
+
+// Handle implements Skill
+func (t *TutorSkill) Handle(ctx context.Context, req *Request) (*Result, error) {
+	switch req.Slot("action", "") {
+	case "start":
+		return t.start(req.Slot("language", t.defaultLanguage))
+	case "stop":
+		return t.stop()
+	default:
+		return t.converse(ctx, req.Utterance)
+	}
+}
+
+// start engages tutor mode for a language
+func (t *TutorSkill) start(name string) (*Result, error) {
+	language := findTutorLanguage(name)
+	if language == nil {
+		language = findTutorLanguage(t.defaultLanguage)
+	}
+	if language == nil {
+		return nil, fmt.Errorf("unsupported tutor language %q", name)
+	}
+
+	t.mu.Lock()
+	t.language = language
+	t.turns = nil
+	t.session = make(map[string]bool)
+	t.mu.Unlock()
+
+	vocab, err := t.loadVocabulary()
+	if err != nil {
+		return nil, err
+	}
+	progress := vocab.progress(language.Name)
+	progress.Sessions++
+	progress.LastPracticed = time.Now()
+	if err := t.store.Save(tutorNamespace, vocab); err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("Tutor mode: %s. Let's talk! Say \"stop practice\" when you're done. How was your day?", language.Name)
+	if known := len(progress.Words); known > 0 {
+		text = fmt.Sprintf("Tutor mode: %s. Welcome back, you've practiced %d words so far. How was your day?", language.Name, known)
+	}
+	return &Result{Text: text, VoiceID: language.Voice}, nil
+}
+
+// stop leaves tutor mode with a short recap
+func (t *TutorSkill) stop() (*Result, error) {
+	t.mu.Lock()
+	language := t.language
+	practiced := len(t.session)
+	t.language = nil
+	t.turns = nil
+	t.mu.Unlock()
+
+	if language == nil {
+		return &Result{Text: "No estábamos practicando ningún idioma."}, nil
+	}
+
+	vocab, err := t.loadVocabulary()
+	if err != nil {
+		return nil, err
+	}
+	total := len(vocab.progress(language.Name).Words)
+
+	return &Result{
+		Text: fmt.Sprintf("¡Buen trabajo! Hoy has practicado %d palabras de %s, y llevas %d en total.", practiced, language.Name, total),
+	}, nil
+}
+
+// converse corrects the user's sentence and keeps the conversation going
+func (t *TutorSkill) converse(ctx context.Context, utterance string) (*Result, error) {
+	t.mu.Lock()
+	language := t.language
+	transcript := strings.Join(t.turns, "\n")
+	t.mu.Unlock()
+
+	if language == nil {
+		return nil, fmt.Errorf("tutor mode is not active")
+	}
+
+	system := fmt.Sprintf(`You are a friendly %[1]s tutor having a spoken conversation with a learner.
+Reply ONLY in %[1]s, using simple words. If the learner's last sentence has mistakes, start with
+"Correction: <corrected sentence>" and a very short explanation; otherwise praise it briefly.
+Then continue the conversation with ONE short question. Plain text, no markdown.
+Finish with a separate last line "VOCAB: word1, word2, ..." listing the key %[1]s words
+(base form, lowercase) the learner used or that you introduced.`, language.Name)
+
+	prompt := fmt.Sprintf("Conversation so far:\n%s\n\nLearner: %s", transcript, utterance)
+	reply, err := t.completer.Prompt(ctx, system, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("tutor request failed: %w", err)
+	}
+
+	words := parseVocabulary(reply)
+	reply = strings.TrimSpace(vocabLinePattern.ReplaceAllString(reply, ""))
+
+	t.mu.Lock()
+	t.turns = append(t.turns, "Learner: "+utterance, "Tutor: "+reply)
+	if len(t.turns) > 12 {
+		t.turns = t.turns[len(t.turns)-12:]
+	}
+	for _, word := range words {
+		t.session[word] = true
+	}
+	t.mu.Unlock()
+
+	if err := t.recordVocabulary(language.Name, words); err != nil {
+		return nil, err
+	}
+
+	return &Result{Text: reply, VoiceID: language.Voice}, nil
+}
+
+// loadVocabulary reads the persisted practice record
+func (t *TutorSkill) loadVocabulary() (*tutorVocabulary, error) {
+	vocab := &tutorVocabulary{}
+	if err := t.store.Load(tutorNamespace, vocab); err != nil {
+		return nil, err
+	}
+	if vocab.Languages == nil {
+		vocab.Languages = make(map[string]*languageProgress)
+	}
+	return vocab, nil
+}
+
+// recordVocabulary increments practice counts for the given words
+func (t *TutorSkill) recordVocabulary(language string, words []string) error {
+	if len(words) == 0 {
+		return nil
+	}
+
+	vocab, err := t.loadVocabulary()
+	if err != nil {
+		return err
+	}
+
+	progress := vocab.progress(language)
+	for _, word := range words {
+		progress.Words[word]++
+	}
+	progress.LastPracticed = time.Now()
+
+	return t.store.Save(tutorNamespace, vocab)
+}
+
+// progress returns (creating if needed) the record for a language
+func (v *tutorVocabulary) progress(language string) *languageProgress {
+	progress, ok := v.Languages[language]
+	if !ok {
+		progress = &languageProgress{}
+		v.Languages[language] = progress
+	}
+	if progress.Words == nil {
+		progress.Words = make(map[string]int)
+	}
+	return progress
+}
+
+// parseVocabulary extracts the words listed on the reply's VOCAB line
+func parseVocabulary(reply string) []string {
+	matches := vocabLinePattern.FindStringSubmatch(reply)
+	if matches == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var words []string
+	for _, word := range strings.Split(matches[1], ",") {
+		word = strings.ToLower(strings.Trim(strings.TrimSpace(word), ".!?\"'"))
+		if word != "" && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// findTutorLanguage resolves a language name in English or Spanish
+func findTutorLanguage(name string) *tutorLanguage {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i := range tutorLanguages {
+		for _, candidate := range tutorLanguages[i].Names {
+			if candidate == name {
+				return &tutorLanguages[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	// wasmHostModule is the import module of the host functions
+	wasmHostModule = "bobo"
+	// wasmMemoryPages caps the memory of a WASM skill (64 KiB pages, 64 MiB)
+	wasmMemoryPages = 1024
+	// maxWasmResponse caps the body http_get hands to a WASM skill
+	maxWasmResponse = 1 << 20
+)
+
+// wasmModule is the compiled module of a WASM skill, with the runtime and
+// host functions its permissions grant
+type wasmModule struct {
+	once     sync.Once
+	err      error
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+// wasm compiles the skill's module on first use. The host functions are
+// linked by permission, so a module that imports one it wasn't approved
+// for fails to start instead of being denied at run time:
+//
+//	log(ptr, len)                                 always
+//	http_get(url_ptr, url_len, buf_ptr, buf_cap)  network; returns the body
+//	                                              length (copied up to
+//	                                              buf_cap) or -1 on error
+func (s *ExternalSkill) wasm() (*wasmModule, error) {
+	s.module.once.Do(func() {
+		ctx := context.Background()
+		code, err := os.ReadFile(filepath.Join(s.dir, s.manifest.Wasm))
+		if err != nil {
+			s.module.err = fmt.Errorf("failed to read module: %w", err)
+			return
+		}
+
+		runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+			WithCloseOnContextDone(true).
+			WithMemoryLimitPages(wasmMemoryPages))
+		wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+
+		host := runtime.NewHostModuleBuilder(wasmHostModule)
+		host.NewFunctionBuilder().WithFunc(s.wasmLog).Export("log")
+		if s.policy.Allows(PermissionNetwork) {
+			host.NewFunctionBuilder().WithFunc(s.wasmHTTPGet).Export("http_get")
+		}
+		if _, err := host.Instantiate(ctx); err != nil {
+			runtime.Close(ctx)
+			s.module.err = fmt.Errorf("failed to link host functions: %w", err)
+			return
+		}
+
+		compiled, err := runtime.CompileModule(ctx, code)
+		if err != nil {
+			runtime.Close(ctx)
+			s.module.err = fmt.Errorf("invalid module: %w", err)
+			return
+		}
+		s.module.runtime, s.module.compiled = runtime, compiled
+	})
+	return &s.module, s.module.err
+}
+
+// runWasm runs the skill's module as a WASI command, with the request on
+// stdin and the result read from stdout like any other installed skill; it
+// sees no files but its data directory (or the home directory with the
+// filesystem permission) and no environment
+func (s *ExternalSkill) runWasm(ctx context.Context, input []byte, stdout, stderr io.Writer) error {
+	module, err := s.wasm()
+	if err != nil {
+		return err
+	}
+
+	root := filepath.Join(s.dir, dataDir)
+	if s.policy.Allows(PermissionFilesystem) {
+		if root, err = os.UserHomeDir(); err != nil {
+			return fmt.Errorf("failed to find the home directory: %w", err)
+		}
+	} else if err := os.MkdirAll(root, 0755); err != nil {
+		return fmt.Errorf("failed to create skill data directory: %w", err)
+	}
+
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(s.manifest.Name).
+		WithEnv("HOME", "/").
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithFSConfig(wazero.NewFSConfig().WithDirMount(root, "/"))
+	instance, err := module.runtime.InstantiateModule(ctx, module.compiled, config)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return instance.Close(ctx)
+}
+
+// wasmLog implements log(ptr, len), written to Bobo's log
+func (s *ExternalSkill) wasmLog(_ context.Context, m api.Module, ptr, length uint32) {
+	if message, ok := m.Memory().Read(ptr, length); ok {
+		slog.Info("🧩 "+string(message), "skill", s.manifest.Name)
+	}
+}
+
+// wasmHTTPGet implements http_get(url_ptr, url_len, buf_ptr, buf_cap)
+func (s *ExternalSkill) wasmHTTPGet(ctx context.Context, m api.Module, urlPtr, urlLen, bufPtr, bufCap uint32) int32 {
+	raw, ok := m.Memory().Read(urlPtr, urlLen)
+	if !ok {
+		return -1
+	}
+	body, err := wasmFetch(ctx, string(raw))
+	if err != nil {
+		slog.Warn("WASM skill request failed", "skill", s.manifest.Name, "error", err)
+		return -1
+	}
+	if !m.Memory().Write(bufPtr, body[:min(len(body), int(bufCap))]) {
+		return -1
+	}
+	return int32(len(body))
+}
+
+// wasmFetch gets the body of a successful http(s) response
+func wasmFetch(ctx context.Context, rawURL string) ([]byte, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("%q is not an http(s) URL", rawURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := skillClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s returned status %d", parsed.Host, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWasmResponse+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxWasmResponse {
+		return nil, fmt.Errorf("response larger than %d bytes", maxWasmResponse)
+	}
+	return body, nil
+}
@@ -0,0 +1,255 @@
+// Package telemetry counts which features are used and how long Bobo takes
+// to answer, on this machine only, and sends an anonymous summary of those
+// counts now and then to users who opted in. No text, names, paths or
+// identifiers are ever collected: only feature names and latency buckets
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// checkInterval is how often Run checks whether a report is due
+const checkInterval = time.Hour
+
+// latencyBuckets are the upper bounds of the latency histogram buckets
+var latencyBuckets = []time.Duration{
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Report is the summary sent, exactly as "bobo telemetry show" prints it
+type Report struct {
+	Version  string                    `json:"version"`
+	OS       string                    `json:"os"`
+	Arch     string                    `json:"arch"`
+	Since    string                    `json:"since"` // day the counts started
+	Features map[string]int            `json:"features"`
+	Latency  map[string]map[string]int `json:"latency"`
+}
+
+// usage is the local aggregate, saved to the telemetry file
+type usage struct {
+	Since    time.Time                 `json:"since"`
+	LastSent time.Time                 `json:"last_sent,omitempty"`
+	Features map[string]int            `json:"features"`
+	Latency  map[string]map[string]int `json:"latency"`
+}
+
+// Collector aggregates usage locally and sends it when a report is due
+type Collector struct {
+	config  *config.TelemetryConfig
+	version string
+	client  *http.Client
+	logger  *slog.Logger
+
+	mu    sync.Mutex
+	usage usage
+}
+
+// NewCollector loads the counts gathered so far from the telemetry file
+func NewCollector(cfg *config.TelemetryConfig, version string) (*Collector, error) {
+	c := &Collector{
+		config:  cfg,
+		version: version,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		logger:  slog.Default(),
+	}
+	c.reset(time.Now())
+
+	data, err := os.ReadFile(cfg.File)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", cfg.File, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &c.usage); err != nil {
+			return nil, fmt.Errorf("invalid telemetry file %s: %w", cfg.File, err)
+		}
+	}
+	return c, nil
+}
+
+// Count records one use of a feature, such as a skill or the wake word; it
+// does nothing on a nil collector
+func (c *Collector) Count(feature string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.usage.Features == nil {
+		c.usage.Features = make(map[string]int)
+	}
+	c.usage.Features[feature]++
+	if err := c.saveLocked(); err != nil {
+		c.logger.Debug("Failed to save usage counts", "error", err)
+	}
+}
+
+// Observe records how long a stage, such as "transcription", took
+func (c *Collector) Observe(stage string, latency time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.usage.Latency == nil {
+		c.usage.Latency = make(map[string]map[string]int)
+	}
+	if c.usage.Latency[stage] == nil {
+		c.usage.Latency[stage] = make(map[string]int)
+	}
+	c.usage.Latency[stage][bucket(latency)]++
+	if err := c.saveLocked(); err != nil {
+		c.logger.Debug("Failed to save usage counts", "error", err)
+	}
+}
+
+// Report returns the summary the next report would send
+func (c *Collector) Report() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := Report{
+		Version:  c.version,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Since:    c.usage.Since.Format("2006-01-02"),
+		Features: make(map[string]int),
+		Latency:  make(map[string]map[string]int),
+	}
+	for feature, count := range c.usage.Features {
+		report.Features[feature] = count
+	}
+	for stage, buckets := range c.usage.Latency {
+		report.Latency[stage] = make(map[string]int)
+		for label, count := range buckets {
+			report.Latency[stage][label] = count
+		}
+	}
+	return report
+}
+
+// Send posts the report to TELEMETRY_URL and starts counting afresh
+func (c *Collector) Send(ctx context.Context) error {
+	if c.config.URL == "" {
+		return fmt.Errorf("TELEMETRY_URL is not set")
+	}
+
+	body, err := json.Marshal(c.Report())
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send usage report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("usage report rejected with status %d", resp.StatusCode)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.reset(now)
+	c.usage.LastSent = now
+	return c.saveLocked()
+}
+
+// Reset drops the counts gathered so far
+func (c *Collector) Reset() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lastSent := c.usage.LastSent
+	c.reset(time.Now())
+	c.usage.LastSent = lastSent
+	return c.saveLocked()
+}
+
+// Run sends a report every TELEMETRY_INTERVAL_HOURS until ctx is cancelled;
+// it does nothing on a nil collector
+func (c *Collector) Run(ctx context.Context) {
+	if c == nil || c.config.URL == "" {
+		return
+	}
+	interval := time.Duration(c.config.IntervalHours) * time.Hour
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		c.mu.Lock()
+		due := time.Since(c.usage.LastSent) >= interval && time.Since(c.usage.Since) >= interval
+		c.mu.Unlock()
+		if due {
+			if err := c.Send(ctx); err != nil {
+				c.logger.Debug("Usage report failed", "error", err)
+			} else {
+				c.logger.Debug("📊 Usage report sent")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reset starts an empty aggregate
+func (c *Collector) reset(now time.Time) {
+	c.usage = usage{
+		Since:    now,
+		Features: make(map[string]int),
+		Latency:  make(map[string]map[string]int),
+	}
+}
+
+// saveLocked writes the aggregate to the telemetry file
+func (c *Collector) saveLocked() error {
+	data, err := json.MarshalIndent(c.usage, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.config.File), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(c.config.File), err)
+	}
+	if err := os.WriteFile(c.config.File, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", c.config.File, err)
+	}
+	return nil
+}
+
+// bucket labels the histogram bucket of a latency, such as "le_500ms"
+func bucket(latency time.Duration) string {
+	for _, bound := range latencyBuckets {
+		if latency <= bound {
+			return "le_" + bound.String()
+		}
+	}
+	return "gt_" + latencyBuckets[len(latencyBuckets)-1].String()
+}
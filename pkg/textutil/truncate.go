@@ -0,0 +1,46 @@
+// Package textutil provides small text helpers shared across packages
+package textutil
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// DefaultPreviewLength is the number of characters Preview keeps unless configured
+const DefaultPreviewLength = 100
+
+// previewLength is the configured length used by Preview
+var previewLength atomic.Int64
+
+func init() {
+	previewLength.Store(DefaultPreviewLength)
+}
+
+// SetPreviewLength sets how many characters Preview keeps; 0 or less keeps everything
+func SetPreviewLength(length int) {
+	previewLength.Store(int64(length))
+}
+
+// Truncate shortens text to at most max characters, ending with "…" when it
+// was cut; it never splits a multi-byte character and max <= 0 keeps everything
+func Truncate(text string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(text) <= max {
+		return text
+	}
+
+	cut, count := 0, 0
+	for i := range text {
+		if count == max-1 {
+			cut = i
+			break
+		}
+		count++
+	}
+	return strings.TrimRight(text[:cut], " \t\n") + "…"
+}
+
+// Preview shortens text for log lines to the configured preview length
+func Preview(text string) string {
+	return Truncate(text, int(previewLength.Load()))
+}
@@ -0,0 +1,176 @@
+package voice
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// alarmLevels is how many rings it takes to reach full volume and length
+const alarmLevels = 4
+
+// Alarm beep: short high-pitched bursts, easy to hear over a room
+const (
+	beepSampleRate = 16000
+	beepFrequency  = 2000
+	beepLength     = 150 * time.Millisecond
+	beepGap        = 100 * time.Millisecond
+)
+
+// Alarm rings due reminders on a GPIO buzzer and/or a dedicated speaker,
+// independently of the TTS engine, escalating until acknowledged
+type Alarm struct {
+	config *config.AlarmConfig
+	player *Player
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	stop context.CancelFunc
+}
+
+// NewAlarm prepares the configured buzzer pin and speaker
+func NewAlarm(cfg *config.AlarmConfig) (*Alarm, error) {
+	alarm := &Alarm{config: cfg, logger: slog.Default()}
+
+	if cfg.GPIOPin >= 0 {
+		if err := exportGPIO(cfg.GPIOPin, "out"); err != nil {
+			return nil, fmt.Errorf("failed to set up the buzzer on GPIO %d: %w", cfg.GPIOPin, err)
+		}
+	}
+	if cfg.AudioDevice != "" {
+		player, err := NewPlayer(cfg.AudioDevice)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up the alarm speaker: %w", err)
+		}
+		alarm.player = player
+	}
+	return alarm, nil
+}
+
+// Ring starts ringing in the background, unless the alarm is already ringing
+func (a *Alarm) Ring(ctx context.Context) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stop != nil {
+		return
+	}
+
+	ctx, stop := context.WithTimeout(ctx, time.Duration(a.config.MaxMinutes)*time.Minute)
+	a.stop = stop
+	go a.ring(ctx)
+}
+
+// Acknowledge stops the alarm, reporting whether it was ringing
+func (a *Alarm) Acknowledge() bool {
+	if a == nil {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stop == nil {
+		return false
+	}
+	a.stop()
+	a.stop = nil
+	return true
+}
+
+// ring repeats longer and louder rings until ctx ends
+func (a *Alarm) ring(ctx context.Context) {
+	defer func() {
+		if a.config.GPIOPin >= 0 {
+			setGPIO(a.config.GPIOPin, false)
+		}
+		a.mu.Lock()
+		if a.stop != nil && ctx.Err() == context.DeadlineExceeded {
+			a.logger.Warn("⏰ Alarm not acknowledged, giving up", "minutes", a.config.MaxMinutes)
+			a.stop = nil
+		}
+		a.mu.Unlock()
+	}()
+
+	for level := 1; ; level = min(level+1, alarmLevels) {
+		a.logger.Info("⏰ Alarm ringing", "level", level)
+		var wg sync.WaitGroup
+		if a.config.GPIOPin >= 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				a.buzz(ctx, level)
+			}()
+		}
+		if a.player != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := a.beep(ctx, level); err != nil && ctx.Err() == nil {
+					a.logger.Warn("Failed to play the alarm", "error", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(a.config.RepeatSeconds) * time.Second):
+		}
+	}
+}
+
+// beeps returns how many beeps a ring has at an escalation level
+func beeps(level int) int {
+	return 4 * level
+}
+
+// buzz pulses the buzzer, more times at higher levels (a buzzer has a single volume)
+func (a *Alarm) buzz(ctx context.Context, level int) {
+	for i := 0; i < beeps(level); i++ {
+		if err := setGPIO(a.config.GPIOPin, true); err != nil {
+			a.logger.Warn("Failed to drive the buzzer", "error", err)
+			return
+		}
+		time.Sleep(beepLength)
+		setGPIO(a.config.GPIOPin, false)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(beepGap):
+		}
+	}
+}
+
+// beep plays a ring on the alarm speaker, louder and longer at higher levels
+func (a *Alarm) beep(ctx context.Context, level int) error {
+	pcm := beepPCM(beeps(level), float64(level)/alarmLevels)
+	return a.player.PlayPCM(ctx, pcm, wavFormat{SampleRate: beepSampleRate, Channels: 1, BitsPerSample: 16})
+}
+
+// beepPCM synthesizes count beeps at volume (0-1) as 16-bit mono PCM
+func beepPCM(count int, volume float64) []byte {
+	tone := int(beepLength.Seconds() * beepSampleRate)
+	gap := int(beepGap.Seconds() * beepSampleRate)
+	pcm := make([]byte, 0, count*(tone+gap)*2)
+	for b := 0; b < count; b++ {
+		for i := 0; i < tone+gap; i++ {
+			sample := 0.0
+			if i < tone {
+				sample = volume * math.MaxInt16 * math.Sin(2*math.Pi*beepFrequency*float64(i)/beepSampleRate)
+			}
+			pcm = binary.LittleEndian.AppendUint16(pcm, uint16(int16(sample)))
+		}
+	}
+	return pcm
+}
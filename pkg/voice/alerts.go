@@ -0,0 +1,125 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/notify"
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
+)
+
+// Alert channels, chosen for each alert type with ALERTS_<TYPE>
+const (
+	alertSpeech  = "speech"  // said at the next pause
+	alertFlash   = "flash"   // the terminal flashes
+	alertDesktop = "desktop" // a desktop notification
+	alertLight   = "light"   // a smart bulb blinks through Home Assistant
+)
+
+// desktopTitleChars keeps the question in a notification title to one line
+const desktopTitleChars = 60
+
+// Screen flash: the terminal switches to reverse video and back
+const (
+	screenFlashes     = 3
+	screenFlashLength = 150 * time.Millisecond
+)
+
+// setupAlerts prepares the desktop notifications and the bulb when an alert
+// type uses them
+func (v *Interface) setupAlerts() {
+	uses := func(channel string) bool {
+		for _, channels := range v.config.Alerts.Channels {
+			if slices.Contains(channels, channel) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var err error
+	if uses(alertDesktop) || v.config.Push.Desktop {
+		if v.desktop, err = notify.NewDesktop(); err != nil {
+			v.logger.Warn("Desktop alerts disabled", "error", err)
+		}
+	}
+	if uses(alertLight) {
+		if v.config.Alerts.Light == "" {
+			v.logger.Warn("Light alerts disabled", "error", "ALERT_LIGHT is not set")
+		} else if v.light, err = notify.NewLight(v.config.Alerts); err != nil {
+			v.logger.Warn("Light alerts disabled", "error", err)
+		} else {
+			v.logger.Info("💡 Light alerts enabled", "light", v.config.Alerts.Light)
+		}
+	}
+}
+
+// alert tells the user about something through the channels chosen for its
+// type (reminder, doorbell, alarm, loud_noise); key deduplicates the spoken
+// announcement
+func (v *Interface) alert(alertType, key, title, message string) {
+	for _, channel := range v.config.Alerts.Channels[alertType] {
+		switch channel {
+		case alertSpeech:
+			v.announce(key, message)
+		case alertFlash:
+			go flashScreen(v.rl.Stdout())
+		case alertDesktop:
+			v.notifyDesktop(title, message)
+		case alertLight:
+			v.flashLight()
+		default:
+			v.logger.Warn("Unknown alert channel", "type", alertType, "channel", channel)
+		}
+	}
+}
+
+// flashScreen makes the terminal flash, for alerts that must be seen
+func flashScreen(out io.Writer) {
+	for range screenFlashes {
+		fmt.Fprint(out, "\x1b[?5h")
+		time.Sleep(screenFlashLength)
+		fmt.Fprint(out, "\x1b[?5l")
+		time.Sleep(screenFlashLength)
+	}
+}
+
+// notifyDesktop shows a desktop notification in the background
+func (v *Interface) notifyDesktop(title, message string) {
+	if v.desktop == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := v.desktop.Send(ctx, title, message); err != nil {
+			v.logger.Warn("Desktop notification failed", "error", err)
+		}
+	}()
+}
+
+// notifyInteraction shows what the user asked and what Bobo answered as a
+// desktop notification, for when they're looking at another window
+func (v *Interface) notifyInteraction(transcription, response string) {
+	if !v.config.Push.Desktop {
+		return
+	}
+	v.notifyDesktop("🗣️ "+textutil.Truncate(transcription, desktopTitleChars), response)
+}
+
+// flashLight blinks the alert bulb in the background
+func (v *Interface) flashLight() {
+	if v.light == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := v.light.Flash(ctx); err != nil {
+			v.logger.Warn("Failed to blink the alert light", "error", err)
+		}
+	}()
+}
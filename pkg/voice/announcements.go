@@ -0,0 +1,122 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// announcementPoll is how often queued announcements look for a pause
+const announcementPoll = 500 * time.Millisecond
+
+// announcement is something Bobo has to say on its own; repeats of the same
+// event are coalesced into one
+type announcement struct {
+	key   string
+	text  string
+	count int
+}
+
+// announcementQueue holds reminders, sound alerts and idle chatter until the
+// user stops talking to Bobo, so that they never talk over each other
+type announcementQueue struct {
+	mu    sync.Mutex
+	items []*announcement
+}
+
+// add queues text, merging it with a queued announcement with the same key
+// (the latest text wins)
+func (q *announcementQueue) add(key, text string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, item := range q.items {
+		if item.key == key {
+			item.text = text
+			item.count++
+			return
+		}
+	}
+	q.items = append(q.items, &announcement{key: key, text: text, count: 1})
+}
+
+// pending reports whether there is anything to announce
+func (q *announcementQueue) pending() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items) > 0
+}
+
+// take empties the queue, returning the announcements in arrival order
+func (q *announcementQueue) take() []*announcement {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := q.items
+	q.items = nil
+	return items
+}
+
+// announce says text at the next pause; events with the same key that fire
+// meanwhile are said only once
+func (v *Interface) announce(key, text string) {
+	if !v.config.TTS.Enabled {
+		return
+	}
+	v.announced.add(key, text)
+}
+
+// runAnnouncements speaks the queued announcements once Bobo has been free
+// and the user quiet for ANNOUNCE_PAUSE_SECONDS, until ctx is cancelled
+func (v *Interface) runAnnouncements(ctx context.Context) {
+	pause := time.Duration(v.config.TTS.AnnouncePauseSeconds) * time.Second
+	ticker := time.NewTicker(announcementPoll)
+	defer ticker.Stop()
+
+	var freeSince time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !v.busy.TryLock() {
+			freeSince = time.Time{}
+			continue
+		}
+		if freeSince.IsZero() {
+			freeSince = time.Now()
+		}
+		idle := time.Since(time.Unix(0, v.lastActivity.Load()))
+		if !v.announced.pending() || time.Since(freeSince) < pause || idle < pause {
+			v.busy.Unlock()
+			continue
+		}
+
+		v.deliverAnnouncements(ctx)
+		v.busy.Unlock()
+	}
+}
+
+// deliverAnnouncements speaks everything queued, in order; callers hold v.busy
+func (v *Interface) deliverAnnouncements(ctx context.Context) {
+	// Don't take Bobo's own voice for the wake word or a loud noise
+	if v.wake != nil {
+		v.wake.SetPaused(true)
+		defer func() { v.wake.SetPaused(v.listeningPaused()) }()
+	}
+	if v.sounds != nil {
+		v.sounds.SetPaused(true)
+		defer func() { v.sounds.SetPaused(v.listeningPaused()) }()
+	}
+
+	for _, item := range v.announced.take() {
+		text := item.text
+		if item.count > 1 {
+			text += fmt.Sprintf(" (%d veces)", item.count)
+		}
+		v.logger.Info("📢 Announcing", "text", text)
+		v.speak(ctx, text)
+	}
+}
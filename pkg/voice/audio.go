@@ -7,68 +7,128 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/recordings"
 )
 
 // AudioRecorder interface for audio recording
 type AudioRecorder struct {
 	config        *config.VoiceConfig
 	AudioFilePath string
+	inputDevice   string
+	onLevel       func(InputLevel)
+	follow        func(ctx context.Context, path string)
+	stream        *StreamRecorder
+	storage       *recordings.Store
+	mu            sync.RWMutex
 	logger        *slog.Logger
 }
 
 // NewAudioRecorder creates a new audio recorder
 func NewAudioRecorder(cfg *config.VoiceConfig) (*AudioRecorder, error) {
+	if err := checkRecordingFormat(cfg.RecordingFormat); err != nil {
+		return nil, err
+	}
+	if err := checkInputChannel(cfg.InputChannel, cfg.Channels); err != nil {
+		return nil, err
+	}
 	return &AudioRecorder{
 		config: cfg,
 		logger: slog.Default(),
 	}, nil
 }
 
-// RecordAudio records audio for the specified duration using ffmpeg
+// SetLevelMeter sets a callback that gets the microphone level about ten
+// times per second while recording, replacing the progress log
+func (a *AudioRecorder) SetLevelMeter(onLevel func(InputLevel)) {
+	a.onLevel = onLevel
+}
+
+// SetFollower sets a callback that gets each recording's path as it starts,
+// to follow the file while it grows; its context ends with the recording
+func (a *AudioRecorder) SetFollower(follow func(ctx context.Context, path string)) {
+	a.follow = follow
+}
+
+// SetStream makes recordings come from the continuous capture while it is live
+func (a *AudioRecorder) SetStream(stream *StreamRecorder) {
+	a.stream = stream
+}
+
+// SetRecordings makes recordings go to the session directory of store
+func (a *AudioRecorder) SetRecordings(store *recordings.Store) {
+	a.storage = store
+}
+
+// RecordAudio records audio for the specified duration
 func (a *AudioRecorder) RecordAudio(ctx context.Context, durationSeconds int) (bool, error) {
-	a.logger.Info("🎤 Recording audio with ffmpeg",
+	return a.recordAudio(ctx, durationSeconds, nil, nil)
+}
+
+// recordAudio records for durationSeconds, or until release is closed
+// (push-to-talk); watch, when set, gets the input level as it goes, like the
+// level meter
+func (a *AudioRecorder) recordAudio(ctx context.Context, durationSeconds int, release <-chan struct{}, watch func(InputLevel)) (bool, error) {
+	a.logger.Info("🎤 Recording audio",
 		"duration", durationSeconds,
 		"sample_rate", a.config.SampleRate,
 		"channels", a.config.Channels,
 	)
 
-	// Create audio file in work/temp directory with ABSOLUTE path
-	workTempDir := "work/temp"
-	if err := os.MkdirAll(workTempDir, 0755); err != nil {
-		// Fallback to system temp if work dir fails
-		workTempDir = os.TempDir()
-	}
-
-	// Make path absolute
-	absWorkDir, err := filepath.Abs(workTempDir)
-	if err != nil {
-		a.logger.Warn("Failed to get absolute path, using relative", "error", err)
-		absWorkDir = workTempDir
+	// Create the audio file in this session's recordings directory
+	a.AudioFilePath = a.storage.NewPath("desk_pet_recording")
+
+	// Follow the recording as it grows, until it is complete
+	if a.follow != nil {
+		followCtx, stopFollowing := context.WithCancel(ctx)
+		followed := make(chan struct{})
+		go func() {
+			defer close(followed)
+			a.follow(followCtx, a.AudioFilePath)
+		}()
+		defer func() {
+			stopFollowing()
+			<-followed
+		}()
 	}
 
-	timestamp := time.Now().Format("20060102_150405")
-	a.AudioFilePath = filepath.Join(absWorkDir, fmt.Sprintf("desk_pet_recording_%s.wav", timestamp))
-
 	// Start recording in background
 	recordingDone := make(chan error, 1)
 	go func() {
-		recordingDone <- a.recordWithFFmpeg(ctx, durationSeconds)
+		if a.stream.Live() {
+			recordingDone <- a.stream.record(ctx, a.AudioFilePath, durationSeconds, release)
+			return
+		}
+		recordingDone <- a.record(ctx, durationSeconds, release)
 	}()
 
-	// Show progress while recording
-	progressTicker := time.NewTicker(1 * time.Second)
+	// Show progress, or the live input level, while recording
+	interval := 1 * time.Second
+	if a.onLevel != nil || watch != nil {
+		interval = 100 * time.Millisecond
+	}
+	progressTicker := time.NewTicker(interval)
 	defer progressTicker.Stop()
 
+	meter := &levelReader{path: a.AudioFilePath}
+	defer meter.close()
+
 	startTime := time.Now()
+	lastLog := 0.0
+	fraction := func() float64 {
+		return min(time.Since(startTime).Seconds()/float64(durationSeconds), 1)
+	}
 	for {
 		select {
 		case err := <-recordingDone:
+			if a.onLevel != nil {
+				a.onLevel(InputLevel{DB: meter.read(), Progress: 1, Done: true})
+			}
 			a.logger.Info("⏹️ Recording complete", "file", a.AudioFilePath)
 			if err != nil {
 				return false, fmt.Errorf("recording failed: %w", err)
@@ -77,45 +137,97 @@ func (a *AudioRecorder) RecordAudio(ctx context.Context, durationSeconds int) (b
 			return true, nil
 
 		case <-progressTicker.C:
+			level := InputLevel{DB: meter.read(), Progress: fraction()}
+			if watch != nil {
+				watch(level)
+			}
+			if a.onLevel != nil {
+				a.onLevel(level)
+				continue
+			}
 			elapsed := time.Since(startTime).Seconds()
+			// The watcher ticks faster than the log
+			if interval < time.Second && elapsed-lastLog < 1 {
+				continue
+			}
+			lastLog = elapsed
 			progress := (elapsed / float64(durationSeconds)) * 100
 			if progress <= 100 {
 				a.logger.Info("🔴 Recording progress", "progress", fmt.Sprintf("%.0f%%", progress))
 			}
 
 		case <-ctx.Done():
+			if a.onLevel != nil {
+				a.onLevel(InputLevel{DB: meter.read(), Progress: fraction(), Done: true})
+			}
 			return false, ctx.Err()
 		}
 	}
 }
 
-// recordWithFFmpeg performs actual audio recording using ffmpeg
-func (a *AudioRecorder) recordWithFFmpeg(ctx context.Context, durationSeconds int) error {
+// record performs actual audio recording with the platform's capture backend,
+// stopping early when release is closed
+func (a *AudioRecorder) record(ctx context.Context, durationSeconds int, release <-chan struct{}) error {
+	backend := a.captureBackend()
+	if backend == "" {
+		return fmt.Errorf("unsupported platform for audio recording")
+	}
+
+	// pw-record and arecord hand over raw audio, written to WAV here
+	if nativeCapture(backend) {
+		return a.recordNative(ctx, backend, durationSeconds, release)
+	}
+
 	// Create context with timeout slightly longer than recording duration
 	recordCtx, cancel := context.WithTimeout(ctx, time.Duration(durationSeconds+2)*time.Second)
 	defer cancel()
 
-	// Build ffmpeg command based on platform
-	args := a.buildFFmpegArgs(durationSeconds)
-	if args == nil {
-		return fmt.Errorf("unsupported platform for audio recording")
+	// Stop recording when the push-to-talk key is released
+	if release != nil {
+		var stop context.CancelFunc
+		recordCtx, stop = context.WithCancel(recordCtx)
+		defer stop()
+		go func() {
+			select {
+			case <-release:
+				stop()
+			case <-recordCtx.Done():
+			}
+		}()
 	}
 
-	// Execute ffmpeg command
-	cmd := exec.CommandContext(recordCtx, "ffmpeg", args...)
+	// Build the capture command for the backend
+	cmd, err := a.recordCommand(recordCtx, backend, durationSeconds)
+	if err != nil {
+		return err
+	}
+	if release != nil {
+		// Interrupt rather than kill, so that the recorder finishes the WAV header
+		cmd.Cancel = func() error {
+			return cmd.Process.Signal(os.Interrupt)
+		}
+		cmd.WaitDelay = 2 * time.Second
+	}
 
 	// Capture stderr for debugging
 	var stderr strings.Builder
 	cmd.Stderr = &stderr
 
-	a.logger.Info("🎙️ Starting ffmpeg recording", "command", "ffmpeg "+strings.Join(args, " "))
+	a.logger.Info("🎙️ Starting recording", "backend", backend, "command", cmd.String())
 
-	if err := cmd.Run(); err != nil {
+	err = cmd.Run()
+	released := false
+	select {
+	case <-release:
+		released = true
+	default:
+	}
+	if err != nil && !released {
 		stderrOutput := stderr.String()
 		if stderrOutput != "" {
-			a.logger.Warn("ffmpeg stderr output", "output", stderrOutput)
+			a.logger.Warn("Recorder stderr output", "output", stderrOutput)
 		}
-		return fmt.Errorf("ffmpeg recording failed: %w", err)
+		return fmt.Errorf("%s recording failed: %w", backend, err)
 	}
 
 	// Verify file was created
@@ -127,9 +239,11 @@ func (a *AudioRecorder) recordWithFFmpeg(ctx context.Context, durationSeconds in
 }
 
 // buildFFmpegArgs builds platform-specific ffmpeg arguments for audio recording
-func (a *AudioRecorder) buildFFmpegArgs(durationSeconds int) []string {
-	platform := a.detectPlatform()
-	a.logger.Info("🔍 Detecting audio recording setup", "platform", platform)
+func (a *AudioRecorder) buildFFmpegArgs(backend string, durationSeconds int) []string {
+	input := a.inputArgs(backend)
+	if input == nil {
+		return nil
+	}
 
 	// Common arguments
 	args := []string{
@@ -140,39 +254,64 @@ func (a *AudioRecorder) buildFFmpegArgs(durationSeconds int) []string {
 	}
 
 	// Platform-specific input arguments
-	switch platform {
-	case "darwin": // macOS
+	args = append(args, input...)
+
+	// Output arguments: mono, flushing every packet so that the level meter
+	// follows the file
+	args = append(args, a.downmixArgs()...)
+	args = append(args, "-flush_packets", "1", a.AudioFilePath)
+
+	return args
+}
+
+// SetInputDevice overrides the capture device (PulseAudio source name or
+// avfoundation device); an empty name restores the system default
+func (a *AudioRecorder) SetInputDevice(device string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inputDevice = device
+}
+
+// device returns the capture device override or the configured device, or
+// fallback when none is set
+func (a *AudioRecorder) device(fallback string) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.inputDevice != "" {
+		return a.inputDevice
+	}
+	if a.config.InputDevice != "" {
+		return a.config.InputDevice
+	}
+	return fallback
+}
+
+// inputArgs returns the ffmpeg input arguments for a capture backend, or nil
+// if ffmpeg can't capture with it
+func (a *AudioRecorder) inputArgs(backend string) []string {
+	switch backend {
+	case backendAVFoundation: // macOS
 		a.logger.Info("🍎 Using macOS avfoundation audio input")
-		args = append(args,
+		return []string{
 			"-f", "avfoundation",
-			"-i", ":0", // Default audio input device
-		)
-	case "linux": // Linux
-		if a.isAudioSystemAvailable("pulse") {
-			a.logger.Info("🔊 Using PulseAudio input")
-			args = append(args,
-				"-f", "pulse",
-				"-i", "default", // Default PulseAudio source
-			)
-		} else if a.isAudioSystemAvailable("alsa") {
-			a.logger.Info("🔉 Using ALSA audio input")
-			args = append(args,
-				"-f", "alsa",
-				"-i", "hw:0", // Hardware device 0
-			)
-		} else {
-			a.logger.Warn("❌ No supported audio system found (pulse/alsa)")
-			return nil
+			"-i", ":" + a.device("0"), // Default audio input device
+		}
+	case backendPulse:
+		a.logger.Info("🔊 Using PulseAudio input")
+		return []string{
+			"-f", "pulse",
+			"-i", a.device("default"), // Default PulseAudio source
+		}
+	case backendALSA:
+		a.logger.Info("🔉 Using ALSA audio input")
+		return []string{
+			"-f", "alsa",
+			"-i", a.device("default"), // Default ALSA device
 		}
 	default:
-		a.logger.Warn("Unsupported platform for audio recording")
+		a.logger.Warn("ffmpeg can't capture audio with this backend", "backend", backend)
 		return nil
 	}
-
-	// Output arguments
-	args = append(args, a.AudioFilePath)
-
-	return args
 }
 
 // detectPlatform detects the current operating system
@@ -190,9 +329,16 @@ func (a *AudioRecorder) detectPlatform() string {
 func (a *AudioRecorder) isAudioSystemAvailable(system string) bool {
 	switch system {
 	case "pulse":
-		// Check if PulseAudio is running
-		cmd := exec.Command("pulseaudio", "--check")
-		return cmd.Run() == nil
+		// Check if PulseAudio (or PipeWire's pulse server) is running
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			return false
+		}
+		if exec.Command("pulseaudio", "--check").Run() == nil {
+			return true
+		}
+		return exec.Command("pactl", "info").Run() == nil
+	case "pipewire":
+		return pipeWireAvailable()
 	case "alsa":
 		// Check if ALSA devices exist
 		_, err := os.Stat("/proc/asound/devices")
@@ -247,17 +393,6 @@ func (a *AudioRecorder) createDummyAudioFile() error {
 	return nil
 }
 
-// Cleanup removes temporary audio files
-func (a *AudioRecorder) Cleanup() error {
-	if a.AudioFilePath != "" && strings.Contains(a.AudioFilePath, "desk_pet_recording_") {
-		if err := os.Remove(a.AudioFilePath); err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("failed to remove audio file: %w", err)
-		}
-		a.AudioFilePath = ""
-	}
-	return nil
-}
-
 // TODO: Implement real audio recording with:
 // 1. PortAudio Go bindings (https://github.com/gordonklaus/portaudio)
 // 2. Or system-specific APIs (ALSA on Linux, Core Audio on macOS)
@@ -0,0 +1,175 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// HeadsetWatcher polls the configured Bluetooth headset and re-routes capture
+// and playback to it while it is connected (PulseAudio/PipeWire on Linux)
+type HeadsetWatcher struct {
+	config      *config.BluetoothConfig
+	recorder    *AudioRecorder
+	mac         string
+	connected   bool
+	previousOut string
+	power       *PowerMonitor
+	logger      *slog.Logger
+}
+
+// NewHeadsetWatcher creates a watcher for the headset MAC address in cfg
+func NewHeadsetWatcher(cfg *config.BluetoothConfig, recorder *AudioRecorder) (*HeadsetWatcher, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("bluetooth headset switching is only supported on Linux")
+	}
+	for _, tool := range []string{"bluetoothctl", "pactl"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return nil, fmt.Errorf("%s not found: %w", tool, err)
+		}
+	}
+
+	return &HeadsetWatcher{
+		config:   cfg,
+		recorder: recorder,
+		mac:      strings.ToUpper(strings.TrimSpace(cfg.Headset)),
+		logger:   slog.Default(),
+	}, nil
+}
+
+// Run polls the headset state until ctx is cancelled, calling onChange after
+// audio has been routed to (connected=true) or away from the headset
+func (h *HeadsetWatcher) Run(ctx context.Context, onChange func(connected bool)) {
+	interval := time.Duration(h.config.PollSeconds) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	for {
+		connected := h.isConnected(ctx)
+		if connected != h.connected {
+			var err error
+			if connected {
+				err = h.routeToHeadset(ctx)
+			} else {
+				err = h.routeToDefault(ctx)
+			}
+
+			if err != nil {
+				h.logger.Warn("Failed to switch audio devices", "headset", h.mac, "error", err)
+			} else {
+				h.connected = connected
+				onChange(connected)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if h.connected {
+				// Leave the system as we found it
+				h.routeToDefault(context.Background())
+			}
+			return
+		case <-time.After(h.power.Interval(interval)):
+		}
+	}
+}
+
+// SetPower polls less often while the power monitor is saving power
+func (h *HeadsetWatcher) SetPower(power *PowerMonitor) {
+	h.power = power
+}
+
+// isConnected asks bluetoothctl whether the headset is currently connected
+func (h *HeadsetWatcher) isConnected(ctx context.Context) bool {
+	output, err := h.run(ctx, "bluetoothctl", "info", h.mac)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(output, "Connected: yes")
+}
+
+// routeToHeadset points the recorder at the headset microphone and makes its
+// speaker the default sink, which espeak plays through
+func (h *HeadsetWatcher) routeToHeadset(ctx context.Context) error {
+	source := h.findDevice(ctx, "sources")
+	if source == "" {
+		// A2DP profiles have no microphone, switch the card to headset mode
+		card := "bluez_card." + strings.ReplaceAll(h.mac, ":", "_")
+		if _, err := h.run(ctx, "pactl", "set-card-profile", card, "headset-head-unit"); err != nil {
+			h.logger.Debug("Could not enable headset profile", "card", card, "error", err)
+		}
+		source = h.findDevice(ctx, "sources")
+	}
+	if source == "" {
+		return fmt.Errorf("no microphone found for headset %s", h.mac)
+	}
+
+	if sink := h.findDevice(ctx, "sinks"); sink != "" {
+		if current, err := h.run(ctx, "pactl", "get-default-sink"); err == nil {
+			h.previousOut = strings.TrimSpace(current)
+		}
+		if _, err := h.run(ctx, "pactl", "set-default-sink", sink); err != nil {
+			return fmt.Errorf("failed to set default sink: %w", err)
+		}
+	}
+
+	h.recorder.SetInputDevice(source)
+	h.logger.Info("🎧 Bluetooth headset connected, audio routed to it", "source", source)
+	return nil
+}
+
+// routeToDefault restores the system input and the previous default sink
+func (h *HeadsetWatcher) routeToDefault(ctx context.Context) error {
+	h.recorder.SetInputDevice("")
+
+	if h.previousOut != "" {
+		if _, err := h.run(ctx, "pactl", "set-default-sink", h.previousOut); err != nil {
+			h.logger.Debug("Could not restore previous sink", "sink", h.previousOut, "error", err)
+		}
+		h.previousOut = ""
+	}
+
+	h.logger.Info("🔈 Bluetooth headset disconnected, back to default audio devices")
+	return nil
+}
+
+// findDevice returns the PulseAudio/PipeWire source or sink name belonging to
+// the headset, e.g. bluez_source.AA_BB_..., bluez_input.AA:BB:... or bluez_output.AA_BB_...
+func (h *HeadsetWatcher) findDevice(ctx context.Context, kind string) string {
+	output, err := h.run(ctx, "pactl", "list", "short", kind)
+	if err != nil {
+		return ""
+	}
+
+	underscored := strings.ReplaceAll(h.mac, ":", "_")
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name := fields[1]
+		if strings.HasSuffix(name, ".monitor") {
+			continue
+		}
+		upper := strings.ToUpper(name)
+		if strings.Contains(upper, underscored) || strings.Contains(upper, h.mac) {
+			return name
+		}
+	}
+	return ""
+}
+
+// run executes a short-lived helper command and returns its output
+func (h *HeadsetWatcher) run(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	return string(output), err
+}
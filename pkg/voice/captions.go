@@ -0,0 +1,164 @@
+package voice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// captionWriteTimeout drops caption clients that stop reading
+const captionWriteTimeout = 2 * time.Second
+
+// Caption speakers
+const (
+	captionUser = "user"
+	captionBobo = "bobo"
+)
+
+// caption is one line of the live captions
+type caption struct {
+	Time    time.Time `json:"time"`
+	Speaker string    `json:"speaker"`
+	Text    string    `json:"text"`
+	// Partial marks an interim transcription, replaced by the next caption
+	Partial bool `json:"partial,omitempty"`
+}
+
+// Captions writes what the user says and what Bobo answers, as it happens,
+// so that the conversation can be followed in text: finished lines are
+// appended to a file and every line, interim transcriptions included, is
+// sent as JSON to the clients connected to a TCP socket
+type Captions struct {
+	config  *config.CaptionsConfig
+	file    *os.File
+	mu      sync.Mutex
+	clients map[net.Conn]bool
+	logger  *slog.Logger
+}
+
+// NewCaptions creates the captions output, opening CAPTIONS_FILE for appending
+func NewCaptions(cfg *config.CaptionsConfig) (*Captions, error) {
+	c := &Captions{
+		config:  cfg,
+		clients: make(map[net.Conn]bool),
+		logger:  slog.Default(),
+	}
+	if cfg.File != "" {
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open captions file: %w", err)
+		}
+		c.file = file
+	}
+	return c, nil
+}
+
+// Run accepts caption clients on CAPTIONS_LISTEN until ctx is cancelled
+func (c *Captions) Run(ctx context.Context) {
+	if c == nil || c.config.Listen == "" {
+		return
+	}
+	listener, err := net.Listen("tcp", c.config.Listen)
+	if err != nil {
+		c.logger.Warn("Captions server failed to start", "error", err)
+		return
+	}
+	// Captions are everything said in the room, so they stay on this machine
+	// unless the network is trusted
+	if !c.config.AllowRemote && !loopbackAddr(listener.Addr()) {
+		listener.Close()
+		c.logger.Warn("Captions server disabled", "error", "CAPTIONS_LISTEN must be a loopback address such as localhost:10800 (or CAPTIONS_ALLOW_REMOTE=true)")
+		return
+	}
+	c.logger.Info("💬 Captions server listening", "addr", c.config.Listen)
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				c.logger.Warn("Captions server stopped", "error", err)
+			}
+			return
+		}
+		c.mu.Lock()
+		c.clients[conn] = true
+		c.mu.Unlock()
+	}
+}
+
+// User captions what the user said; partial marks an interim transcription
+func (c *Captions) User(text string, partial bool) {
+	c.write(caption{Speaker: captionUser, Text: text, Partial: partial})
+}
+
+// Bobo captions what Bobo says
+func (c *Captions) Bobo(text string) {
+	c.write(caption{Speaker: captionBobo, Text: text})
+}
+
+// write sends a caption to the file and the connected clients
+func (c *Captions) write(line caption) {
+	if c == nil || line.Text == "" {
+		return
+	}
+	line.Time = time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file != nil && !line.Partial {
+		speaker := "Tú"
+		if line.Speaker == captionBobo {
+			speaker = "Bobo"
+		}
+		if _, err := fmt.Fprintf(c.file, "%s %s: %s\n", line.Time.Format("15:04:05"), speaker, line.Text); err != nil {
+			c.logger.Warn("Failed to write captions", "error", err)
+		}
+	}
+
+	if len(c.clients) == 0 {
+		return
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	for conn := range c.clients {
+		conn.SetWriteDeadline(time.Now().Add(captionWriteTimeout))
+		if _, err := conn.Write(data); err != nil {
+			conn.Close()
+			delete(c.clients, conn)
+		}
+	}
+}
+
+// Close disconnects the clients and closes the captions file
+func (c *Captions) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for conn := range c.clients {
+		conn.Close()
+		delete(c.clients, conn)
+	}
+	if c.file != nil {
+		return c.file.Close()
+	}
+	return nil
+}
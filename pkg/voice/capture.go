@@ -0,0 +1,224 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Capture backends, selected with AUDIO_BACKEND or detected by captureBackend
+const (
+	backendAVFoundation = "avfoundation" // ffmpeg on macOS
+	backendPulse        = "pulse"        // ffmpeg on PulseAudio or PipeWire's pulse server
+	backendPipeWire     = "pipewire"     // pw-record
+	backendALSA         = "alsa"         // arecord, or ffmpeg when alsa-utils is missing
+)
+
+// captureBackend returns the configured backend, or the best one available
+// on this machine ("" when there is none)
+func (a *AudioRecorder) captureBackend() string {
+	if backend := a.config.AudioBackend; backend != "" && backend != "auto" {
+		return backend
+	}
+
+	switch platform := a.detectPlatform(); platform {
+	case "darwin":
+		return backendAVFoundation
+	case "linux":
+		switch {
+		case a.isAudioSystemAvailable("pulse"):
+			return backendPulse
+		case a.isAudioSystemAvailable("pipewire"):
+			return backendPipeWire
+		case a.isAudioSystemAvailable("alsa"):
+			return backendALSA
+		}
+		a.logger.Warn("❌ No supported audio system found (pulse/pipewire/alsa)")
+		return ""
+	default:
+		a.logger.Warn("Unsupported platform for audio recording", "platform", platform)
+		return ""
+	}
+}
+
+// recordCommand builds the ffmpeg command that records durationSeconds of
+// audio into a.AudioFilePath
+func (a *AudioRecorder) recordCommand(ctx context.Context, backend string, durationSeconds int) (*exec.Cmd, error) {
+	args := a.buildFFmpegArgs(backend, durationSeconds)
+	if args == nil {
+		return nil, fmt.Errorf("unsupported platform for audio recording")
+	}
+	return exec.CommandContext(ctx, "ffmpeg", args...), nil
+}
+
+// nativeCapture reports whether the backend captures raw PCM with its own
+// tool (pw-record, arecord), which Bobo then writes to WAV itself
+func nativeCapture(backend string) bool {
+	switch backend {
+	case backendPipeWire:
+		return true
+	case backendALSA:
+		_, err := exec.LookPath("arecord")
+		return err == nil
+	}
+	return false
+}
+
+// recordNative records durationSeconds of raw PCM, or until release is
+// closed, encoding it to a.AudioFilePath as it comes in
+func (a *AudioRecorder) recordNative(ctx context.Context, backend string, durationSeconds int, release <-chan struct{}) error {
+	captureCtx, cancel := context.WithTimeout(ctx, time.Duration(durationSeconds+2)*time.Second)
+	defer cancel()
+
+	cmd, err := a.streamCommand(captureCtx, backend)
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open %s output: %w", backend, err)
+	}
+
+	format := a.deviceFormat()
+	file, err := createWAV(a.AudioFilePath, a.captureFormat())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	a.logger.Info("🎙️ Starting recording", "backend", backend, "command", cmd.String())
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s recording: %w", backend, err)
+	}
+
+	// Stop capturing when the push-to-talk key is released
+	released := make(chan struct{})
+	go func() {
+		select {
+		case <-release:
+			close(released)
+			cancel()
+		case <-captureCtx.Done():
+		}
+	}()
+
+	// Whole frames only, about a tenth of a second at a time so that the
+	// level meter follows the file
+	frame := format.Channels * format.BitsPerSample / 8
+	chunk := make([]byte, max(format.bytesPerSecond()/10/frame, 1)*frame)
+	remaining := durationSeconds * format.bytesPerSecond()
+	var readErr error
+	for remaining > 0 {
+		n, err := io.ReadFull(stdout, chunk[:min(len(chunk), remaining)])
+		n -= n % frame
+		if _, err := file.Write(a.toMono(chunk[:n])); err != nil {
+			readErr = err
+			break
+		}
+		remaining -= n
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+	cancel()
+	cmd.Wait()
+
+	select {
+	case <-released:
+		readErr = nil
+	default:
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if readErr != nil {
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			a.logger.Warn("Recorder stderr output", "output", output)
+		}
+		return fmt.Errorf("%s recording failed: %w", backend, readErr)
+	}
+	return file.Close()
+}
+
+// deviceFormat is the PCM layout captured from the microphone: signed 16-bit
+// at the configured sample rate and channels
+func (a *AudioRecorder) deviceFormat() wavFormat {
+	return wavFormat{SampleRate: a.config.SampleRate, Channels: a.config.Channels, BitsPerSample: 16}
+}
+
+// captureFormat is the PCM layout Bobo records: the device's, downmixed to
+// mono (see INPUT_CHANNEL)
+func (a *AudioRecorder) captureFormat() wavFormat {
+	return wavFormat{SampleRate: a.config.SampleRate, Channels: 1, BitsPerSample: 16}
+}
+
+// toMono turns whole frames of device PCM into recorded PCM
+func (a *AudioRecorder) toMono(pcm []byte) []byte {
+	return downmix(pcm, a.config.Channels, a.config.InputChannel)
+}
+
+// downmixArgs are the ffmpeg output options that turn the device channels
+// into the recorded mono
+func (a *AudioRecorder) downmixArgs() []string {
+	if a.config.InputChannel > 0 {
+		return []string{"-af", fmt.Sprintf("pan=mono|c0=c%d", a.config.InputChannel-1)}
+	}
+	return []string{"-ac", "1"}
+}
+
+// streamCommand builds the command that captures audio continuously as raw
+// signed 16-bit PCM on stdout with the capture backend
+func (a *AudioRecorder) streamCommand(ctx context.Context, backend string) (*exec.Cmd, error) {
+	rate := strconv.Itoa(a.config.SampleRate)
+	channels := strconv.Itoa(a.config.Channels)
+
+	switch backend {
+	case backendPipeWire:
+		args := []string{"--rate", rate, "--channels", channels, "--format", "s16"}
+		if device := a.device(""); device != "" {
+			args = append(args, "--target", device)
+		}
+		return exec.CommandContext(ctx, "pw-record", append(args, "-")...), nil
+
+	case backendALSA:
+		if _, err := exec.LookPath("arecord"); err == nil {
+			return exec.CommandContext(ctx, "arecord", "-q",
+				"-D", a.device("default"),
+				"-f", "S16_LE",
+				"-r", rate,
+				"-c", channels,
+				"-t", "raw",
+			), nil
+		}
+	}
+
+	input := a.inputArgs(backend)
+	if input == nil {
+		return nil, fmt.Errorf("unsupported platform for audio capture")
+	}
+	args := append([]string{"-loglevel", "error"}, input...)
+	args = append(args, "-ac", channels, "-ar", rate, "-f", "s16le", "-")
+	return exec.CommandContext(ctx, "ffmpeg", args...), nil
+}
+
+// pipeWireAvailable checks for pw-record and a running PipeWire daemon
+func pipeWireAvailable() bool {
+	if _, err := exec.LookPath("pw-record"); err != nil {
+		return false
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = filepath.Join("/run/user", strconv.Itoa(os.Getuid()))
+	}
+	_, err := os.Stat(filepath.Join(runtimeDir, "pipewire-0"))
+	return err == nil
+}
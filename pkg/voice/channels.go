@@ -0,0 +1,42 @@
+package voice
+
+import "fmt"
+
+// checkInputChannel validates INPUT_CHANNEL against the CHANNELS captured:
+// 0 mixes them all, 1..CHANNELS picks one
+func checkInputChannel(channel, channels int) error {
+	if channels < 1 {
+		return fmt.Errorf("invalid channel count %d", channels)
+	}
+	if channel < 0 || channel > channels {
+		return fmt.Errorf("invalid input channel %d (use 0 to mix all %d channels, or 1 to %d)", channel, channels, channels)
+	}
+	return nil
+}
+
+// downmix converts interleaved 16-bit PCM with the given number of channels
+// to mono: channel (counted from 1) alone, or the average of all channels
+// when channel is 0
+func downmix(pcm []byte, channels, channel int) []byte {
+	if channels <= 1 {
+		return pcm
+	}
+
+	frame := channels * 2
+	mono := make([]byte, len(pcm)/frame*2)
+	for i := range len(pcm) / frame {
+		samples := pcm[i*frame : (i+1)*frame]
+		var value int
+		if channel > 0 {
+			value = int(int16(uint16(samples[2*channel-2]) | uint16(samples[2*channel-1])<<8))
+		} else {
+			for c := range channels {
+				value += int(int16(uint16(samples[2*c]) | uint16(samples[2*c+1])<<8))
+			}
+			value /= channels
+		}
+		mono[2*i] = byte(value)
+		mono[2*i+1] = byte(uint16(int16(value)) >> 8)
+	}
+	return mono
+}
@@ -0,0 +1,171 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// maxStitchWords is the most words the end of a chunk's transcript and the
+// start of the next one are compared over to drop what both heard
+const maxStitchWords = 8
+
+// transcribeChunked transcribes recordings longer than TRANSCRIBE_CHUNK_SECONDS
+// in overlapping chunks at once, so they take about as long as short ones;
+// ok is false for shorter recordings, which are left to the caller
+func (v *Interface) transcribeChunked(ctx context.Context, audioPath, language string) (text string, ok bool, err error) {
+	cfg := v.config.Voice
+	if cfg.TranscribeChunk <= 0 {
+		return "", false, nil
+	}
+	chunk := time.Duration(cfg.TranscribeChunk) * time.Second
+	overlap := time.Duration(cfg.TranscribeOverlap * float64(time.Second))
+	chunks, err := splitRecording(audioPath, chunk, overlap)
+	if err != nil {
+		v.logger.Warn("Failed to split the recording, transcribing it whole", "error", err)
+		return "", false, nil
+	}
+	if chunks == nil {
+		return "", false, nil
+	}
+	defer func() {
+		for _, path := range chunks {
+			os.Remove(path)
+		}
+	}()
+
+	v.logger.Debug("Transcribing in chunks", "chunks", len(chunks), "workers", cfg.TranscribeWorkers)
+	texts, err := transcribeChunks(ctx, v.transcriber, chunks, language, cfg.TranscribeWorkers)
+	if err != nil {
+		return "", true, err
+	}
+	return stitchTranscripts(texts), true, nil
+}
+
+// splitRecording writes the WAV recording at path as chunks of chunk length,
+// each starting overlap before the previous one ends, and returns their
+// paths for the caller to remove; nil when the recording fits in one
+func splitRecording(path string, chunk, overlap time.Duration) ([]string, error) {
+	if overlap < 0 || overlap >= chunk {
+		return nil, fmt.Errorf("the chunk overlap (%s) must be shorter than the chunks (%s)", overlap, chunk)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	format, pcm, err := parseWAV(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	blockAlign := format.Channels * format.BitsPerSample / 8
+	if blockAlign <= 0 || format.SampleRate <= 0 {
+		return nil, fmt.Errorf("%s: unsupported format (%d-bit, %d channels)", path, format.BitsPerSample, format.Channels)
+	}
+
+	frames := len(pcm) / blockAlign
+	chunkFrames := int(chunk.Seconds() * float64(format.SampleRate))
+	stepFrames := chunkFrames - int(overlap.Seconds()*float64(format.SampleRate))
+	if frames <= chunkFrames+stepFrames/2 {
+		return nil, nil
+	}
+
+	var paths []string
+	for start := 0; start < frames; start += stepFrames {
+		end := start + chunkFrames
+		// A short tail goes with the chunk before it
+		if frames-end < stepFrames/2 {
+			end = frames
+		}
+		chunkPath := fmt.Sprintf("%s.chunk%d.wav", strings.TrimSuffix(path, ".wav"), len(paths))
+		if err := writeWAV(chunkPath, pcm[start*blockAlign:end*blockAlign], format); err != nil {
+			for _, written := range paths {
+				os.Remove(written)
+			}
+			return nil, err
+		}
+		paths = append(paths, chunkPath)
+		if end == frames {
+			break
+		}
+	}
+	return paths, nil
+}
+
+// transcribeChunks transcribes the chunks with up to workers at a time,
+// returning their transcripts in order
+func transcribeChunks(ctx context.Context, transcriber Transcriber, chunks []string, language string, workers int) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	texts := make([]string, len(chunks))
+	errs := make([]error, len(chunks))
+	slots := make(chan struct{}, max(workers, 1))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			texts[i], errs[i] = transcriber.Transcribe(ctx, chunk, language)
+			if errs[i] != nil {
+				// One missing chunk spoils the transcript, so stop the others
+				cancel()
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+	}
+	return texts, nil
+}
+
+// stitchTranscripts joins the transcripts of overlapping chunks, dropping
+// the words at the start of each that the previous one ended with
+func stitchTranscripts(texts []string) string {
+	var words []string
+	for _, text := range texts {
+		next := strings.Fields(cleanTranscription(text))
+		words = append(words, next[overlappingWords(words, next):]...)
+	}
+	return strings.Join(words, " ")
+}
+
+// overlappingWords is how many words next starts with that previous ends
+// with, ignoring case and punctuation
+func overlappingWords(previous, next []string) int {
+	for n := min(len(previous), len(next), maxStitchWords); n > 0; n-- {
+		matched := true
+		for i := range n {
+			if stitchKey(previous[len(previous)-n+i]) != stitchKey(next[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return n
+		}
+	}
+	return 0
+}
+
+// stitchKey is a word as compared when stitching: lower case, without
+// punctuation
+func stitchKey(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}))
+}
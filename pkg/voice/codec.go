@@ -0,0 +1,76 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// recordingCodecs are the ffmpeg encoder settings of each RecordingFormat
+// besides WAV: lossless FLAC, or Opus in OGG, tiny and good enough for speech
+var recordingCodecs = map[string][]string{
+	"flac": {"-c:a", "flac"},
+	"ogg":  {"-c:a", "libopus", "-b:a", "32k"},
+}
+
+// checkRecordingFormat validates RecordingFormat and that ffmpeg can convert to it
+func checkRecordingFormat(format string) error {
+	if format == "wav" {
+		return nil
+	}
+	if _, ok := recordingCodecs[format]; !ok {
+		return fmt.Errorf("unknown recording format %q (expected wav, flac or ogg)", format)
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is needed to record to %s: %w", format, err)
+	}
+	return nil
+}
+
+// encodeRecording converts a WAV recording to format, replacing it, and
+// returns the new path
+func encodeRecording(ctx context.Context, path, format string) (string, error) {
+	codec, ok := recordingCodecs[format]
+	if !ok || !isWAV(path) {
+		return path, nil
+	}
+
+	encoded := strings.TrimSuffix(path, filepath.Ext(path)) + "." + format
+	if err := ffmpegConvert(ctx, path, encoded, codec); err != nil {
+		return path, err
+	}
+	os.Remove(path)
+	return encoded, nil
+}
+
+// decodeRecording returns a WAV version of the recording at path, converting
+// archived FLAC/OGG recordings to a temporary file that cleanup removes
+func decodeRecording(ctx context.Context, path string) (wav string, cleanup func(), err error) {
+	if isWAV(path) {
+		return path, func() {}, nil
+	}
+
+	wav = strings.TrimSuffix(path, filepath.Ext(path)) + ".decoded.wav"
+	if err := ffmpegConvert(ctx, path, wav, []string{"-c:a", "pcm_s16le"}); err != nil {
+		return "", nil, err
+	}
+	return wav, func() { os.Remove(wav) }, nil
+}
+
+// isWAV reports whether path names a WAV file
+func isWAV(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".wav")
+}
+
+// ffmpegConvert transcodes src to dst with the given codec arguments
+func ffmpegConvert(ctx context.Context, src, dst string, codec []string) error {
+	args := append([]string{"-y", "-loglevel", "error", "-i", src}, codec...)
+	output, err := exec.CommandContext(ctx, "ffmpeg", append(args, dst)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to convert %s: %w: %s", filepath.Base(src), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package voice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// errMisheard reports a transcript the transcriber was too unsure of to be
+// worth answering
+var errMisheard = errors.New("transcription confidence below REJECT_CONFIDENCE")
+
+// misheardReply asks the user to say it again
+const misheardReply = "Perdona, no te he entendido bien. ¿Me lo repites?"
+
+// Segment is a stretch of a transcription and how confident the transcriber
+// was of it, from 0 to 1
+type Segment struct {
+	Text       string
+	Confidence float64
+}
+
+// ConfidenceTranscriber is implemented by transcribers that score what they
+// transcribe, used to ask the user to repeat what was barely understood
+type ConfidenceTranscriber interface {
+	TranscribeSegments(ctx context.Context, audioFilePath, language string) ([]Segment, error)
+}
+
+// segmentsText joins the text of the segments
+func segmentsText(segments []Segment) string {
+	texts := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if text := strings.TrimSpace(segment.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// meanConfidence is the confidence of the segments weighted by their
+// length, so a long clear sentence outweighs a mumbled word
+func meanConfidence(segments []Segment) float64 {
+	var sum, total float64
+	for _, segment := range segments {
+		length := float64(utf8.RuneCountInString(strings.TrimSpace(segment.Text)))
+		sum += segment.Confidence * length
+		total += length
+	}
+	if total == 0 {
+		return 1
+	}
+	return sum / total
+}
+
+// transcribeScored transcribes with the transcriber's confidence scores,
+// failing with errMisheard when they fall below REJECT_CONFIDENCE
+func (v *Interface) transcribeScored(ctx context.Context, scorer ConfidenceTranscriber, audioPath, language string) (string, error) {
+	segments, err := scorer.TranscribeSegments(ctx, audioPath, language)
+	if err != nil {
+		return "", err
+	}
+	transcription := cleanTranscription(segmentsText(segments))
+	if confidence := meanConfidence(segments); transcription != "" && confidence < v.config.Voice.RejectConfidence {
+		v.logger.Warn("🤷 Misheard, asking to repeat", "transcription", transcription, "confidence", confidence)
+		return "", errMisheard
+	}
+	return transcription, nil
+}
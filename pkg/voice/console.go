@@ -0,0 +1,35 @@
+package voice
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// Console is where logs and status lines go. It writes to stdout until the
+// interactive prompt starts, then through readline so that output arriving
+// while the user types is printed above the prompt instead of garbling it
+var Console = &console{out: os.Stdout}
+
+// console is an io.Writer whose destination can be swapped while in use
+type console struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// Write implements io.Writer; whole writes are never interleaved
+func (c *console) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.out.Write(p)
+}
+
+// SetOutput redirects the console, nil meaning back to stdout
+func (c *console) SetOutput(out io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if out == nil {
+		out = os.Stdout
+	}
+	c.out = out
+}
@@ -0,0 +1,390 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// Deepgram endpoints: the same path takes whole recordings over HTTPS and
+// live audio over a WebSocket
+const (
+	deepgramListenURL = "https://api.deepgram.com/v1/listen"
+	deepgramStreamURL = "wss://api.deepgram.com/v1/listen"
+)
+
+// Streaming: the growing recording is sent every deepgramSendInterval, and
+// Deepgram gets deepgramFinalTimeout to finish once it ends
+const (
+	deepgramSendInterval = 100 * time.Millisecond
+	deepgramFinalTimeout = 5 * time.Second
+)
+
+// deepgramAlternative is a transcript hypothesis in Deepgram responses
+type deepgramAlternative struct {
+	Transcript string         `json:"transcript"`
+	Confidence float64        `json:"confidence"`
+	Words      []deepgramWord `json:"words"`
+}
+
+// deepgramWord is a word of a hypothesis, timed in seconds
+type deepgramWord struct {
+	Word           string  `json:"word"`
+	PunctuatedWord string  `json:"punctuated_word"`
+	Start          float64 `json:"start"`
+	End            float64 `json:"end"`
+	Confidence     float64 `json:"confidence"`
+	Speaker        int     `json:"speaker"` // with diarize
+}
+
+// deepgramResponse is the subset of the prerecorded response used
+type deepgramResponse struct {
+	Results struct {
+		Channels []struct {
+			Alternatives []deepgramAlternative `json:"alternatives"`
+		} `json:"channels"`
+	} `json:"results"`
+}
+
+// deepgramStreamResult is the subset of a streaming message used
+type deepgramStreamResult struct {
+	Type    string `json:"type"`
+	IsFinal bool   `json:"is_final"`
+	Channel struct {
+		Alternatives []deepgramAlternative `json:"alternatives"`
+	} `json:"channel"`
+}
+
+// streamedTranscript is the final transcript of a recording streamed while
+// it was made
+type streamedTranscript struct {
+	path   string
+	finals []deepgramAlternative
+}
+
+// DeepgramTranscriber implements transcription with Deepgram, streaming
+// recordings while they are made so the transcript is ready when they end
+type DeepgramTranscriber struct {
+	config *config.VoiceConfig
+	client *http.Client
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	streamed streamedTranscript
+}
+
+// NewDeepgramTranscriber creates a transcriber authenticated with DEEPGRAM_API_KEY
+func NewDeepgramTranscriber(cfg *config.VoiceConfig) (*DeepgramTranscriber, error) {
+	if cfg.DeepgramAPIKey == "" {
+		return nil, fmt.Errorf("DEEPGRAM_API_KEY is not set")
+	}
+	return &DeepgramTranscriber{
+		config: cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+		logger: slog.Default(),
+	}, nil
+}
+
+// query builds the listen parameters shared by both endpoints
+func (d *DeepgramTranscriber) query(language string) url.Values {
+	query := url.Values{}
+	query.Set("model", d.config.DeepgramModel)
+	query.Set("language", language)
+	query.Set("smart_format", "true")
+	return query
+}
+
+// Transcribe returns the transcript streamed while the recording was made,
+// or sends the whole recording to Deepgram
+func (d *DeepgramTranscriber) Transcribe(ctx context.Context, audioFilePath, language string) (string, error) {
+	segments, err := d.TranscribeSegments(ctx, audioFilePath, language)
+	return cleanTranscription(segmentsText(segments)), err
+}
+
+// TranscribeSegments is Transcribe with Deepgram's confidence in each part
+// of the transcript
+func (d *DeepgramTranscriber) TranscribeSegments(ctx context.Context, audioFilePath, language string) ([]Segment, error) {
+	finals, err := d.finals(ctx, audioFilePath, language)
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]Segment, 0, len(finals))
+	for _, final := range finals {
+		segments = append(segments, Segment{Text: strings.TrimSpace(final.Transcript), Confidence: final.Confidence})
+	}
+	return segments, nil
+}
+
+// TranscribeWords is Transcribe with the timing of every word
+func (d *DeepgramTranscriber) TranscribeWords(ctx context.Context, audioFilePath, language string) (*TranscriptionResult, error) {
+	finals, err := d.finals(ctx, audioFilePath, language)
+	if err != nil {
+		return nil, err
+	}
+	result := &TranscriptionResult{Language: language}
+	var texts []string
+	for _, final := range finals {
+		texts = append(texts, strings.TrimSpace(final.Transcript))
+		for _, word := range final.Words {
+			text := word.PunctuatedWord
+			if text == "" {
+				text = word.Word
+			}
+			result.Words = append(result.Words, Word{
+				Text:       text,
+				Start:      time.Duration(word.Start * float64(time.Second)),
+				End:        time.Duration(word.End * float64(time.Second)),
+				Confidence: word.Confidence,
+			})
+		}
+	}
+	result.Text = cleanTranscription(strings.Join(texts, " "))
+	return result, nil
+}
+
+// finals returns the best hypothesis for each part of the recording, the
+// streamed ones if it was streamed while made
+func (d *DeepgramTranscriber) finals(ctx context.Context, audioFilePath, language string) ([]deepgramAlternative, error) {
+	// Preprocessing transcribes a cleaned-up copy of the streamed recording
+	recording := strings.TrimSuffix(audioFilePath, ".processed.wav")
+	if recording != audioFilePath {
+		recording += ".wav"
+	}
+	d.mu.Lock()
+	streamed := d.streamed
+	d.streamed = streamedTranscript{}
+	d.mu.Unlock()
+	if streamed.path == recording {
+		d.logger.Debug("Using the streamed transcript", "path", recording)
+		return streamed.finals, nil
+	}
+	return d.listen(ctx, audioFilePath, d.query(language))
+}
+
+// TranscribeSpeakers sends the whole recording to Deepgram with diarization,
+// since the streamed transcript doesn't tell the speakers apart
+func (d *DeepgramTranscriber) TranscribeSpeakers(ctx context.Context, audioFilePath, language string) ([]Turn, error) {
+	d.mu.Lock()
+	d.streamed = streamedTranscript{}
+	d.mu.Unlock()
+
+	query := d.query(language)
+	query.Set("diarize", "true")
+	finals, err := d.listen(ctx, audioFilePath, query)
+	if err != nil || len(finals) == 0 {
+		return nil, err
+	}
+
+	// Deepgram numbers speakers from 0, in no particular order
+	numbers := make(map[int]int)
+	var turns []Turn
+	for _, word := range finals[0].Words {
+		if _, ok := numbers[word.Speaker]; !ok {
+			numbers[word.Speaker] = len(numbers) + 1
+		}
+		text := word.PunctuatedWord
+		if text == "" {
+			text = word.Word
+		}
+		turns = appendTurn(turns, Turn{
+			Speaker: numbers[word.Speaker],
+			Text:    text,
+			Start:   time.Duration(word.Start * float64(time.Second)),
+			End:     time.Duration(word.End * float64(time.Second)),
+		})
+	}
+	return turns, nil
+}
+
+// listen sends a whole recording to Deepgram, returning its best hypothesis
+func (d *DeepgramTranscriber) listen(ctx context.Context, audioFilePath string, query url.Values) ([]deepgramAlternative, error) {
+	audio, err := os.ReadFile(audioFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", audioFilePath, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, deepgramListenURL+"?"+query.Encode(), bytes.NewReader(audio))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+	req.Header.Set("Authorization", "Token "+d.config.DeepgramAPIKey)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Deepgram request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Deepgram response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Deepgram returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result deepgramResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Deepgram response: %w", err)
+	}
+	if len(result.Results.Channels) == 0 || len(result.Results.Channels[0].Alternatives) == 0 {
+		return nil, nil
+	}
+	return result.Results.Channels[0].Alternatives[:1], nil
+}
+
+// TranscribeStream sends the recording growing at audioFilePath to Deepgram
+// as it is made, reporting interim transcripts, and keeps the final one for
+// Transcribe once ctx ends with the recording
+func (d *DeepgramTranscriber) TranscribeStream(ctx context.Context, audioFilePath, language string, partial func(text string)) error {
+	// The stream is described by the recording's header
+	var format wavFormat
+	ticker := time.NewTicker(deepgramSendInterval)
+	defer ticker.Stop()
+	for {
+		data, err := os.ReadFile(audioFilePath)
+		if err == nil {
+			if format, _, err = parseWAV(data); err == nil && format.BitsPerSample == 16 && format.Channels >= 1 {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+
+	query := d.query(language)
+	query.Set("encoding", "linear16")
+	query.Set("sample_rate", strconv.Itoa(format.SampleRate))
+	query.Set("channels", strconv.Itoa(format.Channels))
+	query.Set("interim_results", "true")
+	// The recording may end while connecting, its audio is sent all the same
+	dialCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	ws, err := dialWebSocket(dialCtx, deepgramStreamURL+"?"+query.Encode(), http.Header{
+		"Authorization": {"Token " + d.config.DeepgramAPIKey},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to Deepgram: %w", err)
+	}
+	defer ws.Close()
+
+	// Final results are consecutive parts of the recording; the interim one
+	// is the best guess at the part being said
+	var mu sync.Mutex
+	var finals []deepgramAlternative
+	received := make(chan error, 1)
+	go func() {
+		for {
+			opcode, message, err := ws.ReadMessage()
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				received <- err
+				return
+			}
+			var result deepgramStreamResult
+			if opcode != wsText || json.Unmarshal(message, &result) != nil || result.Type != "Results" || len(result.Channel.Alternatives) == 0 {
+				continue
+			}
+			best := result.Channel.Alternatives[0]
+			text := strings.TrimSpace(best.Transcript)
+
+			mu.Lock()
+			if result.IsFinal && text != "" {
+				finals = append(finals, best)
+			}
+			heard := finalsText(finals)
+			if !result.IsFinal && text != "" {
+				heard = strings.TrimSpace(heard + " " + text)
+			}
+			mu.Unlock()
+			if ctx.Err() == nil && heard != "" {
+				partial(heard)
+			}
+		}
+	}()
+
+	var sent int
+	send := func() error {
+		data, err := os.ReadFile(audioFilePath)
+		if err != nil {
+			return nil
+		}
+		_, pcm, err := parseWAV(data)
+		if err != nil {
+			return nil
+		}
+		frame := format.Channels * 2
+		pcm = pcm[:len(pcm)/frame*frame]
+		if len(pcm) <= sent {
+			return nil
+		}
+		if err := ws.WriteMessage(wsBinary, pcm[sent:]); err != nil {
+			return fmt.Errorf("failed to stream audio to Deepgram: %w", err)
+		}
+		sent = len(pcm)
+		return nil
+	}
+
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+			continue
+		case err := <-received:
+			return fmt.Errorf("Deepgram closed the stream: %w", err)
+		case <-ticker.C:
+		}
+		if err := send(); err != nil {
+			return err
+		}
+	}
+
+	// The recording is complete: send the rest and wait for the last results
+	if err := send(); err != nil {
+		return err
+	}
+	if err := ws.WriteMessage(wsText, []byte(`{"type": "CloseStream"}`)); err != nil {
+		return fmt.Errorf("failed to close the Deepgram stream: %w", err)
+	}
+	select {
+	case err := <-received:
+		if err != nil {
+			return fmt.Errorf("Deepgram stream failed: %w", err)
+		}
+	case <-time.After(deepgramFinalTimeout):
+		return fmt.Errorf("Deepgram did not finish the transcript in %s", deepgramFinalTimeout)
+	}
+
+	mu.Lock()
+	streamed := finals
+	mu.Unlock()
+	d.mu.Lock()
+	d.streamed = streamedTranscript{path: audioFilePath, finals: streamed}
+	d.mu.Unlock()
+	return nil
+}
+
+// finalsText joins the transcripts of consecutive final results
+func finalsText(finals []deepgramAlternative) string {
+	texts := make([]string, 0, len(finals))
+	for _, final := range finals {
+		texts = append(texts, strings.TrimSpace(final.Transcript))
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,151 @@
+package voice
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// Noise suppression: a high-pass filter for rumble and hum, then spectral
+// gating against a noise profile taken from the quietest parts of the recording
+const (
+	highPassCutoff = 100.0 // Hz
+	gateFrameSize  = 512   // samples per analysis frame (power of two)
+	gateNoiseShare = 0.1   // share of the quietest frames that make the noise profile
+	gateThreshold  = 3.0   // bins this many times above the noise are kept
+	gateFloor      = 0.1   // gain of gated bins (-20 dB)
+	gateRelease    = 0.5   // per-frame decay of a bin's gain, so words don't end abruptly
+)
+
+// suppressNoise denoises interleaved 16-bit samples in place, channel by channel
+func suppressNoise(samples []int16, format wavFormat) {
+	channels := format.Channels
+	for c := 0; c < channels; c++ {
+		signal := make([]float64, len(samples)/channels)
+		for i := range signal {
+			signal[i] = float64(samples[i*channels+c])
+		}
+		signal = spectralGate(highPass(signal, format.SampleRate))
+		for i, value := range signal {
+			samples[i*channels+c] = clampSample(value)
+		}
+	}
+}
+
+// highPass applies a first-order high-pass filter at highPassCutoff
+func highPass(signal []float64, sampleRate int) []float64 {
+	rc := 1 / (2 * math.Pi * highPassCutoff)
+	alpha := rc / (rc + 1/float64(sampleRate))
+
+	out := make([]float64, len(signal))
+	for i := 1; i < len(signal); i++ {
+		out[i] = alpha * (out[i-1] + signal[i] - signal[i-1])
+	}
+	return out
+}
+
+// spectralGate attenuates the frequency bins that don't rise above the
+// background noise, frame by frame
+func spectralGate(signal []float64) []float64 {
+	n, hop := gateFrameSize, gateFrameSize/2
+	if len(signal) < n {
+		return signal
+	}
+
+	// Pad so that every sample is covered by two overlapping frames
+	padded := make([]float64, hop+len(signal)+n)
+	copy(padded[hop:], signal)
+
+	// Periodic Hann windows at 50% overlap add up to one
+	window := make([]float64, n)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n))
+	}
+
+	frames := (len(padded)-n)/hop + 1
+	spectra := make([][]complex128, frames)
+	energy := make([]float64, frames)
+	for f := range spectra {
+		frame := make([]complex128, n)
+		for i := range frame {
+			frame[i] = complex(padded[f*hop+i]*window[i], 0)
+		}
+		fft(frame, false)
+		spectra[f] = frame
+		for k := 0; k <= n/2; k++ {
+			energy[f] += real(frame[k])*real(frame[k]) + imag(frame[k])*imag(frame[k])
+		}
+	}
+
+	// The quietest frames are taken to be background noise
+	order := make([]int, frames)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return energy[order[i]] < energy[order[j]] })
+	quiet := max(1, int(float64(frames)*gateNoiseShare))
+	noise := make([]float64, n/2+1)
+	for _, f := range order[:quiet] {
+		for k := range noise {
+			noise[k] += cmplx.Abs(spectra[f][k]) / float64(quiet)
+		}
+	}
+
+	out := make([]float64, len(padded))
+	gains := make([]float64, n/2+1)
+	for f, frame := range spectra {
+		for k := 0; k <= n/2; k++ {
+			gain := gateFloor
+			if cmplx.Abs(frame[k]) > noise[k]*gateThreshold {
+				gain = 1
+			}
+			gains[k] = max(gain, gains[k]*gateRelease)
+			frame[k] *= complex(gains[k], 0)
+			if k > 0 && k < n/2 {
+				frame[n-k] = cmplx.Conj(frame[k])
+			}
+		}
+		fft(frame, true)
+		for i := range frame {
+			out[f*hop+i] += real(frame[i])
+		}
+	}
+	return out[hop : hop+len(signal)]
+}
+
+// fft transforms x in place (len(x) a power of two); inverse also scales by 1/n
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = even+odd, even-odd
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		for i := range x {
+			x[i] /= complex(float64(n), 0)
+		}
+	}
+}
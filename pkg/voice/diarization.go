@@ -0,0 +1,97 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// speakerTurnMarker ends segments after which someone else speaks, in the
+// text of whisper.cpp with tinydiarize
+const speakerTurnMarker = "[SPEAKER_TURN]"
+
+// tinydiarizeArgs ask whisper.cpp to mark speaker turns; it takes a
+// tinydiarize model such as ggml-small.en-tdrz.bin
+var tinydiarizeArgs = []string{"--tinydiarize", "true"}
+
+// Turn is what one speaker said until someone else spoke
+type Turn struct {
+	Speaker int // numbered from 1 in order of appearance
+	Text    string
+	Start   time.Duration
+	End     time.Duration
+}
+
+// SpeakerTranscriber is implemented by transcribers that tell apart the
+// people speaking in a recording
+type SpeakerTranscriber interface {
+	TranscribeSpeakers(ctx context.Context, audioFilePath, language string) ([]Turn, error)
+}
+
+// diarizeKey marks contexts whose recordings may have several people
+// speaking: long recordings and processed files
+type diarizeKey struct{}
+
+// withDiarization labels the speakers of recordings transcribed with ctx
+// when DIARIZE is on
+func withDiarization(ctx context.Context) context.Context {
+	return context.WithValue(ctx, diarizeKey{}, true)
+}
+
+// diarizing reports whether the recording transcribed with ctx should be
+// labelled by speaker
+func (v *Interface) diarizing(ctx context.Context) bool {
+	if !v.config.Voice.Diarize {
+		return false
+	}
+	if _, ok := TranscriberAs[SpeakerTranscriber](v.transcriber); !ok {
+		return false
+	}
+	marked, _ := ctx.Value(diarizeKey{}).(bool)
+	return marked
+}
+
+// nextSpeaker is who speaks after a segment: tinydiarize only marks turns,
+// so two people are assumed to take turns
+func nextSpeaker(speaker int, turn bool) int {
+	if !turn {
+		return speaker
+	}
+	return 3 - speaker
+}
+
+// appendTurn adds turn to turns, joining it to the last one when the same
+// person is still speaking
+func appendTurn(turns []Turn, turn Turn) []Turn {
+	if turn.Text == "" {
+		return turns
+	}
+	if last := len(turns) - 1; last >= 0 && turns[last].Speaker == turn.Speaker {
+		turns[last].Text += " " + turn.Text
+		turns[last].End = turn.End
+		return turns
+	}
+	return append(turns, turn)
+}
+
+// speakersText writes turns as a "Speaker 1: ..." line each, or as plain
+// text when a single person spoke. Hallucinations are removed turn by turn,
+// so a turn that was only one neither gets a line nor counts as a speaker
+func speakersText(turns []Turn) string {
+	var lines, texts []string
+	speakers := make(map[int]bool)
+	for _, turn := range turns {
+		text := cleanTranscription(turn.Text) // drops hallucinations too
+		if text == "" {
+			continue
+		}
+		speakers[turn.Speaker] = true
+		texts = append(texts, text)
+		lines = append(lines, fmt.Sprintf("Speaker %d: %s", turn.Speaker, text))
+	}
+	if len(speakers) < 2 {
+		return strings.Join(texts, " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,96 @@
+package voice
+
+import (
+	"fmt"
+	"math"
+	"os"
+)
+
+// rmsDB returns the RMS level of 16-bit samples in dBFS (-inf..0)
+func rmsDB(samples []int16) float64 {
+	if len(samples) == 0 {
+		return math.Inf(-1)
+	}
+
+	var sum float64
+	for _, sample := range samples {
+		v := float64(sample) / 32768.0
+		sum += v * v
+	}
+
+	rms := math.Sqrt(sum / float64(len(samples)))
+	if rms == 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(rms)
+}
+
+// goertzelPower returns the signal power at a single frequency
+func goertzelPower(samples []int16, sampleRate int, frequency float64) float64 {
+	k := 2 * math.Cos(2*math.Pi*frequency/float64(sampleRate))
+	var s1, s2 float64
+	for _, sample := range samples {
+		s0 := float64(sample)/32768.0 + k*s1 - s2
+		s2, s1 = s1, s0
+	}
+	return s1*s1 + s2*s2 - k*s1*s2
+}
+
+// tonality returns the share of energy in the strongest probed frequency within
+// [low, high] Hz and that frequency; pure tones (beeps, bells) score high
+func tonality(samples []int16, sampleRate int, low, high float64) (float64, float64) {
+	if len(samples) == 0 {
+		return 0, 0
+	}
+
+	var total, peak, peakFrequency float64
+	for frequency := low; frequency <= high; frequency += 50 {
+		power := goertzelPower(samples, sampleRate, frequency)
+		total += power
+		if power > peak {
+			peak, peakFrequency = power, frequency
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return peak / total, peakFrequency
+}
+
+// bytesToSamples converts little-endian 16-bit PCM to samples
+func bytesToSamples(data []byte) []int16 {
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(uint16(data[2*i]) | uint16(data[2*i+1])<<8)
+	}
+	return samples
+}
+
+// samplesToBytes converts samples to little-endian 16-bit PCM
+func samplesToBytes(samples []int16) []byte {
+	data := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		data[2*i] = byte(sample)
+		data[2*i+1] = byte(uint16(sample) >> 8)
+	}
+	return data
+}
+
+// clampSample rounds a sample value into the 16-bit range
+func clampSample(value float64) int16 {
+	return int16(max(min(math.Round(value), math.MaxInt16), math.MinInt16))
+}
+
+// wavLevelDB returns the RMS level in dBFS of a 16-bit PCM WAV file
+func wavLevelDB(path string) (float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	_, pcm, err := parseWAV(data)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return rmsDB(bytesToSamples(pcm)), nil
+}
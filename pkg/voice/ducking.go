@@ -0,0 +1,245 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// duckRelease is how long other audio stays ducked after Bobo stops, so it
+// doesn't surge between the sentences of a long answer
+const duckRelease = 800 * time.Millisecond
+
+// sinkInput is a PulseAudio/PipeWire playback stream and its channel volumes
+type sinkInput struct {
+	id      string
+	volumes []string
+}
+
+// Ducker lowers or pauses other audio (music, videos) while Bobo talks and
+// brings it back afterwards (PulseAudio/PipeWire or MPRIS players on Linux)
+type Ducker struct {
+	config *config.TTSConfig
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	speaking int
+	release  *time.Timer
+	restore  func() // undoes the current ducking, nil when not ducked
+}
+
+// NewDucker creates a ducker for the DUCKING mode, checking its tools
+func NewDucker(cfg *config.TTSConfig) (*Ducker, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("ducking is only supported on Linux")
+	}
+	var tool string
+	switch cfg.Ducking {
+	case "volume":
+		tool = "pactl"
+	case "pause":
+		tool = "playerctl"
+	default:
+		return nil, fmt.Errorf("unknown DUCKING mode %q (available: volume, pause)", cfg.Ducking)
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("%s not found: %w", tool, err)
+	}
+	return &Ducker{config: cfg, logger: slog.Default()}, nil
+}
+
+// Duck lowers other audio until the matching Release
+func (d *Ducker) Duck(ctx context.Context) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.speaking++
+	if d.release != nil {
+		d.release.Stop()
+		d.release = nil
+	}
+	if d.restore != nil {
+		return
+	}
+
+	var err error
+	if d.config.Ducking == "pause" {
+		d.restore, err = d.pausePlayers(ctx)
+	} else {
+		d.restore, err = d.lowerStreams(ctx)
+	}
+	if err != nil {
+		d.logger.Debug("Failed to duck other audio", "mode", d.config.Ducking, "error", err)
+	}
+}
+
+// Release brings other audio back shortly after the last Duck is released
+func (d *Ducker) Release() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.speaking--
+	if d.speaking > 0 || d.restore == nil {
+		return
+	}
+	d.release = time.AfterFunc(duckRelease, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.speaking > 0 || d.restore == nil {
+			return
+		}
+		d.restore()
+		d.restore = nil
+		d.release = nil
+	})
+}
+
+// Close restores other audio right away
+func (d *Ducker) Close() error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.release != nil {
+		d.release.Stop()
+		d.release = nil
+	}
+	if d.restore != nil {
+		d.restore()
+		d.restore = nil
+	}
+	return nil
+}
+
+// lowerStreams turns the playback streams already playing down to
+// DUCKING_LEVEL percent of their volume; Bobo's own speech starts later and
+// keeps its volume
+func (d *Ducker) lowerStreams(ctx context.Context) (func(), error) {
+	output, err := d.run(ctx, "pactl", "list", "sink-inputs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list playback streams: %w", err)
+	}
+	inputs := parseSinkInputs(output)
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	var lowered []sinkInput
+	for _, input := range inputs {
+		args := []string{"set-sink-input-volume", input.id}
+		for _, volume := range input.volumes {
+			raw, _ := strconv.Atoi(volume)
+			args = append(args, strconv.Itoa(raw*d.config.DuckingLevel/100))
+		}
+		if _, err := d.run(ctx, "pactl", args...); err != nil {
+			d.logger.Debug("Failed to lower playback stream", "stream", input.id, "error", err)
+			continue
+		}
+		lowered = append(lowered, input)
+	}
+	d.logger.Debug("🔉 Other audio lowered", "streams", len(lowered))
+
+	return func() {
+		for _, input := range lowered {
+			// Streams that ended meanwhile can't be restored, nor need to
+			args := append([]string{"set-sink-input-volume", input.id}, input.volumes...)
+			d.run(context.Background(), "pactl", args...)
+		}
+	}, nil
+}
+
+// parseSinkInputs reads the stream ids and raw channel volumes from
+// "pactl list sink-inputs"
+func parseSinkInputs(output string) []sinkInput {
+	var inputs []sinkInput
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if id, ok := strings.CutPrefix(line, "Sink Input #"); ok {
+			inputs = append(inputs, sinkInput{id: id})
+			continue
+		}
+		volumes, ok := strings.CutPrefix(line, "Volume:")
+		if !ok || len(inputs) == 0 {
+			continue
+		}
+		// front-left: 65536 / 100% / 0.00 dB,   front-right: 65536 / 100% / 0.00 dB
+		input := &inputs[len(inputs)-1]
+		for _, channel := range strings.Split(volumes, ",") {
+			_, value, found := strings.Cut(channel, ":")
+			fields := strings.Fields(value)
+			if !found || len(fields) == 0 {
+				continue
+			}
+			if _, err := strconv.Atoi(fields[0]); err == nil {
+				input.volumes = append(input.volumes, fields[0])
+			}
+		}
+	}
+
+	// Streams without a volume (e.g. corked passthrough) are left alone
+	var usable []sinkInput
+	for _, input := range inputs {
+		if len(input.volumes) > 0 {
+			usable = append(usable, input)
+		}
+	}
+	return usable
+}
+
+// pausePlayers pauses the media players that are playing, to resume just
+// those afterwards
+func (d *Ducker) pausePlayers(ctx context.Context) (func(), error) {
+	output, err := d.run(ctx, "playerctl", "--all-players", "--format", "{{playerName}}:{{status}}", "status")
+	if err != nil {
+		// playerctl fails when no player is running
+		return nil, nil
+	}
+
+	var paused []string
+	for _, line := range strings.Split(output, "\n") {
+		name, status, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || status != "Playing" {
+			continue
+		}
+		if _, err := d.run(ctx, "playerctl", "--player", name, "pause"); err != nil {
+			d.logger.Debug("Failed to pause media player", "player", name, "error", err)
+			continue
+		}
+		paused = append(paused, name)
+	}
+	if len(paused) == 0 {
+		return nil, nil
+	}
+	d.logger.Debug("⏸️ Media players paused", "players", paused)
+
+	return func() {
+		for _, name := range paused {
+			d.run(context.Background(), "playerctl", "--player", name, "play")
+		}
+	}, nil
+}
+
+// run executes a short-lived helper command and returns its output
+func (d *Ducker) run(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	return string(output), err
+}
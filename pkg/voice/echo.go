@@ -0,0 +1,48 @@
+package voice
+
+import (
+	"sync"
+	"time"
+)
+
+// echoTail is how long the room keeps ringing after Bobo stops talking
+const echoTail = 300 * time.Millisecond
+
+// echoGate mutes the always-open microphone while Bobo talks, so that the
+// stream, the wake word and the sound monitor don't hear Bobo itself
+type echoGate struct {
+	mu       sync.Mutex
+	speaking int
+	until    time.Time
+}
+
+// begin mutes the microphone until the matching end
+func (g *echoGate) begin() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.speaking++
+}
+
+// end unmutes the microphone once the echo has died down
+func (g *echoGate) end() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.speaking--
+	g.until = time.Now().Add(echoTail)
+}
+
+// muted reports whether captured audio should be ignored
+func (g *echoGate) muted() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.speaking > 0 || time.Now().Before(g.until)
+}
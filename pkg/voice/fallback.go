@@ -0,0 +1,247 @@
+package voice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// fallbackEngine is one engine of a fallback chain
+type fallbackEngine struct {
+	name        string
+	transcriber Transcriber
+	timeout     time.Duration // zero for no limit besides the caller's
+}
+
+// FallbackTranscriber tries its engines in order (e.g. whisper.cpp, then
+// the OpenAI API) until one hears something, each within its own timeout
+type FallbackTranscriber struct {
+	engines []fallbackEngine
+	logger  *slog.Logger
+}
+
+// newFallbackTranscriber chains primary, the engine TRANSCRIBER names, with
+// the TRANSCRIBER_FALLBACK engines; those that fail to start are skipped
+func newFallbackTranscriber(cfg *config.Config, primary Transcriber) *FallbackTranscriber {
+	logger := slog.Default()
+	timeouts := parseTranscriberTimeouts(cfg.Voice.TranscriberTimeouts, logger)
+	name := TranscriberName(cfg)
+	f := &FallbackTranscriber{
+		engines: []fallbackEngine{{name: name, transcriber: primary, timeout: timeouts[name]}},
+		logger:  logger,
+	}
+	for _, engine := range cfg.Voice.TranscriberFallback {
+		engine = strings.TrimSpace(engine)
+		if engine == "" || engine == name {
+			continue
+		}
+		transcriber, err := newEngine(cfg, engine)
+		if err != nil {
+			logger.Warn("Skipping fallback transcriber", "engine", engine, "error", err)
+			continue
+		}
+		f.engines = append(f.engines, fallbackEngine{name: engine, transcriber: transcriber, timeout: timeouts[engine]})
+	}
+	return f
+}
+
+// parseTranscriberTimeouts reads TRANSCRIBER_TIMEOUTS entries
+// ("whisper.cpp=20", seconds), skipping the malformed ones
+func parseTranscriberTimeouts(entries []string, logger *slog.Logger) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || name == "" || err != nil || seconds <= 0 {
+			logger.Warn("Ignoring invalid transcriber timeout", "entry", entry)
+			continue
+		}
+		timeouts[name] = time.Duration(seconds * float64(time.Second))
+	}
+	return timeouts
+}
+
+// The chain offers whatever its engines do, forwarding each optional
+// interface to the engines that implement it; TranscriberAs tells callers
+// whether any does
+var (
+	_ AlternativesTranscriber = (*FallbackTranscriber)(nil)
+	_ ConfidenceTranscriber   = (*FallbackTranscriber)(nil)
+	_ LanguageTranscriber     = (*FallbackTranscriber)(nil)
+	_ ModelTranscriber        = (*FallbackTranscriber)(nil)
+	_ SpeakerTranscriber      = (*FallbackTranscriber)(nil)
+	_ StreamingTranscriber    = (*FallbackTranscriber)(nil)
+	_ WordTranscriber         = (*FallbackTranscriber)(nil)
+)
+
+// TranscriberAs returns transcriber as the optional interface T (such as
+// StreamingTranscriber) if it implements it; a fallback chain does when any
+// of its engines does
+func TranscriberAs[T any](transcriber Transcriber) (T, bool) {
+	if f, ok := transcriber.(*FallbackTranscriber); ok && len(fallbackEngines[T](f)) == 0 {
+		var none T
+		return none, false
+	}
+	as, ok := transcriber.(T)
+	return as, ok
+}
+
+// fallbackEngines returns the engines of the chain that implement T, in order
+func fallbackEngines[T any](f *FallbackTranscriber) []fallbackEngine {
+	var engines []fallbackEngine
+	for _, engine := range f.engines {
+		if _, ok := engine.transcriber.(T); ok {
+			engines = append(engines, engine)
+		}
+	}
+	return engines
+}
+
+// fallbackCall calls call on each engine implementing T in turn, within its
+// timeout, returning the first result that isn't an error or empty
+func fallbackCall[T, R any](ctx context.Context, f *FallbackTranscriber, call func(ctx context.Context, engine T) (R, error), empty func(result R) bool) (R, error) {
+	var none R
+	engines := fallbackEngines[T](f)
+	if len(engines) == 0 {
+		return none, fmt.Errorf("no transcriber in the fallback chain is a %s", reflect.TypeFor[T]().Name())
+	}
+
+	var errs []error
+	for _, engine := range engines {
+		result, err := withEngineTimeout(ctx, engine, func(ctx context.Context) (R, error) {
+			return call(ctx, engine.transcriber.(T))
+		})
+		if ctx.Err() != nil {
+			return none, ctx.Err()
+		}
+		switch {
+		case err != nil:
+			f.logger.Warn("Transcriber failed", "engine", engine.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", engine.name, err))
+			continue
+		case empty(result):
+			f.logger.Warn("Transcriber heard nothing", "engine", engine.name)
+			continue
+		}
+
+		if engine.name == f.engines[0].name {
+			f.logger.Debug("Transcribed", "engine", engine.name)
+		} else {
+			f.logger.Info("🔁 Transcribed by a fallback engine", "engine", engine.name)
+		}
+		return result, nil
+	}
+	if len(errs) == len(engines) {
+		return none, fmt.Errorf("all transcribers failed: %w", errors.Join(errs...))
+	}
+	// Some engine worked and heard nothing, so there was nothing to hear
+	return none, nil
+}
+
+// Transcribe implements Transcriber, returning the first engine's result
+// that isn't an error or empty
+func (f *FallbackTranscriber) Transcribe(ctx context.Context, audioFilePath, language string) (string, error) {
+	return fallbackCall(ctx, f, func(ctx context.Context, engine Transcriber) (string, error) {
+		return engine.Transcribe(ctx, audioFilePath, language)
+	}, emptyText)
+}
+
+// Alternatives implements AlternativesTranscriber
+func (f *FallbackTranscriber) Alternatives(ctx context.Context, audioFilePath, language string, n int) ([]string, error) {
+	return fallbackCall(ctx, f, func(ctx context.Context, engine AlternativesTranscriber) ([]string, error) {
+		return engine.Alternatives(ctx, audioFilePath, language, n)
+	}, func(alternatives []string) bool { return len(alternatives) == 0 })
+}
+
+// TranscribeSegments implements ConfidenceTranscriber
+func (f *FallbackTranscriber) TranscribeSegments(ctx context.Context, audioFilePath, language string) ([]Segment, error) {
+	return fallbackCall(ctx, f, func(ctx context.Context, engine ConfidenceTranscriber) ([]Segment, error) {
+		return engine.TranscribeSegments(ctx, audioFilePath, language)
+	}, func(segments []Segment) bool { return emptyText(segmentsText(segments)) })
+}
+
+// TranscribeLanguage implements LanguageTranscriber
+func (f *FallbackTranscriber) TranscribeLanguage(ctx context.Context, audioFilePath string) (text, language string, err error) {
+	type detected struct{ text, language string }
+	result, err := fallbackCall(ctx, f, func(ctx context.Context, engine LanguageTranscriber) (detected, error) {
+		text, language, err := engine.TranscribeLanguage(ctx, audioFilePath)
+		return detected{text, language}, err
+	}, func(result detected) bool { return emptyText(result.text) })
+	return result.text, result.language, err
+}
+
+// TranscribeSpeakers implements SpeakerTranscriber
+func (f *FallbackTranscriber) TranscribeSpeakers(ctx context.Context, audioFilePath, language string) ([]Turn, error) {
+	return fallbackCall(ctx, f, func(ctx context.Context, engine SpeakerTranscriber) ([]Turn, error) {
+		return engine.TranscribeSpeakers(ctx, audioFilePath, language)
+	}, func(turns []Turn) bool { return emptyText(speakersText(turns)) })
+}
+
+// TranscribeWords implements WordTranscriber
+func (f *FallbackTranscriber) TranscribeWords(ctx context.Context, audioFilePath, language string) (*TranscriptionResult, error) {
+	return fallbackCall(ctx, f, func(ctx context.Context, engine WordTranscriber) (*TranscriptionResult, error) {
+		return engine.TranscribeWords(ctx, audioFilePath, language)
+	}, func(result *TranscriptionResult) bool { return result == nil || emptyText(result.Text) })
+}
+
+// TranscribeStream implements StreamingTranscriber with the first engine
+// that streams: interim hypotheses are only shown, so there is nothing to
+// fall back from
+func (f *FallbackTranscriber) TranscribeStream(ctx context.Context, audioFilePath, language string, partial func(text string)) error {
+	engines := fallbackEngines[StreamingTranscriber](f)
+	if len(engines) == 0 {
+		return fmt.Errorf("no transcriber in the fallback chain can stream")
+	}
+	return engines[0].transcriber.(StreamingTranscriber).TranscribeStream(ctx, audioFilePath, language, partial)
+}
+
+// emptyText reports whether a transcript has nothing in it
+func emptyText(text string) bool {
+	return cleanTranscription(text) == ""
+}
+
+// withEngineTimeout runs call within the engine's timeout
+func withEngineTimeout[R any](ctx context.Context, engine fallbackEngine, call func(ctx context.Context) (R, error)) (R, error) {
+	if engine.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, engine.timeout)
+		defer cancel()
+	}
+	result, err := call(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		var none R
+		return none, fmt.Errorf("timed out after %s", engine.timeout)
+	}
+	return result, err
+}
+
+// SetModel implements ModelTranscriber for the engines that can switch models
+func (f *FallbackTranscriber) SetModel(path string) {
+	for _, engine := range f.engines {
+		if model, ok := engine.transcriber.(ModelTranscriber); ok {
+			model.SetModel(path)
+		}
+	}
+}
+
+// Close stops the engines that hold resources, such as whisper.cpp servers
+func (f *FallbackTranscriber) Close() error {
+	var errs []error
+	for _, engine := range f.engines {
+		if closer, ok := engine.transcriber.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", engine.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,155 @@
+package voice
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Follow-up listening: after an answer the microphone stays open for
+// FOLLOW_UP_SECONDS, and a reply started in that window is answered as if
+// Bobo had been called again
+const (
+	followUpMaxSeconds = 12 // longest follow-up, like a long recording
+	followUpMarginDB   = 12.0
+	followUpPause      = 1 * time.Second // silence that ends the follow-up
+)
+
+// Earcon announcing the follow-up window: two soft rising notes
+var followUpNotes = []float64{660, 880}
+
+const (
+	followUpNoteLength = 90 * time.Millisecond
+	followUpVolume     = 0.15
+)
+
+// repeatWaitSeconds is how long Bobo waits for a misheard question to be
+// said again when follow-ups are off (FOLLOW_UP_SECONDS=0)
+const repeatWaitSeconds = 5
+
+// followUp keeps listening after an answer, answering follow-ups until a
+// window passes without anyone talking; callers hold v.busy
+func (v *Interface) followUp(ctx context.Context) error {
+	window := v.config.Voice.FollowUpSeconds
+	if window <= 0 || v.scripted {
+		return nil
+	}
+	return v.listen(ctx, window)
+}
+
+// listenAgain asks for a misheard question to be said again and answers the
+// repetition; callers hold v.busy
+func (v *Interface) listenAgain(ctx context.Context) error {
+	v.speak(ctx, misheardReply)
+	if v.scripted {
+		return nil
+	}
+	return v.listen(ctx, max(v.config.Voice.FollowUpSeconds, repeatWaitSeconds))
+}
+
+// listen answers what is said within window seconds, then goes on with
+// follow-ups while FOLLOW_UP_SECONDS allows
+func (v *Interface) listen(ctx context.Context, window int) error {
+	for ctx.Err() == nil {
+		if v.player != nil {
+			if err := v.player.PlayPCM(ctx, followUpEarcon(), wavFormat{SampleRate: beepSampleRate, Channels: 1, BitsPerSample: 16}); err != nil {
+				v.logger.Debug("Failed to play the follow-up earcon", "error", err)
+			}
+		}
+		v.stream.ClearPreRoll()
+		fmt.Fprintln(v.rl.Stdout(), "\n  👂 Still listening...")
+
+		heard, err := v.recorder.recordUtterance(ctx, window, followUpMaxSeconds)
+		if err != nil {
+			return fmt.Errorf("follow-up recording failed: %w", err)
+		}
+		if !heard {
+			os.Remove(v.recorder.AudioFilePath)
+			return nil
+		}
+
+		v.touch()
+		transcription, err := v.transcribe(ctx, v.recorder.AudioFilePath)
+		if errors.Is(err, errMisheard) {
+			v.speak(ctx, misheardReply)
+			window = max(v.config.Voice.FollowUpSeconds, repeatWaitSeconds)
+			continue
+		}
+		if err != nil || transcription == "" {
+			return err
+		}
+		if err := v.respond(ctx, transcription, v.recorder.AudioFilePath); err != nil {
+			return err
+		}
+
+		if window = v.config.Voice.FollowUpSeconds; window <= 0 {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// recordUtterance records a spoken reply, giving up when nobody starts
+// talking within waitSeconds and stopping at the first pause once they have;
+// it reports whether any speech was heard
+func (a *AudioRecorder) recordUtterance(ctx context.Context, waitSeconds, maxSeconds int) (bool, error) {
+	release := make(chan struct{})
+	released := false
+	stop := func() {
+		if !released {
+			close(release)
+			released = true
+		}
+	}
+
+	var (
+		start   = time.Now()
+		floor   = math.Inf(1)
+		heard   bool
+		quietAt time.Time
+	)
+	watch := func(level InputLevel) {
+		// The quietest reading so far stands for the room (-Inf until the
+		// first audio comes in)
+		if !math.IsInf(level.DB, -1) {
+			floor = min(floor, level.DB)
+			if level.DB > floor+followUpMarginDB {
+				heard, quietAt = true, time.Time{}
+				return
+			}
+		}
+
+		switch {
+		case !heard && time.Since(start) > time.Duration(waitSeconds)*time.Second:
+			stop()
+		case heard && quietAt.IsZero():
+			quietAt = time.Now()
+		case heard && time.Since(quietAt) > followUpPause:
+			stop()
+		}
+	}
+
+	if _, err := a.recordAudio(ctx, maxSeconds, release, watch); err != nil {
+		return false, err
+	}
+	return heard, nil
+}
+
+// followUpEarcon synthesizes the follow-up earcon as 16-bit mono PCM
+func followUpEarcon() []byte {
+	length := int(followUpNoteLength.Seconds() * beepSampleRate)
+	pcm := make([]byte, 0, len(followUpNotes)*length*2)
+	for _, frequency := range followUpNotes {
+		for i := 0; i < length; i++ {
+			// Fade each note in and out so it doesn't click
+			envelope := math.Sin(math.Pi * float64(i) / float64(length))
+			sample := followUpVolume * envelope * math.MaxInt16 * math.Sin(2*math.Pi*frequency*float64(i)/beepSampleRate)
+			pcm = binary.LittleEndian.AppendUint16(pcm, uint16(int16(sample)))
+		}
+	}
+	return pcm
+}
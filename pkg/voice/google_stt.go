@@ -0,0 +1,238 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// googleSTTURL is the synchronous Speech-to-Text endpoint, which takes up
+// to a minute of audio
+const googleSTTURL = "https://speech.googleapis.com/v1/speech:recognize"
+
+// googleLanguages turns the short language codes whisper uses into the
+// regional ones Speech-to-Text expects
+var googleLanguages = map[string]string{
+	"es": "es-ES",
+	"en": "en-US",
+	"ca": "ca-ES",
+	"fr": "fr-FR",
+	"de": "de-DE",
+	"it": "it-IT",
+	"pt": "pt-PT",
+}
+
+// googleRecognizeResponse is the subset of the recognize response used
+type googleRecognizeResponse struct {
+	Results []struct {
+		Alternatives []struct {
+			Transcript string  `json:"transcript"`
+			Confidence float64 `json:"confidence"`
+			// Words are only timed in the best reading, in "1.500s" offsets
+			Words []struct {
+				Word       string  `json:"word"`
+				StartTime  string  `json:"startTime"`
+				EndTime    string  `json:"endTime"`
+				Confidence float64 `json:"confidence"`
+			} `json:"words"`
+		} `json:"alternatives"`
+	} `json:"results"`
+}
+
+// GoogleSTTTranscriber implements transcription with Google Cloud
+// Speech-to-Text, using the same gcloud credentials as Vertex AI
+type GoogleSTTTranscriber struct {
+	config  *config.VoiceConfig
+	project string
+	client  *http.Client
+}
+
+// NewGoogleSTTTranscriber creates a transcriber billed to project, or to the
+// credentials' project if empty
+func NewGoogleSTTTranscriber(cfg *config.VoiceConfig, project string) (*GoogleSTTTranscriber, error) {
+	ctx := context.Background()
+	client, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	client.Timeout = 30 * time.Second
+
+	if project == "" {
+		if credentials, err := google.FindDefaultCredentials(ctx); err == nil {
+			project = credentials.ProjectID
+		}
+	}
+	return &GoogleSTTTranscriber{config: cfg, project: project, client: client}, nil
+}
+
+// Transcribe sends a WAV recording to Speech-to-Text
+func (g *GoogleSTTTranscriber) Transcribe(ctx context.Context, audioFilePath, language string) (string, error) {
+	alternatives, err := g.recognize(ctx, audioFilePath, language, 1)
+	if err != nil || len(alternatives) == 0 {
+		return "", err
+	}
+	return alternatives[0], nil
+}
+
+// TranscribeSegments is Transcribe with Speech-to-Text's confidence in each
+// part of the transcript
+func (g *GoogleSTTTranscriber) TranscribeSegments(ctx context.Context, audioFilePath, language string) ([]Segment, error) {
+	result, err := g.recognizeResponse(ctx, audioFilePath, language, 1)
+	if err != nil {
+		return nil, err
+	}
+	var segments []Segment
+	for _, part := range result.Results {
+		if len(part.Alternatives) == 0 {
+			continue
+		}
+		// A confidence of 0 means Speech-to-Text didn't score the part
+		best := part.Alternatives[0]
+		if best.Confidence == 0 {
+			best.Confidence = 1
+		}
+		segments = append(segments, Segment{Text: best.Transcript, Confidence: best.Confidence})
+	}
+	return segments, nil
+}
+
+// TranscribeWords is Transcribe with the timing of every word
+func (g *GoogleSTTTranscriber) TranscribeWords(ctx context.Context, audioFilePath, language string) (*TranscriptionResult, error) {
+	response, err := g.recognizeResponse(ctx, audioFilePath, language, 1)
+	if err != nil {
+		return nil, err
+	}
+	result := &TranscriptionResult{Language: language}
+	var texts []string
+	for _, part := range response.Results {
+		if len(part.Alternatives) == 0 {
+			continue
+		}
+		best := part.Alternatives[0]
+		texts = append(texts, strings.TrimSpace(best.Transcript))
+		for _, word := range best.Words {
+			start, _ := time.ParseDuration(word.StartTime)
+			end, _ := time.ParseDuration(word.EndTime)
+			confidence := word.Confidence
+			if confidence == 0 {
+				confidence = 1
+			}
+			result.Words = append(result.Words, Word{Text: word.Word, Start: start, End: end, Confidence: confidence})
+		}
+	}
+	result.Text = strings.Join(texts, " ")
+	return result, nil
+}
+
+// Alternatives asks Speech-to-Text for its n best hypotheses
+func (g *GoogleSTTTranscriber) Alternatives(ctx context.Context, audioFilePath, language string, n int) ([]string, error) {
+	return g.recognize(ctx, audioFilePath, language, n)
+}
+
+// recognize returns up to n hypotheses for the recording, best first
+func (g *GoogleSTTTranscriber) recognize(ctx context.Context, audioFilePath, language string, n int) ([]string, error) {
+	result, err := g.recognizeResponse(ctx, audioFilePath, language, n)
+	if err != nil {
+		return nil, err
+	}
+
+	// Results are consecutive parts of the audio; alternatives rank readings
+	// of each part, so hypothesis i joins the i-th reading of every part
+	var alternatives []string
+	for i := range max(n, 1) {
+		var parts []string
+		for _, part := range result.Results {
+			if len(part.Alternatives) == 0 {
+				continue
+			}
+			parts = append(parts, strings.TrimSpace(part.Alternatives[min(i, len(part.Alternatives)-1)].Transcript))
+		}
+		text := strings.Join(parts, " ")
+		if text == "" || (i > 0 && text == alternatives[len(alternatives)-1]) {
+			break
+		}
+		alternatives = append(alternatives, text)
+	}
+	return alternatives, nil
+}
+
+// recognizeResponse asks Speech-to-Text for up to n readings of each part of
+// the recording
+func (g *GoogleSTTTranscriber) recognizeResponse(ctx context.Context, audioFilePath, language string, n int) (*googleRecognizeResponse, error) {
+	data, err := os.ReadFile(audioFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", audioFilePath, err)
+	}
+	format, pcm, err := parseWAV(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", audioFilePath, err)
+	}
+	if format.BitsPerSample != 16 {
+		return nil, fmt.Errorf("%s: unsupported format (%d-bit)", audioFilePath, format.BitsPerSample)
+	}
+
+	if code, ok := googleLanguages[language]; ok {
+		language = code
+	}
+	recognitionConfig := map[string]any{
+		"encoding":                   "LINEAR16",
+		"sampleRateHertz":            format.SampleRate,
+		"audioChannelCount":          format.Channels,
+		"languageCode":               language,
+		"maxAlternatives":            max(n, 1),
+		"enableAutomaticPunctuation": true,
+		"enableWordTimeOffsets":      true,
+		"enableWordConfidence":       true,
+	}
+	if g.config.GoogleSTTModel != "" {
+		recognitionConfig["model"] = g.config.GoogleSTTModel
+	}
+	body, err := json.Marshal(map[string]any{
+		"config": recognitionConfig,
+		"audio":  map[string]string{"content": base64.StdEncoding.EncodeToString(pcm)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleSTTURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.project != "" {
+		// User credentials from gcloud need a project to bill the API to
+		req.Header.Set("X-Goog-User-Project", g.project)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Speech-to-Text request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Speech-to-Text response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Speech-to-Text returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result googleRecognizeResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Speech-to-Text response: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,43 @@
+package voice
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gpioRoot is the sysfs GPIO interface
+const gpioRoot = "/sys/class/gpio"
+
+// exportGPIO makes a sysfs GPIO line available as an input ("in") or output ("out")
+func exportGPIO(pin int, direction string) error {
+	dir := filepath.Join(gpioRoot, "gpio"+strconv.Itoa(pin))
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.WriteFile(filepath.Join(gpioRoot, "export"), []byte(strconv.Itoa(pin)), 0); err != nil {
+			return err
+		}
+		// udev needs a moment to hand the new line to the gpio group
+		time.Sleep(200 * time.Millisecond)
+	}
+	return os.WriteFile(filepath.Join(dir, "direction"), []byte(direction), 0)
+}
+
+// setGPIO drives a sysfs GPIO output high or low
+func setGPIO(pin int, on bool) error {
+	value := "0"
+	if on {
+		value = "1"
+	}
+	return os.WriteFile(filepath.Join(gpioRoot, "gpio"+strconv.Itoa(pin), "value"), []byte(value), 0)
+}
+
+// readGPIO reports whether a sysfs GPIO input is high
+func readGPIO(pin int) (bool, error) {
+	value, err := os.ReadFile(filepath.Join(gpioRoot, "gpio"+strconv.Itoa(pin), "value"))
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(value)) == "1", nil
+}
@@ -0,0 +1,146 @@
+package voice
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// hallucinationPatterns match phrases whisper makes up on silence or noise,
+// learned from the subtitles of the videos it was trained on
+var hallucinationPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)subt[íi]tul(os|ado|ación) (realizad[oa]s? |hechos? )?por[^!?]*?(amara\.org|[.!?]|$)[.!?]*`),
+	regexp.MustCompile(`(?i)\bamara\.org\b[.!?]*`),
+	regexp.MustCompile(`(?i)¡?\bgracias por (ver|vernos|su atención|tu atención|mirar)\b[^.!?]*[.!?]*`),
+	regexp.MustCompile(`(?i)¡?\b(no olvides|no te olvides de )?suscr[íi]be(te|rte)\b[^.!?]*[.!?]*`),
+	regexp.MustCompile(`(?i)\bnos vemos en el (próximo|siguiente) (v[íi]deo|episodio)\b[^.!?]*[.!?]*`),
+	regexp.MustCompile(`(?i)\bthank(s| you) (so much )?for watching\b[^.!?]*[.!?]*`),
+	regexp.MustCompile(`(?i)\b(please (like and )?subscribe|like and subscribe|subscribe to (my|the|our) channel)\b[^.!?]*[.!?]*`),
+	regexp.MustCompile(`(?i)\bsee you in the next (video|episode)\b[^.!?]*[.!?]*`),
+	regexp.MustCompile(`(?i)\b(subtitles|captions|transcription) by\b[^.!?]*[.!?]*`),
+	regexp.MustCompile(`(?i)\bsous-titr(es|age)\b[^.!?]*[.!?]*`),
+}
+
+// removeHallucinations strips known whisper hallucinations from a transcript
+func removeHallucinations(text string) string {
+	for _, pattern := range hallucinationPatterns {
+		text = pattern.ReplaceAllString(text, "")
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// whisperJSON is the subset of whisper.cpp's --output-json-full file used to
+// weigh each segment by how confident the model was
+type whisperJSON struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []whisperSegment `json:"transcription"`
+}
+
+// transcript is a whisper transcription and what was left out of it
+type transcript struct {
+	text     string
+	segments []Segment // the segments kept, with their confidence
+	words    []Word    // the words of the segments kept, with their timing
+	turns    []Turn    // the segments kept by speaker, with tinydiarize
+	dropped  []string  // low-confidence segments
+	language string    // the language spoken, detected with "auto"
+}
+
+// whisperSegment is one transcribed segment and its tokens; with
+// tinydiarize, SpeakerTurnNext marks that someone else speaks next
+type whisperSegment struct {
+	Text            string         `json:"text"`
+	Offsets         whisperOffsets `json:"offsets"`
+	SpeakerTurnNext bool           `json:"speaker_turn_next"`
+	Tokens          []whisperToken `json:"tokens"`
+}
+
+// whisperToken is a token, the probability whisper gave it and when it was
+// said
+type whisperToken struct {
+	Text    string         `json:"text"`
+	P       float64        `json:"p"`
+	Offsets whisperOffsets `json:"offsets"`
+}
+
+// whisperOffsets is when a segment or token was said, in milliseconds
+type whisperOffsets struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// reportDropped shows the segments left out for low confidence, so that the
+// user knows what was not heard
+func (t transcript) reportDropped() {
+	if len(t.dropped) > 0 {
+		fmt.Fprintf(Console, "🔇 Dropped low-confidence segments: %q\n", t.dropped)
+	}
+}
+
+// readWhisperJSON reads a whisper.cpp JSON output file
+func readWhisperJSON(path string) (whisperJSON, error) {
+	var output whisperJSON
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return output, err
+	}
+	err = json.Unmarshal(data, &output)
+	return output, err
+}
+
+// transcript joins the segments whose mean token probability reaches
+// minConfidence into a clean transcript
+func (output whisperJSON) transcript(minConfidence float64) transcript {
+	var result transcript
+	var kept []string
+	speaker := 1
+	for _, segment := range output.Transcription {
+		text := strings.TrimSpace(segment.Text)
+		confidence, scored := segment.confidence()
+		if scored && confidence < minConfidence {
+			result.dropped = append(result.dropped, text)
+			speaker = nextSpeaker(speaker, segment.SpeakerTurnNext)
+			continue
+		}
+		result.turns = appendTurn(result.turns, Turn{
+			Speaker: speaker,
+			Text:    text,
+			Start:   time.Duration(segment.Offsets.From) * time.Millisecond,
+			End:     time.Duration(segment.Offsets.To) * time.Millisecond,
+		})
+		speaker = nextSpeaker(speaker, segment.SpeakerTurnNext)
+		kept = append(kept, text)
+		if !scored {
+			confidence = 1
+		}
+		result.segments = append(result.segments, Segment{Text: text, Confidence: confidence})
+		result.words = append(result.words, tokenWords(segment.Tokens)...)
+	}
+	result.text = cleanTranscription(strings.Join(kept, " "))
+	result.language = languageCode(output.Result.Language)
+	return result
+}
+
+// confidence is the mean probability of the segment's text tokens; it
+// reports false for a segment without any
+func (segment whisperSegment) confidence() (float64, bool) {
+	var sum float64
+	var count int
+	for _, token := range segment.Tokens {
+		// Skip special tokens such as [_BEG_] and [_TT_150]
+		if strings.HasPrefix(token.Text, "[_") {
+			continue
+		}
+		sum += token.P
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
@@ -4,26 +4,102 @@ package voice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/chzyer/readline"
+	"github.com/jparrill/bobo-desk-pet/pkg/ambient"
+	"github.com/jparrill/bobo-desk-pet/pkg/calendar"
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
 	"github.com/jparrill/bobo-desk-pet/pkg/claude"
+	"github.com/jparrill/bobo-desk-pet/pkg/cluster"
 	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/contacts"
+	"github.com/jparrill/bobo-desk-pet/pkg/history"
+	"github.com/jparrill/bobo-desk-pet/pkg/hooks"
+	"github.com/jparrill/bobo-desk-pet/pkg/intents"
+	"github.com/jparrill/bobo-desk-pet/pkg/memory"
+	"github.com/jparrill/bobo-desk-pet/pkg/moderation"
+	"github.com/jparrill/bobo-desk-pet/pkg/notify"
+	"github.com/jparrill/bobo-desk-pet/pkg/persona"
+	"github.com/jparrill/bobo-desk-pet/pkg/quota"
+	"github.com/jparrill/bobo-desk-pet/pkg/recordings"
+	"github.com/jparrill/bobo-desk-pet/pkg/skills"
+	"github.com/jparrill/bobo-desk-pet/pkg/telemetry"
+	"github.com/jparrill/bobo-desk-pet/pkg/textutil"
 )
 
+// commandPrompt is the readline prompt, after a status icon
+const commandPrompt = "Command (r/l/p/t/x/s/+/-/q): "
+
 // Interface represents the main voice interface
 type Interface struct {
 	config       *config.Config
 	claudeClient *claude.SmartClient
 	recorder     *AudioRecorder
+	storage      *recordings.Store
 	transcriber  Transcriber
+	wakeASR      Transcriber // what listens for wake words, when not the main transcriber
 	tts          TextToSpeech
+	localTTS     TextToSpeech
+	player       *Player
+	ducker       *Ducker
+	ttsLimited   atomic.Bool
+	quota        *quota.Guard
+	history      *history.Store
+	personas     *persona.Registry
+	skills       *skills.Registry
+	reminders    *skills.RemindersSkill
+	countdowns   *skills.CountdownSkill
+	timers       *skills.TimersSkill
+	twilio       *notify.Twilio
+	push         *notify.Push
+	lastActivity atomic.Int64
+	memory       *memory.Store
+	ambient      *ambient.Engine
+	sounds       *SoundMonitor
+	stream       *StreamRecorder
+	echo         *echoGate
+	partial      atomic.Pointer[string]
+	wake         *WakeWordDetector
+	alarm        *Alarm
+	headset      *HeadsetWatcher
+	presence     *PresenceSensor
+	power        *PowerMonitor
+	absent       atomic.Bool
+	cluster      *cluster.Node
+	satellite    *SatelliteServer
+	captions     *Captions
+	desktop      *notify.Desktop
+	light        *notify.Light
+	intents      *intents.Exporter
+	hooks        *hooks.Runner
+	telemetry    *telemetry.Collector
+	speech       speechState
+	busy         sync.Mutex
+	announced    announcementQueue
+	pending      *pendingQuestion
+	clarifying   *pendingQuestion
+	repair       *pendingQuestion
+	repairedPath string
+	skillVoices  map[string]skillVoice
+	langVoices   map[string]skillVoice
+	languageMu   sync.Mutex
+	language     string // last detected spoken language, guarded by languageMu
+	lastExchange string
+	lastID       string
+	lastAnswer   *history.Interaction // for "write that down"
+	scripted     bool
 	logger       *slog.Logger
 	rl           *readline.Instance
 }
@@ -36,44 +112,101 @@ func New(cfg *config.Config) (*Interface, error) {
 	}, nil
 }
 
+// SetScripted makes Bobo answer text read by RunScript instead of listening:
+// no microphone, speech recognition, voice or prompt are set up
+func (v *Interface) SetScripted(scripted bool) {
+	v.scripted = scripted
+}
+
+// SetTelemetry counts feature usage and latencies in collector, which is
+// nil unless the user opted in
+func (v *Interface) SetTelemetry(collector *telemetry.Collector) {
+	v.telemetry = collector
+}
+
+// Hooks returns the hook runner so plugins can register their own hooks once
+// the interface is initialized
+func (v *Interface) Hooks() *hooks.Runner {
+	return v.hooks
+}
+
 // Initialize initializes all voice interface components
 func (v *Interface) Initialize(ctx context.Context) error {
 	v.logger.Info("🔄 Initializing voice interface...")
 
 	// Initialize speech recognition
 	var err error
-	if v.config.Voice.UseWhisperCpp {
-		v.logger.Info("🔄 Setting up whisper.cpp (fast & lightweight)...")
-		v.transcriber, err = NewWhisperCppTranscriber(v.config.Voice)
+	if v.scripted {
+		v.logger.Info("📜 Scripted mode, answering text from stdin")
+	} else {
+		v.logger.Info("🔄 Setting up speech recognition...", "engine", TranscriberName(v.config))
+		v.transcriber, err = NewTranscriber(v.config)
 		if err != nil {
-			return fmt.Errorf("failed to initialize whisper.cpp: %w", err)
+			return err
 		}
-		v.logger.Info("✅ whisper.cpp ready")
-	} else {
-		// TODO: Implement Python Whisper fallback
-		return fmt.Errorf("Python Whisper not implemented yet, use whisper.cpp")
+		v.logger.Info("✅ Speech recognition ready")
 	}
 
 	// Initialize Claude client
 	v.logger.Info("🔄 Connecting to Claude...")
 	v.claudeClient = claude.NewSmartClient(v.config.VertexAI)
+	if experiment := claude.NewExperiment(v.config.Experiment); experiment != nil {
+		v.claudeClient.SetExperiment(experiment)
+		v.logger.Info("🧪 A/B experiment enabled", "name", v.config.Experiment.Name)
+	}
+	if v.quota = quota.NewGuard(v.config.Quota); v.quota != nil {
+		v.claudeClient.SetQuota(v.quota)
+		v.logger.Info("⛔ Cloud usage caps enabled")
+	}
 	if err := v.claudeClient.Initialize(ctx); err != nil {
 		return fmt.Errorf("failed to initialize Claude client: %w", err)
 	}
 	v.logger.Info("✅ Claude connected")
 
-	// Initialize audio recorder
-	v.logger.Info("🔄 Setting up audio recorder...")
-	v.recorder, err = NewAudioRecorder(v.config.Voice)
+	// Keep this session's recordings together, dropping the expired ones
+	v.storage, err = recordings.NewStore(v.config.Recordings)
 	if err != nil {
-		return fmt.Errorf("failed to initialize audio recorder: %w", err)
+		return err
+	}
+	v.cleanRecordings()
+
+	// Initialize audio recorder
+	if !v.scripted {
+		v.logger.Info("🔄 Setting up audio recorder...")
+		v.recorder, err = NewAudioRecorder(v.config.Voice)
+		if err != nil {
+			return fmt.Errorf("failed to initialize audio recorder: %w", err)
+		}
+		v.recorder.SetRecordings(v.storage)
+		v.logger.Info("✅ Audio recorder ready")
+	}
+
+	// Initialize audio playback
+	if !v.scripted {
+		v.player, err = NewPlayer(v.config.Voice.OutputDevice)
+		if err != nil {
+			v.logger.Warn("Audio playback unavailable", "error", err)
+		}
 	}
-	v.logger.Info("✅ Audio recorder ready")
 
 	// Initialize TTS
-	if v.config.TTS.Enabled {
+	if v.config.TTS.Enabled && !v.scripted {
 		v.logger.Info("🔄 Setting up text-to-speech...")
-		v.tts, err = NewTextToSpeech(v.config.TTS)
+		if v.config.Wyoming.TTSURI != "" {
+			var wyomingTTS *WyomingTTS
+			if wyomingTTS, err = NewWyomingTTS(v.config.Wyoming, v.player); err == nil {
+				wyomingTTS.SetQuota(v.quota)
+				v.tts = wyomingTTS
+			}
+			// Fall back to the local voice once the remote TTS cap is reached
+			if err == nil && v.quota.Limited(quota.TTSCharacters) {
+				if local, localErr := NewTextToSpeech(v.config.TTS); localErr == nil {
+					v.localTTS = local
+				}
+			}
+		} else {
+			v.tts, err = NewTextToSpeech(v.config.TTS)
+		}
 		if err != nil {
 			v.logger.Warn("Failed to initialize TTS", "error", err)
 			v.config.TTS.Enabled = false
@@ -82,10 +215,259 @@ func (v *Interface) Initialize(ctx context.Context) error {
 		}
 	}
 
-	// Initialize readline for proper terminal input handling
-	v.rl, err = readline.New("🎤 Command (r/l/t/x/s/q): ")
+	// Lower the music while Bobo talks
+	if v.config.TTS.Enabled && !v.scripted && v.config.TTS.Ducking != "off" && v.config.TTS.Ducking != "" {
+		if v.ducker, err = NewDucker(v.config.TTS); err != nil {
+			v.logger.Warn("Ducking disabled", "error", err)
+		} else {
+			v.logger.Info("🔉 Ducking other audio while speaking", "mode", v.config.TTS.Ducking)
+		}
+	}
+
+	v.skillVoices = parseVoices(v.config.TTS.SkillVoices, v.logger)
+	v.langVoices = parseVoices(v.config.TTS.LanguageVoices, v.logger)
+
+	// Initialize personas
+	v.personas, err = persona.NewRegistry(v.config.Persona.File, v.config.Persona.Default)
+	if err != nil {
+		return fmt.Errorf("failed to load personas: %w", err)
+	}
+	v.applyPersona(v.personas.Active())
+
+	// Initialize persistent memory and local skills
+	v.memory, err = memory.Open(v.config.Memory.Dir)
 	if err != nil {
-		return fmt.Errorf("failed to initialize readline: %w", err)
+		return fmt.Errorf("failed to open memory store: %w", err)
+	}
+	if err := v.quota.SetStore(v.memory); err != nil {
+		v.logger.Warn("Failed to restore quota usage", "error", err)
+	}
+
+	// Talk as the user asked in earlier sessions ("habla más despacio")
+	v.loadSpeechPreferences()
+
+	v.skills = skills.NewRegistry()
+	tutor := skills.NewTutorSkill(v.claudeClient, v.memory, v.config.Skills.TutorLanguage)
+	v.skills.Register(tutor)
+	v.skills.Register(skills.NewPronunciationSkill(tutor, v.config.Skills.TutorLanguage))
+	v.skills.Register(skills.NewFlashcardsSkill(v.claudeClient, v.memory))
+	v.skills.Register(skills.NewStorySkill(v.claudeClient, v.config.Skills.StoryRate))
+
+	var calendarWriter skills.CalendarWriter
+	if v.config.Calendar.CalDAVURL != "" {
+		calendarWriter = calendar.NewCalDAV(v.config.Calendar)
+		v.logger.Info("📅 Reminders are copied to the CalDAV calendar")
+	}
+	v.reminders = skills.NewRemindersSkill(v.memory, calendarWriter)
+	v.skills.Register(v.reminders)
+	if v.config.Skills.HabitSummary != "" {
+		if day, hour, minute, err := skills.ParseWeeklyTime(v.config.Skills.HabitSummary); err != nil {
+			v.logger.Warn("Weekly habit summary disabled", "error", err)
+		} else {
+			v.reminders.SetWeeklySummary(day, hour, minute)
+		}
+	}
+	v.timers = skills.NewTimersSkill()
+	v.skills.Register(v.timers)
+	v.countdowns = skills.NewCountdownSkill(v.memory)
+	v.skills.Register(v.countdowns)
+
+	// Deliver critical reminders and alarms by SMS/call
+	if v.config.Twilio.AccountSID != "" && v.config.Twilio.From != "" {
+		v.twilio = notify.NewTwilio(v.config.Twilio)
+		v.reminders.SetPhoneDelivery(true)
+		v.logger.Info("📱 Twilio SMS/call delivery enabled")
+	}
+
+	// Push notifications for when the user is away from the desk
+	if push := notify.NewPush(v.config.Push); push.Enabled() {
+		v.push = push
+		v.logger.Info("📲 Push notifications enabled", "ntfy", v.config.Push.NtfyURL != "", "pushover", v.config.Push.PushoverUser != "")
+	}
+
+	// Flash, notify or blink a bulb for alerts that must be seen
+	v.setupAlerts()
+
+	// Resolve the people the user mentions from their contacts
+	directory := contacts.NewDirectory(v.config.Contacts)
+	if err := directory.Load(ctx); err != nil {
+		v.logger.Warn("Failed to load contacts", "error", err)
+	}
+	if directory.Len() > 0 {
+		v.skills.Register(skills.NewContactsSkill(directory))
+		v.reminders.SetContacts(directory)
+	}
+
+	// Third-party skills installed with "bobo skills install"
+	installed, err := skills.NewInstaller(v.config.Skills.Dir, v.config.Skills.IndexURL).Load()
+	if err != nil {
+		v.logger.Warn("Failed to load installed skills", "error", err)
+	}
+	for _, skill := range installed {
+		v.skills.Register(skill)
+	}
+
+	// The user's own skills, written in Starlark
+	scripts, err := skills.LoadScripts(v.config.Skills.ScriptsDir, &skills.ScriptHost{
+		Completer: v.claudeClient,
+		Speak:     v.speak,
+		Store:     v.memory,
+	})
+	if err != nil {
+		v.logger.Warn("Failed to load script skills", "error", err)
+	}
+	for _, skill := range scripts {
+		v.skills.Register(skill)
+	}
+	v.logger.Info("🧩 Skills ready", "skills", strings.Join(v.skills.Names(), ", "))
+
+	// Share memory with other Bobo instances when configured
+	if v.config.Sync.Listen != "" || len(v.config.Sync.Peers) > 0 {
+		v.cluster = cluster.NewNode(v.config.Sync, v.memory)
+		v.logger.Info("🔗 Multi-instance sync enabled", "instance", v.config.Sync.Instance, "peers", len(v.config.Sync.Peers))
+	}
+
+	// Accept utterances from satellite microphones
+	if v.config.Satellite.Listen != "" {
+		v.satellite = NewSatelliteServer(v.config.Satellite, v.tts, SatelliteHandlers{
+			Transcribe: v.transcribe,
+			Answer:     v.answerText,
+			Recognize:  v.recognize,
+		})
+		v.satellite.SetRecordings(v.storage)
+	}
+
+	// Caption the conversation for those who can't hear it
+	if v.config.Captions.File != "" || v.config.Captions.Listen != "" {
+		if v.captions, err = NewCaptions(v.config.Captions); err != nil {
+			v.logger.Warn("Captions disabled", "error", err)
+		}
+	}
+
+	// Publish recognized intents to Rhasspy/openHAB setups
+	if v.config.Intents.HTTPURL != "" || v.config.Intents.MQTTBroker != "" {
+		v.intents = intents.NewExporter(v.config.Intents)
+		v.logger.Info("📤 Intent export enabled", "http", v.config.Intents.HTTPURL != "", "mqtt", v.config.Intents.MQTTBroker)
+	}
+
+	// Let plugins and user scripts rewrite or veto text along the way
+	v.hooks = hooks.NewRunner(v.config.Hooks)
+	if v.config.Moderation.Enabled {
+		filter, err := moderation.NewFilter(v.config.Moderation, v.claudeClient)
+		if err != nil {
+			return fmt.Errorf("failed to set up moderation: %w", err)
+		}
+		v.hooks.Register(hooks.BeforeSpeak, filter.Hook)
+		v.logger.Info("🚫 Moderation enabled", "categories", v.config.Moderation.Categories)
+	}
+	if n := v.hooks.Len(); n > 0 {
+		v.logger.Info("🪝 Hooks enabled", "hooks", n)
+	}
+
+	// Initialize conversation history
+	if v.config.History.Enabled {
+		v.history, err = history.NewStore(v.config.History.Dir)
+		if err != nil {
+			v.logger.Warn("Failed to initialize conversation history", "error", err)
+		} else if err := history.CheckRetention(v.config.History.Retention); err != nil {
+			return err
+		}
+	}
+
+	// Show what whisper makes of the recording while it is made (opt-in);
+	// Deepgram always gets the recording as it is made, which is what makes
+	// its transcript ready as soon as the user stops
+	streams := TranscriberName(v.config) == "deepgram"
+	if (v.config.Voice.PartialTranscripts || streams) && !v.scripted {
+		if _, ok := TranscriberAs[StreamingTranscriber](v.transcriber); ok {
+			v.recorder.SetFollower(v.followTranscript)
+			v.logger.Info("💬 Live transcription enabled", "shown", v.config.Voice.PartialTranscripts)
+		} else {
+			v.logger.Warn("Live transcription is not supported by the speech-to-text engine", "engine", TranscriberName(v.config))
+		}
+	}
+
+	// Don't let the open microphone hear Bobo talking
+	if v.config.Voice.MuteWhileSpeaking {
+		v.echo = &echoGate{}
+	}
+
+	// Keep the microphone open so that recordings start instantly (opt-in,
+	// and needed by the wake word and the pre-roll)
+	if (v.config.Voice.StreamCapture || v.config.WakeWord.Enabled || v.config.Voice.PreRollMillis > 0) && !v.scripted {
+		v.stream = NewStreamRecorder(v.config.Voice, v.recorder)
+		v.recorder.SetStream(v.stream)
+		v.stream.SetEchoGate(v.echo)
+		v.logger.Info("🎙️ Continuous capture enabled", "buffer_seconds", v.config.Voice.StreamBufferSeconds)
+	}
+
+	// Listen for the wake word (opt-in)
+	if v.config.WakeWord.Enabled && v.stream != nil {
+		if err := v.setupWakeWord(); err != nil {
+			v.logger.Warn("Wake word disabled", "error", err)
+		} else {
+			v.logger.Info("👂 Wake word enabled", "phrases", v.wakePhrases(v.personas.Active()))
+		}
+	}
+
+	// Initialize the non-speech sound monitor (opt-in)
+	if v.config.Sound.Enabled {
+		v.sounds = NewSoundMonitor(v.config.Sound, v.recorder)
+		v.sounds.SetEchoGate(v.echo)
+		v.logger.Info("👂 Sound monitor enabled", "webhook", v.config.Sound.WebhookURL != "")
+	}
+
+	// Ring reminders on a buzzer or dedicated speaker when configured
+	if v.config.Alarm.GPIOPin >= 0 || v.config.Alarm.AudioDevice != "" {
+		v.alarm, err = NewAlarm(v.config.Alarm)
+		if err != nil {
+			v.logger.Warn("Alarm buzzer disabled", "error", err)
+		} else {
+			v.logger.Info("⏰ Alarm enabled", "gpio", v.config.Alarm.GPIOPin, "device", v.config.Alarm.AudioDevice)
+		}
+	}
+
+	// Switch to lighter settings on battery
+	if v.config.Power.Enabled {
+		v.power = NewPowerMonitor(v.config.Power)
+	}
+
+	// Follow the Bluetooth headset when configured
+	if v.config.Bluetooth.Headset != "" {
+		v.headset, err = NewHeadsetWatcher(v.config.Bluetooth, v.recorder)
+		if err != nil {
+			v.logger.Warn("Bluetooth headset switching disabled", "error", err)
+		} else {
+			v.headset.SetPower(v.power)
+			v.logger.Info("🎧 Watching Bluetooth headset", "device", v.config.Bluetooth.Headset)
+		}
+	}
+
+	// Pause listening while nobody is at the desk
+	if p := v.config.Presence; p.Bluetooth != "" || p.Host != "" || p.PIRPin >= 0 {
+		v.presence, err = NewPresenceSensor(p)
+		if err != nil {
+			v.logger.Warn("Presence detection disabled", "error", err)
+		} else {
+			v.presence.SetPower(v.power)
+			v.logger.Info("👀 Presence detection enabled", "bluetooth", p.Bluetooth, "host", p.Host, "pir_pin", p.PIRPin)
+		}
+	}
+
+	// Initialize idle presence behaviors (opt-in)
+	if v.config.Ambient.Enabled {
+		v.ambient = ambient.NewEngine(v.config.Ambient)
+		v.logger.Info("🐾 Ambient behaviors enabled", "idle_minutes", v.config.Ambient.IdleMinutes)
+	}
+
+	// Initialize readline for proper terminal input handling
+	if !v.scripted {
+		v.rl, err = readline.New("🎤 " + commandPrompt)
+		if err != nil {
+			return fmt.Errorf("failed to initialize readline: %w", err)
+		}
+		Console.SetOutput(v.rl.Stdout())
+		v.recorder.SetLevelMeter(v.showInputLevel)
 	}
 
 	v.logger.Info("🎉 Voice interface ready!")
@@ -97,9 +479,16 @@ func (v *Interface) Run(ctx context.Context) error {
 	v.logger.Info("🎯 Commands:")
 	v.logger.Info("  • 'r' + ENTER: Record and process voice (7 seconds)")
 	v.logger.Info("  • 'l' + ENTER: Long recording (12 seconds)")
+	if v.config.Voice.PushToTalk {
+		v.logger.Info("  • Hold SPACE: Push to talk, release to stop (or tap to start and stop)")
+	}
+	v.logger.Info("  • 'p <file>' + ENTER: Process an existing audio file")
+	v.logger.Info("  • 'm <model>' + ENTER: Switch the whisper model (tiny, base, small, medium)")
 	v.logger.Info("  • 't' + ENTER: Test microphone levels")
 	v.logger.Info("  • 'x' + ENTER: Test TTS voice")
 	v.logger.Info("  • 's' + ENTER: Toggle speech", "currently", map[bool]string{true: "ON", false: "OFF"}[v.config.TTS.Enabled])
+	v.logger.Info("  • '+' / '-' + ENTER: Rate the last answer 👍/👎")
+	v.logger.Info("  • ENTER while Bobo talks: Cut a long answer short (say 'sigue' to go on)")
 	v.logger.Info("  • 'q' + ENTER: Quit")
 
 	statusMsg := "Disabled"
@@ -108,11 +497,7 @@ func (v *Interface) Run(ctx context.Context) error {
 	}
 	v.logger.Info("🔊 TTS", "status", statusMsg)
 
-	recognition := "Python Whisper"
-	if v.config.Voice.UseWhisperCpp {
-		recognition = "whisper.cpp"
-	}
-	v.logger.Info("🎤 Speech Recognition", "engine", recognition)
+	v.logger.Info("🎤 Speech Recognition", "engine", TranscriberName(v.config))
 
 	// Create context that cancels on interrupt
 	ctx, cancel := context.WithCancel(ctx)
@@ -127,6 +512,94 @@ func (v *Interface) Run(ctx context.Context) error {
 		cancel()
 	}()
 
+	v.touch()
+
+	// Sync memory with the other instances
+	if v.cluster != nil {
+		go v.cluster.Run(ctx)
+	}
+
+	// Drop recordings and transcripts past their retention
+	go v.runJanitor(ctx)
+
+	// Announce reminders when they are due, and everything else Bobo has to
+	// say on its own at the next pause
+	go v.runReminders(ctx)
+	go v.runTimers(ctx)
+	if v.config.Alerts.Weather {
+		go v.runWeatherAlerts(ctx)
+	}
+	go v.runAnnouncements(ctx)
+
+	// Serve satellite microphones
+	if v.satellite != nil {
+		go v.satellite.Run(ctx)
+	}
+	go v.captions.Run(ctx)
+
+	// Start idle presence behaviors
+	if v.ambient != nil {
+		go v.ambient.Run(ctx, func(behavior ambient.Behavior) {
+			v.showBehavior(ctx, behavior)
+		})
+	}
+
+	// Capture continuously into the ring buffer
+	if v.stream != nil {
+		go v.stream.Run(ctx)
+	}
+
+	// Answer when called by the wake word
+	if v.wake != nil {
+		go v.wake.Run(ctx, func(wake WakeWord) {
+			v.wakeUp(ctx, wake)
+		})
+	}
+
+	// Start listening for doorbells, alarms and loud noises
+	if v.sounds != nil {
+		go v.sounds.Run(ctx, func(event SoundEvent) {
+			v.announceSound(ctx, event)
+		})
+	}
+
+	// Re-route audio when the Bluetooth headset comes and goes
+	if v.headset != nil {
+		go v.headset.Run(ctx, func(connected bool) {
+			if v.sounds != nil {
+				v.sounds.Restart()
+			}
+			if v.stream != nil {
+				v.stream.Restart()
+			}
+			if connected {
+				fmt.Fprintln(v.rl.Stdout(), "\n  🎧 Using the Bluetooth headset")
+			} else {
+				fmt.Fprintln(v.rl.Stdout(), "\n  🔈 Headset disconnected, back to the default devices")
+			}
+		})
+	}
+
+	// Save power while the laptop runs on battery
+	if v.power != nil {
+		go v.power.Run(ctx, v.setPowerSaving)
+	}
+
+	// Pause and resume listening as people leave and come back
+	if v.presence != nil {
+		go v.presence.Run(ctx, v.setPresent)
+	}
+
+	// Record while SPACE is held at the empty prompt
+	if v.config.Voice.PushToTalk {
+		v.rl.Config.Listener = newPushToTalk(func(release <-chan struct{}) {
+			v.touch()
+			if err := v.processVoiceCommand(ctx, v.config.Voice.PushToTalkMaxSeconds, release); err != nil {
+				v.logger.Error("Push-to-talk failed", "error", err)
+			}
+		})
+	}
+
 	// Note: Using readline for proper terminal input handling
 
 	for {
@@ -148,20 +621,59 @@ func (v *Interface) Run(ctx context.Context) error {
 				return fmt.Errorf("error reading input: %w", err)
 			}
 
+			// Any input silences a ringing alarm
+			if v.alarm.Acknowledge() {
+				v.logger.Info("🔕 Alarm stopped")
+				continue
+			}
+
+			// ...or cuts a long answer short, to be resumed with "sigue"
+			if v.speech.Interrupt() {
+				v.logger.Info("⏸️ Answer interrupted")
+				continue
+			}
+
 			// Clean and validate command
 			command := strings.TrimSpace(strings.ToLower(line))
+			if command != "" {
+				v.touch()
+			}
+			if v.ambient != nil && command != "" {
+				v.ambient.Touch()
+			}
 
+			// "p <file>" keeps the path as typed
+			if file, ok := strings.CutPrefix(strings.TrimSpace(line), "p "); ok {
+				if err := v.ProcessFile(ctx, strings.TrimSpace(file)); err != nil {
+					v.logger.Error("Processing the audio file failed", "error", err)
+				}
+				continue
+			}
+
+			if name, ok := strings.CutPrefix(command, "m "); ok {
+				if err := v.switchModel(ctx, strings.TrimSpace(name)); err != nil {
+					v.logger.Error("Switching the whisper model failed", "error", err)
+				}
+				continue
+			}
+
+			// Voice commands run off this loop, so that ENTER can cut their
+			// answer short; the busy lock keeps them one at a time
 			switch command {
 			case "r":
-				if err := v.processVoiceCommand(ctx, 7); err != nil {
-					v.logger.Error("Voice command failed", "error", err)
-				}
+				go func() {
+					if err := v.processVoiceCommand(ctx, 7, nil); err != nil {
+						v.logger.Error("Voice command failed", "error", err)
+					}
+				}()
 
 			case "l":
 				v.logger.Info("🎤 Long recording mode...")
-				if err := v.processVoiceCommand(ctx, 12); err != nil {
-					v.logger.Error("Long voice command failed", "error", err)
-				}
+				go func() {
+					if err := v.processVoiceCommand(withDiarization(ctx), 12, nil); err != nil {
+						v.logger.Error("Long voice command failed", "error", err)
+					}
+				}()
 
 			case "t":
 				v.logger.Info("🎤 Testing microphone...")
@@ -180,6 +692,13 @@ func (v *Interface) Run(ctx context.Context) error {
 				status := map[bool]string{true: "ON", false: "OFF"}[v.config.TTS.Enabled]
 				v.logger.Info("🔊 TTS toggled", "status", status)
 
+			case "+", "-":
+				feedback := history.FeedbackPositive
+				if command == "-" {
+					feedback = history.FeedbackNegative
+				}
+				v.recordFeedback(feedback)
+
 			case "q":
 				v.logger.Info("👋 Goodbye!")
 				return nil
@@ -188,16 +707,45 @@ func (v *Interface) Run(ctx context.Context) error {
 				continue
 
 			default:
-				v.logger.Warn("❓ Unknown command", "command", command, "available", "r/l/t/x/s/q")
+				v.logger.Warn("❓ Unknown command", "command", command, "available", "r/l/p/m/t/x/s/+/-/q")
 			}
 		}
 	}
 }
 
-// processVoiceCommand handles voice recording, transcription, and Claude interaction
-func (v *Interface) processVoiceCommand(ctx context.Context, durationSeconds int) error {
+// processVoiceCommand handles voice recording, transcription, and Claude
+// interaction; with push-to-talk the recording ends when release is closed
+func (v *Interface) processVoiceCommand(ctx context.Context, durationSeconds int, release <-chan struct{}) error {
+	v.busy.Lock()
+	defer v.busy.Unlock()
+
+	if release != nil {
+		v.telemetry.Count("push_to_talk")
+	}
+
+	// Keep idle behaviors quiet while we listen and answer
+	if v.ambient != nil {
+		v.ambient.SetPaused(true)
+		defer func() {
+			v.ambient.Touch()
+			v.ambient.SetPaused(v.absent.Load())
+		}()
+	}
+
+	// Release the microphone from the sound monitor while recording
+	if v.sounds != nil {
+		v.sounds.SetPaused(true)
+		defer func() { v.sounds.SetPaused(v.listeningPaused()) }()
+	}
+
+	// Don't take Bobo's own answer for the wake word
+	if v.wake != nil {
+		v.wake.SetPaused(true)
+		defer func() { v.wake.SetPaused(v.listeningPaused()) }()
+	}
+
 	// Record audio
-	success, err := v.recorder.RecordAudio(ctx, durationSeconds)
+	success, err := v.recorder.recordAudio(ctx, durationSeconds, release, nil)
 	if err != nil {
 		return fmt.Errorf("recording failed: %w", err)
 	}
@@ -206,67 +754,957 @@ func (v *Interface) processVoiceCommand(ctx context.Context, durationSeconds int
 		return nil
 	}
 
+	if v.recorder.AudioFilePath == "" {
+		return fmt.Errorf("no audio file to process")
+	}
+
+	// Let the nearest instance answer when several of them heard the user
+	if v.cluster != nil {
+		if level, err := wavLevelDB(v.recorder.AudioFilePath); err == nil && !v.cluster.Claim(ctx, level) {
+			return nil
+		}
+	}
+
 	// Process the recorded audio
-	return v.processAudio(ctx)
+	return v.processAudio(ctx, v.recorder.AudioFilePath)
 }
 
-// processAudio transcribes audio and gets Claude's response
-func (v *Interface) processAudio(ctx context.Context) error {
-	if v.recorder.AudioFilePath == "" {
-		return fmt.Errorf("no audio file to process")
+// answerText answers an utterance from a satellite, Home Assistant or a
+// script, returning the spoken answer instead of playing it on the local speakers
+func (v *Interface) answerText(ctx context.Context, transcription, audioPath string) (string, error) {
+	reply, err := v.answer(ctx, transcription, audioPath)
+	if err != nil {
+		return "", err
 	}
+	return strings.Join(reply.parts, " "), nil
+}
+
+// answer answers an utterance, collecting what would be said and shown
+func (v *Interface) answer(ctx context.Context, transcription, audioPath string) (*satelliteReply, error) {
+	v.busy.Lock()
+	defer v.busy.Unlock()
 
+	v.touch()
+	if v.ambient != nil {
+		v.ambient.Touch()
+	}
+
+	reply := &satelliteReply{}
+	if err := v.respond(context.WithValue(ctx, satelliteReplyKey{}, reply), transcription, audioPath); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// recognize maps text to the local skill that would handle it
+func (v *Interface) recognize(text string) (string, map[string]string, bool) {
+	skill, req := v.skills.Match(text)
+	if skill == nil {
+		return "", nil, false
+	}
+	return skill.Name(), req.Slots, true
+}
+
+// satelliteReplyKey marks contexts whose answers go back to a satellite
+type satelliteReplyKey struct{}
+
+// satelliteReply collects what Bobo says and shows while answering a satellite
+type satelliteReply struct {
+	parts []string
+	cards []*card.Card
+}
+
+// processAudio transcribes audio and gets Claude's response, then listens
+// for a follow-up
+func (v *Interface) processAudio(ctx context.Context, audioPath string) error {
 	v.logger.Info("🔄 Processing audio...")
 
-	// Transcribe audio
+	transcription, err := v.transcribe(ctx, audioPath)
+	if errors.Is(err, errMisheard) {
+		return v.listenAgain(ctx)
+	}
+	if err != nil || transcription == "" {
+		return err
+	}
+	if err := v.respond(ctx, transcription, audioPath); err != nil {
+		return err
+	}
+	return v.followUp(ctx)
+}
+
+// transcribe turns recorded audio into text ("" when no speech was detected)
+func (v *Interface) transcribe(ctx context.Context, audioPath string) (string, error) {
+	// Archived recordings (FLAC, OGG) are decoded for the transcriber
+	audioPath, cleanup, err := decodeRecording(ctx, audioPath)
+	if err != nil {
+		return "", fmt.Errorf("transcription failed: %w", err)
+	}
+	defer cleanup()
+
+	// Transcribe a cleaned-up copy, keeping the original recording for the history
+	if preprocessing(v.config.Voice) {
+		processed := processedPath(audioPath)
+		err := preprocessRecording(audioPath, processed, v.config.Voice)
+		if errors.Is(err, errNoSpeech) {
+			v.logger.Warn("❌ No speech detected", "threshold_db", v.config.Voice.SilenceThresholdDB)
+			return "", nil
+		}
+		if err != nil {
+			v.logger.Warn("Audio preprocessing failed, using the original recording", "error", err)
+		} else {
+			defer os.Remove(processed)
+			audioPath = processed
+		}
+	}
+
 	v.logger.Info("🔄 Transcribing...")
-	transcription, err := v.transcriber.Transcribe(ctx, v.recorder.AudioFilePath, "es")
+	start := time.Now()
+	var transcription string
+	labelled := v.diarizing(ctx)
+	if labelled {
+		var turns []Turn
+		turns, err = v.transcriber.(SpeakerTranscriber).TranscribeSpeakers(ctx, audioPath, v.transcriptionLanguage())
+		transcription = speakersText(turns)
+	} else if language := v.transcriptionLanguage(); language == autoLanguage {
+		var detected string
+		transcription, detected, err = v.transcriber.(LanguageTranscriber).TranscribeLanguage(ctx, audioPath)
+		v.useLanguage(detected)
+	} else if text, chunked, chunkErr := v.transcribeChunked(ctx, audioPath, language); chunked {
+		// Long recordings are split up; their chunks aren't scored
+		transcription, err = text, chunkErr
+	} else if scorer, ok := TranscriberAs[ConfidenceTranscriber](v.transcriber); ok && v.config.Voice.RejectConfidence > 0 {
+		transcription, err = v.transcribeScored(ctx, scorer, audioPath, language)
+	} else {
+		transcription, err = v.transcriber.Transcribe(ctx, audioPath, language)
+	}
+	if errors.Is(err, errMisheard) {
+		return "", err
+	}
 	if err != nil {
-		return fmt.Errorf("transcription failed: %w", err)
+		return "", fmt.Errorf("transcription failed: %w", err)
 	}
+	v.telemetry.Observe("transcription", time.Since(start))
 
-	transcription = strings.TrimSpace(transcription)
+	// Labelled transcripts were cleaned up turn by turn, keeping their lines
+	if !labelled {
+		transcription = removeHallucinations(transcription)
+	}
 	if transcription == "" {
 		v.logger.Warn("❌ No speech detected")
-		return nil
+		return "", nil
 	}
 
 	v.logger.Info("👤 You said", "transcription", transcription)
+	v.captions.User(transcription, false)
+	return transcription, nil
+}
+
+// archiveRecording converts a WAV recording to RecordingFormat once it has
+// been transcribed, returning the path to keep in the history
+func (v *Interface) archiveRecording(ctx context.Context, audioPath string) string {
+	if audioPath == "" || v.config.Voice.RecordingFormat == "wav" {
+		return audioPath
+	}
+	archived, err := encodeRecording(ctx, audioPath, v.config.Voice.RecordingFormat)
+	if err != nil {
+		v.logger.Warn("Failed to convert the recording, keeping the WAV", "format", v.config.Voice.RecordingFormat, "error", err)
+	}
+	return archived
+}
+
+// transcriptionLanguage is the language whisper should expect, "es" unless an
+// active skill asks for another one
+func (v *Interface) transcriptionLanguage() string {
+	if hint := v.skills.TranscriptionLanguage(); hint != "" {
+		return hint
+	}
+	if language := v.config.Voice.Language; language != "" && (language != autoLanguage || v.detectsLanguage()) {
+		return language
+	}
+	return defaultLanguage
+}
+
+// pendingQuestion is a Claude request waiting for the user to confirm its cost,
+// to give a missing detail or to accept another reading of a misheard question
+type pendingQuestion struct {
+	transcription string
+	audioPath     string
+	slot          string
+}
+
+// maxClarificationWords is the longest reply taken as the answer to a
+// clarifying question; longer ones are handled as a new request
+const maxClarificationWords = 5
+
+// respond answers a transcribed utterance with a local command, a skill or Claude
+func (v *Interface) respond(ctx context.Context, transcription, audioPath string) error {
+	audioPath = v.archiveRecording(ctx, audioPath)
+
+	transcription, ok := v.hooks.Run(ctx, hooks.OnTranscript, transcription, "")
+	if !ok {
+		return nil
+	}
+
+	// Answer to "¿sigo?" after a cost estimate
+	if pending := v.pending; pending != nil {
+		v.pending = nil
+		if confirmed, ok := parseConfirmation(transcription); ok {
+			if !confirmed {
+				v.speak(ctx, "Vale, lo dejo.")
+				return nil
+			}
+			return v.ask(ctx, pending.transcription, pending.audioPath, true)
+		}
+	}
+
+	// Answer to "¿Quisiste decir...?" after a garbled transcript
+	if pending := v.repair; pending != nil {
+		v.repair = nil
+		if confirmed, ok := parseConfirmation(transcription); ok {
+			if !confirmed {
+				v.speak(ctx, "Vale. ¿Me lo repites?")
+				return nil
+			}
+			v.repairedPath = pending.audioPath
+			return v.ask(ctx, pending.transcription, pending.audioPath, false)
+		}
+	}
+
+	// Answer to a clarifying question ("¿De qué ciudad?"), merged into the request
+	if pending := v.clarifying; pending != nil {
+		v.clarifying = nil
+		// "No" drops the request; "sí, Madrid" answers it
+		if confirmed, ok := parseConfirmation(transcription); ok && !confirmed {
+			v.speak(ctx, "Vale, lo dejo.")
+			return nil
+		}
+		if len(strings.Fields(transcription)) <= maxClarificationWords {
+			merged := v.claudeClient.MergeClarification(pending.transcription, pending.slot, transcription)
+			v.logger.Info("🧩 Clarified request", "request", merged)
+			return v.ask(ctx, merged, pending.audioPath, false)
+		}
+	}
+
+	// Answer usage summary requests locally
+	if isDailySummaryRequest(transcription) {
+		return v.speakDailySummary(ctx)
+	}
+
+	// Persona switch ("talk to me as the butler")
+	if name, ok := persona.ParseSwitch(transcription); ok && v.personas.Find(name) != nil {
+		return v.switchPersona(ctx, name)
+	}
+
+	// "Eso no era para ti" after a false wake-up
+	if v.wake != nil && isWakeRejection(transcription) {
+		return v.rejectWakeUp(ctx)
+	}
+
+	// "Sigue" after cutting a long answer short; otherwise it's a question
+	// like any other ("¿qué más?")
+	if isResumeRequest(transcription) && v.speech.pending() {
+		return v.resume(ctx)
+	}
+
+	// "Habla más despacio", saved for the next sessions too
+	if adjustment, ok := parseSpeechAdjustment(transcription); ok {
+		return v.adjustSpeech(ctx, adjustment)
+	}
+
+	// "Olvida los últimos 10 minutos"
+	if period, ok := history.ParseForget(transcription); ok {
+		return v.forget(ctx, period, audioPath)
+	}
+
+	// "Apunta eso" saves the previous answer to the notes directory
+	if whole, ok := parseWriteDown(transcription); ok {
+		return v.writeDown(ctx, whole)
+	}
+
+	// Spoken feedback about the previous answer
+	if feedback := detectFeedback(transcription); feedback != "" {
+		v.recordFeedback(feedback)
+		return nil
+	}
+
+	// Compound requests ("recuérdame llamar a mamá y dime el tiempo") are
+	// answered part by part
+	if parts := v.splitIntents(transcription); len(parts) > 1 {
+		return v.respondEach(ctx, parts, audioPath)
+	}
+	return v.handle(ctx, transcription, audioPath)
+}
+
+// handle answers a single request with a local skill or Claude
+func (v *Interface) handle(ctx context.Context, transcription, audioPath string) error {
+	// Local skills take precedence over a free-form conversation
+	if skill, req := v.skills.Match(transcription); skill != nil {
+		// Installed skills reach home automation only when approved to
+		if scoped, ok := skill.(skills.Scoped); !ok || scoped.Policy().Allows(skills.PermissionHomeControl) {
+			v.exportIntent(skill.Name(), req.Slots, transcription)
+		}
+		return v.runSkill(ctx, skill, req, audioPath)
+	}
+
+	return v.ask(ctx, transcription, audioPath, false)
+}
+
+// ask sends an utterance to Claude, first asking for missing details and for
+// confirmation of requests whose estimated cost exceeds the configured threshold
+func (v *Interface) ask(ctx context.Context, transcription, audioPath string, confirmed bool) error {
+	if question, slot := v.claudeClient.Clarification(transcription); question != "" {
+		v.logger.Info("❓ Asking for a missing detail", "slot", slot)
+		v.clarifying = &pendingQuestion{transcription: transcription, audioPath: audioPath, slot: slot}
+		v.speak(ctx, question)
+		return nil
+	}
 
-	// Send to Claude
-	v.logger.Info("🤖 Claude is thinking...")
 	messages := []claude.Message{
 		{Role: "user", Content: transcription},
 	}
 
-	response, err := v.claudeClient.SendMessage(ctx, messages)
+	if threshold := v.config.VertexAI.ConfirmCostAbove; threshold > 0 && !confirmed {
+		estimate := v.claudeClient.Estimate(messages)
+		if cost := estimate.Cost(v.config.VertexAI.InputTokenPrice, v.config.VertexAI.OutputTokenPrice); cost > threshold {
+			v.logger.Info("💰 Expensive request, asking for confirmation",
+				"estimated_cost", fmt.Sprintf("$%.4f", cost),
+				"input_tokens", estimate.InputTokens,
+				"output_tokens", estimate.OutputTokens,
+			)
+			v.pending = &pendingQuestion{transcription: transcription, audioPath: audioPath}
+			v.speak(ctx, fmt.Sprintf("Esto puede costar hasta %.2f dólares. ¿Sigo?", cost))
+			return nil
+		}
+	}
+
+	// Let hooks rewrite or veto the question before it is sent
+	question, ok := v.hooks.Run(ctx, hooks.BeforeLLM, transcription, "")
+	if !ok {
+		return nil
+	}
+	messages[0].Content = question
+
+	// Send to Claude
+	v.logger.Info("🤖 Claude is thinking...")
+
+	startTime := time.Now()
+	answer, err := v.claudeClient.Ask(ctx, messages)
+	latency := time.Since(startTime)
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		// Degraded mode: only local skills until the cap resets
+		v.logger.Warn("⛔ Claude unavailable", "error", err)
+		v.speak(ctx, quotaNotice(exceeded))
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("Claude request failed: %w", err)
 	}
 
+	response := answer.Text
 	if response == "" {
 		v.logger.Warn("❌ Claude didn't respond")
 		return nil
 	}
+	if errors.As(answer.Degraded, &exceeded) {
+		response += " " + quotaNotice(exceeded)
+	}
+	if response, ok = v.hooks.Run(ctx, hooks.AfterLLM, response, transcription); !ok {
+		return nil
+	}
 
 	v.logger.Info("🎯 Claude", "response", response)
+	v.telemetry.Count(claudeFeature(answer.Intent))
+	v.telemetry.Observe("claude", latency)
+	v.exportIntent(answer.Intent, nil, transcription)
+	if answer.Variant != "" {
+		v.logger.Info("🧪 Variant result",
+			"variant", answer.Variant,
+			"latency", latency.Round(time.Millisecond),
+			"output_tokens", answer.Usage.OutputTokens,
+			"response_chars", len([]rune(response)),
+		)
+	}
+
+	// Record the exchange in the conversation history
+	v.recordInteraction(&history.Interaction{
+		Transcription: transcription,
+		Response:      response,
+		AudioFile:     audioPath,
+		Sources:       answer.Sources,
+		Intent:        answer.Intent,
+		InputTokens:   answer.Usage.InputTokens,
+		OutputTokens:  answer.Usage.OutputTokens,
+		Cost:          answer.Usage.Cost(v.config.VertexAI.InputTokenPrice, v.config.VertexAI.OutputTokenPrice),
+		LatencyMs:     latency.Milliseconds(),
+		Variant:       answer.Variant,
+	})
+
+	// A confused answer usually means whisper misheard: offer another reading
+	// of the recording instead of answering the garbled question
+	if v.config.Voice.RepairTranscripts && audioPath != v.repairedPath && isConfusedReply(response) {
+		if candidate := v.repairCandidate(ctx, transcription, audioPath); candidate != "" {
+			v.logger.Info("🩹 Offering another transcription", "original", transcription, "candidate", candidate)
+			v.repair = &pendingQuestion{transcription: candidate, audioPath: audioPath}
+			v.speak(ctx, fmt.Sprintf("No te he entendido bien. ¿Quisiste decir «%s»?", candidate))
+			return nil
+		}
+	}
+	v.lastExchange = transcription + " " + response
 
 	// Speak response if TTS is enabled
+	v.showCard(ctx, answer.Card)
+	v.speak(ctx, response)
+	v.pushSlowAnswer(transcription, response, latency)
+
+	return nil
+}
+
+// showCard draws structured details of an answer at the prompt; answers to a
+// satellite or script keep them for it instead
+func (v *Interface) showCard(ctx context.Context, c *card.Card) {
+	if c == nil {
+		return
+	}
+	if reply, ok := ctx.Value(satelliteReplyKey{}).(*satelliteReply); ok {
+		reply.cards = append(reply.cards, c)
+		return
+	}
+	c.Render(Console)
+}
+
+// speak says text aloud when TTS is enabled, logging failures; answers to a
+// satellite are collected for it instead
+func (v *Interface) speak(ctx context.Context, text string) {
+	text, ok := v.hooks.Run(ctx, hooks.BeforeSpeak, text, "")
+	if !ok {
+		return
+	}
+	if sentences := splitSentences(text); len(sentences) >= longAnswerSentences && v.config.TTS.Enabled && ctx.Value(satelliteReplyKey{}) == nil {
+		v.sayResumable(ctx, sentences)
+		return
+	}
+	v.say(ctx, text)
+}
+
+// say speaks text that has already been through the BeforeSpeak hooks
+func (v *Interface) say(ctx context.Context, text string) {
+	if reply, ok := ctx.Value(satelliteReplyKey{}).(*satelliteReply); ok {
+		reply.parts = append(reply.parts, text)
+		return
+	}
+	v.captions.Bobo(text)
 	if v.config.TTS.Enabled && v.tts != nil {
-		if err := v.tts.Speak(ctx, response); err != nil {
+		v.echo.begin()
+		defer v.echo.end()
+		v.ducker.Duck(ctx)
+		defer v.ducker.Release()
+
+		err := v.tts.Speak(ctx, text)
+		var exceeded *quota.ExceededError
+		if errors.As(err, &exceeded) && v.localTTS != nil {
+			if !v.ttsLimited.Swap(true) {
+				v.logger.Warn("⛔ Remote TTS unavailable, using the local voice", "error", err)
+				text = quotaNotice(exceeded) + " " + text
+			}
+			err = v.localTTS.Speak(ctx, text)
+		} else if err == nil {
+			v.ttsLimited.Store(false)
+		}
+		if err != nil && ctx.Err() == nil {
 			v.logger.Warn("TTS failed", "error", err)
 		}
+
+		// Bobo's voice is no pre-roll for the next recording
+		v.stream.ClearPreRoll()
+	}
+}
+
+// quotaNotice tells the user which cloud cap was reached and what Bobo does instead
+func quotaNotice(err *quota.ExceededError) string {
+	period := "esta hora"
+	if err.Window == "day" {
+		period = "hoy"
+	}
+
+	switch err.Resource {
+	case quota.Searches:
+		return fmt.Sprintf("(No he buscado en internet: ya he hecho todas las búsquedas permitidas por %s.)", period)
+	case quota.TTSCharacters:
+		return fmt.Sprintf("He gastado la voz en la nube por %s, así que uso mi voz local.", period)
+	default:
+		return fmt.Sprintf("He llegado al límite de uso de Claude por %s. Hasta las %s solo puedo usar mis habilidades locales.", period, err.Reset.Format("15:04"))
+	}
+}
+
+// isDailySummaryRequest checks whether the user is asking how the day went
+func isDailySummaryRequest(transcription string) bool {
+	text := strings.ToLower(transcription)
+	phrases := []string{
+		"qué tal el día", "que tal el dia", "qué tal el dia", "que tal el día",
+		"resumen del día", "resumen del dia", "how was my day", "daily summary",
+	}
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// showBehavior renders an idle behavior and optionally says it aloud
+func (v *Interface) showBehavior(ctx context.Context, behavior ambient.Behavior) {
+	line := behavior.Face
+	if behavior.Text != "" {
+		line += "  " + behavior.Text
+	}
+	fmt.Fprintf(v.rl.Stdout(), "\n  %s\n", line)
+
+	if behavior.Text != "" && v.config.Ambient.Speak {
+		v.announce("ambient", behavior.Text)
+	}
+}
+
+// runReminders checks for due reminders until ctx is cancelled
+func (v *Interface) runReminders(ctx context.Context) {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, reminder := range v.reminders.Due(now) {
+				message := "⏰ Recordatorio: " + reminder.Text
+				if reminder.Contact != "" {
+					message = "⏰ Recordatorio para " + reminder.Contact + ": " + reminder.Text
+				}
+				fmt.Fprintf(v.rl.Stdout(), "\n  %s\n", message)
+				if v.alarm != nil {
+					v.alarm.Ring(ctx)
+					fmt.Fprintln(v.rl.Stdout(), "  🔔 Press ENTER to stop the alarm")
+				}
+				v.alert("reminder", "reminder:"+message, "⏰ Recordatorio", message)
+				v.deliverReminder(ctx, reminder)
+				if v.away() {
+					v.pushNotification("⏰ Recordatorio", strings.TrimPrefix(message, "⏰ "))
+				}
+			}
+			if summary, ok := v.reminders.WeeklySummary(now); ok {
+				fmt.Fprintf(v.rl.Stdout(), "\n  📊 %s\n", summary)
+				v.announce("habits", summary)
+			}
+			for _, milestone := range v.countdowns.Milestones(now) {
+				fmt.Fprintf(v.rl.Stdout(), "\n  📆 %s\n", milestone.Text)
+				// One key per countdown, so two reaching a milestone are both said
+				v.announce("countdown:"+milestone.Name, milestone.Text)
+			}
+		}
+	}
+}
+
+// runTimers rings the timers that run out until ctx is cancelled, each
+// announced by name so several can run at once
+func (v *Interface) runTimers(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, timer := range v.timers.Due(now) {
+				message := fmt.Sprintf("⏲️ ¡Tiempo! Ha terminado %s.", timer.Label())
+				fmt.Fprintf(v.rl.Stdout(), "\n  %s\n", message)
+				if v.alarm != nil {
+					v.alarm.Ring(ctx)
+					fmt.Fprintln(v.rl.Stdout(), "  🔔 Press ENTER to stop the alarm")
+				}
+				v.alert("timer", "timer:"+timer.Label(), "⏲️ Temporizador", message)
+				if v.away() {
+					v.pushNotification("⏲️ Temporizador", strings.TrimPrefix(message, "⏲️ "))
+				}
+			}
+		}
+	}
+}
+
+// deliverReminder texts or calls a reminder that asked for phone delivery;
+// reminders for a contact go to the contact's phone
+func (v *Interface) deliverReminder(ctx context.Context, reminder skills.Reminder) {
+	if v.twilio == nil || (reminder.Notify == "" && reminder.Phone == "") {
+		return
 	}
 
+	to := reminder.Phone
+	if to == "" {
+		to = v.twilio.DefaultRecipient()
+	}
+	if to == "" {
+		v.logger.Warn("No phone number to deliver the reminder to", "reminder", textutil.Preview(reminder.Text))
+		return
+	}
+
+	message := "Recordatorio de Bobo: " + reminder.Text
+	var err error
+	if reminder.Notify == skills.NotifyCall {
+		err = v.twilio.Call(ctx, to, message)
+	} else {
+		err = v.twilio.SendSMS(ctx, to, message)
+	}
+	if err != nil {
+		v.logger.Warn("Failed to deliver reminder by phone", "error", err)
+		return
+	}
+	v.logger.Info("📱 Reminder delivered by phone", "channel", reminder.Notify, "contact", reminder.Contact)
+}
+
+// touch records user activity at the desk
+func (v *Interface) touch() {
+	v.lastActivity.Store(time.Now().UnixNano())
+}
+
+// setPresent pauses the always-on microphone and idle behaviors while nobody
+// is at the desk, and resumes them when somebody comes back
+func (v *Interface) setPresent(present bool) {
+	v.absent.Store(!present)
+	if v.ambient != nil {
+		v.ambient.SetPaused(!present)
+	}
+	v.updateListening()
+
+	if present {
+		v.logger.Info("👀 Somebody is back, listening again")
+		fmt.Fprintln(v.rl.Stdout(), "\n  👋 Welcome back")
+	} else {
+		v.logger.Info("💤 Nobody around, pausing listening", "away_minutes", v.config.Presence.AwayMinutes)
+		fmt.Fprintln(v.rl.Stdout(), "\n  💤 Nobody around, listening paused")
+	}
+}
+
+// setPowerSaving switches to lighter settings on battery and back on the charger
+func (v *Interface) setPowerSaving(saving bool, percent int) {
+	if model, ok := TranscriberAs[ModelTranscriber](v.transcriber); ok && v.config.Power.WhisperModel != "" {
+		if saving {
+			model.SetModel(v.config.Power.WhisperModel)
+		} else {
+			model.SetModel("")
+		}
+	}
+	v.updateListening()
+
+	if saving {
+		v.logger.Info("🔋 On battery, saving power", "percent", percent)
+		v.rl.SetPrompt("🔋 " + commandPrompt)
+	} else {
+		v.logger.Info("🔌 Back on the charger, full settings restored")
+		v.rl.SetPrompt("🎤 " + commandPrompt)
+	}
+}
+
+// listeningPaused reports whether the always-on microphone should be closed,
+// because nobody is around or to save battery
+func (v *Interface) listeningPaused() bool {
+	return v.absent.Load() || v.power.Saving()
+}
+
+// updateListening pauses or resumes the always-on microphone
+func (v *Interface) updateListening() {
+	paused := v.listeningPaused()
+	if v.sounds != nil {
+		v.sounds.SetPaused(paused)
+	}
+	if v.stream != nil {
+		v.stream.SetPaused(paused)
+	}
+	v.wake.SetPaused(paused)
+}
+
+// away reports whether the user has been inactive long enough to be away,
+// or has left the desk
+func (v *Interface) away() bool {
+	if v.absent.Load() {
+		return true
+	}
+	idle := time.Since(time.Unix(0, v.lastActivity.Load()))
+	return idle >= time.Duration(v.config.Push.AwayMinutes)*time.Minute
+}
+
+// pushSlowAnswer sends answers that took long enough for the user to walk away
+func (v *Interface) pushSlowAnswer(question, answer string, latency time.Duration) {
+	if v.config.Push.SlowSeconds <= 0 || latency < time.Duration(v.config.Push.SlowSeconds)*time.Second {
+		return
+	}
+	v.pushNotification("🤖 "+question, answer)
+}
+
+// pushNotification sends a push notification in the background when enabled
+func (v *Interface) pushNotification(title, message string) {
+	if v.push == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := v.push.Send(ctx, title, message); err != nil {
+			v.logger.Warn("Push notification failed", "error", err)
+		}
+	}()
+}
+
+// announceSound tells the user about a detected sound event
+func (v *Interface) announceSound(ctx context.Context, event SoundEvent) {
+	messages := map[string]string{
+		SoundDoorbell:  "🔔 Creo que han llamado al timbre.",
+		SoundAlarm:     "🚨 Oigo una alarma sonando.",
+		SoundLoudNoise: "💥 He oído un ruido fuerte.",
+	}
+
+	message, ok := messages[event.Type]
+	if !ok {
+		return
+	}
+	fmt.Fprintf(v.rl.Stdout(), "\n  %s\n", message)
+
+	v.alert(event.Type, "sound:"+event.Type, "Bobo", message)
+
+	// Alarms are important enough to call the user
+	if event.Type == SoundAlarm && v.twilio != nil && v.config.Twilio.CallAlarms && v.twilio.DefaultRecipient() != "" {
+		if err := v.twilio.Call(ctx, v.twilio.DefaultRecipient(), "Bobo oye una alarma sonando en casa."); err != nil {
+			v.logger.Warn("Failed to call about the alarm", "error", err)
+		}
+	}
+}
+
+// runSkill executes a matched skill, speaks its answer and records it in the history
+func (v *Interface) runSkill(ctx context.Context, skill skills.Skill, req *skills.Request, audioPath string) error {
+	v.logger.Info("🧩 Running skill", "skill", skill.Name(), "slots", req.Slots)
+
+	startTime := time.Now()
+	result, err := skill.Handle(ctx, req)
+	latency := time.Since(startTime)
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		v.logger.Warn("⛔ Skill needs Claude", "skill", skill.Name(), "error", err)
+		v.speak(ctx, quotaNotice(exceeded))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("skill %s failed: %w", skill.Name(), err)
+	}
+	if result == nil || result.Text == "" {
+		return nil
+	}
+
+	v.logger.Info("🎯 Bobo", "response", result.Text)
+	v.telemetry.Count(skillFeature(skill))
+	v.telemetry.Observe("skill", latency)
+	v.recordInteraction(&history.Interaction{
+		Transcription: req.Utterance,
+		Response:      result.Text,
+		AudioFile:     audioPath,
+		Intent:        skill.Name(),
+		LatencyMs:     latency.Milliseconds(),
+	})
+
+	// Use the skill's voice settings for this answer only
+	selector, canSelect := v.tts.(VoiceSelector)
+	if voiceID, rate, changed := v.skillVoice(skill, result); changed && canSelect {
+		defer selector.SetVoice(v.voice())
+		selector.SetVoice(voiceID, rate)
+	}
+	v.showCard(ctx, result.Card)
+	v.speak(ctx, result.Text)
+	v.pushSlowAnswer(req.Utterance, result.Text, latency)
+
+	return nil
+}
+
+// claudeFeature names a Claude answer in the usage counts by its intent,
+// one of a fixed set (weather, news, chat...)
+func claudeFeature(intent string) string {
+	if intent == "" {
+		return "claude"
+	}
+	return "claude:" + intent
+}
+
+// skillFeature names a skill in the usage counts; installed and script
+// skills are counted together, since their names could tell who uses them
+func skillFeature(skill skills.Skill) string {
+	switch skill.(type) {
+	case *skills.ExternalSkill:
+		return "skill:external"
+	case *skills.ScriptSkill:
+		return "skill:script"
+	}
+	return "skill:" + skill.Name()
+}
+
+// exportIntent publishes a recognized intent when intent export is enabled
+func (v *Interface) exportIntent(name string, slots map[string]string, text string) {
+	if v.intents == nil || name == "" {
+		return
+	}
+	v.intents.Publish(intents.Intent{Name: name, Slots: slots, Text: text})
+}
+
+// recordInteraction appends an exchange to the history and remembers it for
+// feedback and notes, showing it as a desktop notification when enabled
+func (v *Interface) recordInteraction(interaction *history.Interaction) {
+	v.notifyInteraction(interaction.Transcription, interaction.Response)
+	v.lastAnswer = interaction
+	if v.history == nil {
+		return
+	}
+	if err := v.history.Append(interaction); err != nil {
+		v.logger.Warn("Failed to record interaction", "error", err)
+		return
+	}
+	v.lastID = interaction.ID
+}
+
+// switchPersona activates another persona and greets the user with it
+func (v *Interface) switchPersona(ctx context.Context, name string) error {
+	p, err := v.personas.Activate(name)
+	if err != nil {
+		return err
+	}
+
+	v.applyPersona(p)
+	v.logger.Info("🎭 Persona switched", "persona", p.Name, "wake_word", p.WakeWord)
+	v.speak(ctx, fmt.Sprintf("Vale, ahora soy %s.", p.WakeWord))
+	return nil
+}
+
+// applyPersona pushes the persona's prompt and voice into the Claude client and TTS
+func (v *Interface) applyPersona(p *persona.Persona) {
+	v.claudeClient.SetPersonaPrompt(p.SystemPrompt)
+	v.wake.SetPhrases(v.wakePhrases(p))
+	if selector, ok := v.tts.(VoiceSelector); ok {
+		selector.SetVoice(v.voice())
+	}
+}
+
+// detectFeedback recognizes spoken feedback about the previous answer
+func detectFeedback(transcription string) string {
+	text := strings.ToLower(strings.Trim(transcription, " .!¡?¿"))
+
+	negative := []string{"that was wrong", "that's wrong", "eso está mal", "eso esta mal", "te has equivocado", "respuesta incorrecta"}
+	for _, phrase := range negative {
+		if strings.Contains(text, phrase) {
+			return history.FeedbackNegative
+		}
+	}
+
+	positive := []string{"that was right", "good answer", "buena respuesta", "bien hecho", "eso es correcto"}
+	for _, phrase := range positive {
+		if strings.Contains(text, phrase) {
+			return history.FeedbackPositive
+		}
+	}
+
+	return ""
+}
+
+// parseConfirmation reads a yes/no answer, reporting false in ok for anything else
+func parseConfirmation(transcription string) (confirmed, ok bool) {
+	text := strings.ToLower(strings.Trim(transcription, " .,!¡?¿"))
+
+	for _, phrase := range []string{"sí", "si", "vale", "adelante", "sigue", "claro", "yes", "ok", "okay", "go ahead"} {
+		if text == phrase || strings.HasPrefix(text, phrase+" ") || strings.HasPrefix(text, phrase+",") {
+			return true, true
+		}
+	}
+	for _, phrase := range []string{"no", "cancela", "déjalo", "dejalo", "cancel", "stop"} {
+		if text == phrase || strings.HasPrefix(text, phrase+" ") || strings.HasPrefix(text, phrase+",") {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// recordFeedback tags the previous interaction with user feedback
+func (v *Interface) recordFeedback(feedback string) {
+	if v.history == nil || v.lastID == "" {
+		v.logger.Warn("⚠️ No previous answer to rate")
+		return
+	}
+
+	v.telemetry.Count("feedback:" + feedback)
+	if err := v.history.SetFeedback(v.lastID, feedback); err != nil {
+		v.logger.Warn("Failed to record feedback", "error", err)
+		return
+	}
+
+	icon := map[string]string{history.FeedbackPositive: "👍", history.FeedbackNegative: "👎"}[feedback]
+	v.logger.Info(icon+" Feedback recorded", "interaction", v.lastID)
+}
+
+// speakDailySummary writes today's usage summary to disk and reads it out
+func (v *Interface) speakDailySummary(ctx context.Context) error {
+	if v.history == nil {
+		v.logger.Warn("⚠️ Conversation history is disabled, no summary available")
+		return nil
+	}
+
+	summary, err := v.history.Summarize(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build daily summary: %w", err)
+	}
+
+	if path, err := v.history.WriteSummary(summary); err != nil {
+		v.logger.Warn("Failed to write daily summary", "error", err)
+	} else {
+		v.logger.Info("📊 Daily summary saved", "file", path)
+	}
+
+	spoken := summary.Spoken()
+	v.logger.Info("🎯 Bobo", "response", spoken)
+	v.speak(ctx, spoken)
 	return nil
 }
 
+// levelMeterWidth is the length of the input level bar, covering -60..0 dBFS
+const levelMeterWidth = 30
+
+// showInputLevel redraws the live microphone level meter on one terminal line
+func (v *Interface) showInputLevel(level InputLevel) {
+	filled := 0
+	if !math.IsInf(level.DB, -1) {
+		filled = max(0, min(levelMeterWidth, int((level.DB+60)/60*levelMeterWidth)))
+	}
+	db := "  -∞"
+	if filled > 0 {
+		db = fmt.Sprintf("%4.0f", level.DB)
+	}
+
+	fmt.Fprintf(Console, "\r\033[K  🎙️  %s%s %s dB %3.0f%%",
+		strings.Repeat("█", filled), strings.Repeat("░", levelMeterWidth-filled), db, level.Progress*100)
+	if partial := v.partial.Load(); partial != nil && *partial != "" {
+		fmt.Fprintf(Console, "  💬 %s", partialTail(*partial))
+	}
+	if level.Done {
+		fmt.Fprintln(Console)
+	}
+}
+
 // testMicrophone tests microphone recording
 func (v *Interface) testMicrophone(ctx context.Context, durationSeconds int) error {
 	_, err := v.recorder.RecordAudio(ctx, durationSeconds)
 	if err != nil {
 		return err
 	}
+
+	// Let the user hear how they sound
+	if v.player != nil {
+		v.logger.Info("🔁 Playing the recording back...")
+		if err := v.player.Play(ctx, v.recorder.AudioFilePath); err != nil {
+			v.logger.Warn("Failed to play the recording back", "error", err)
+		}
+	}
 	v.logger.Info("✅ Microphone test complete!")
 	return nil
 }
@@ -293,24 +1731,45 @@ func (v *Interface) Shutdown() error {
 
 	var errs []error
 
+	v.player.Stop()
+	v.ducker.Close()
+	v.captions.Close()
+
 	if v.rl != nil {
+		Console.SetOutput(nil)
 		if err := v.rl.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("readline shutdown: %w", err))
 		}
 	}
 
+	// Write the end-of-day usage report
+	if v.history != nil {
+		if summary, err := v.history.Summarize(time.Now()); err != nil {
+			errs = append(errs, fmt.Errorf("daily summary: %w", err))
+		} else if summary.Interactions > 0 {
+			if _, err := v.history.WriteSummary(summary); err != nil {
+				errs = append(errs, fmt.Errorf("daily summary: %w", err))
+			}
+		}
+	}
+
 	if v.claudeClient != nil {
 		if err := v.claudeClient.Shutdown(); err != nil {
 			errs = append(errs, fmt.Errorf("Claude client shutdown: %w", err))
 		}
 	}
 
-	if v.recorder != nil {
-		if err := v.recorder.Cleanup(); err != nil {
-			errs = append(errs, fmt.Errorf("recorder cleanup: %w", err))
+	// Stop the whisper.cpp servers holding the models
+	for _, transcriber := range []Transcriber{v.transcriber, v.wakeASR} {
+		if closer, ok := transcriber.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("transcriber shutdown: %w", err))
+			}
 		}
 	}
 
+	v.cleanRecordings()
+
 	if len(errs) > 0 {
 		return fmt.Errorf("shutdown errors: %v", errs)
 	}
@@ -0,0 +1,110 @@
+package voice
+
+import (
+	"context"
+	"strings"
+)
+
+// autoLanguage asks the transcriber to detect the spoken language
+const autoLanguage = "auto"
+
+// defaultLanguage is what Bobo speaks when no language is configured or the
+// transcriber can't detect it
+const defaultLanguage = "es"
+
+// LanguageTranscriber is implemented by transcribers that can detect the
+// spoken language, used with TRANSCRIPTION_LANGUAGE=auto
+type LanguageTranscriber interface {
+	// TranscribeLanguage transcribes audio in whichever language was spoken,
+	// returning it as an ISO 639-1 code ("" if unknown)
+	TranscribeLanguage(ctx context.Context, audioFilePath string) (text, language string, err error)
+}
+
+// languageCodes maps the language names some APIs return to ISO 639-1 codes
+var languageCodes = map[string]string{
+	"spanish":    "es",
+	"english":    "en",
+	"catalan":    "ca",
+	"french":     "fr",
+	"german":     "de",
+	"italian":    "it",
+	"portuguese": "pt",
+	"dutch":      "nl",
+	"galician":   "gl",
+	"basque":     "eu",
+	"russian":    "ru",
+	"chinese":    "zh",
+	"japanese":   "ja",
+}
+
+// languageCode turns a detected language ("es", "es-ES" or "spanish") into
+// its ISO 639-1 code
+func languageCode(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if code, ok := languageCodes[language]; ok {
+		return code
+	}
+	if code, _, _ := strings.Cut(language, "-"); len(code) == 2 {
+		return code
+	}
+	return ""
+}
+
+// detectsLanguage reports whether the spoken language is detected for every
+// recording
+func (v *Interface) detectsLanguage() bool {
+	if v.config.Voice.Language != autoLanguage {
+		return false
+	}
+	_, ok := TranscriberAs[LanguageTranscriber](v.transcriber)
+	return ok
+}
+
+// useLanguage makes Claude answer, and the TTS speak, in the language the
+// user just spoke. Satellites transcribe outside v.busy, so the switch is
+// made under its own lock
+func (v *Interface) useLanguage(language string) {
+	v.languageMu.Lock()
+	defer v.languageMu.Unlock()
+	if language == "" || language == v.language {
+		return
+	}
+	v.logger.Info("🌍 Language detected", "language", language)
+	v.language = language
+	v.claudeClient.SetReplyLanguage(language)
+
+	if selector, ok := v.tts.(VoiceSelector); ok {
+		selector.SetVoice(v.languageVoice(language))
+	}
+}
+
+// voice returns the voice and rate answers are spoken with in the language
+// last spoken
+func (v *Interface) voice() (voiceID string, rate int) {
+	v.languageMu.Lock()
+	language := v.language
+	v.languageMu.Unlock()
+	return v.languageVoice(language)
+}
+
+// languageVoice returns the voice and rate of a language: the persona's in
+// Bobo's own language, and the LANGUAGE_VOICES one for any other language
+// (the persona's if none is set)
+func (v *Interface) languageVoice(language string) (voiceID string, rate int) {
+	active := v.personas.Active()
+	voiceID, rate = active.VoiceID, active.Rate
+	if language == "" || language == defaultLanguage {
+		return voiceID, rate
+	}
+	if voice, ok := v.langVoices[language]; ok {
+		if voice.voiceID != "" {
+			voiceID = voice.voiceID
+		}
+		if voice.rate > 0 {
+			rate = voice.rate
+		}
+	} else {
+		v.logger.Debug("No voice for the detected language", "language", language)
+	}
+	return voiceID, rate
+}
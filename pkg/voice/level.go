@@ -0,0 +1,66 @@
+package voice
+
+import (
+	"io"
+	"os"
+)
+
+// InputLevel is a live reading of the microphone during a recording
+type InputLevel struct {
+	// DB is the RMS level of the latest audio in dBFS (-Inf when silent)
+	DB float64
+	// Progress is the share of the recording time elapsed (0-1)
+	Progress float64
+	// Done marks the last reading, sent once the recording has stopped
+	Done bool
+}
+
+// wavHeaderSize is the size of the WAV header written by ffmpeg and by wavHeader
+const wavHeaderSize = 44
+
+// levelReader follows a WAV file while it is being recorded, measuring the
+// level of the audio appended since the previous read
+type levelReader struct {
+	path   string
+	file   *os.File
+	offset int64
+	last   float64
+}
+
+// read returns the level of the audio written since the last call, or the
+// previous level when nothing new has been written yet
+func (r *levelReader) read() float64 {
+	if r.file == nil {
+		file, err := os.Open(r.path)
+		if err != nil {
+			return r.last
+		}
+		r.file, r.offset = file, wavHeaderSize
+	}
+
+	info, err := r.file.Stat()
+	if err != nil || info.Size() <= r.offset+1 {
+		return r.last
+	}
+
+	// Read whole samples only
+	size := (info.Size() - r.offset) &^ 1
+	data := make([]byte, size)
+	n, err := r.file.ReadAt(data, r.offset)
+	if err != nil && err != io.EOF {
+		return r.last
+	}
+	n &^= 1
+	r.offset += int64(n)
+	if n > 0 {
+		r.last = rmsDB(bytesToSamples(data[:n]))
+	}
+	return r.last
+}
+
+// close releases the recording file
+func (r *levelReader) close() {
+	if r.file != nil {
+		r.file.Close()
+	}
+}
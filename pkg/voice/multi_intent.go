@@ -0,0 +1,117 @@
+package voice
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// intentSeparator finds the conjunctions that may join two requests in one
+// breath ("…y dime…", "…, luego…", "…and then…")
+var intentSeparator = regexp.MustCompile(`(?i)\s*,?\s+(?:y|and)(?:\s+(?:luego|después|despues|también|tambien|además|ademas|then|also))?\s+|\s*[,;]\s*(?:luego|después|despues|then)\s+|\s*;\s*`)
+
+// requestStarts are the words a request usually opens with, telling a second
+// request apart from a list ("pan y leche") or a place ("Madrid y Barcelona")
+var requestStarts = map[string]bool{
+	// Spanish
+	"dime": true, "pon": true, "ponme": true, "recuérdame": true, "recuerdame": true,
+	"apunta": true, "añade": true, "anade": true, "busca": true, "llama": true,
+	"enciende": true, "apaga": true, "sube": true, "baja": true, "cuéntame": true,
+	"cuentame": true, "explícame": true, "explicame": true, "léeme": true, "leeme": true,
+	"avísame": true, "avisame": true, "quita": true, "borra": true, "cambia": true,
+	"qué": true, "cuál": true, "cómo": true, "cuánto": true, "cuánta": true,
+	"cuántos": true, "cuántas": true, "quién": true, "dónde": true, "cuándo": true,
+	"hay": true,
+	// English
+	"tell": true, "set": true, "remind": true, "add": true, "search": true,
+	"call": true, "turn": true, "play": true, "what": true, "what's": true,
+	"which": true, "how": true, "who": true, "where": true, "when": true,
+	"read": true,
+}
+
+// splitIntents splits a compound utterance into the requests it contains, in
+// order; it returns nil unless there are several and one of them is for a
+// local skill (Claude copes with compound questions on its own)
+func (v *Interface) splitIntents(transcription string) []string {
+	// A running mode takes every utterance as it is
+	if v.skills.Engaged() {
+		return nil
+	}
+
+	separators := intentSeparator.FindAllStringIndex(transcription, -1)
+	if len(separators) == 0 {
+		return nil
+	}
+
+	// Pieces that don't open a request belong to the previous one
+	var parts []string
+	start := 0
+	for i, separator := range separators {
+		end := len(transcription)
+		if i+1 < len(separators) {
+			end = separators[i+1][0]
+		}
+		piece := transcription[separator[1]:end]
+		if startsRequest(piece) {
+			parts = append(parts, transcription[start:separator[0]])
+			start = separator[1]
+		}
+	}
+	parts = append(parts, transcription[start:])
+	if len(parts) < 2 {
+		return nil
+	}
+
+	local := false
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+		if skill, _ := v.skills.Match(parts[i]); skill != nil {
+			local = true
+		}
+	}
+	if !local {
+		return nil
+	}
+	return parts
+}
+
+// startsRequest reports whether text opens with a request word
+func startsRequest(text string) bool {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) == 0 {
+		return false
+	}
+	return requestStarts[strings.Trim(words[0], "¿¡?!,.")]
+}
+
+// respondEach answers the requests of a compound utterance one after the
+// other, speaking all the answers together at the end
+func (v *Interface) respondEach(ctx context.Context, parts []string, audioPath string) error {
+	v.logger.Info("🧩 Several requests at once", "requests", len(parts))
+
+	// Answers already collected for a satellite just keep being collected
+	collected, forward := ctx.Value(satelliteReplyKey{}).(*satelliteReply)
+	if !forward {
+		collected = &satelliteReply{}
+	}
+	partCtx := context.WithValue(ctx, satelliteReplyKey{}, collected)
+
+	for _, part := range parts {
+		v.logger.Info("👤 Request", "transcription", part)
+		if err := v.handle(partCtx, part, audioPath); err != nil {
+			v.logger.Error("Failed to answer a request", "request", part, "error", err)
+			collected.parts = append(collected.parts, "No he podido con «"+part+"».")
+		}
+	}
+	if forward {
+		return nil
+	}
+
+	for _, c := range collected.cards {
+		v.showCard(ctx, c)
+	}
+	if len(collected.parts) > 0 {
+		v.say(ctx, strings.Join(collected.parts, " "))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package voice
+
+import (
+	"math"
+	"sort"
+)
+
+// Gain normalization: the loudest stretches of the recording (the speech) are
+// brought to the target level, without clipping and without turning a silent
+// recording into loud noise
+const (
+	normalizeFrame      = 20    // milliseconds per level measurement
+	normalizeSpeechRank = 0.95  // frames this loud or louder are taken to be speech
+	normalizeCeilingDB  = -1.0  // highest peak allowed after the gain, in dBFS
+	normalizeMaxGainDB  = 30.0  // most a recording is amplified
+	normalizeSilenceDB  = -70.0 // recordings quieter than this are left alone
+)
+
+// normalizeGain scales interleaved 16-bit samples in place so that the speech
+// level reaches targetDB (dBFS RMS), returning the applied gain in dB
+func normalizeGain(samples []int16, format wavFormat, targetDB float64) float64 {
+	frame := max(1, format.SampleRate*format.Channels*normalizeFrame/1000)
+	var levels []float64
+	for start := 0; start < len(samples); start += frame {
+		levels = append(levels, rmsDB(samples[start:min(start+frame, len(samples))]))
+	}
+	if len(levels) == 0 {
+		return 0
+	}
+	sort.Float64s(levels)
+	speech := levels[min(len(levels)-1, int(float64(len(levels))*normalizeSpeechRank))]
+	if speech < normalizeSilenceDB {
+		return 0
+	}
+
+	var peak int
+	for _, sample := range samples {
+		peak = max(peak, abs(int(sample)))
+	}
+	peakDB := 20 * math.Log10(float64(peak)/32768.0)
+
+	gainDB := min(targetDB-speech, normalizeCeilingDB-peakDB, normalizeMaxGainDB)
+	gain := math.Pow(10, gainDB/20)
+	for i, sample := range samples {
+		samples[i] = clampSample(float64(sample) * gain)
+	}
+	return gainDB
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,132 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/history"
+)
+
+// noteNameWords is how many words of the question name a note's file
+const noteNameWords = 6
+
+// Phrases asking Bobo to save the last answer; like "sigue", the utterance
+// must be one of them, optionally followed by a whole-exchange phrase
+var writeDownPhrases = []string{
+	"write that down", "write it down", "note that down", "note that", "save that",
+	"apunta eso", "apúntalo", "apuntalo", "apúntame eso", "apuntame eso",
+	"anota eso", "anótalo", "anotalo", "guarda eso", "guárdalo", "guardalo", "toma nota",
+}
+
+// Phrases after a write-down one that also save the question
+var wholeExchangePhrases = []string{
+	"con la pregunta", "con mi pregunta", "y la pregunta", "con la conversación", "con la conversacion",
+	"with the question", "with my question", "and the question", "with the conversation",
+}
+
+// parseWriteDown reports whether the user asks to save the last answer, and
+// whether the question goes with it
+func parseWriteDown(transcription string) (whole, ok bool) {
+	text := strings.ToLower(strings.Trim(transcription, " .,!¡?¿"))
+	text = strings.TrimPrefix(text, "bobo, ")
+	text = strings.TrimPrefix(text, "bobo ")
+	for _, phrase := range writeDownPhrases {
+		if text == phrase {
+			return false, true
+		}
+		rest, found := strings.CutPrefix(text, phrase+" ")
+		if !found {
+			continue
+		}
+		for _, suffix := range wholeExchangePhrases {
+			if strings.Trim(rest, " ,") == suffix {
+				return true, true
+			}
+		}
+	}
+	return false, false
+}
+
+// writeDown saves the last answer, and the question if whole, to a
+// markdown file in the notes directory
+func (v *Interface) writeDown(ctx context.Context, whole bool) error {
+	last := v.lastAnswer
+	if last == nil {
+		v.speak(ctx, "No tengo nada que apuntar todavía.")
+		return nil
+	}
+
+	path, err := saveNote(v.config.Notes.Dir, last, whole)
+	if err != nil {
+		return err
+	}
+	v.logger.Info("📝 Answer written down", "path", path, "with_question", whole)
+	v.speak(ctx, "Apuntado.")
+	return nil
+}
+
+// saveNote writes an exchange to dir as "2006-01-02-1504-<topic>.md",
+// named after the words of the question
+func saveNote(dir string, interaction *history.Interaction, whole bool) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	timestamp := interaction.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", strings.Trim(interaction.Transcription, " .,!¡?¿"))
+	fmt.Fprintf(&b, "*%s*\n\n", timestamp.Format("Monday, 2 January 2006 15:04"))
+	if whole {
+		fmt.Fprintf(&b, "**You:** %s\n\n", interaction.Transcription)
+		fmt.Fprintf(&b, "**Bobo:** %s\n", interaction.Response)
+	} else {
+		fmt.Fprintf(&b, "%s\n", interaction.Response)
+	}
+	if len(interaction.Sources) > 0 {
+		b.WriteString("\n*Sources:*\n")
+		for _, source := range interaction.Sources {
+			fmt.Fprintf(&b, "- %s\n", source)
+		}
+	}
+
+	// A second note on the same topic in the same minute gets a number
+	name := timestamp.Format("2006-01-02-1504") + "-" + noteTopic(interaction.Transcription)
+	path := filepath.Join(dir, name+".md")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.md", name, i))
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write note: %w", err)
+	}
+	return path, nil
+}
+
+// noteAccents are folded out of note file names
+var noteAccents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n", "ç", "c", "à", "a", "è", "e", "ò", "o")
+
+// noteTopic turns a question into a file name: its first words, lowercase,
+// without accents and joined by dashes ("¿Qué tiempo hace en Bilbao?" is
+// "que-tiempo-hace-en-bilbao")
+func noteTopic(question string) string {
+	words := strings.FieldsFunc(noteAccents.Replace(strings.ToLower(question)), func(r rune) bool {
+		return r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r))
+	})
+	if len(words) > noteNameWords {
+		words = words[:noteNameWords]
+	}
+	if len(words) == 0 {
+		return "nota"
+	}
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,112 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// openAITranscriptionURL is the OpenAI audio transcription endpoint, which
+// takes files up to 25 MB
+const openAITranscriptionURL = "https://api.openai.com/v1/audio/transcriptions"
+
+// openAITranscription is the subset of the transcription response used
+type openAITranscription struct {
+	Text     string `json:"text"`
+	Language string `json:"language"` // verbose_json only
+}
+
+// OpenAITranscriber implements transcription with the OpenAI Whisper API
+type OpenAITranscriber struct {
+	config *config.VoiceConfig
+	client *http.Client
+}
+
+// NewOpenAITranscriber creates a transcriber authenticated with OPENAI_API_KEY
+func NewOpenAITranscriber(cfg *config.VoiceConfig) (*OpenAITranscriber, error) {
+	if cfg.OpenAIAPIKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY is not set")
+	}
+	return &OpenAITranscriber{
+		config: cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Transcribe uploads a recording to the OpenAI Whisper API
+func (o *OpenAITranscriber) Transcribe(ctx context.Context, audioFilePath, language string) (string, error) {
+	result, err := o.transcribe(ctx, audioFilePath, language)
+	return result.Text, err
+}
+
+// TranscribeLanguage lets the API detect the spoken language, which only
+// whisper-1 reports back
+func (o *OpenAITranscriber) TranscribeLanguage(ctx context.Context, audioFilePath string) (string, string, error) {
+	result, err := o.transcribe(ctx, audioFilePath, autoLanguage)
+	return result.Text, languageCode(result.Language), err
+}
+
+// transcribe uploads a recording in language, or "auto" to detect it
+func (o *OpenAITranscriber) transcribe(ctx context.Context, audioFilePath, language string) (openAITranscription, error) {
+	var result openAITranscription
+	audio, err := os.ReadFile(audioFilePath)
+	if err != nil {
+		return result, fmt.Errorf("failed to read %s: %w", audioFilePath, err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filepath.Base(audioFilePath))
+	if err != nil {
+		return result, fmt.Errorf("failed to create request: %w", err)
+	}
+	part.Write(audio)
+	form.WriteField("model", o.config.OpenAISTTModel)
+	format := "json"
+	if language != autoLanguage {
+		form.WriteField("language", language)
+	} else if o.config.OpenAISTTModel == "whisper-1" {
+		format = "verbose_json"
+	}
+	form.WriteField("response_format", format)
+	if prompt := transcriptionPrompt(ctx, o.config); prompt != "" {
+		form.WriteField("prompt", prompt)
+	}
+	form.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, openAITranscriptionURL, &body)
+	if err != nil {
+		return result, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+o.config.OpenAIAPIKey)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return result, fmt.Errorf("OpenAI transcription request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return result, fmt.Errorf("failed to read OpenAI transcription response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("OpenAI transcription returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return result, fmt.Errorf("failed to parse OpenAI transcription response: %w", err)
+	}
+	result.Text = cleanTranscription(result.Text)
+	return result, nil
+}
@@ -0,0 +1,101 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Interim transcription: the recording so far is transcribed again every
+// partialInterval, once at least minPartialAudio more has been recorded
+const (
+	partialInterval = 1500 * time.Millisecond
+	minPartialAudio = 500 * time.Millisecond
+	partialWidth    = 40 // characters of the interim transcription shown
+)
+
+// followRecording transcribes snapshots of the WAV recording growing at
+// path with transcribe until ctx is cancelled, calling partial whenever the
+// hypothesis changes
+func followRecording(ctx context.Context, path string, transcribe func(ctx context.Context, snapshot string) (string, error), partial func(text string)) error {
+	snapshot := strings.TrimSuffix(path, ".wav") + ".partial.wav"
+	defer os.Remove(snapshot)
+
+	ticker := time.NewTicker(partialInterval)
+	defer ticker.Stop()
+
+	var transcribed int
+	var last string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // not created yet
+		}
+		format, pcm, err := parseWAV(data)
+		if err != nil || format.BitsPerSample != 16 || format.Channels < 1 {
+			continue
+		}
+		frame := format.Channels * 2
+		pcm = pcm[:len(pcm)/frame*frame]
+		if len(pcm)-transcribed < format.bytesPerSecond()*int(minPartialAudio/time.Millisecond)/1000 {
+			continue
+		}
+
+		if err := writeWAV(snapshot, pcm, format); err != nil {
+			return err
+		}
+		text, err := transcribe(ctx, snapshot)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("interim transcription failed: %w", err)
+		}
+		transcribed = len(pcm)
+		if text != last {
+			last = text
+			partial(text)
+		}
+	}
+}
+
+// followTranscript streams the recording at path to the transcriber until it
+// is complete, showing the interim transcription next to the level meter
+// with PARTIAL_TRANSCRIPTS
+func (v *Interface) followTranscript(ctx context.Context, path string) {
+	streaming, ok := TranscriberAs[StreamingTranscriber](v.transcriber)
+	if !ok {
+		return
+	}
+	defer v.partial.Store(nil)
+
+	err := streaming.TranscribeStream(ctx, path, v.transcriptionLanguage(), func(text string) {
+		if !v.config.Voice.PartialTranscripts {
+			return
+		}
+		text = removeHallucinations(text)
+		v.partial.Store(&text)
+		v.captions.User(text, true)
+	})
+	if err != nil {
+		v.logger.Debug("Live transcription stopped", "error", err)
+	}
+}
+
+// partialTail is the end of an interim transcription, short enough to fit
+// on the level meter's line
+func partialTail(text string) string {
+	runes := []rune(text)
+	if len(runes) <= partialWidth {
+		return text
+	}
+	return "…" + string(runes[len(runes)-partialWidth+1:])
+}
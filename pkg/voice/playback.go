@@ -0,0 +1,162 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// playerCandidates are the players tried for each file type, best first
+var playerCandidates = map[string][]string{
+	".wav": {"paplay", "pw-play", "aplay", "afplay", "ffplay"},
+	".mp3": {"mpg123", "ffplay", "afplay"},
+}
+
+// Player plays WAV and MP3 files (recordings, synthesized speech, earcons)
+// on the speakers, one at a time
+type Player struct {
+	device  string
+	players map[string]string // file extension -> player command
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	stop    context.CancelFunc
+	playing int // number of the current playback, to tell when it ends
+}
+
+// NewPlayer finds the players available for each file type; device is a
+// PulseAudio/PipeWire sink or an ALSA device such as plughw:1,0 (empty or
+// "default" for the default output)
+func NewPlayer(device string) (*Player, error) {
+	p := &Player{
+		device:  device,
+		players: make(map[string]string),
+		logger:  slog.Default(),
+	}
+
+	for ext, candidates := range playerCandidates {
+		// ALSA device names are only understood by aplay
+		if ext == ".wav" && strings.Contains(device, ":") {
+			candidates = append([]string{"aplay"}, candidates...)
+		}
+		for _, player := range candidates {
+			if _, err := exec.LookPath(player); err == nil {
+				p.players[ext] = player
+				break
+			}
+		}
+	}
+	if p.players[".wav"] == "" {
+		return nil, fmt.Errorf("no audio player found (tried: %s)", strings.Join(playerCandidates[".wav"], ", "))
+	}
+	return p, nil
+}
+
+// Play plays the file at path until it ends, ctx is cancelled or Stop is called
+func (p *Player) Play(ctx context.Context, path string) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	player := p.players[ext]
+	if player == "" {
+		return fmt.Errorf("no player for %s files", ext)
+	}
+
+	playCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	p.mu.Lock()
+	if p.stop != nil {
+		p.stop()
+	}
+	p.playing++
+	p.stop = stop
+	playing := p.playing
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		if p.playing == playing {
+			p.stop = nil
+		}
+		p.mu.Unlock()
+	}()
+
+	output, err := exec.CommandContext(playCtx, player, p.args(player, path)...).CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if playCtx.Err() != nil {
+		// Interrupted by Stop
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", player, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// PlayPCM plays raw PCM audio, such as a synthesized earcon
+func (p *Player) PlayPCM(ctx context.Context, pcm []byte, format wavFormat) error {
+	file, err := os.CreateTemp("", "bobo-playback-*.wav")
+	if err != nil {
+		return fmt.Errorf("failed to create playback file: %w", err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	if err := writeWAV(file.Name(), pcm, format); err != nil {
+		return err
+	}
+	return p.Play(ctx, file.Name())
+}
+
+// Stop interrupts the current playback, reporting whether anything was playing
+func (p *Player) Stop() bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stop == nil {
+		return false
+	}
+	p.stop()
+	p.stop = nil
+	return true
+}
+
+// args builds the command line of a player for path on the selected device
+func (p *Player) args(player, path string) []string {
+	device := p.device
+	if device == "default" {
+		device = ""
+	}
+
+	var args []string
+	switch player {
+	case "paplay":
+		if device != "" {
+			args = append(args, "--device="+device)
+		}
+	case "pw-play":
+		if device != "" {
+			args = append(args, "--target", device)
+		}
+	case "aplay":
+		args = append(args, "-q")
+		if device != "" {
+			args = append(args, "-D", device)
+		}
+	case "mpg123":
+		args = append(args, "-q")
+		if device != "" {
+			args = append(args, "-a", device)
+		}
+	case "ffplay":
+		args = append(args, "-nodisp", "-autoexit", "-loglevel", "error")
+	}
+	return append(args, path)
+}
@@ -0,0 +1,128 @@
+package voice
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// powerSupplyRoot lists the power supplies on Linux
+const powerSupplyRoot = "/sys/class/power_supply"
+
+// pmsetPercent finds the charge in `pmset -g batt` output, e.g. "85%;"
+var pmsetPercent = regexp.MustCompile(`(\d+)%`)
+
+// PowerMonitor watches the battery of a laptop and tells when to save power
+type PowerMonitor struct {
+	config *config.PowerConfig
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	saving bool
+}
+
+// NewPowerMonitor creates a battery monitor
+func NewPowerMonitor(cfg *config.PowerConfig) *PowerMonitor {
+	return &PowerMonitor{config: cfg, logger: slog.Default()}
+}
+
+// Run checks the battery until ctx is cancelled, calling onChange when the
+// power saver turns on (on battery at or below BelowPercent) or off
+func (p *PowerMonitor) Run(ctx context.Context, onChange func(saving bool, percent int)) {
+	interval := time.Duration(p.config.PollSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		onBattery, percent, ok := readBattery(ctx)
+		saving := ok && onBattery && percent <= p.config.BelowPercent
+
+		p.mu.Lock()
+		changed := saving != p.saving
+		p.saving = saving
+		p.mu.Unlock()
+		if changed {
+			onChange(saving, percent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Saving reports whether the power saver is on
+func (p *PowerMonitor) Saving() bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.saving
+}
+
+// Interval stretches a polling interval by PollMultiplier while saving power
+func (p *PowerMonitor) Interval(interval time.Duration) time.Duration {
+	if p.Saving() && p.config.PollMultiplier > 1 {
+		return interval * time.Duration(p.config.PollMultiplier)
+	}
+	return interval
+}
+
+// readBattery reports whether the computer runs on battery and its charge;
+// ok is false when there is no battery (desktops, the Raspberry Pi)
+func readBattery(ctx context.Context) (onBattery bool, percent int, ok bool) {
+	switch runtime.GOOS {
+	case "linux":
+		supplies, _ := os.ReadDir(powerSupplyRoot)
+		for _, supply := range supplies {
+			dir := filepath.Join(powerSupplyRoot, supply.Name())
+			if readSysfs(dir, "type") != "Battery" {
+				continue
+			}
+			capacity, err := strconv.Atoi(readSysfs(dir, "capacity"))
+			if err != nil {
+				continue
+			}
+			return readSysfs(dir, "status") == "Discharging", capacity, true
+		}
+	case "darwin":
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		output, err := exec.CommandContext(ctx, "pmset", "-g", "batt").Output()
+		if err != nil {
+			return false, 0, false
+		}
+		match := pmsetPercent.FindSubmatch(output)
+		if match == nil {
+			return false, 0, false
+		}
+		percent, _ = strconv.Atoi(string(match[1]))
+		return strings.Contains(string(output), "'Battery Power'"), percent, true
+	}
+	return false, 0, false
+}
+
+// readSysfs reads a sysfs attribute
+func readSysfs(dir, name string) string {
+	value, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(value))
+}
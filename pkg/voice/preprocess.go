@@ -0,0 +1,53 @@
+package voice
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// preprocessing reports whether recordings are cleaned up before transcription
+func preprocessing(cfg *config.VoiceConfig) bool {
+	return cfg.NoiseSuppression || cfg.NormalizeLevel || cfg.TrimSilence
+}
+
+// preprocessRecording writes a copy of the 16-bit PCM WAV file at src to dst
+// with noise suppression, silence trimming and gain normalization applied, as
+// configured; it fails with errNoSpeech if only silence is left
+func preprocessRecording(src, dst string, cfg *config.VoiceConfig) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	format, pcm, err := parseWAV(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	if format.BitsPerSample != 16 || format.Channels < 1 {
+		return fmt.Errorf("%s: unsupported format (%d-bit, %d channels)", src, format.BitsPerSample, format.Channels)
+	}
+
+	samples := bytesToSamples(pcm)
+	if cfg.NoiseSuppression {
+		suppressNoise(samples, format)
+	}
+	// SILENCE_THRESHOLD_DB is a level in the recording as captured: once the
+	// gain is normalized, silence may be loud enough to pass for speech
+	if cfg.TrimSilence {
+		samples = trimSilence(samples, format, cfg.SilenceThresholdDB)
+		if samples == nil {
+			return errNoSpeech
+		}
+	}
+	if cfg.NormalizeLevel {
+		normalizeGain(samples, format, cfg.NormalizeTargetDB)
+	}
+	return writeWAV(dst, samplesToBytes(samples), format)
+}
+
+// processedPath names the preprocessed copy of a recording
+func processedPath(path string) string {
+	return strings.TrimSuffix(path, ".wav") + ".processed.wav"
+}
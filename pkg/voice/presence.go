@@ -0,0 +1,129 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// pirPollInterval is how often the motion sensor is read; PIR modules only
+// hold their output high for a few seconds after movement
+const pirPollInterval = 500 * time.Millisecond
+
+// PresenceSensor tells whether somebody is at the desk from a phone in
+// Bluetooth range, a host answering on the Wi-Fi and/or a PIR motion sensor
+type PresenceSensor struct {
+	config   *config.PresenceConfig
+	present  bool
+	lastSeen time.Time
+	power    *PowerMonitor
+	logger   *slog.Logger
+}
+
+// NewPresenceSensor checks the tools and GPIO line needed by the configured sources
+func NewPresenceSensor(cfg *config.PresenceConfig) (*PresenceSensor, error) {
+	if cfg.Bluetooth != "" {
+		if _, err := exec.LookPath("hcitool"); err != nil {
+			return nil, fmt.Errorf("hcitool not found: %w", err)
+		}
+	}
+	if cfg.Host != "" {
+		if _, err := exec.LookPath("ping"); err != nil {
+			return nil, fmt.Errorf("ping not found: %w", err)
+		}
+	}
+	if cfg.PIRPin >= 0 {
+		if err := exportGPIO(cfg.PIRPin, "in"); err != nil {
+			return nil, fmt.Errorf("failed to set up the motion sensor on GPIO %d: %w", cfg.PIRPin, err)
+		}
+	}
+
+	return &PresenceSensor{
+		config:   cfg,
+		present:  true,
+		lastSeen: time.Now(),
+		logger:   slog.Default(),
+	}, nil
+}
+
+// Run watches the sources until ctx is cancelled, calling onChange when
+// nobody has been seen for AwayMinutes and again when somebody comes back
+func (p *PresenceSensor) Run(ctx context.Context, onChange func(present bool)) {
+	interval := time.Duration(p.config.PollSeconds) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(pirPollInterval)
+	defer ticker.Stop()
+
+	var lastPoll time.Time
+	for {
+		now := time.Now()
+		seen := p.motion()
+		if !seen && now.Sub(lastPoll) >= p.power.Interval(interval) {
+			lastPoll = now
+			seen = p.phoneNearby(ctx) || p.hostReachable(ctx)
+		}
+		if seen {
+			p.lastSeen = now
+		}
+
+		present := now.Sub(p.lastSeen) < time.Duration(p.config.AwayMinutes)*time.Minute
+		if present != p.present {
+			p.present = present
+			onChange(present)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetPower checks Bluetooth and Wi-Fi less often while the power monitor is saving power
+func (p *PresenceSensor) SetPower(power *PowerMonitor) {
+	p.power = power
+}
+
+// motion reads the PIR sensor
+func (p *PresenceSensor) motion() bool {
+	if p.config.PIRPin < 0 {
+		return false
+	}
+	high, err := readGPIO(p.config.PIRPin)
+	if err != nil {
+		p.logger.Debug("Failed to read the motion sensor", "pin", p.config.PIRPin, "error", err)
+	}
+	return high
+}
+
+// phoneNearby asks for the Bluetooth device's name, which only answers in
+// range (the phone doesn't need to be paired or connected)
+func (p *PresenceSensor) phoneNearby(ctx context.Context) bool {
+	if p.config.Bluetooth == "" {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "hcitool", "name", p.config.Bluetooth).Output()
+	return err == nil && strings.TrimSpace(string(output)) != ""
+}
+
+// hostReachable pings the host (e.g. a phone on the Wi-Fi)
+func (p *PresenceSensor) hostReachable(ctx context.Context) bool {
+	if p.config.Host == "" {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return exec.CommandContext(ctx, "ping", "-c", "1", "-W", "2", p.config.Host).Run() == nil
+}
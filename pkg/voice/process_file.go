@@ -0,0 +1,60 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// ProcessFile runs an existing recording, such as a voice memo, through
+// transcription and the answer as if it had just been recorded; the file is
+// left untouched, a copy goes to the session's recordings. With DIARIZE
+// the transcript is labelled by speaker
+func (v *Interface) ProcessFile(ctx context.Context, path string) error {
+	v.busy.Lock()
+	defer v.busy.Unlock()
+	v.touch()
+
+	// Don't take Bobo's own answer for the wake word
+	if v.wake != nil {
+		v.wake.SetPaused(true)
+		defer func() { v.wake.SetPaused(v.listeningPaused()) }()
+	}
+
+	audioPath, err := v.importRecording(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	v.logger.Info("🔄 Processing audio file...", "file", path)
+	transcription, err := v.transcribe(withDiarization(ctx), audioPath)
+	if err != nil || transcription == "" {
+		return err
+	}
+	return v.respond(ctx, transcription, audioPath)
+}
+
+// importRecording copies the audio file at path into the session's
+// recordings as a WAV file, converting other formats with ffmpeg
+func (v *Interface) importRecording(ctx context.Context, path string) (string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("failed to open audio file: %w", err)
+	}
+
+	imported := v.storage.NewPath("desk_pet_file")
+	if isWAV(path) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err := os.WriteFile(imported, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to copy %s: %w", path, err)
+		}
+		return imported, nil
+	}
+
+	if err := ffmpegConvert(ctx, path, imported, []string{"-ac", "1", "-c:a", "pcm_s16le"}); err != nil {
+		return "", err
+	}
+	return imported, nil
+}
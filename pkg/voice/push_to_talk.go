@@ -0,0 +1,102 @@
+package voice
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Terminals report key presses but not releases: a held key is seen as the
+// first press followed by autorepeated presses, and released when they stop
+const (
+	pushToTalkKey = ' '
+	// keyRepeatDelay is longer than the usual autorepeat delay; a press not
+	// repeated within it was a tap
+	keyRepeatDelay = 700 * time.Millisecond
+	// keyRepeatGap is longer than the usual autorepeat interval; repeats
+	// stopping for this long mean the key was released
+	keyRepeatGap = 200 * time.Millisecond
+)
+
+// pushToTalk turns holding the push-to-talk key at an empty prompt into a
+// recording: released keys stop it, and a single tap starts one that lasts
+// until the next tap
+type pushToTalk struct {
+	// record records and answers until release is closed
+	record func(release <-chan struct{})
+
+	mu     sync.Mutex
+	active bool
+	keys   chan struct{}
+}
+
+// newPushToTalk creates the key handler for a recording function
+func newPushToTalk(record func(release <-chan struct{})) *pushToTalk {
+	return &pushToTalk{record: record}
+}
+
+// OnChange implements readline.Listener, taking the push-to-talk key out of
+// the line when it is pressed at an empty prompt
+func (p *pushToTalk) OnChange(line []rune, pos int, key rune) ([]rune, int, bool) {
+	if key != pushToTalkKey || strings.TrimSpace(string(line)) != "" {
+		return nil, 0, false
+	}
+	p.press()
+	return []rune{}, 0, true
+}
+
+// press starts a recording, or passes the key on to the running one
+func (p *pushToTalk) press() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.active {
+		select {
+		case p.keys <- struct{}{}:
+		default:
+		}
+		return
+	}
+
+	p.active = true
+	p.keys = make(chan struct{}, 64)
+	go p.hold(p.keys)
+}
+
+// hold records while the key is held, or until the next tap
+func (p *pushToTalk) hold(keys chan struct{}) {
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.record(release)
+	}()
+
+	select {
+	case <-keys:
+		// Held: record until the autorepeat stops
+		for held := true; held; {
+			select {
+			case <-keys:
+			case <-time.After(keyRepeatGap):
+				held = false
+			case <-done:
+				held = false
+			}
+		}
+	case <-time.After(keyRepeatDelay):
+		// Tapped: record until the next tap
+		select {
+		case <-keys:
+		case <-done:
+		}
+	case <-done:
+	}
+	close(release)
+	<-done
+
+	// Presses while answering don't start another recording
+	p.mu.Lock()
+	p.active = false
+	p.mu.Unlock()
+}
@@ -0,0 +1,74 @@
+package voice
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// maxRepairAlternatives is how many other hypotheses are considered when
+// repairing a misheard question
+const maxRepairAlternatives = 3
+
+// confusedReplyPattern matches answers where Claude says it could not make
+// sense of the question, which usually means the transcript was garbled
+var confusedReplyPattern = regexp.MustCompile(`(?i)(no (te )?(he )?entiend|no (te )?(he )?entendido|no tiene (mucho )?sentido|no sé (bien )?(a qué|qué) te refieres|no estoy segur[oa] de (a qué|qué) te refieres|(puedes|podrías) (repetir|reformular|aclarar)|me lo (puedes )?repet|i don'?t understand|i'?m not sure what you mean|doesn'?t (quite )?make sense|(could|can) you (please )?(rephrase|repeat|clarify))`)
+
+// isConfusedReply reports whether Claude's answer signals it did not understand
+func isConfusedReply(response string) bool {
+	return confusedReplyPattern.MatchString(response)
+}
+
+// repairCandidate looks for another reading of the recording, preferring the
+// one that shares most words with the previous exchange ("" when none)
+func (v *Interface) repairCandidate(ctx context.Context, transcription, audioPath string) string {
+	alternatives, ok := TranscriberAs[AlternativesTranscriber](v.transcriber)
+	if !ok || audioPath == "" {
+		return ""
+	}
+
+	audioPath, cleanup, err := decodeRecording(ctx, audioPath)
+	if err != nil {
+		v.logger.Warn("Failed to decode the recording", "error", err)
+		return ""
+	}
+	defer cleanup()
+
+	hypotheses, err := alternatives.Alternatives(ctx, audioPath, v.transcriptionLanguage(), maxRepairAlternatives)
+	if err != nil {
+		v.logger.Warn("Failed to get alternative transcriptions", "error", err)
+	}
+
+	previous := contentWords(v.lastExchange)
+	original := strings.ToLower(strings.Trim(transcription, " .,!¡?¿"))
+	best, bestScore := "", -1
+	for _, hypothesis := range hypotheses {
+		if strings.ToLower(strings.Trim(hypothesis, " .,!¡?¿")) == original {
+			continue
+		}
+		score := 0
+		for word := range contentWords(hypothesis) {
+			if previous[word] {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = hypothesis, score
+		}
+	}
+	return best
+}
+
+// contentWords returns the lowercase words of text long enough to carry meaning
+func contentWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) > 3 {
+			words[word] = true
+		}
+	}
+	return words
+}
@@ -0,0 +1,113 @@
+package voice
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// whisperSampleRate is the only rate whisper.cpp transcribes correctly
+const whisperSampleRate = 16000
+
+// resampleTaps is the number of input samples on each side of an output
+// sample that the windowed-sinc filter looks at
+const resampleTaps = 16
+
+// resample converts interleaved 16-bit samples from one rate to another with
+// a windowed-sinc filter, which also keeps frequencies above the lower
+// Nyquist limit from folding back as noise when downsampling
+func resample(samples []int16, channels, from, to int) []int16 {
+	if from == to || from <= 0 || to <= 0 || channels < 1 {
+		return samples
+	}
+
+	frames := len(samples) / channels
+	out := make([]int16, int(int64(frames)*int64(to)/int64(from))*channels)
+	ratio := float64(from) / float64(to)
+	cutoff := min(1, 1/ratio) // of the input Nyquist frequency
+	width := float64(resampleTaps) / cutoff
+
+	for i := 0; i < len(out)/channels; i++ {
+		center := float64(i) * ratio
+		first := max(0, int(math.Ceil(center-width)))
+		last := min(frames-1, int(math.Floor(center+width)))
+		for c := 0; c < channels; c++ {
+			var sum, weights float64
+			for j := first; j <= last; j++ {
+				x := float64(j) - center
+				weight := cutoff * sinc(cutoff*x) * hann(x/width)
+				sum += weight * float64(samples[j*channels+c])
+				weights += weight
+			}
+			if weights != 0 {
+				sum /= weights
+			}
+			out[i*channels+c] = clampSample(sum)
+		}
+	}
+	return out
+}
+
+// sinc is the normalized sinc function
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// hann is a Hann window over [-1, 1]
+func hann(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.5 + 0.5*math.Cos(math.Pi*x)
+}
+
+// resampledForWhisper returns the path of a 16 kHz copy of the WAV file at
+// path, or path itself when it is already at that rate; the copy is for the
+// caller to remove
+func resampledForWhisper(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	format, pcm, err := parseWAV(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	if format.SampleRate == whisperSampleRate {
+		return path, nil
+	}
+	if format.BitsPerSample != 16 || format.Channels < 1 {
+		return "", fmt.Errorf("%s: unsupported format (%d-bit, %d channels)", path, format.BitsPerSample, format.Channels)
+	}
+
+	samples := resample(bytesToSamples(pcm), format.Channels, format.SampleRate, whisperSampleRate)
+	resampled := strings.TrimSuffix(path, ".wav") + ".16k.wav"
+	format.SampleRate = whisperSampleRate
+	if err := writeWAV(resampled, samplesToBytes(samples), format); err != nil {
+		return "", err
+	}
+	return resampled, nil
+}
+
+// monoSamples reads a 16-bit WAV file as mono samples at rate, the input
+// speech recognition libraries take
+func monoSamples(path string, rate int) ([]int16, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	format, pcm, err := parseWAV(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if format.BitsPerSample != 16 || format.Channels < 1 {
+		return nil, fmt.Errorf("%s: unsupported format (%d-bit, %d channels)", path, format.BitsPerSample, format.Channels)
+	}
+
+	mono := bytesToSamples(downmix(pcm, format.Channels, 0))
+	return resample(mono, 1, format.SampleRate, rate), nil
+}
@@ -0,0 +1,149 @@
+package voice
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// longAnswerSentences is the length from which an answer is said sentence
+// by sentence, so an interruption only loses the sentence being said
+const longAnswerSentences = 4
+
+// Phrases asking Bobo to go on with an interrupted answer; the whole
+// utterance must be one of them, since "sigue" also starts real requests
+var resumePhrases = []string{
+	"sigue", "continúa", "continua", "continuar", "sigue hablando", "sigue por donde ibas",
+	"continúa por donde ibas", "continua por donde ibas", "qué más", "que más", "que mas",
+	"continue", "go on", "keep going", "carry on",
+}
+
+// speechState tracks the long answer being said and the rest of the last
+// one that was cut off
+type speechState struct {
+	mu          sync.Mutex
+	stop        context.CancelFunc // interrupts the long answer being said
+	interrupted []string           // sentences not said yet, starting with the one cut off
+}
+
+// begin registers a long answer that stop interrupts, dropping any earlier
+// interrupted one
+func (s *speechState) begin(stop context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stop = stop
+	s.interrupted = nil
+}
+
+// end unregisters the long answer, keeping rest to resume it later
+func (s *speechState) end(rest []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stop = nil
+	s.interrupted = rest
+}
+
+// Interrupt stops the long answer being said, reporting whether there was one
+func (s *speechState) Interrupt() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == nil {
+		return false
+	}
+	s.stop()
+	s.stop = nil
+	return true
+}
+
+// pending reports whether an interrupted answer is waiting to be resumed
+func (s *speechState) pending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.interrupted) > 0
+}
+
+// take returns and forgets the rest of the interrupted answer
+func (s *speechState) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rest := s.interrupted
+	s.interrupted = nil
+	return rest
+}
+
+// sayResumable says sentences one by one, remembering where it was cut off
+// (by ENTER, or by turning speech off) so "sigue" can go on from there
+func (v *Interface) sayResumable(ctx context.Context, sentences []string) {
+	speechCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	v.speech.begin(stop)
+
+	for i, sentence := range sentences {
+		if !v.config.TTS.Enabled {
+			// Speech turned off halfway: the rest can still be asked for
+			v.speech.end(sentences[i:])
+			return
+		}
+		v.say(speechCtx, sentence)
+		if speechCtx.Err() != nil {
+			v.logger.Info("⏸️ Answer interrupted, say 'sigue' to go on", "remaining", len(sentences)-i)
+			v.speech.end(sentences[i:])
+			return
+		}
+	}
+	v.speech.end(nil)
+}
+
+// resume goes on with the last interrupted answer from the sentence it was
+// cut off at
+func (v *Interface) resume(ctx context.Context) error {
+	rest := v.speech.take()
+	if len(rest) == 0 {
+		v.speak(ctx, "No tengo nada a medias.")
+		return nil
+	}
+	v.logger.Info("▶️ Resuming the interrupted answer", "remaining", len(rest))
+	v.sayResumable(ctx, rest)
+	return nil
+}
+
+// isResumeRequest reports whether the user asks to go on with an
+// interrupted answer
+func isResumeRequest(transcription string) bool {
+	text := strings.ToLower(strings.Trim(transcription, " .,!¡?¿"))
+	text = strings.TrimPrefix(text, "bobo, ")
+	text = strings.TrimPrefix(text, "bobo ")
+	for _, phrase := range resumePhrases {
+		if text == phrase {
+			return true
+		}
+	}
+	return false
+}
+
+// splitSentences splits text after sentence-ending punctuation followed by
+// a space, keeping the punctuation; decimals and most abbreviations stay
+// whole because the next word must start a sentence
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(strings.TrimSpace(text))
+	start := 0
+	for i := 0; i < len(runes)-2; i++ {
+		if !strings.ContainsRune(".!?…", runes[i]) || !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		next := runes[i+2]
+		if !unicode.IsUpper(next) && !unicode.IsDigit(next) && next != '¿' && next != '¡' && next != '«' && next != '"' {
+			continue
+		}
+		if sentence := strings.TrimSpace(string(runes[start : i+1])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = i + 1
+	}
+	if sentence := strings.TrimSpace(string(runes[start:])); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
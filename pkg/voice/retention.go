@@ -0,0 +1,101 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/history"
+)
+
+// janitorInterval is how often retention policies are enforced
+const janitorInterval = time.Hour
+
+// runJanitor enforces RECORDING_RETENTION and HISTORY_RETENTION until ctx is
+// cancelled
+func (v *Interface) runJanitor(ctx context.Context) {
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+
+	v.pruneHistory()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		v.cleanRecordings()
+		v.pruneHistory()
+	}
+}
+
+// cleanRecordings removes the recordings RECORDING_RETENTION no longer keeps
+func (v *Interface) cleanRecordings() {
+	report, err := v.storage.Clean(false)
+	if err != nil {
+		v.logger.Warn("Failed to clean up recordings", "error", err)
+		return
+	}
+	if len(report.Removed) > 0 {
+		v.logger.Info("🧹 Old recordings removed", "files", len(report.Removed), "bytes", report.Bytes, "kept", report.Kept)
+	}
+}
+
+// pruneHistory removes the transcripts HISTORY_RETENTION no longer keeps
+func (v *Interface) pruneHistory() {
+	cutoff := history.RetentionCutoff(v.config.History.Retention, time.Now())
+	if v.history == nil || cutoff.IsZero() {
+		return
+	}
+	removed, err := v.history.Prune(cutoff)
+	if err != nil {
+		v.logger.Warn("Failed to prune the conversation history", "error", err)
+		return
+	}
+	if len(removed) > 0 {
+		v.logger.Info("🧹 Old transcripts removed", "interactions", len(removed), "retention", v.config.History.Retention)
+	}
+}
+
+// forget erases the conversation of the last period: transcripts, their
+// recordings and what Bobo keeps of it to understand the next question
+func (v *Interface) forget(ctx context.Context, period time.Duration, audioPath string) error {
+	removed := 0
+	if v.history != nil {
+		interactions, err := v.history.Forget(time.Now().Add(-period))
+		if err != nil {
+			return fmt.Errorf("failed to forget the conversation: %w", err)
+		}
+		for _, interaction := range interactions {
+			if interaction.AudioFile != "" {
+				os.Remove(interaction.AudioFile)
+			}
+		}
+		removed = len(interactions)
+	}
+	if audioPath != "" {
+		os.Remove(audioPath)
+	}
+
+	v.lastExchange, v.lastID, v.lastAnswer = "", "", nil
+	v.pending, v.clarifying, v.repair = nil, nil, nil
+	v.logger.Info("🙈 Conversation forgotten", "period", period, "interactions", removed)
+
+	v.speak(ctx, "Hecho, he olvidado lo que hemos hablado "+spokenPeriod(period)+".")
+	return nil
+}
+
+// spokenPeriod says a period of whole minutes or hours ("en la última hora")
+func spokenPeriod(period time.Duration) string {
+	switch {
+	case period == time.Hour:
+		return "en la última hora"
+	case period%time.Hour == 0:
+		return fmt.Sprintf("en las últimas %d horas", int(period/time.Hour))
+	case period == time.Minute:
+		return "en el último minuto"
+	default:
+		return fmt.Sprintf("en los últimos %d minutos", int(period/time.Minute))
+	}
+}
@@ -0,0 +1,429 @@
+// Package voice provides the satellite microphone server
+package voice
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"os"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/mdns"
+	"github.com/jparrill/bobo-desk-pet/pkg/recordings"
+	"github.com/jparrill/bobo-desk-pet/pkg/wyoming"
+)
+
+// typeAuth is the event satellites open with when SATELLITE_TOKEN is set,
+// carrying it as "token"; it is Bobo's own, Wyoming has no authentication
+const typeAuth = "auth"
+
+// authLimits are the only events read before a satellite has authenticated:
+// a short header and data, and no payload
+var authLimits = wyoming.Limits{Header: 4096, Data: 4096}
+
+// maxUtteranceBytes caps the audio buffered for one utterance (~60s at 16kHz 16-bit mono)
+const maxUtteranceBytes = 60 * 16000 * 2
+
+// SatelliteHandlers connect the satellite server to the rest of Bobo
+type SatelliteHandlers struct {
+	// Transcribe turns an utterance saved at audioPath into text
+	Transcribe func(ctx context.Context, audioPath string) (string, error)
+	// Answer runs a transcription through skills and Claude and returns the reply
+	Answer func(ctx context.Context, transcription, audioPath string) (string, error)
+	// Recognize maps text to a local intent and its slots without running it
+	Recognize func(text string) (intent string, slots map[string]string, ok bool)
+}
+
+// SatelliteServer accepts Wyoming-compatible connections from lightweight
+// satellites (ESP32, phone apps, ...) that stream microphone audio to Bobo and
+// play back the spoken answer. The same socket serves Home Assistant's Assist
+// pipeline, which can use Bobo as its speech-to-text, text-to-speech, intent
+// recognition or conversation ("handle") service
+type SatelliteServer struct {
+	config   *config.SatelliteConfig
+	tts      TextToSpeech
+	handlers SatelliteHandlers
+	storage  *recordings.Store
+	logger   *slog.Logger
+}
+
+// NewSatelliteServer creates a satellite server answering with handlers and
+// rendering replies with tts (when it can synthesize to a file)
+func NewSatelliteServer(cfg *config.SatelliteConfig, tts TextToSpeech, handlers SatelliteHandlers) *SatelliteServer {
+	return &SatelliteServer{
+		config:   cfg,
+		tts:      tts,
+		handlers: handlers,
+		logger:   slog.Default(),
+	}
+}
+
+// SetRecordings saves satellite audio in the session directory of store
+func (s *SatelliteServer) SetRecordings(store *recordings.Store) {
+	s.storage = store
+}
+
+// Run accepts satellite connections until ctx is cancelled
+func (s *SatelliteServer) Run(ctx context.Context) {
+	listener, err := net.Listen("tcp", s.config.Listen)
+	if err != nil {
+		s.logger.Warn("Satellite server failed to start", "error", err)
+		return
+	}
+	// Answers cost Claude queries, so only trusted satellites get them
+	if s.config.Token == "" && !s.config.AllowOpen && !loopbackAddr(listener.Addr()) {
+		listener.Close()
+		s.logger.Warn("Satellite server disabled", "error", "SATELLITE_TOKEN is required to listen beyond this machine (or SATELLITE_ALLOW_UNAUTHENTICATED=true)")
+		return
+	}
+	s.logger.Info("📡 Satellite server listening", "addr", s.config.Listen, "token", s.config.Token != "")
+
+	// Let satellites and Home Assistant find the server by themselves, if
+	// they can reach it and only trusted ones get answers
+	switch {
+	case !s.config.Advertise:
+	case loopbackAddr(listener.Addr()):
+		s.logger.Debug("Satellite server not advertised", "reason", "listening on loopback")
+	case s.config.Token == "":
+		s.logger.Warn("Satellite server not advertised", "error", "SATELLITE_ADVERTISE needs SATELLITE_TOKEN")
+	default:
+		go s.advertise(ctx, listener.Addr())
+	}
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Warn("Satellite server stopped", "error", err)
+			}
+			return
+		}
+		go s.serve(ctx, conn)
+	}
+}
+
+// advertise announces the server listening at addr as a Wyoming service on
+// the local network until ctx is cancelled
+func (s *SatelliteServer) advertise(ctx context.Context, addr net.Addr) {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return
+	}
+	advertiser := mdns.NewAdvertiser(mdns.Service{
+		Instance: s.config.Name,
+		Type:     "_wyoming._tcp",
+		Port:     tcp.Port,
+	})
+	if err := advertiser.Run(ctx); err != nil {
+		s.logger.Warn("Satellite server discovery disabled", "error", err)
+	}
+}
+
+// serve handles the events of one satellite connection
+func (s *SatelliteServer) serve(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	remote := conn.RemoteAddr().String()
+	s.logger.Info("📡 Satellite connected", "remote", remote)
+	defer s.logger.Info("📡 Satellite disconnected", "remote", remote)
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	var (
+		format     wyoming.AudioFormat
+		audio      []byte
+		recording  bool
+		transcribe bool
+		authorized = s.config.Token == ""
+	)
+
+	for {
+		limits := wyoming.DefaultLimits
+		if !authorized {
+			limits = authLimits
+		}
+		event, err := wyoming.ReadEventLimits(reader, limits)
+		if err != nil {
+			if ctx.Err() == nil && !isClosedConn(err) {
+				s.logger.Debug("Satellite read failed", "remote", remote, "error", err)
+			}
+			return
+		}
+
+		if !authorized {
+			if event.Type != typeAuth || subtle.ConstantTimeCompare([]byte(event.String("token")), []byte(s.config.Token)) != 1 {
+				s.logger.Warn("📡 Satellite rejected", "remote", remote, "error", "missing or wrong token")
+				s.sendError(conn, "unauthorized")
+				return
+			}
+			authorized = true
+			continue
+		}
+
+		switch event.Type {
+		case wyoming.TypeDescribe:
+			err = wyoming.WriteEvent(conn, s.info())
+
+		case wyoming.TypeTranscribe:
+			// Speech-to-text only: the next utterance gets a transcript, no answer
+			transcribe = true
+
+		case wyoming.TypeSynthesize:
+			err = s.synthesize(ctx, conn, event.String("text"))
+
+		case wyoming.TypeRecognize:
+			err = s.recognize(conn, event.String("text"))
+
+		case wyoming.TypeTranscript:
+			err = s.handle(ctx, conn, remote, event.String("text"), "")
+
+		case wyoming.TypeAudioStart:
+			format = event.Format()
+			audio = audio[:0]
+			recording = true
+
+		case wyoming.TypeAudioChunk:
+			if !recording {
+				continue
+			}
+			if len(audio)+len(event.Payload) > maxUtteranceBytes {
+				recording = false
+				err = s.sendError(conn, "utterance too long")
+				break
+			}
+			audio = append(audio, event.Payload...)
+
+		case wyoming.TypeAudioStop:
+			if !recording {
+				continue
+			}
+			recording = false
+			err = s.answer(ctx, conn, remote, format, audio, transcribe)
+			transcribe = false
+		}
+
+		if err != nil {
+			s.logger.Debug("Satellite write failed", "remote", remote, "error", err)
+			return
+		}
+	}
+}
+
+// answer saves an utterance, transcribes it and, unless only a transcript was
+// requested, runs it through Bobo and sends the reply back
+func (s *SatelliteServer) answer(ctx context.Context, conn net.Conn, remote string, format wyoming.AudioFormat, audio []byte, transcribeOnly bool) error {
+	if len(audio) == 0 {
+		if transcribeOnly {
+			return wyoming.WriteEvent(conn, transcriptEvent(""))
+		}
+		return wyoming.WriteEvent(conn, &wyoming.Event{Type: wyoming.TypeNotHandled})
+	}
+
+	path, err := s.saveUtterance(format, audio)
+	if err != nil {
+		s.logger.Warn("Failed to save satellite audio", "remote", remote, "error", err)
+		return s.sendError(conn, "failed to save audio")
+	}
+
+	s.logger.Info("📡 Utterance from satellite", "remote", remote, "seconds", len(audio)/max(format.Rate*format.Width*format.Channels, 1))
+	transcript, err := s.handlers.Transcribe(ctx, path)
+	if err != nil {
+		s.logger.Warn("Satellite transcription failed", "remote", remote, "error", err)
+		return s.sendError(conn, err.Error())
+	}
+
+	if err := wyoming.WriteEvent(conn, transcriptEvent(transcript)); err != nil {
+		return err
+	}
+	if transcribeOnly {
+		return nil
+	}
+	if transcript == "" {
+		return wyoming.WriteEvent(conn, &wyoming.Event{Type: wyoming.TypeNotHandled})
+	}
+
+	return s.handle(ctx, conn, remote, transcript, path)
+}
+
+// handle answers a transcript and sends the reply as text and speech
+func (s *SatelliteServer) handle(ctx context.Context, conn net.Conn, remote, transcript, audioPath string) error {
+	reply, err := s.handlers.Answer(ctx, transcript, audioPath)
+	if err != nil {
+		s.logger.Warn("Satellite request failed", "remote", remote, "error", err)
+		return s.sendError(conn, err.Error())
+	}
+
+	if reply == "" {
+		return wyoming.WriteEvent(conn, &wyoming.Event{Type: wyoming.TypeNotHandled})
+	}
+	if err := wyoming.WriteEvent(conn, &wyoming.Event{
+		Type: wyoming.TypeHandled,
+		Data: map[string]any{"text": reply},
+	}); err != nil {
+		return err
+	}
+
+	return s.sendSpeech(ctx, conn, reply)
+}
+
+// synthesize serves a text-to-speech request
+func (s *SatelliteServer) synthesize(ctx context.Context, conn net.Conn, text string) error {
+	format, pcm, err := s.render(ctx, text)
+	if err != nil {
+		s.logger.Warn("Failed to synthesize speech", "error", err)
+		return s.sendError(conn, err.Error())
+	}
+	return wyoming.WriteAudio(conn, format, pcm)
+}
+
+// recognize serves an intent recognition request from the local skills
+func (s *SatelliteServer) recognize(conn net.Conn, text string) error {
+	name, slots, ok := s.handlers.Recognize(text)
+	if !ok {
+		return wyoming.WriteEvent(conn, &wyoming.Event{
+			Type: wyoming.TypeNotRecognized,
+			Data: map[string]any{"text": text},
+		})
+	}
+
+	entities := make([]any, 0, len(slots))
+	for slot, value := range slots {
+		entities = append(entities, map[string]any{"name": slot, "value": value})
+	}
+	return wyoming.WriteEvent(conn, &wyoming.Event{
+		Type: wyoming.TypeIntent,
+		Data: map[string]any{"name": name, "entities": entities, "text": text},
+	})
+}
+
+// transcriptEvent builds a transcript event
+func transcriptEvent(text string) *wyoming.Event {
+	return &wyoming.Event{Type: wyoming.TypeTranscript, Data: map[string]any{"text": text}}
+}
+
+// saveUtterance writes satellite audio next to the local recordings
+func (s *SatelliteServer) saveUtterance(format wyoming.AudioFormat, audio []byte) (string, error) {
+	path := s.storage.NewPath("satellite_recording")
+	err := writeWAV(path, audio, wavFormat{
+		SampleRate:    format.Rate,
+		Channels:      format.Channels,
+		BitsPerSample: format.Width * 8,
+	})
+	return path, err
+}
+
+// sendSpeech synthesizes the reply and streams it to the satellite; satellites
+// still get the text in the handled event when no audio can be produced
+func (s *SatelliteServer) sendSpeech(ctx context.Context, conn net.Conn, text string) error {
+	if _, ok := s.tts.(Synthesizer); !ok {
+		return nil
+	}
+
+	format, pcm, err := s.render(ctx, text)
+	if err != nil {
+		s.logger.Warn("Failed to synthesize satellite reply", "error", err)
+		return nil
+	}
+	return wyoming.WriteAudio(conn, format, pcm)
+}
+
+// render synthesizes text with the local TTS engine and returns its raw PCM
+func (s *SatelliteServer) render(ctx context.Context, text string) (wyoming.AudioFormat, []byte, error) {
+	var format wyoming.AudioFormat
+
+	synth, ok := s.tts.(Synthesizer)
+	if !ok {
+		return format, nil, fmt.Errorf("text-to-speech cannot render audio")
+	}
+
+	file, err := os.CreateTemp("", "bobo-reply-*.wav")
+	if err != nil {
+		return format, nil, fmt.Errorf("failed to create reply audio file: %w", err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	if err := synth.Synthesize(ctx, text, file.Name()); err != nil {
+		return format, nil, err
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return format, nil, fmt.Errorf("failed to read reply audio: %w", err)
+	}
+	wav, pcm, err := parseWAV(data)
+	if err != nil {
+		return format, nil, fmt.Errorf("invalid reply audio: %w", err)
+	}
+
+	format = wyoming.AudioFormat{Rate: wav.SampleRate, Width: wav.BitsPerSample / 8, Channels: wav.Channels}
+	return format, pcm, nil
+}
+
+// sendError reports a failure to the satellite
+func (s *SatelliteServer) sendError(conn net.Conn, text string) error {
+	return wyoming.WriteEvent(conn, &wyoming.Event{
+		Type: wyoming.TypeError,
+		Data: map[string]any{"text": text, "code": "bobo-error"},
+	})
+}
+
+// info describes the services Bobo offers: speech-to-text, text-to-speech
+// (when the TTS engine can render audio), intent recognition and answering
+func (s *SatelliteServer) info() *wyoming.Event {
+	attribution := map[string]any{"name": "Bobo", "url": "https://github.com/jparrill/bobo-desk-pet"}
+	service := func(name, description string, extra map[string]any) []any {
+		program := map[string]any{
+			"name":        name,
+			"description": description,
+			"attribution": attribution,
+			"installed":   true,
+		}
+		maps.Copy(program, extra)
+		return []any{program}
+	}
+	model := map[string]any{
+		"name":        "bobo",
+		"description": "Bobo",
+		"attribution": attribution,
+		"installed":   true,
+		"languages":   []string{"es", "en"},
+	}
+
+	data := map[string]any{
+		"asr":    service("bobo-asr", "Bobo speech-to-text", map[string]any{"models": []any{model}}),
+		"intent": service("bobo-intent", "Bobo local skills", map[string]any{"models": []any{model}}),
+		"handle": service("bobo", "Bobo desk pet assistant", map[string]any{"models": []any{model}}),
+	}
+	if _, ok := s.tts.(Synthesizer); ok {
+		data["tts"] = service("bobo-tts", "Bobo text-to-speech", map[string]any{"voices": []any{model}})
+	}
+
+	return &wyoming.Event{Type: wyoming.TypeInfo, Data: data}
+}
+
+// loopbackAddr reports whether a listener at addr can only be reached from
+// this machine
+func loopbackAddr(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// isClosedConn reports whether err only means the connection went away
+func isClosedConn(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)
+}
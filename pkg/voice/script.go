@@ -0,0 +1,80 @@
+package voice
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/card"
+	"github.com/jparrill/bobo-desk-pet/pkg/history"
+)
+
+// scriptAnswer is one answer printed by RunScript in JSON mode
+type scriptAnswer struct {
+	Question  string       `json:"question"`
+	Answer    string       `json:"answer"`
+	Cards     []*card.Card `json:"cards,omitempty"`
+	LatencyMs int64        `json:"latency_ms"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// RunScript answers newline-delimited questions from in, writing each answer
+// to out as a line of text or, when jsonOutput is set, as a JSON object.
+// "+" and "-" rate the previous answer and "q" stops, as at the prompt
+func (v *Interface) RunScript(ctx context.Context, in io.Reader, out io.Writer, jsonOutput bool) error {
+	scanner := bufio.NewScanner(in)
+	encoder := json.NewEncoder(out)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+			continue
+		case "q":
+			return nil
+		case "+":
+			v.recordFeedback(history.FeedbackPositive)
+			continue
+		case "-":
+			v.recordFeedback(history.FeedbackNegative)
+			continue
+		}
+
+		start := time.Now()
+		reply, err := v.answer(ctx, line, "")
+		answer := ""
+		if reply != nil {
+			answer = strings.Join(reply.parts, " ")
+		}
+		if !jsonOutput {
+			// Keep one line per question so the output lines up with the input
+			if err != nil {
+				v.logger.Error("Failed to answer", "question", line, "error", err)
+			}
+			fmt.Fprintln(out, answer)
+			continue
+		}
+
+		result := scriptAnswer{Question: line, Answer: answer, LatencyMs: time.Since(start).Milliseconds()}
+		if reply != nil {
+			result.Cards = reply.cards
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("failed to write answer: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return nil
+}
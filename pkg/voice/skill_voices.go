@@ -0,0 +1,69 @@
+package voice
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/skills"
+)
+
+// skillVoice is the voice and rate a skill's answers are spoken with; empty
+// fields keep the persona's
+type skillVoice struct {
+	voiceID string
+	rate    int
+}
+
+// parseVoices reads SKILL_VOICES ("story=es+f3:140", "reminders=es+m2") and
+// LANGUAGE_VOICES ("en=Samantha:180") entries, skipping the malformed ones
+func parseVoices(entries []string, logger *slog.Logger) map[string]skillVoice {
+	voices := make(map[string]skillVoice)
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			logger.Warn("Ignoring invalid voice", "entry", entry)
+			continue
+		}
+
+		var voice skillVoice
+		voiceID, rate, hasRate := strings.Cut(value, ":")
+		voice.voiceID = strings.TrimSpace(voiceID)
+		if hasRate {
+			n, err := strconv.Atoi(strings.TrimSpace(rate))
+			if err != nil || n <= 0 {
+				logger.Warn("Ignoring invalid voice rate", "entry", entry)
+				continue
+			}
+			voice.rate = n
+		}
+		voices[name] = voice
+	}
+	return voices
+}
+
+// skillVoice picks the voice a skill's answer is spoken with: the answer's
+// own, then SKILL_VOICES, then the skill's manifest, then the persona's. It
+// reports whether that differs from the persona's
+func (v *Interface) skillVoice(skill skills.Skill, result *skills.Result) (voiceID string, rate int, changed bool) {
+	voiceID, rate = v.voice()
+	choices := []skillVoice{}
+	if voiced, ok := skill.(skills.Voiced); ok {
+		id, r := voiced.Voice()
+		choices = append(choices, skillVoice{voiceID: id, rate: r})
+	}
+	choices = append(choices, v.skillVoices[skill.Name()])
+	choices = append(choices, skillVoice{voiceID: result.VoiceID, rate: result.SpeechRate})
+
+	// Later choices win
+	for _, choice := range choices {
+		if choice.voiceID != "" {
+			voiceID, changed = choice.voiceID, true
+		}
+		if choice.rate > 0 {
+			rate, changed = choice.rate, true
+		}
+	}
+	return voiceID, rate, changed
+}
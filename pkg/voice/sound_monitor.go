@@ -0,0 +1,282 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// monitorSampleRate is the rate used for sound analysis
+const monitorSampleRate = 16000
+
+// SoundEvent is a detected non-speech sound
+type SoundEvent struct {
+	Type    string    `json:"type"`
+	LevelDB float64   `json:"level_db"`
+	Time    time.Time `json:"time"`
+}
+
+// Sound event types
+const (
+	SoundLoudNoise = "loud_noise"
+	SoundAlarm     = "alarm"
+	SoundDoorbell  = "doorbell"
+)
+
+// SoundMonitor listens to the microphone continuously and classifies
+// non-speech events with simple level/tonality heuristics
+type SoundMonitor struct {
+	config   *config.SoundConfig
+	recorder *AudioRecorder
+	client   *http.Client
+	echo     *echoGate
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	paused   bool
+	cancel   context.CancelFunc
+	lastSent map[string]time.Time
+}
+
+// NewSoundMonitor creates a sound monitor that reuses the recorder's input device
+func NewSoundMonitor(cfg *config.SoundConfig, recorder *AudioRecorder) *SoundMonitor {
+	return &SoundMonitor{
+		config:   cfg,
+		recorder: recorder,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		logger:   slog.Default(),
+		lastSent: make(map[string]time.Time),
+	}
+}
+
+// Run keeps capturing and classifying audio until ctx is cancelled, calling
+// onEvent for every detection outside the cooldown window
+func (m *SoundMonitor) Run(ctx context.Context, onEvent func(SoundEvent)) {
+	for ctx.Err() == nil {
+		m.mu.Lock()
+		paused := m.paused
+		m.mu.Unlock()
+
+		if paused {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(500 * time.Millisecond):
+			}
+			continue
+		}
+
+		if err := m.capture(ctx, onEvent); err != nil && ctx.Err() == nil {
+			m.logger.Warn("Sound monitor capture stopped, retrying", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}
+}
+
+// SetPaused releases the microphone (e.g. during a recording) or resumes monitoring
+func (m *SoundMonitor) SetPaused(paused bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.paused = paused
+	if paused && m.cancel != nil {
+		m.cancel()
+	}
+}
+
+// SetEchoGate ignores the audio captured while Bobo talks
+func (m *SoundMonitor) SetEchoGate(echo *echoGate) {
+	m.echo = echo
+}
+
+// Restart stops the current capture so it is reopened on the recorder's
+// current input device
+func (m *SoundMonitor) Restart() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+}
+
+// capture streams raw PCM from ffmpeg and analyzes it in 100ms frames
+func (m *SoundMonitor) capture(ctx context.Context, onEvent func(SoundEvent)) error {
+	input := m.recorder.inputArgs(m.recorder.captureBackend())
+	if input == nil {
+		return fmt.Errorf("unsupported platform for audio capture")
+	}
+
+	captureCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	if m.paused {
+		m.mu.Unlock()
+		return nil
+	}
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	args := append([]string{"-loglevel", "error"}, input...)
+	args = append(args, "-ac", "1", "-ar", fmt.Sprint(monitorSampleRate), "-f", "s16le", "-")
+	cmd := exec.CommandContext(captureCtx, "ffmpeg", args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open ffmpeg output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+	defer cmd.Wait()
+
+	classifier := newSoundClassifier(m.config)
+	frame := make([]byte, monitorSampleRate/10*2)
+
+	for {
+		if _, err := io.ReadFull(stdout, frame); err != nil {
+			if captureCtx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("error reading audio: %w", err)
+		}
+		if m.echo.muted() {
+			continue
+		}
+
+		if event, ok := classifier.push(bytesToSamples(frame)); ok {
+			if m.shouldEmit(event.Type) {
+				m.logger.Info("👂 Sound detected", "type", event.Type, "level_db", fmt.Sprintf("%.1f", event.LevelDB))
+				onEvent(event)
+				m.sendWebhook(event)
+			}
+		}
+	}
+}
+
+// shouldEmit applies the per-type cooldown
+func (m *SoundMonitor) shouldEmit(eventType string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cooldown := time.Duration(m.config.CooldownSeconds) * time.Second
+	if last, ok := m.lastSent[eventType]; ok && time.Since(last) < cooldown {
+		return false
+	}
+	m.lastSent[eventType] = time.Now()
+	return true
+}
+
+// sendWebhook posts the event to the configured webhook, if any
+func (m *SoundMonitor) sendWebhook(event SoundEvent) {
+	if m.config.WebhookURL == "" {
+		return
+	}
+
+	go func() {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		resp, err := m.client.Post(m.config.WebhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			m.logger.Warn("Sound webhook failed", "error", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			m.logger.Warn("Sound webhook rejected event", "status", resp.StatusCode)
+		}
+	}()
+}
+
+// soundClassifier keeps a little state across 100ms frames
+type soundClassifier struct {
+	loudThreshold float64
+	baseline      float64
+	tonalFrames   int
+	tonalBand     string
+	tonalFreq     float64
+	peakLevel     float64
+}
+
+func newSoundClassifier(cfg *config.SoundConfig) *soundClassifier {
+	return &soundClassifier{
+		loudThreshold: cfg.LoudThresholdDB,
+		baseline:      -60,
+	}
+}
+
+// push analyzes one frame and returns an event when one is recognized
+func (c *soundClassifier) push(samples []int16) (SoundEvent, bool) {
+	level := rmsDB(samples)
+	now := time.Now()
+
+	// Track the background noise floor slowly
+	if level > -100 {
+		c.baseline = c.baseline*0.98 + level*0.02
+	}
+
+	// Tonal sounds well above the background: alarms beep high, doorbells chime lower
+	tonal := false
+	if level > c.baseline+10 {
+		ratio, frequency := tonality(samples, monitorSampleRate, 300, 4000)
+		switch {
+		case ratio > 0.2 && frequency >= 2000:
+			tonal = c.trackTone("high", frequency, level)
+		case ratio > 0.3 && frequency <= 1500:
+			tonal = c.trackTone("low", frequency, level)
+		}
+	}
+
+	if !tonal && c.tonalFrames > 0 {
+		// Tone ended: a short chime is a doorbell
+		frames, band, peak := c.tonalFrames, c.tonalBand, c.peakLevel
+		c.tonalFrames, c.tonalBand, c.peakLevel = 0, "", -100
+		if band == "low" && frames >= 3 && frames <= 20 {
+			return SoundEvent{Type: SoundDoorbell, LevelDB: peak, Time: now}, true
+		}
+	}
+
+	// A sustained high-pitched tone (2s+) is an alarm
+	if c.tonalBand == "high" && c.tonalFrames == 20 {
+		return SoundEvent{Type: SoundAlarm, LevelDB: c.peakLevel, Time: now}, true
+	}
+
+	if !tonal && level >= c.loudThreshold {
+		return SoundEvent{Type: SoundLoudNoise, LevelDB: level, Time: now}, true
+	}
+
+	return SoundEvent{}, false
+}
+
+// trackTone counts consecutive tonal frames in the same band at a stable pitch
+// (speech pitch wanders, bells and beeps don't)
+func (c *soundClassifier) trackTone(band string, frequency, level float64) bool {
+	if c.tonalBand != band || math.Abs(frequency-c.tonalFreq) > 100 {
+		c.tonalBand = band
+		c.tonalFreq = frequency
+		c.tonalFrames = 0
+		c.peakLevel = -100
+	}
+	c.tonalFrames++
+	if level > c.peakLevel {
+		c.peakLevel = level
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package voice
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+)
+
+// speechPreferencesNamespace is where the user's spoken voice adjustments
+// are kept in the memory store, so they outlive the session
+const speechPreferencesNamespace = "speech"
+
+// Spoken adjustments move the rate and volume by a step, half a step for
+// "un poco" and two for "mucho", within sensible limits
+const (
+	rateStep     = 20 // words per minute
+	minRateDelta = -80
+	maxRateDelta = 120
+	gainStep     = 0.2
+	minGain      = 0.4
+	maxGain      = 2.0 // espeak's loudest
+)
+
+// speechPreferences are the user's adjustments on top of every voice
+type speechPreferences struct {
+	RateDelta int     `json:"rate_delta"`
+	Gain      float64 `json:"gain,omitempty"`
+}
+
+// gain returns the volume factor, 1 when never adjusted
+func (p speechPreferences) gain() float64 {
+	if p.Gain <= 0 {
+		return 1
+	}
+	return p.Gain
+}
+
+// speechAdjustment is a spoken request to change how Bobo talks
+type speechAdjustment struct {
+	rate  int     // words per minute to add
+	gain  float64 // volume factor change
+	reset bool    // back to the configured voice
+	reply string
+}
+
+// speechAdjustments are the recognized requests, matched against the whole
+// utterance once polite fillers are dropped, so "¿quién es más alto?" is
+// still a question
+var speechAdjustments = []struct {
+	phrases []string
+	rate    int
+	gain    float64
+	reply   string
+}{
+	{[]string{"más despacio", "mas despacio", "más lento", "mas lento", "slower", "slow down", "more slowly"}, -rateStep, 0, "Vale, hablaré más despacio."},
+	{[]string{"más rápido", "mas rapido", "más deprisa", "mas deprisa", "faster", "speed up", "more quickly"}, rateStep, 0, "Vale, hablaré más rápido."},
+	{[]string{"más alto", "mas alto", "sube el volumen", "sube la voz", "louder", "speak up", "volume up"}, 0, gainStep, "Vale, hablaré más alto."},
+	{[]string{"más bajo", "mas bajo", "baja el volumen", "baja la voz", "quieter", "softer", "volume down"}, 0, -gainStep, "Vale, hablaré más bajo."},
+}
+
+// resetPhrases bring back the configured rate and volume
+var resetPhrases = []string{
+	"habla normal", "habla como siempre", "voz normal", "volumen normal", "velocidad normal",
+	"speak normally", "talk normally", "normal voice", "normal volume", "normal speed",
+}
+
+// speechFillers are dropped before matching; the verbs ("habla", "speak")
+// only go for rate and volume changes, since "normal" alone means nothing
+var speechFillers = []string{
+	"bobo", "por favor", "puedes", "podrías", "podrias", "un poco", "un poquito", "algo",
+	"mucho", "bastante", "please", "can you", "could you", "a bit", "a little", "much", "a lot",
+}
+
+// parseSpeechAdjustment recognizes "habla más despacio", "un poco más alto"
+// or "habla normal"
+func parseSpeechAdjustment(transcription string) (speechAdjustment, bool) {
+	text := " " + strings.ToLower(strings.Trim(transcription, " .,!¡?¿")) + " "
+	text = strings.NewReplacer(",", " ", "¿", " ", "?", " ").Replace(text)
+
+	scale := 1.0
+	switch {
+	case strings.Contains(text, " un poco ") || strings.Contains(text, " un poquito ") ||
+		strings.Contains(text, " a bit ") || strings.Contains(text, " a little "):
+		scale = 0.5
+	case strings.Contains(text, " mucho ") || strings.Contains(text, " a lot ") || strings.Contains(text, " much "):
+		scale = 2
+	}
+	for _, filler := range speechFillers {
+		text = strings.ReplaceAll(text, " "+filler+" ", " ")
+	}
+	text = strings.Join(strings.Fields(text), " ")
+
+	if slices.Contains(resetPhrases, text) {
+		return speechAdjustment{reset: true, reply: "Vale, vuelvo a mi voz de siempre."}, true
+	}
+	for _, verb := range []string{"habla", "háblame", "hablame", "speak", "talk"} {
+		text = strings.TrimPrefix(text, verb+" ")
+	}
+	for _, adjustment := range speechAdjustments {
+		if slices.Contains(adjustment.phrases, text) {
+			return speechAdjustment{
+				rate:  int(math.Round(float64(adjustment.rate) * scale)),
+				gain:  adjustment.gain * scale,
+				reply: adjustment.reply,
+			}, true
+		}
+	}
+	return speechAdjustment{}, false
+}
+
+// loadSpeechPreferences applies the adjustments saved in earlier sessions
+func (v *Interface) loadSpeechPreferences() {
+	var prefs speechPreferences
+	if err := v.memory.Load(speechPreferencesNamespace, &prefs); err != nil {
+		v.logger.Warn("Failed to load speech preferences", "error", err)
+		return
+	}
+	if prefs.RateDelta != 0 || prefs.gain() != 1 {
+		v.logger.Info("🗣️ Speech preferences restored", "rate_delta", prefs.RateDelta, "gain", prefs.gain())
+	}
+	v.applySpeechPreferences(prefs)
+}
+
+// adjustSpeech changes how Bobo talks from now on and saves it
+func (v *Interface) adjustSpeech(ctx context.Context, adjustment speechAdjustment) error {
+	var prefs speechPreferences
+	if err := v.memory.Load(speechPreferencesNamespace, &prefs); err != nil {
+		v.logger.Warn("Failed to load speech preferences", "error", err)
+	}
+	if adjustment.reset {
+		prefs = speechPreferences{}
+	} else {
+		prefs.RateDelta = min(max(prefs.RateDelta+adjustment.rate, minRateDelta), maxRateDelta)
+		prefs.Gain = math.Round(min(max(prefs.gain()+adjustment.gain, minGain), maxGain)*100) / 100
+	}
+	if err := v.memory.Save(speechPreferencesNamespace, prefs); err != nil {
+		v.logger.Warn("Failed to save speech preferences", "error", err)
+	}
+	v.logger.Info("🗣️ Speech adjusted", "rate_delta", prefs.RateDelta, "gain", prefs.gain())
+
+	if !v.applySpeechPreferences(prefs) {
+		v.speak(ctx, "Lo apunto, pero esta voz no se puede ajustar.")
+		return nil
+	}
+	v.speak(ctx, adjustment.reply)
+	return nil
+}
+
+// applySpeechPreferences pushes the adjustments into the TTS engines,
+// reporting whether the main one can apply them
+func (v *Interface) applySpeechPreferences(prefs speechPreferences) bool {
+	if adjuster, ok := v.localTTS.(SpeechAdjuster); ok {
+		adjuster.Adjust(prefs.RateDelta, prefs.gain())
+	}
+	adjuster, ok := v.tts.(SpeechAdjuster)
+	return ok && adjuster.Adjust(prefs.RateDelta, prefs.gain())
+}
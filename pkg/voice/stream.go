@@ -0,0 +1,321 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// streamChunk is how much audio the stream reads at a time
+const streamChunk = 20 * time.Millisecond
+
+// StreamRecorder keeps the microphone open and the latest audio in a ring
+// buffer, so that recordings start instantly and consumers such as wake-word
+// or voice activity detectors can follow the audio live
+type StreamRecorder struct {
+	recorder *AudioRecorder
+	format   wavFormat
+	preRoll  int // bytes of audio before the start of a recording kept in it
+	echo     *echoGate
+	logger   *slog.Logger
+
+	mu          sync.Mutex
+	ring        []byte
+	written     int64 // bytes captured since the stream started
+	preRollFrom int64 // position the pre-roll may not reach back past
+	live        bool
+	paused      bool
+	cancel      context.CancelFunc
+	subscribers map[chan []byte]struct{}
+}
+
+// NewStreamRecorder creates a stream that captures with the recorder's
+// backend and input device, keeping the last StreamBufferSeconds of audio
+func NewStreamRecorder(cfg *config.VoiceConfig, recorder *AudioRecorder) *StreamRecorder {
+	format := recorder.captureFormat()
+	size := cfg.StreamBufferSeconds * format.SampleRate * format.Channels * 2
+	frame := format.Channels * format.BitsPerSample / 8
+	return &StreamRecorder{
+		recorder:    recorder,
+		format:      format,
+		preRoll:     format.bytesPerSecond() * cfg.PreRollMillis / 1000 / frame * frame,
+		logger:      slog.Default(),
+		ring:        make([]byte, max(size, format.bytesPerSecond())),
+		subscribers: make(map[chan []byte]struct{}),
+	}
+}
+
+// bytesPerSecond is the data rate of the PCM format
+func (f wavFormat) bytesPerSecond() int {
+	return f.SampleRate * f.Channels * f.BitsPerSample / 8
+}
+
+// Run keeps capturing until ctx is cancelled, restarting the capture when it stops
+func (s *StreamRecorder) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		s.mu.Lock()
+		paused := s.paused
+		s.mu.Unlock()
+
+		if paused {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(500 * time.Millisecond):
+			}
+			continue
+		}
+
+		if err := s.capture(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Warn("Audio stream stopped, retrying", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}
+}
+
+// capture runs the capture command, filling the ring buffer
+func (s *StreamRecorder) capture(ctx context.Context) error {
+	backend := s.recorder.captureBackend()
+	if backend == "" {
+		return fmt.Errorf("unsupported platform for audio capture")
+	}
+
+	captureCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	if s.paused {
+		s.mu.Unlock()
+		return nil
+	}
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	cmd, err := s.recorder.streamCommand(captureCtx, backend)
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open capture output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s capture: %w", backend, err)
+	}
+	defer cmd.Wait()
+
+	s.logger.Info("🎙️ Audio stream started", "backend", backend, "buffer_seconds", len(s.ring)/s.format.bytesPerSecond())
+	defer s.setLive(false)
+
+	// What was buffered before a pause is too old for a pre-roll
+	s.ClearPreRoll()
+
+	// Whole frames only, so that samples never straddle two reads
+	device := s.recorder.deviceFormat()
+	frame := device.Channels * 2
+	chunk := make([]byte, device.bytesPerSecond()*int(streamChunk/time.Millisecond)/1000/frame*frame)
+	for {
+		n, err := io.ReadFull(stdout, chunk)
+		if n -= n % frame; n > 0 {
+			s.push(s.recorder.toMono(chunk[:n]))
+		}
+		if err != nil {
+			if captureCtx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("error reading audio: %w", err)
+		}
+	}
+}
+
+// Restart reopens the capture on the recorder's current input device
+func (s *StreamRecorder) Restart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// SetPaused closes the microphone (e.g. when nobody is around) or reopens it
+func (s *StreamRecorder) SetPaused(paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = paused
+	if paused && s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// setLive records whether audio is flowing
+func (s *StreamRecorder) setLive(live bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.live = live
+}
+
+// SetEchoGate replaces the audio captured while Bobo talks with silence
+func (s *StreamRecorder) SetEchoGate(echo *echoGate) {
+	s.echo = echo
+}
+
+// push appends captured audio to the ring buffer and hands it to the subscribers
+func (s *StreamRecorder) push(data []byte) {
+	if s.echo.muted() {
+		clear(data)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.live = true
+	chunk := append([]byte(nil), data...)
+	for len(data) > 0 {
+		offset := int(s.written % int64(len(s.ring)))
+		n := copy(s.ring[offset:], data)
+		s.written += int64(n)
+		data = data[n:]
+	}
+
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- chunk:
+		default:
+			// Slow consumers miss audio rather than stall the capture
+		}
+	}
+}
+
+// ClearPreRoll keeps the audio captured so far out of the pre-roll of the
+// next recording, such as Bobo's own voice right before the user talks
+func (s *StreamRecorder) ClearPreRoll() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preRollFrom = s.written
+}
+
+// Live reports whether the stream is capturing
+func (s *StreamRecorder) Live() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.live
+}
+
+// Format returns the sample rate and channels of the captured PCM (16-bit)
+func (s *StreamRecorder) Format() (sampleRate, channels int) {
+	return s.format.SampleRate, s.format.Channels
+}
+
+// Position returns the stream position, in bytes captured so far
+func (s *StreamRecorder) Position() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.written
+}
+
+// Since returns a copy of the audio captured after position pos that is
+// still buffered, and the new position
+func (s *StreamRecorder) Since(pos int64) ([]byte, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.since(pos)
+}
+
+// since implements Since; callers hold the lock
+func (s *StreamRecorder) since(pos int64) ([]byte, int64) {
+	size := int64(len(s.ring))
+	pos = max(pos, s.written-size, 0)
+	out := make([]byte, 0, s.written-pos)
+	for pos < s.written {
+		offset := pos % size
+		end := min(size, offset+s.written-pos)
+		out = append(out, s.ring[offset:end]...)
+		pos += end - offset
+	}
+	return out, pos
+}
+
+// Subscribe returns a channel with each chunk of audio as it is captured
+// (shared between subscribers, so read-only), and a function that ends the
+// subscription
+func (s *StreamRecorder) Subscribe() (<-chan []byte, func()) {
+	ch := make(chan []byte, 64)
+
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subscribers, ch)
+			s.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// record writes the audio captured from now on, after the pre-roll, to a
+// WAV file at path, for durationSeconds or until release is closed
+func (s *StreamRecorder) record(ctx context.Context, path string, durationSeconds int, release <-chan struct{}) error {
+	file, err := createWAV(path, s.format)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	s.mu.Lock()
+	end := s.written + int64(durationSeconds*s.format.bytesPerSecond())
+	pos := max(s.written-int64(s.preRoll), s.preRollFrom)
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for stopped := false; !stopped; {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-release:
+			stopped = true
+		case <-ticker.C:
+			if !s.Live() {
+				return fmt.Errorf("audio stream stopped while recording")
+			}
+		}
+
+		data, next := s.Since(pos)
+		start := next - int64(len(data))
+		if start > pos {
+			s.logger.Warn("Recording fell behind the audio stream", "lost_bytes", start-pos)
+		}
+		if next > end {
+			data = data[:max(end-start, 0)]
+		}
+		pos = next
+		if _, err := file.Write(data); err != nil {
+			return err
+		}
+		if pos >= end {
+			stopped = true
+		}
+	}
+
+	return file.Close()
+}
@@ -8,7 +8,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jparrill/bobo-desk-pet/pkg/config"
@@ -19,11 +23,152 @@ type Transcriber interface {
 	Transcribe(ctx context.Context, audioFilePath, language string) (string, error)
 }
 
+// AlternativesTranscriber is implemented by transcribers that can offer other
+// hypotheses for a recording, used to repair misheard questions
+type AlternativesTranscriber interface {
+	Alternatives(ctx context.Context, audioFilePath, language string, n int) ([]string, error)
+}
+
+// ModelTranscriber is implemented by transcribers whose model can be swapped
+// at runtime, used to switch to a lighter one on battery
+type ModelTranscriber interface {
+	// SetModel switches to the model at path, or back to the configured one if empty
+	SetModel(path string)
+}
+
+// StreamingTranscriber is implemented by transcribers that can follow a
+// recording while it is being made, reporting interim hypotheses
+type StreamingTranscriber interface {
+	// TranscribeStream transcribes the recording growing at audioFilePath
+	// until ctx is cancelled, calling partial with each new hypothesis
+	TranscribeStream(ctx context.Context, audioFilePath, language string, partial func(text string)) error
+}
+
+// NewTranscriber creates the configured speech-to-text engine, backed by the
+// TRANSCRIBER_FALLBACK engines when there are any
+func NewTranscriber(cfg *config.Config) (Transcriber, error) {
+	transcriber, err := newEngine(cfg, TranscriberName(cfg))
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Voice.TranscriberFallback) == 0 {
+		return transcriber, nil
+	}
+	return newFallbackTranscriber(cfg, transcriber), nil
+}
+
+// newEngine creates the speech-to-text engine called engine
+func newEngine(cfg *config.Config, engine string) (Transcriber, error) {
+	switch engine {
+	case "wyoming":
+		transcriber, err := NewWyomingTranscriber(cfg.Wyoming)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Wyoming speech-to-text: %w", err)
+		}
+		return transcriber, nil
+	case "whisper.cpp":
+		transcriber, err := NewWhisperCppTranscriber(cfg.Voice)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize whisper.cpp: %w", err)
+		}
+		return transcriber, nil
+	case "whisper-lib":
+		transcriber, err := newWhisperLibTranscriber(cfg.Voice)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize in-process whisper: %w", err)
+		}
+		return transcriber, nil
+	case "vosk":
+		transcriber, err := newVoskTranscriber(cfg.Voice)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Vosk: %w", err)
+		}
+		return transcriber, nil
+	case "google":
+		transcriber, err := NewGoogleSTTTranscriber(cfg.Voice, cfg.VertexAI.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Google Speech-to-Text: %w", err)
+		}
+		return transcriber, nil
+	case "openai":
+		transcriber, err := NewOpenAITranscriber(cfg.Voice)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize the OpenAI Whisper API: %w", err)
+		}
+		return transcriber, nil
+	case "deepgram":
+		transcriber, err := NewDeepgramTranscriber(cfg.Voice)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Deepgram: %w", err)
+		}
+		return transcriber, nil
+	case "python-whisper":
+		// TODO: Implement Python Whisper fallback
+		return nil, fmt.Errorf("Python Whisper not implemented yet, use whisper.cpp")
+	default:
+		return nil, fmt.Errorf("unknown transcriber %q (available: whisper.cpp, whisper-lib, vosk, wyoming, google, openai, deepgram)", engine)
+	}
+}
+
+// TranscriberName names the speech-to-text engine NewTranscriber creates:
+// TRANSCRIBER, or else Wyoming when WYOMING_ASR_URI is set and whisper.cpp
+// unless USE_WHISPER_CPP is off
+func TranscriberName(cfg *config.Config) string {
+	switch {
+	case cfg.Voice.Transcriber != "":
+		return cfg.Voice.Transcriber
+	case cfg.Wyoming.ASRURI != "":
+		return "wyoming"
+	case cfg.Voice.UseWhisperCpp:
+		return "whisper.cpp"
+	default:
+		return "python-whisper"
+	}
+}
+
+// cloudTranscribers are the engines that send recordings to a third-party API
+var cloudTranscribers = map[string]bool{"google": true, "openai": true, "deepgram": true}
+
+// whisperThreads is how many CPU threads whisper.cpp uses: WHISPER_THREADS,
+// or every core when it is 0
+func whisperThreads(cfg *config.VoiceConfig) int {
+	if cfg.WhisperThreads > 0 {
+		return cfg.WhisperThreads
+	}
+	return runtime.NumCPU()
+}
+
+// whisperRuntimeArgs are the whisper-cli and whisper-server arguments for
+// the threads and GPU to run on
+func whisperRuntimeArgs(cfg *config.VoiceConfig) []string {
+	args := []string{"--threads", strconv.Itoa(whisperThreads(cfg))}
+	if !cfg.WhisperGPU {
+		args = append(args, "--no-gpu")
+	} else if cfg.WhisperGPUDevice > 0 {
+		args = append(args, "--device", strconv.Itoa(cfg.WhisperGPUDevice))
+	}
+	if cfg.WhisperFlashAttn {
+		args = append(args, "--flash-attn")
+	}
+	return args
+}
+
+// alternativeDecodings are the whisper.cpp decoding settings tried for
+// alternative hypotheses, since whisper-cli cannot print an n-best list
+var alternativeDecodings = [][]string{
+	{"--beam-size", "5"},
+	{"--temperature", "0.4", "--best-of", "5"},
+	{"--temperature", "0.8", "--best-of", "5"},
+}
+
 // WhisperCppTranscriber implements transcription using whisper.cpp
 type WhisperCppTranscriber struct {
 	config         *config.VoiceConfig
 	whisperCppPath string
-	modelPath      string
+	server         *whisperServer // keeps the model loaded, nil to run whisper-cli per request
+
+	mu        sync.Mutex
+	modelPath string
 }
 
 // NewWhisperCppTranscriber creates a new whisper.cpp transcriber
@@ -33,14 +178,59 @@ func NewWhisperCppTranscriber(cfg *config.VoiceConfig) (*WhisperCppTranscriber,
 		modelPath: cfg.WhisperModelPath,
 	}
 
-	// Find whisper.cpp binary
-	if err := transcriber.findWhisperCpp(); err != nil {
+	// Find whisper.cpp binary; with an external server it's only a fallback
+	if err := transcriber.findWhisperCpp(); err != nil && cfg.WhisperServerURL == "" {
 		return nil, fmt.Errorf("whisper.cpp not found: %w", err)
 	}
 
+	switch {
+	case cfg.WhisperServerURL != "":
+		transcriber.server = newWhisperServer("", cfg.WhisperServerURL, nil)
+		fmt.Fprintf(Console, "✅ Using the whisper.cpp server at: %s\n", cfg.WhisperServerURL)
+	case cfg.WhisperServer:
+		binary, err := findWhisperServer(transcriber.whisperCppPath)
+		if err != nil {
+			fmt.Fprintf(Console, "⚠️  %v, running whisper-cli per request\n", err)
+			break
+		}
+		transcriber.server = newWhisperServer(binary, "", whisperRuntimeArgs(cfg))
+		// Load the model while the rest of Bobo starts
+		go func() {
+			if _, err := transcriber.server.ready(transcriber.model()); err != nil {
+				fmt.Fprintf(Console, "⚠️  whisper.cpp server failed to start: %v\n", err)
+			}
+		}()
+	}
+
 	return transcriber, nil
 }
 
+// Close stops the whisper.cpp server Bobo started, if any
+func (w *WhisperCppTranscriber) Close() error {
+	if w.server == nil {
+		return nil
+	}
+	return w.server.Close()
+}
+
+// SetModel switches to the model at path, or back to WhisperModelPath if empty
+func (w *WhisperCppTranscriber) SetModel(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if path == "" {
+		path = w.config.WhisperModelPath
+	}
+	w.modelPath = path
+}
+
+// model returns the model in use
+func (w *WhisperCppTranscriber) model() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.modelPath
+}
+
 // findWhisperCpp locates the whisper.cpp binary
 func (w *WhisperCppTranscriber) findWhisperCpp() error {
 	// Try environment path first
@@ -49,7 +239,7 @@ func (w *WhisperCppTranscriber) findWhisperCpp() error {
 			// Test if it's executable
 			if err := w.testWhisperCpp(w.config.WhisperCppPath); err == nil {
 				w.whisperCppPath = w.config.WhisperCppPath
-				fmt.Printf("✅ Found whisper.cpp at: %s\n", w.whisperCppPath)
+				fmt.Fprintf(Console, "✅ Found whisper.cpp at: %s\n", w.whisperCppPath)
 				return nil
 			}
 		}
@@ -70,7 +260,7 @@ func (w *WhisperCppTranscriber) findWhisperCpp() error {
 	for _, path := range searchPaths {
 		if err := w.testWhisperCpp(path); err == nil {
 			w.whisperCppPath = path
-			fmt.Printf("✅ Found whisper.cpp at: %s\n", path)
+			fmt.Fprintf(Console, "✅ Found whisper.cpp at: %s\n", path)
 			return nil
 		}
 	}
@@ -99,8 +289,99 @@ func (w *WhisperCppTranscriber) testWhisperCpp(path string) error {
 
 // Transcribe transcribes audio using whisper.cpp
 func (w *WhisperCppTranscriber) Transcribe(ctx context.Context, audioFilePath, language string) (string, error) {
-	if w.whisperCppPath == "" {
-		return "", fmt.Errorf("whisper.cpp not initialized")
+	return w.run(ctx, audioFilePath, language)
+}
+
+// TranscribeStream transcribes what has been recorded so far again and
+// again while the recording is made
+func (w *WhisperCppTranscriber) TranscribeStream(ctx context.Context, audioFilePath, language string, partial func(text string)) error {
+	return followRecording(ctx, audioFilePath, func(ctx context.Context, snapshot string) (string, error) {
+		result, err := w.decode(ctx, snapshot, language)
+		return result.text, err
+	}, partial)
+}
+
+// Alternatives transcribes the recording again with other decoding settings,
+// returning up to n distinct hypotheses
+func (w *WhisperCppTranscriber) Alternatives(ctx context.Context, audioFilePath, language string, n int) ([]string, error) {
+	var alternatives []string
+	seen := make(map[string]bool)
+	for _, decoding := range alternativeDecodings {
+		if len(alternatives) >= n {
+			break
+		}
+		text, err := w.run(ctx, audioFilePath, language, decoding...)
+		if err != nil {
+			return alternatives, err
+		}
+		key := strings.ToLower(strings.Trim(text, " .,!¡?¿"))
+		if key != "" && !seen[key] {
+			seen[key] = true
+			alternatives = append(alternatives, text)
+		}
+	}
+	return alternatives, nil
+}
+
+// TranscribeLanguage lets whisper detect the spoken language, returning the
+// language it picked
+func (w *WhisperCppTranscriber) TranscribeLanguage(ctx context.Context, audioFilePath string) (string, string, error) {
+	result, err := w.decode(ctx, audioFilePath, autoLanguage)
+	result.reportDropped()
+	return result.text, result.language, err
+}
+
+// TranscribeSegments is Transcribe with whisper's confidence in each segment
+func (w *WhisperCppTranscriber) TranscribeSegments(ctx context.Context, audioFilePath, language string) ([]Segment, error) {
+	result, err := w.decode(ctx, audioFilePath, language)
+	result.reportDropped()
+	return result.segments, err
+}
+
+// TranscribeWords is Transcribe with the timing of every word
+func (w *WhisperCppTranscriber) TranscribeWords(ctx context.Context, audioFilePath, language string) (*TranscriptionResult, error) {
+	result, err := w.decode(ctx, audioFilePath, language)
+	if err != nil {
+		return nil, err
+	}
+	return &TranscriptionResult{Text: result.text, Language: result.language, Words: result.words}, nil
+}
+
+// TranscribeSpeakers splits the transcript at the speaker turns marked by
+// tinydiarize, with WHISPER_DIARIZE_MODEL if set
+func (w *WhisperCppTranscriber) TranscribeSpeakers(ctx context.Context, audioFilePath, language string) ([]Turn, error) {
+	model := w.model()
+	if w.config.WhisperDiarizeModel != "" {
+		model = w.config.WhisperDiarizeModel
+	}
+	result, err := w.decodeModel(ctx, audioFilePath, language, model, tinydiarizeArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return result.turns, nil
+}
+
+// run executes whisper.cpp on a recording with extra decoding arguments
+func (w *WhisperCppTranscriber) run(ctx context.Context, audioFilePath, language string, extraArgs ...string) (string, error) {
+	result, err := w.decode(ctx, audioFilePath, language, extraArgs...)
+	result.reportDropped()
+	return result.text, err
+}
+
+// decode executes whisper.cpp on a recording, returning the transcription
+// and the low-confidence segments left out of it
+func (w *WhisperCppTranscriber) decode(ctx context.Context, audioFilePath, language string, extraArgs ...string) (transcript, error) {
+	return w.decodeModel(ctx, audioFilePath, language, w.model(), extraArgs...)
+}
+
+// decodeModel is decode with another model than the current one
+func (w *WhisperCppTranscriber) decodeModel(ctx context.Context, audioFilePath, language, model string, extraArgs ...string) (transcript, error) {
+	// The server takes the prompt as a form field of the same name
+	if prompt := transcriptionPrompt(ctx, w.config); prompt != "" {
+		extraArgs = append([]string{"--prompt", prompt}, extraArgs...)
+	}
+	if w.whisperCppPath == "" && w.server == nil {
+		return transcript{}, fmt.Errorf("whisper.cpp not initialized")
 	}
 
 	// Create context with timeout
@@ -110,24 +391,56 @@ func (w *WhisperCppTranscriber) Transcribe(ctx context.Context, audioFilePath, l
 	// Make audio file path absolute
 	absAudioPath, err := filepath.Abs(audioFilePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to get absolute path for audio file: %w", err)
+		return transcript{}, fmt.Errorf("failed to get absolute path for audio file: %w", err)
 	}
 
 	// Check if file exists
 	if _, err := os.Stat(absAudioPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("audio file does not exist: %s", absAudioPath)
+		return transcript{}, fmt.Errorf("audio file does not exist: %s", absAudioPath)
+	}
+
+	// whisper.cpp assumes 16 kHz and quietly mistranscribes other rates
+	resampled, err := resampledForWhisper(absAudioPath)
+	if err != nil {
+		return transcript{}, fmt.Errorf("failed to resample audio for whisper.cpp: %w", err)
+	}
+	if resampled != absAudioPath {
+		defer os.Remove(resampled)
+		absAudioPath = resampled
+	}
+
+	// The server has the model loaded already; whisper-cli is the fallback.
+	// A managed server only keeps the main model loaded, or every long
+	// recording with WHISPER_DIARIZE_MODEL would restart it twice
+	if w.server != nil && (w.server.binary == "" || model == w.model()) {
+		output, err := w.server.transcribe(ctx, absAudioPath, language, model, extraArgs)
+		if err == nil {
+			return output.transcript(w.config.WhisperMinConfidence), nil
+		}
+		if w.whisperCppPath == "" || ctx.Err() != nil {
+			return transcript{}, err
+		}
+		fmt.Fprintf(Console, "⚠️  whisper.cpp server failed, running whisper-cli: %v\n", err)
 	}
 
 	// Build command arguments
 	args := []string{
 		"--language", language,
-		"--threads", "4",
 		"--file", absAudioPath,  // Use absolute path
 		"--output-txt",
+		"--output-json-full",
 		"--no-timestamps",
 		"--no-prints",
-		"-m", w.modelPath,
+		"-m", model,
+	}
+	args = append(args, whisperRuntimeArgs(w.config)...)
+	// whisper-cli takes tinydiarize as a switch, and only splits segments
+	// at speaker turns with timestamps on
+	if i := slices.Index(extraArgs, tinydiarizeArgs[0]); i >= 0 {
+		extraArgs = slices.Delete(slices.Clone(extraArgs), i+1, i+2)
+		args = slices.DeleteFunc(args, func(arg string) bool { return arg == "--no-timestamps" })
 	}
+	args = append(args, extraArgs...)
 
 	// Execute whisper.cpp
 	cmd := exec.CommandContext(ctx, w.whisperCppPath, args...)
@@ -139,11 +452,20 @@ func (w *WhisperCppTranscriber) Transcribe(ctx context.Context, audioFilePath, l
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("whisper.cpp failed: %w, output: %s", err, string(output))
+		return transcript{}, fmt.Errorf("whisper.cpp failed: %w, output: %s", err, string(output))
+	}
+
+	// Prefer the JSON output, which drops segments whisper was unsure of
+	jsonFile := absAudioPath + ".json"
+	result, err := readWhisperJSON(jsonFile)
+	os.Remove(jsonFile)
+	if err == nil {
+		os.Remove(absAudioPath + ".txt")
+		return result.transcript(w.config.WhisperMinConfidence), nil
 	}
 
 	// Parse output from stdout
-	transcription := ""
+	var transcription string
 	if len(output) > 0 {
 		transcription = w.parseWhisperOutput(string(output))
 	}
@@ -159,7 +481,9 @@ func (w *WhisperCppTranscriber) Transcribe(ctx context.Context, audioFilePath, l
 		}
 	}
 
-	return w.cleanTranscription(transcription), nil
+	// Without the JSON output there are no scores to go by
+	transcription = cleanTranscription(transcription)
+	return transcript{text: transcription, segments: []Segment{{Text: transcription, Confidence: 1}}}, nil
 }
 
 // parseWhisperOutput parses whisper.cpp stdout output
@@ -208,13 +532,15 @@ func (w *WhisperCppTranscriber) isDebugLine(line string) bool {
 }
 
 // cleanTranscription cleans up whisper.cpp output
-func (w *WhisperCppTranscriber) cleanTranscription(text string) string {
+func cleanTranscription(text string) string {
 	// Remove common artifacts
 	text = strings.ReplaceAll(text, "[BLANK_AUDIO]", "")
 	text = strings.ReplaceAll(text, "(silence)", "")
 	text = strings.ReplaceAll(text, "(music)", "")
 	text = strings.ReplaceAll(text, "[música]", "")
 	text = strings.ReplaceAll(text, "[MÚSICA]", "")
+	text = strings.ReplaceAll(text, speakerTurnMarker, "")
+	text = removeHallucinations(text)
 
 	// Remove multiple spaces
 	spaceRegex := regexp.MustCompile(`\s+`)
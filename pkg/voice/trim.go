@@ -0,0 +1,34 @@
+package voice
+
+import "errors"
+
+// Silence trimming: the recording is cut to the stretch between the first
+// and last frames above the threshold, with some margin for soft consonants
+const (
+	trimFrame  = 20  // milliseconds per level measurement
+	trimMargin = 300 // milliseconds kept before and after the speech
+)
+
+// errNoSpeech reports a recording where nothing rises above the silence threshold
+var errNoSpeech = errors.New("nothing above the silence threshold")
+
+// trimSilence returns the part of the interleaved 16-bit samples that holds
+// sound above thresholdDB (dBFS RMS), or nil if there is none
+func trimSilence(samples []int16, format wavFormat, thresholdDB float64) []int16 {
+	frame := max(format.Channels, format.SampleRate*trimFrame/1000*format.Channels)
+	first, last := -1, -1
+	for start := 0; start < len(samples); start += frame {
+		if rmsDB(samples[start:min(start+frame, len(samples))]) >= thresholdDB {
+			if first < 0 {
+				first = start
+			}
+			last = min(start+frame, len(samples))
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	margin := format.SampleRate * trimMargin / 1000 * format.Channels
+	return samples[max(first-margin, 0):min(last+margin, len(samples))]
+}
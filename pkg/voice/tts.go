@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
 	"os/exec"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jparrill/bobo-desk-pet/pkg/config"
@@ -18,18 +20,52 @@ type TextToSpeech interface {
 	Speak(ctx context.Context, text string) error
 }
 
+// VoiceSelector is implemented by TTS engines that can change voice and rate at runtime
+type VoiceSelector interface {
+	SetVoice(voiceID string, rate int)
+}
+
+// SpeechAdjuster is implemented by TTS engines that can speak faster or
+// louder than the selected voice, following the user's spoken preferences
+type SpeechAdjuster interface {
+	// Adjust adds rateDelta words per minute to every voice and scales its
+	// volume by gain (1 leaves it unchanged), reporting whether the engine
+	// can apply both
+	Adjust(rateDelta int, gain float64) bool
+}
+
+// Synthesizer is implemented by TTS engines that can render speech to a WAV file
+// instead of the local speakers
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text, path string) error
+}
+
 // SystemTTS implements TTS using system commands (espeak, say, etc.)
 type SystemTTS struct {
-	config  *config.TTSConfig
-	command string
-	args    []string
-	logger  *slog.Logger
+	config    *config.TTSConfig
+	command   string
+	buildArgs func(voice string, rate int) []string
+	fileArgs  func(path string) []string
+	gainArgs  func(gain float64) []string
+	logger    *slog.Logger
+
+	mu        sync.Mutex // guards the voice and adjustments below
+	voice     string
+	rate      int
+	rateDelta int
+	gain      float64
 }
 
+// minSpeechRate keeps slowed-down speech understandable
+const minSpeechRate = 80
+
 // NewTextToSpeech creates a new text-to-speech engine
 func NewTextToSpeech(cfg *config.TTSConfig) (TextToSpeech, error) {
 	tts := &SystemTTS{
 		config: cfg,
+		voice:  cfg.VoiceID,
+		rate:   cfg.Rate,
+		gain:   1,
 		logger: slog.Default(),
 	}
 
@@ -44,27 +80,47 @@ func NewTextToSpeech(cfg *config.TTSConfig) (TextToSpeech, error) {
 // detectTTSSystem detects available TTS system on the platform
 func (s *SystemTTS) detectTTSSystem() error {
 	// Try different TTS systems in order of preference
+	espeakArgs := func(voice string, rate int) []string {
+		if voice == "" {
+			voice = "es"
+		}
+		return []string{"-v", voice, "-s", fmt.Sprintf("%d", rate)}
+	}
+	espeakFile := func(path string) []string {
+		return []string{"-w", path}
+	}
+	espeakGain := func(gain float64) []string {
+		// Amplitude 100 is espeak's normal volume
+		return []string{"-a", fmt.Sprintf("%d", int(math.Round(100*gain)))}
+	}
+
 	systems := []struct {
 		command string
-		args    []string
+		args    func(voice string, rate int) []string
+		file    func(path string) []string
+		gain    func(gain float64) []string
 		test    []string
 	}{
 		{
 			// espeak-ng (Linux - preferred)
 			command: "espeak-ng",
-			args:    []string{"-v", "es", "-s", fmt.Sprintf("%d", s.config.Rate)},
+			args:    espeakArgs,
+			file:    espeakFile,
+			gain:    espeakGain,
 			test:    []string{"--help"},
 		},
 		{
 			// espeak (Linux - fallback)
 			command: "espeak",
-			args:    []string{"-v", "es", "-s", fmt.Sprintf("%d", s.config.Rate)},
+			args:    espeakArgs,
+			file:    espeakFile,
+			gain:    espeakGain,
 			test:    []string{"--help"},
 		},
 		{
 			// festival (Linux - alternative)
 			command: "festival",
-			args:    []string{"--tts"},
+			args:    func(string, int) []string { return []string{"--tts"} },
 			test:    []string{"--help"},
 		},
 	}
@@ -75,7 +131,9 @@ func (s *SystemTTS) detectTTSSystem() error {
 		triedCommands = append(triedCommands, system.command)
 		if s.testCommand(system.command, system.test) {
 			s.command = system.command
-			s.args = system.args
+			s.buildArgs = system.args
+			s.fileArgs = system.file
+			s.gainArgs = system.gain
 			s.logger.Info("🔊 TTS system detected", "command", system.command)
 			return nil
 		}
@@ -103,7 +161,7 @@ func (s *SystemTTS) Speak(ctx context.Context, text string) error {
 	s.logger.Info("🔊 Speaking response...")
 
 	// Clean text for speech
-	cleanText := s.cleanTextForSpeech(text)
+	cleanText := cleanTextForSpeech(text)
 	if cleanText == "" {
 		s.logger.Warn("⚠️ No speakable text after cleaning")
 		return nil
@@ -114,9 +172,7 @@ func (s *SystemTTS) Speak(ctx context.Context, text string) error {
 	defer cancel()
 
 	// Build command
-	args := make([]string, len(s.args))
-	copy(args, s.args)
-	args = append(args, cleanText)
+	args := append(s.args(), cleanText)
 
 	cmd := exec.CommandContext(ctx, s.command, args...)
 
@@ -129,8 +185,71 @@ func (s *SystemTTS) Speak(ctx context.Context, text string) error {
 	return nil
 }
 
+// Synthesize renders text to a WAV file at path instead of playing it
+func (s *SystemTTS) Synthesize(ctx context.Context, text, path string) error {
+	if s.fileArgs == nil {
+		return fmt.Errorf("%s cannot write audio files", s.command)
+	}
+
+	cleanText := cleanTextForSpeech(text)
+	if cleanText == "" {
+		return fmt.Errorf("no speakable text after cleaning")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	args := append(s.args(), s.fileArgs(path)...)
+	args = append(args, cleanText)
+
+	if output, err := exec.CommandContext(ctx, s.command, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("TTS command failed: %w, output: %s", err, string(output))
+	}
+	return nil
+}
+
+// SetVoice changes the voice and speech rate used for subsequent utterances;
+// empty/zero values restore the configured defaults
+func (s *SystemTTS) SetVoice(voiceID string, rate int) {
+	if voiceID == "" {
+		voiceID = s.config.VoiceID
+	}
+	if rate <= 0 {
+		rate = s.config.Rate
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.voice = voiceID
+	s.rate = rate
+}
+
+// Adjust makes every voice rateDelta words per minute faster and gain times
+// louder; the volume can't be changed when the command has no option for it
+// (macOS say)
+func (s *SystemTTS) Adjust(rateDelta int, gain float64) bool {
+	if gain <= 0 {
+		gain = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateDelta = rateDelta
+	s.gain = gain
+	return s.gainArgs != nil || gain == 1
+}
+
+// args returns the command arguments for the selected voice, adjusted
+func (s *SystemTTS) args() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	args := s.buildArgs(s.voice, max(s.rate+s.rateDelta, minSpeechRate))
+	if s.gainArgs != nil && s.gain != 1 {
+		args = append(args, s.gainArgs(s.gain)...)
+	}
+	return args
+}
+
 // cleanTextForSpeech cleans text for speech synthesis
-func (s *SystemTTS) cleanTextForSpeech(text string) string {
+func cleanTextForSpeech(text string) string {
 	// Remove emojis and special characters (keep accented characters)
 	emojiRegex := regexp.MustCompile(`[^\w\s\.\,\!\?\:\;\-\(\)\'\"áéíóúñÁÉÍÓÚÑüÜ]`)
 	cleanText := emojiRegex.ReplaceAllString(text, " ")
@@ -0,0 +1,85 @@
+package voice
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// unprimedKey marks contexts whose recordings are transcribed without the
+// prompt: whisper tends to echo it on silence, which would wake Bobo up
+type unprimedKey struct{}
+
+// withoutPrompt transcribes the recordings of ctx without priming whisper
+func withoutPrompt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unprimedKey{}, true)
+}
+
+// vocabularyWarning is the last problem with VOCABULARY_FILE reported, so it
+// is reported once rather than on every transcription
+var vocabularyWarning struct {
+	sync.Mutex
+	last string
+}
+
+// transcriptionPrompt is the text whisper is primed with so it spells the
+// configured names and terms right: WHISPER_PROMPT followed by the terms of
+// VOCABULARY and VOCABULARY_FILE ("Kubernetes, Itziar."); none for contexts
+// marked withoutPrompt
+func transcriptionPrompt(ctx context.Context, cfg *config.VoiceConfig) string {
+	if unprimed, _ := ctx.Value(unprimedKey{}).(bool); unprimed {
+		return ""
+	}
+	terms := append([]string(nil), cfg.Vocabulary...)
+	if cfg.VocabularyFile != "" {
+		// Read on every transcription so edits apply right away
+		fileTerms, err := readVocabulary(cfg.VocabularyFile)
+		warning := ""
+		if err != nil {
+			warning = err.Error()
+		}
+		vocabularyWarning.Lock()
+		if warning != "" && warning != vocabularyWarning.last {
+			fmt.Fprintf(Console, "⚠️  Vocabulary file ignored: %v\n", err)
+		}
+		vocabularyWarning.last = warning
+		vocabularyWarning.Unlock()
+		terms = append(terms, fileTerms...)
+	}
+
+	var parts []string
+	if prompt := strings.TrimSpace(cfg.WhisperPrompt); prompt != "" {
+		parts = append(parts, prompt)
+	}
+	if len(terms) > 0 {
+		parts = append(parts, strings.Join(terms, ", ")+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// readVocabulary reads one term per line, skipping blank lines and "#"
+// comments
+func readVocabulary(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var terms []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if term := strings.TrimSpace(scanner.Text()); term != "" && !strings.HasPrefix(term, "#") {
+			terms = append(terms, term)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return terms, nil
+}
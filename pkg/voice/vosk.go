@@ -0,0 +1,158 @@
+//go:build vosk
+
+package voice
+
+/*
+#cgo LDFLAGS: -lvosk
+#include <stdlib.h>
+#include <vosk_api.h>
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// voskSampleRate is the rate Vosk's small models are trained at
+const voskSampleRate = 16000
+
+// voskChunk is how many samples are fed to the recognizer at a time
+const voskChunk = 4000
+
+// voskResult is a recognizer result, with alternatives when asked for
+type voskResult struct {
+	Text         string `json:"text"`
+	Alternatives []struct {
+		Text string `json:"text"`
+	} `json:"alternatives"`
+}
+
+// VoskTranscriber transcribes with Vosk linked into Bobo, which is light
+// enough for a Raspberry Pi; build with -tags vosk
+type VoskTranscriber struct {
+	config *config.VoiceConfig
+	model  *C.VoskModel
+}
+
+// newVoskTranscriber loads the configured Vosk model into memory
+func newVoskTranscriber(cfg *config.VoiceConfig) (Transcriber, error) {
+	if cfg.VoskModelPath == "" {
+		return nil, fmt.Errorf("VOSK_MODEL is not set")
+	}
+	C.vosk_set_log_level(-1)
+
+	cPath := C.CString(cfg.VoskModelPath)
+	defer C.free(unsafe.Pointer(cPath))
+	model := C.vosk_model_new(cPath)
+	if model == nil {
+		return nil, fmt.Errorf("failed to load Vosk model %s", cfg.VoskModelPath)
+	}
+	fmt.Fprintf(Console, "✅ Loaded Vosk model: %s\n", cfg.VoskModelPath)
+	return &VoskTranscriber{config: cfg, model: model}, nil
+}
+
+// Close frees the loaded model
+func (v *VoskTranscriber) Close() error {
+	if v.model != nil {
+		C.vosk_model_free(v.model)
+		v.model = nil
+	}
+	return nil
+}
+
+// Transcribe transcribes audio with Vosk; the language comes with the model
+func (v *VoskTranscriber) Transcribe(ctx context.Context, audioFilePath, language string) (string, error) {
+	alternatives, err := v.decode(ctx, audioFilePath, 1)
+	if err != nil || len(alternatives) == 0 {
+		return "", err
+	}
+	return alternatives[0], nil
+}
+
+// TranscribeStream transcribes what has been recorded so far again and
+// again while the recording is made
+func (v *VoskTranscriber) TranscribeStream(ctx context.Context, audioFilePath, language string, partial func(text string)) error {
+	return followRecording(ctx, audioFilePath, func(ctx context.Context, snapshot string) (string, error) {
+		return v.Transcribe(ctx, snapshot, language)
+	}, partial)
+}
+
+// Alternatives asks Vosk for its n best hypotheses
+func (v *VoskTranscriber) Alternatives(ctx context.Context, audioFilePath, language string, n int) ([]string, error) {
+	return v.decode(ctx, audioFilePath, n)
+}
+
+// decode runs a recognizer over the recording, returning up to n
+// hypotheses, best first
+func (v *VoskTranscriber) decode(ctx context.Context, audioFilePath string, n int) ([]string, error) {
+	samples, err := monoSamples(audioFilePath, voskSampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	recognizer := C.vosk_recognizer_new(v.model, C.float(voskSampleRate))
+	if recognizer == nil {
+		return nil, fmt.Errorf("failed to create Vosk recognizer")
+	}
+	defer C.vosk_recognizer_free(recognizer)
+	if n > 1 {
+		C.vosk_recognizer_set_max_alternatives(recognizer, C.int(n))
+	}
+
+	// Vosk ends an utterance at each long pause; the parts are joined
+	var parts []voskResult
+	for start := 0; start < len(samples); start += voskChunk {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		chunk := samples[start:min(start+voskChunk, len(samples))]
+		if C.vosk_recognizer_accept_waveform_s(recognizer, (*C.short)(unsafe.Pointer(&chunk[0])), C.int(len(chunk))) == 1 {
+			part, err := parseVoskResult(C.GoString(C.vosk_recognizer_result(recognizer)))
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+	}
+	part, err := parseVoskResult(C.GoString(C.vosk_recognizer_final_result(recognizer)))
+	if err != nil {
+		return nil, err
+	}
+	parts = append(parts, part)
+
+	// Hypothesis i joins the i-th reading of every part
+	var alternatives []string
+	for i := range max(n, 1) {
+		var texts []string
+		for _, part := range parts {
+			text := part.Text
+			if len(part.Alternatives) > 0 {
+				text = part.Alternatives[min(i, len(part.Alternatives)-1)].Text
+			}
+			if text = strings.TrimSpace(text); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		text := cleanTranscription(strings.Join(texts, " "))
+		if text == "" || (i > 0 && text == alternatives[len(alternatives)-1]) {
+			break
+		}
+		alternatives = append(alternatives, text)
+	}
+	return alternatives, nil
+}
+
+// parseVoskResult decodes a recognizer's JSON result
+func parseVoskResult(data string) (voskResult, error) {
+	var result voskResult
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return result, fmt.Errorf("invalid Vosk result: %w", err)
+	}
+	return result, nil
+}
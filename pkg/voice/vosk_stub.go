@@ -0,0 +1,14 @@
+//go:build !vosk
+
+package voice
+
+import (
+	"fmt"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// newVoskTranscriber needs libvosk linked in, which this build hasn't
+func newVoskTranscriber(cfg *config.VoiceConfig) (Transcriber, error) {
+	return nil, fmt.Errorf("this build has no Vosk; rebuild with: make build-vosk")
+}
@@ -0,0 +1,448 @@
+package voice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/persona"
+	"github.com/jparrill/bobo-desk-pet/pkg/recordings"
+	"github.com/jparrill/bobo-desk-pet/pkg/skills"
+)
+
+// Wake word listening: utterances are cut from the live stream by their level
+// against the background, then transcribed and compared with the wake phrases
+const (
+	wakeMarginDB        = 12.0 // how far above the background speech starts
+	wakeNoiseSmoothing  = 0.05 // how fast the background level follows the room
+	wakePreRoll         = 300 * time.Millisecond
+	wakeTrailingSilence = 500 * time.Millisecond
+	wakeMinUtterance    = 300 * time.Millisecond
+	wakeMaxUtterance    = 5 * time.Second
+	wakeSensitivityStep = 0.05 // taken off on every "eso no era para ti"
+	wakeMinSensitivity  = 0.05
+	wakeMaxPhraseStart  = 1 // words allowed before the phrase ("oye", "eh")
+)
+
+// Environments with their own wake word sensitivity, told apart by the
+// background level
+const (
+	wakeQuiet = "quiet"
+	wakeNoisy = "noisy"
+)
+
+// WakeWord is a detected wake phrase
+type WakeWord struct {
+	Phrase    string
+	Score     float64
+	Rest      string // what was said after the phrase in the same breath
+	AudioPath string // the trigger utterance
+}
+
+// wakeUtterance is speech cut from the stream, waiting to be checked
+type wakeUtterance struct {
+	pcm         []byte
+	environment string
+}
+
+// WakeWordDetector listens to the continuous capture for the wake phrases
+type WakeWordDetector struct {
+	config     *config.WakeWordConfig
+	stream     *StreamRecorder
+	storage    *recordings.Store
+	transcribe func(ctx context.Context, audioPath string) (string, error)
+	verify     func(ctx context.Context, audioPath string) (string, error)
+	logger     *slog.Logger
+
+	mu          sync.Mutex
+	phrases     []string
+	paused      bool
+	sensitivity map[string]float64 // adjusted by false activations, saved to StateFile
+	last        string             // environment of the last detection
+}
+
+// NewWakeWordDetector creates a detector for phrases on the stream, using
+// transcribe to read the utterances
+func NewWakeWordDetector(cfg *config.WakeWordConfig, stream *StreamRecorder, phrases []string, transcribe func(ctx context.Context, audioPath string) (string, error)) (*WakeWordDetector, error) {
+	d := &WakeWordDetector{
+		config:     cfg,
+		stream:     stream,
+		transcribe: transcribe,
+		logger:     slog.Default(),
+		phrases:    phrases,
+		sensitivity: map[string]float64{
+			wakeQuiet: cfg.Sensitivity,
+			wakeNoisy: cfg.NoisySensitivity,
+		},
+	}
+
+	data, err := os.ReadFile(cfg.StateFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", cfg.StateFile, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &d.sensitivity); err != nil {
+			return nil, fmt.Errorf("invalid wake word state %s: %w", cfg.StateFile, err)
+		}
+	}
+	return d, nil
+}
+
+// SetPhrases replaces the wake phrases (e.g. after a persona switch)
+func (d *WakeWordDetector) SetPhrases(phrases []string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.phrases = phrases
+}
+
+// SetRecordings saves the utterances in the session directory of store
+func (d *WakeWordDetector) SetRecordings(store *recordings.Store) {
+	d.storage = store
+}
+
+// SetVerifier adds a second, more accurate reading of every detection before
+// it counts, to cut false activations
+func (d *WakeWordDetector) SetVerifier(verify func(ctx context.Context, audioPath string) (string, error)) {
+	d.verify = verify
+}
+
+// SetPaused stops listening for the wake phrases, or resumes
+func (d *WakeWordDetector) SetPaused(paused bool) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused = paused
+}
+
+// Reject lowers the sensitivity of the environment of the last detection after
+// a false activation, returning that environment and its new sensitivity
+func (d *WakeWordDetector) Reject() (string, float64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	environment := d.last
+	if environment == "" {
+		environment = wakeQuiet
+	}
+	d.sensitivity[environment] = max(d.sensitivity[environment]-wakeSensitivityStep, wakeMinSensitivity)
+
+	data, err := json.MarshalIndent(d.sensitivity, "", "  ")
+	if err != nil {
+		return environment, d.sensitivity[environment], err
+	}
+	if err := os.MkdirAll(filepath.Dir(d.config.StateFile), 0755); err != nil {
+		return environment, d.sensitivity[environment], fmt.Errorf("failed to create %s: %w", filepath.Dir(d.config.StateFile), err)
+	}
+	if err := os.WriteFile(d.config.StateFile, data, 0644); err != nil {
+		return environment, d.sensitivity[environment], fmt.Errorf("failed to write %s: %w", d.config.StateFile, err)
+	}
+	return environment, d.sensitivity[environment], nil
+}
+
+// Run listens until ctx is cancelled, calling onWake for every wake phrase heard
+func (d *WakeWordDetector) Run(ctx context.Context, onWake func(WakeWord)) {
+	chunks, unsubscribe := d.stream.Subscribe()
+	defer unsubscribe()
+
+	// Utterances are checked one at a time; speech heard meanwhile is dropped
+	utterances := make(chan wakeUtterance, 1)
+	defer close(utterances)
+	go func() {
+		for utterance := range utterances {
+			d.check(ctx, utterance, onWake)
+		}
+	}()
+
+	sampleRate, channels := d.stream.Format()
+	format := wavFormat{SampleRate: sampleRate, Channels: channels, BitsPerSample: 16}
+	duration := func(data []byte) time.Duration {
+		return time.Duration(len(data)) * time.Second / time.Duration(format.bytesPerSecond())
+	}
+
+	var (
+		floor    = 0.0
+		measured = false
+		preRoll  [][]byte
+		speech   []byte
+		talking  bool
+		silence  time.Duration
+	)
+	for {
+		var chunk []byte
+		select {
+		case <-ctx.Done():
+			return
+		case chunk = <-chunks:
+		}
+
+		if d.isPaused() {
+			talking, speech, preRoll = false, nil, nil
+			continue
+		}
+
+		// Digital silence (a muted microphone, or Bobo talking) tells nothing
+		// about the room
+		level := rmsDB(bytesToSamples(chunk))
+		if math.IsInf(level, -1) {
+			talking, speech, preRoll = false, nil, nil
+			continue
+		}
+		if !measured {
+			floor, measured = level, true
+		}
+		loud := level > floor+wakeMarginDB
+
+		if !talking {
+			if !loud {
+				floor += wakeNoiseSmoothing * (level - floor)
+				preRoll = append(preRoll, chunk)
+				for len(preRoll) > 1 && duration(preRoll[0])*time.Duration(len(preRoll)) > wakePreRoll {
+					preRoll = preRoll[1:]
+				}
+				continue
+			}
+			talking, silence = true, 0
+			speech = nil
+			for _, previous := range preRoll {
+				speech = append(speech, previous...)
+			}
+			preRoll = nil
+		}
+
+		speech = append(speech, chunk...)
+		if loud {
+			silence = 0
+		} else {
+			silence += duration(chunk)
+		}
+		if silence < wakeTrailingSilence && duration(speech) < wakeMaxUtterance {
+			continue
+		}
+
+		talking = false
+		if duration(speech)-silence < wakeMinUtterance {
+			continue
+		}
+		environment := wakeQuiet
+		if floor > d.config.NoisyLevelDB {
+			environment = wakeNoisy
+		}
+		select {
+		case utterances <- wakeUtterance{pcm: speech, environment: environment}:
+		default:
+		}
+	}
+}
+
+// isPaused reports whether listening is paused
+func (d *WakeWordDetector) isPaused() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.paused
+}
+
+// check transcribes an utterance and calls onWake if it starts with a wake phrase
+func (d *WakeWordDetector) check(ctx context.Context, utterance wakeUtterance, onWake func(WakeWord)) {
+	if d.isPaused() || ctx.Err() != nil {
+		return
+	}
+
+	path := d.storage.NewPath("wake_recording")
+
+	sampleRate, channels := d.stream.Format()
+	if err := writeWAV(path, utterance.pcm, wavFormat{SampleRate: sampleRate, Channels: channels, BitsPerSample: 16}); err != nil {
+		d.logger.Warn("Failed to save the wake word utterance", "error", err)
+		return
+	}
+
+	transcription, err := d.transcribe(ctx, path)
+	if err != nil {
+		d.logger.Warn("Failed to transcribe the wake word utterance", "error", err)
+	}
+
+	d.mu.Lock()
+	phrases := d.phrases
+	sensitivity := d.sensitivity[utterance.environment]
+	d.mu.Unlock()
+
+	// Full sensitivity accepts half the phrase misheard, none needs it word for word
+	threshold := 1 - sensitivity/2
+	wake, ok := matchWakePhrase(transcription, phrases)
+	if !ok || wake.Score < threshold {
+		d.logger.Debug("Not a wake word", "heard", transcription, "score", wake.Score, "environment", utterance.environment)
+		os.Remove(path)
+		return
+	}
+
+	// Read the trigger again, more carefully, before answering
+	if d.verify != nil {
+		confirmation, err := d.verify(ctx, path)
+		if err != nil {
+			d.logger.Warn("Failed to verify the wake word", "error", err)
+		}
+		verified, ok := matchWakePhrase(confirmation, phrases)
+		if !ok || verified.Score < threshold {
+			d.logger.Info("🙉 Wake word not confirmed", "heard", transcription, "verified", confirmation, "environment", utterance.environment)
+			os.Remove(path)
+			return
+		}
+		wake = verified
+	}
+
+	d.mu.Lock()
+	d.last = utterance.environment
+	d.mu.Unlock()
+
+	d.logger.Info("👂 Wake word heard", "phrase", wake.Phrase, "score", fmt.Sprintf("%.2f", wake.Score), "environment", utterance.environment)
+	wake.AudioPath = path
+	onWake(wake)
+}
+
+// matchWakePhrase finds the wake phrase that best matches the beginning of
+// the transcription, with what was said after it
+func matchWakePhrase(transcription string, phrases []string) (WakeWord, bool) {
+	words := strings.Fields(transcription)
+	var best WakeWord
+	found := false
+	for _, phrase := range phrases {
+		length := len(strings.Fields(phrase))
+		if length == 0 {
+			continue
+		}
+		for start := 0; start <= wakeMaxPhraseStart && start+length <= len(words); start++ {
+			score, _ := skills.AlignScore(phrase, strings.Join(words[start:start+length], " "))
+			if !found || score > best.Score {
+				rest := strings.Trim(strings.Join(words[start+length:], " "), " ,.:;!¡")
+				best, found = WakeWord{Phrase: phrase, Score: score, Rest: rest}, true
+			}
+		}
+	}
+	return best, found
+}
+
+// isWakeRejection recognizes the user telling Bobo it was woken up by mistake
+func isWakeRejection(transcription string) bool {
+	text := strings.ToLower(strings.Trim(transcription, " .!¡?¿"))
+	for _, phrase := range []string{"no era para ti", "no te hablaba a ti", "no te estaba hablando", "no hablaba contigo", "that wasn't for you", "that was not for you", "i wasn't talking to you"} {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// setupWakeWord creates the wake word detector, listening with the lighter
+// WAKE_WORD_MODEL when one is configured and verifying with the main one.
+// Wake words are listened for all the time, so never in the cloud: a cloud
+// TRANSCRIBER leaves them to whisper.cpp, and fallback engines are left out
+func (v *Interface) setupWakeWord() error {
+	transcriber := v.transcriber
+	if chain, ok := transcriber.(*FallbackTranscriber); ok {
+		transcriber = chain.engines[0].transcriber
+	}
+	cloud := cloudTranscribers[TranscriberName(v.config)]
+	if model := v.config.WakeWord.Model; model != "" || cloud {
+		voiceConfig := *v.config.Voice
+		if model != "" {
+			voiceConfig.WhisperModelPath = model
+		}
+		voiceConfig.TranscriberFallback = nil
+		if cloud {
+			voiceConfig.Transcriber = "whisper.cpp"
+		}
+		cfg := *v.config
+		cfg.Voice = &voiceConfig
+
+		var err error
+		if transcriber, err = NewTranscriber(&cfg); err != nil {
+			if cloud {
+				return fmt.Errorf("wake words need whisper.cpp, as %s would hear everything said near Bobo: %w", TranscriberName(v.config), err)
+			}
+			return fmt.Errorf("failed to load the wake word model: %w", err)
+		}
+		v.wakeASR = transcriber
+	}
+
+	transcribe := func(ctx context.Context, audioPath string) (string, error) {
+		transcription, err := transcriber.Transcribe(withoutPrompt(ctx), audioPath, v.transcriptionLanguage())
+		return removeHallucinations(transcription), err
+	}
+
+	var err error
+	v.wake, err = NewWakeWordDetector(v.config.WakeWord, v.stream, v.wakePhrases(v.personas.Active()), transcribe)
+	if err != nil {
+		return err
+	}
+
+	v.wake.SetRecordings(v.storage)
+
+	// Confirm detections with the main model and the usual clean-up; when
+	// that is the model that listened, it would only hear the same again
+	if v.config.WakeWord.Verify && v.wakeASR != nil {
+		v.wake.SetVerifier(v.transcribe)
+	}
+	return nil
+}
+
+// wakePhrases are the configured wake phrases, or the persona's wake word
+func (v *Interface) wakePhrases(p *persona.Persona) []string {
+	if len(v.config.WakeWord.Phrases) > 0 || p == nil {
+		return v.config.WakeWord.Phrases
+	}
+	return []string{p.WakeWord}
+}
+
+// wakeUp answers what was said right after the wake phrase, or records the
+// request when the phrase came alone
+func (v *Interface) wakeUp(ctx context.Context, wake WakeWord) {
+	v.touch()
+	v.telemetry.Count("wake_word")
+	fmt.Fprintf(v.rl.Stdout(), "\n  👂 %s\n", wake.Phrase)
+
+	if wake.Rest == "" {
+		os.Remove(wake.AudioPath)
+		v.stream.ClearPreRoll()
+		if err := v.processVoiceCommand(ctx, 7, nil); err != nil {
+			v.logger.Error("Voice command failed", "error", err)
+		}
+		return
+	}
+
+	v.busy.Lock()
+	defer v.busy.Unlock()
+	v.wake.SetPaused(true)
+	defer func() { v.wake.SetPaused(v.listeningPaused()) }()
+
+	v.logger.Info("👤 You said", "transcription", wake.Rest)
+	if err := v.respond(ctx, wake.Rest, wake.AudioPath); err != nil {
+		v.logger.Error("Failed to answer", "error", err)
+		return
+	}
+	if err := v.followUp(ctx); err != nil {
+		v.logger.Error("Follow-up failed", "error", err)
+	}
+}
+
+// rejectWakeUp makes the wake word harder to trigger after a false activation
+func (v *Interface) rejectWakeUp(ctx context.Context) error {
+	environment, sensitivity, err := v.wake.Reject()
+	if err != nil {
+		v.logger.Warn("Failed to save the wake word sensitivity", "error", err)
+	}
+	v.logger.Info("👂 Wake word sensitivity lowered", "environment", environment, "sensitivity", fmt.Sprintf("%.2f", sensitivity))
+	v.speak(ctx, "Perdona, me había parecido que me llamabas.")
+	return nil
+}
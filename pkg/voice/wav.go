@@ -0,0 +1,126 @@
+package voice
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// wavFormat describes the PCM layout of a WAV file
+type wavFormat struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+}
+
+// parseWAV returns the format and raw PCM data of a WAV file's contents
+func parseWAV(data []byte) (wavFormat, []byte, error) {
+	var format wavFormat
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return format, nil, fmt.Errorf("not a WAV file")
+	}
+
+	// Walk the chunks until the sample data
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		offset += 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 || offset+16 > len(data) {
+				return format, nil, fmt.Errorf("invalid fmt chunk")
+			}
+			format.Channels = int(binary.LittleEndian.Uint16(data[offset+2:]))
+			format.SampleRate = int(binary.LittleEndian.Uint32(data[offset+4:]))
+			format.BitsPerSample = int(binary.LittleEndian.Uint16(data[offset+14:]))
+		case "data":
+			// A zero size is a file still being recorded (see wavWriter)
+			end := len(data)
+			if size > 0 {
+				end = min(offset+size, len(data))
+			}
+			return format, data[offset:end], nil
+		}
+		offset += size + size%2
+	}
+	return format, nil, fmt.Errorf("no audio data")
+}
+
+// writeWAV writes PCM data to path as a WAV file
+func writeWAV(path string, pcm []byte, format wavFormat) error {
+	if err := os.WriteFile(path, append(wavHeader(len(pcm), format), pcm...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// wavHeader builds the 44-byte header of a PCM WAV file with dataSize bytes of audio
+func wavHeader(dataSize int, format wavFormat) []byte {
+	blockAlign := format.Channels * format.BitsPerSample / 8
+
+	header := make([]byte, wavHeaderSize)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(36+dataSize))
+	copy(header[8:], "WAVE")
+	copy(header[12:], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(header[22:], uint16(format.Channels))
+	binary.LittleEndian.PutUint32(header[24:], uint32(format.SampleRate))
+	binary.LittleEndian.PutUint32(header[28:], uint32(format.SampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(header[32:], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(header[34:], uint16(format.BitsPerSample))
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(dataSize))
+	return header
+}
+
+// wavWriter writes a WAV file as the audio comes in; the sizes in the header
+// are filled in by Close, so readers can follow the file meanwhile
+type wavWriter struct {
+	file   *os.File
+	format wavFormat
+	size   int
+	closed bool
+}
+
+// createWAV starts a WAV file at path
+func createWAV(path string, format wavFormat) (*wavWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := file.Write(wavHeader(0, format)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return &wavWriter{file: file, format: format}, nil
+}
+
+// Write appends PCM data
+func (w *wavWriter) Write(pcm []byte) (int, error) {
+	n, err := w.file.Write(pcm)
+	w.size += n
+	if err != nil {
+		return n, fmt.Errorf("failed to write %s: %w", w.file.Name(), err)
+	}
+	return n, nil
+}
+
+// Close completes the header and closes the file; later calls do nothing
+func (w *wavWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	_, err := w.file.WriteAt(wavHeader(w.size, w.format), 0)
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", w.file.Name(), err)
+	}
+	return nil
+}
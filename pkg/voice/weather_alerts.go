@@ -0,0 +1,66 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/claude"
+)
+
+// Weather alerts: the forecast is checked every weatherAlertInterval for
+// severe weather within weatherAlertHorizon
+const (
+	weatherAlertInterval = 30 * time.Minute
+	weatherAlertHorizon  = 12 * time.Hour
+)
+
+// runWeatherAlerts warns of severe weather coming at DEFAULT_LOCATION until
+// ctx is cancelled, each alert once
+func (v *Interface) runWeatherAlerts(ctx context.Context) {
+	location := v.config.VertexAI.DefaultLocation
+	if location == "" {
+		v.logger.Warn("Weather alerts disabled", "error", "DEFAULT_LOCATION is not set")
+		return
+	}
+	provider := claude.NewOpenMeteo(location)
+	v.logger.Info("🌩️ Weather alerts enabled", "location", location)
+
+	ticker := time.NewTicker(weatherAlertInterval)
+	defer ticker.Stop()
+
+	// Alerts already given, by kind and start
+	warned := make(map[string]time.Time)
+	for {
+		alerts, err := provider.Alerts(ctx, location, weatherAlertHorizon)
+		if err != nil {
+			v.logger.Warn("Failed to check for weather alerts", "error", err)
+		}
+		for _, alert := range alerts {
+			key := alert.Kind + alert.Start.Format("2006-01-02")
+			if _, ok := warned[key]; ok {
+				continue
+			}
+			warned[key] = alert.Start
+			message := "⚠️ " + alert.Text
+			fmt.Fprintf(v.rl.Stdout(), "\n  %s\n", message)
+			v.alert("weather", "weather:"+alert.Kind, "⚠️ Aviso meteorológico", message)
+			if v.away() {
+				v.pushNotification("⚠️ Aviso meteorológico", strings.TrimPrefix(message, "⚠️ "))
+			}
+		}
+		// Forget alerts from days gone by
+		for key, start := range warned {
+			if time.Since(start) > 2*weatherAlertHorizon {
+				delete(warned, key)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
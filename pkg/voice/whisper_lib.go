@@ -0,0 +1,258 @@
+//go:build whisper
+
+package voice
+
+/*
+#cgo LDFLAGS: -lwhisper
+#include <stdlib.h>
+#include <whisper.h>
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// libDecoding is a set of whisper decoding parameters; zero values keep
+// whisper.cpp's defaults
+type libDecoding struct {
+	beamSize    int
+	temperature float32
+	bestOf      int
+}
+
+// libAlternativeDecodings mirror alternativeDecodings for the linked library
+var libAlternativeDecodings = []libDecoding{
+	{beamSize: 5},
+	{temperature: 0.4, bestOf: 5},
+	{temperature: 0.8, bestOf: 5},
+}
+
+// WhisperLibTranscriber transcribes with whisper.cpp linked into Bobo, so the
+// model stays loaded and no output files are parsed; build with -tags whisper
+type WhisperLibTranscriber struct {
+	config *config.VoiceConfig
+
+	mu        sync.Mutex // a whisper context runs one transcription at a time
+	ctx       *C.struct_whisper_context
+	modelPath string
+}
+
+// newWhisperLibTranscriber loads the configured model into memory
+func newWhisperLibTranscriber(cfg *config.VoiceConfig) (Transcriber, error) {
+	w := &WhisperLibTranscriber{config: cfg}
+	if err := w.load(cfg.WhisperModelPath); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(Console, "✅ Loaded whisper model in process: %s\n", cfg.WhisperModelPath)
+	return w, nil
+}
+
+// load replaces the loaded model with the one at path
+func (w *WhisperLibTranscriber) load(path string) error {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	params := C.whisper_context_default_params()
+	params.use_gpu = C.bool(w.config.WhisperGPU)
+	params.gpu_device = C.int(w.config.WhisperGPUDevice)
+	params.flash_attn = C.bool(w.config.WhisperFlashAttn)
+	ctx := C.whisper_init_from_file_with_params(cPath, params)
+	if ctx == nil {
+		return fmt.Errorf("failed to load whisper model %s", path)
+	}
+	if w.ctx != nil {
+		C.whisper_free(w.ctx)
+	}
+	w.ctx, w.modelPath = ctx, path
+	return nil
+}
+
+// SetModel switches to the model at path, or back to WhisperModelPath if empty
+func (w *WhisperLibTranscriber) SetModel(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if path == "" {
+		path = w.config.WhisperModelPath
+	}
+	if path == w.modelPath {
+		return
+	}
+	if err := w.load(path); err != nil {
+		fmt.Fprintf(Console, "⚠️  %v, keeping %s\n", err, w.modelPath)
+	}
+}
+
+// Close frees the loaded model
+func (w *WhisperLibTranscriber) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ctx != nil {
+		C.whisper_free(w.ctx)
+		w.ctx = nil
+	}
+	return nil
+}
+
+// Transcribe transcribes audio with the linked whisper.cpp
+func (w *WhisperLibTranscriber) Transcribe(ctx context.Context, audioFilePath, language string) (string, error) {
+	result, err := w.decode(ctx, audioFilePath, language, libDecoding{})
+	result.reportDropped()
+	return result.text, err
+}
+
+// TranscribeLanguage transcribes audio in whichever language was spoken,
+// returning it
+func (w *WhisperLibTranscriber) TranscribeLanguage(ctx context.Context, audioFilePath string) (string, string, error) {
+	result, err := w.decode(ctx, audioFilePath, autoLanguage, libDecoding{})
+	result.reportDropped()
+	return result.text, result.language, err
+}
+
+// TranscribeSegments is Transcribe with whisper's confidence in each segment
+func (w *WhisperLibTranscriber) TranscribeSegments(ctx context.Context, audioFilePath, language string) ([]Segment, error) {
+	result, err := w.decode(ctx, audioFilePath, language, libDecoding{})
+	result.reportDropped()
+	return result.segments, err
+}
+
+// TranscribeWords is Transcribe with the timing of every word
+func (w *WhisperLibTranscriber) TranscribeWords(ctx context.Context, audioFilePath, language string) (*TranscriptionResult, error) {
+	result, err := w.decode(ctx, audioFilePath, language, libDecoding{})
+	if err != nil {
+		return nil, err
+	}
+	return &TranscriptionResult{Text: result.text, Language: result.language, Words: result.words}, nil
+}
+
+// TranscribeStream transcribes what has been recorded so far again and
+// again while the recording is made
+func (w *WhisperLibTranscriber) TranscribeStream(ctx context.Context, audioFilePath, language string, partial func(text string)) error {
+	return followRecording(ctx, audioFilePath, func(ctx context.Context, snapshot string) (string, error) {
+		result, err := w.decode(ctx, snapshot, language, libDecoding{})
+		return result.text, err
+	}, partial)
+}
+
+// Alternatives transcribes the recording again with other decoding settings,
+// returning up to n distinct hypotheses
+func (w *WhisperLibTranscriber) Alternatives(ctx context.Context, audioFilePath, language string, n int) ([]string, error) {
+	var alternatives []string
+	seen := make(map[string]bool)
+	for _, decoding := range libAlternativeDecodings {
+		if len(alternatives) >= n {
+			break
+		}
+		result, err := w.decode(ctx, audioFilePath, language, decoding)
+		if err != nil {
+			return alternatives, err
+		}
+		text := result.text
+		key := strings.ToLower(strings.Trim(text, " .,!¡?¿"))
+		if key != "" && !seen[key] {
+			seen[key] = true
+			alternatives = append(alternatives, text)
+		}
+	}
+	return alternatives, nil
+}
+
+// decode runs the model on a recording, returning the transcription and
+// the low-confidence segments left out of it
+func (w *WhisperLibTranscriber) decode(ctx context.Context, audioFilePath, language string, decoding libDecoding) (transcript, error) {
+	samples, err := whisperSamples(audioFilePath)
+	if err != nil {
+		return transcript{}, err
+	}
+	if len(samples) == 0 {
+		return transcript{}, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ctx == nil {
+		return transcript{}, fmt.Errorf("whisper model not loaded")
+	}
+	if err := ctx.Err(); err != nil {
+		return transcript{}, err
+	}
+
+	strategy := C.enum_whisper_sampling_strategy(C.WHISPER_SAMPLING_GREEDY)
+	if decoding.beamSize > 0 {
+		strategy = C.WHISPER_SAMPLING_BEAM_SEARCH
+	}
+	params := C.whisper_full_default_params(strategy)
+
+	cLanguage := C.CString(language)
+	defer C.free(unsafe.Pointer(cLanguage))
+	params.language = cLanguage
+	if prompt := transcriptionPrompt(ctx, w.config); prompt != "" {
+		cPrompt := C.CString(prompt)
+		defer C.free(unsafe.Pointer(cPrompt))
+		params.initial_prompt = cPrompt
+	}
+	params.n_threads = C.int(whisperThreads(w.config))
+	params.no_timestamps = true
+	params.token_timestamps = true
+	params.print_progress = false
+	params.print_realtime = false
+	params.print_timestamps = false
+	params.print_special = false
+	if decoding.beamSize > 0 {
+		params.beam_search.beam_size = C.int(decoding.beamSize)
+	}
+	if decoding.temperature > 0 {
+		params.temperature = C.float(decoding.temperature)
+	}
+	if decoding.bestOf > 0 {
+		params.greedy.best_of = C.int(decoding.bestOf)
+	}
+
+	if C.whisper_full(w.ctx, params, (*C.float)(&samples[0]), C.int(len(samples))) != 0 {
+		return transcript{}, fmt.Errorf("whisper failed to transcribe %s", audioFilePath)
+	}
+
+	var output whisperJSON
+	for i := range C.whisper_full_n_segments(w.ctx) {
+		segment := whisperSegment{Text: C.GoString(C.whisper_full_get_segment_text(w.ctx, i))}
+		for j := range C.whisper_full_n_tokens(w.ctx, i) {
+			data := C.whisper_full_get_token_data(w.ctx, i, j)
+			token := whisperToken{
+				Text: C.GoString(C.whisper_full_get_token_text(w.ctx, i, j)),
+				P:    float64(data.p),
+			}
+			// Token times are in hundredths of a second
+			token.Offsets.From = int64(data.t0) * 10
+			token.Offsets.To = int64(data.t1) * 10
+			segment.Tokens = append(segment.Tokens, token)
+		}
+		output.Transcription = append(output.Transcription, segment)
+	}
+
+	output.Result.Language = C.GoString(C.whisper_lang_str(C.whisper_full_lang_id(w.ctx)))
+	return output.transcript(w.config.WhisperMinConfidence), nil
+}
+
+// whisperSamples reads a 16-bit WAV file as the 16 kHz mono float samples
+// whisper takes
+func whisperSamples(path string) ([]float32, error) {
+	mono, err := monoSamples(path, whisperSampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]float32, len(mono))
+	for i, sample := range mono {
+		samples[i] = float32(sample) / 32768
+	}
+	return samples, nil
+}
@@ -0,0 +1,14 @@
+//go:build !whisper
+
+package voice
+
+import (
+	"fmt"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+)
+
+// newWhisperLibTranscriber needs whisper.cpp linked in, which this build hasn't
+func newWhisperLibTranscriber(cfg *config.VoiceConfig) (Transcriber, error) {
+	return nil, fmt.Errorf("this build has no in-process whisper; rebuild with: go build -tags whisper ./cmd/bobo")
+}
@@ -0,0 +1,52 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/models"
+)
+
+// switchModel makes the transcriber use another whisper model from the
+// catalog ("m small"), downloading and verifying it first if needed
+func (v *Interface) switchModel(ctx context.Context, name string) error {
+	transcriber, ok := TranscriberAs[ModelTranscriber](v.transcriber)
+	if !ok {
+		return fmt.Errorf("%s can't switch models, only whisper.cpp and whisper-lib can", TranscriberName(v.config))
+	}
+	model, err := models.Find(name)
+	if err != nil {
+		return err
+	}
+
+	// Models are kept next to the configured one
+	manager := models.NewManager(filepath.Dir(v.config.Voice.WhisperModelPath))
+	path := manager.Path(model)
+	if _, err := os.Stat(path); err != nil {
+		v.logger.Info("⬇️ Downloading whisper model", "model", model.Name, "size", model.Size)
+		shown := 0
+		path, err = manager.Download(ctx, model, func(done, total int64) {
+			// Every tenth of the way
+			if total <= 0 {
+				return
+			}
+			if percent := int(done * 100 / total); percent >= shown+10 {
+				shown = percent / 10 * 10
+				fmt.Fprintf(v.rl.Stdout(), "  ⬇️  %d%%\n", shown)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// On battery the lighter model stays until Bobo is plugged back in
+	v.config.Voice.WhisperModelPath = path
+	if !v.power.Saving() {
+		transcriber.SetModel("")
+	}
+	v.logger.Info("🎙️ Whisper model switched", "model", model.Name, "path", path)
+	return nil
+}
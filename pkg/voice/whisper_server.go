@@ -0,0 +1,281 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serverStartTimeout is how long a managed whisper.cpp server gets to load
+// its model
+const serverStartTimeout = 2 * time.Minute
+
+// whisperServerResult is the subset of the server's verbose_json response used
+type whisperServerResult struct {
+	Text             string `json:"text"`
+	Language         string `json:"language"`
+	DetectedLanguage string `json:"detected_language"`
+	Segments         []struct {
+		Text  string  `json:"text"`
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Words []struct {
+			Word        string  `json:"word"`
+			Start       float64 `json:"start"`
+			End         float64 `json:"end"`
+			Probability float64 `json:"probability"`
+		} `json:"words"`
+	} `json:"segments"`
+}
+
+// whisperServer talks to a whisper.cpp server, which keeps the model loaded
+// between transcriptions; a managed server is started, restarted on a model
+// change and stopped by Bobo
+type whisperServer struct {
+	binary string   // whisper-server to manage, empty for an external server
+	args   []string // threads and GPU of a managed server
+	client *http.Client
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	url    string
+	cmd    *exec.Cmd
+	exited chan struct{}
+	model  string
+}
+
+// newWhisperServer uses the whisper.cpp server at url, or manages one
+// running binary with args when url is empty
+func newWhisperServer(binary, url string, args []string) *whisperServer {
+	return &whisperServer{
+		binary: binary,
+		args:   args,
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{},
+		logger: slog.Default(),
+	}
+}
+
+// findWhisperServer locates the whisper.cpp server binary next to the CLI
+func findWhisperServer(cliPath string) (string, error) {
+	candidates := []string{"whisper-server", "server"}
+	if strings.Contains(cliPath, "/") {
+		dir := filepath.Dir(cliPath)
+		for _, name := range candidates {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+	}
+	if path, err := exec.LookPath("whisper-server"); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("whisper-server binary not found next to %s or in PATH", cliPath)
+}
+
+// ready returns the server's URL, first starting a managed server with model
+// if it isn't running it yet
+func (s *whisperServer) ready(model string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.binary == "" {
+		return s.url, nil
+	}
+	if s.cmd != nil && s.model == model && !s.hasExited() {
+		return s.url, nil
+	}
+	s.stopLocked()
+
+	port, err := freePort()
+	if err != nil {
+		return "", err
+	}
+	args := append([]string{
+		"-m", model,
+		"--host", "127.0.0.1",
+		"--port", strconv.Itoa(port),
+	}, s.args...)
+	cmd := exec.Command(s.binary, args...)
+	if strings.Contains(s.binary, "/") {
+		cmd.Dir = filepath.Dir(s.binary)
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start whisper.cpp server: %w", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	s.cmd, s.exited, s.model = cmd, exited, model
+	s.url = fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	s.logger.Info("🧠 Loading whisper model into the whisper.cpp server", "model", filepath.Base(model), "port", port)
+	start := time.Now()
+	if err := s.waitHealthy(); err != nil {
+		s.stopLocked()
+		return "", err
+	}
+	s.logger.Info("✅ whisper.cpp server ready", "load_time", time.Since(start).Round(time.Millisecond))
+	return s.url, nil
+}
+
+// waitHealthy polls the managed server until it has loaded the model
+func (s *whisperServer) waitHealthy() error {
+	deadline := time.After(serverStartTimeout)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.exited:
+			return fmt.Errorf("whisper.cpp server exited while loading the model")
+		case <-deadline:
+			return fmt.Errorf("whisper.cpp server not ready after %s", serverStartTimeout)
+		case <-ticker.C:
+		}
+
+		resp, err := s.client.Get(s.url + "/health")
+		if err != nil {
+			continue // not listening yet
+		}
+		resp.Body.Close()
+		// Older servers have no /health but only listen once the model is loaded
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			return nil
+		}
+	}
+}
+
+// hasExited reports whether the managed server process is gone
+func (s *whisperServer) hasExited() bool {
+	select {
+	case <-s.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// stopLocked kills the managed server, if any
+func (s *whisperServer) stopLocked() {
+	if s.cmd == nil {
+		return
+	}
+	s.cmd.Process.Kill()
+	<-s.exited
+	s.cmd = nil
+}
+
+// Close stops a managed server
+func (s *whisperServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopLocked()
+	return nil
+}
+
+// transcribe sends a 16 kHz WAV recording to the server; extraArgs are
+// whisper-cli decoding flags, passed on as the matching form fields
+func (s *whisperServer) transcribe(ctx context.Context, audioPath, language, model string, extraArgs []string) (whisperJSON, error) {
+	var output whisperJSON
+	url, err := s.ready(model)
+	if err != nil {
+		return output, err
+	}
+
+	audio, err := os.ReadFile(audioPath)
+	if err != nil {
+		return output, fmt.Errorf("failed to read audio file: %w", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filepath.Base(audioPath))
+	if err != nil {
+		return output, fmt.Errorf("failed to create request: %w", err)
+	}
+	part.Write(audio)
+	form.WriteField("language", language)
+	form.WriteField("response_format", "verbose_json")
+	for i := 0; i+1 < len(extraArgs); i += 2 {
+		field := strings.ReplaceAll(strings.TrimLeft(extraArgs[i], "-"), "-", "_")
+		form.WriteField(field, extraArgs[i+1])
+	}
+	form.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url+"/inference", &body)
+	if err != nil {
+		return output, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return output, fmt.Errorf("whisper.cpp server request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return output, fmt.Errorf("failed to read whisper.cpp server response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return output, fmt.Errorf("whisper.cpp server returned status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var result whisperServerResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return output, fmt.Errorf("failed to parse whisper.cpp server response: %w", err)
+	}
+
+	// Words stand in for the tokens of the CLI's JSON file, each starting
+	// with a space as a token that begins a word does
+	for _, segment := range result.Segments {
+		// With tinydiarize the server marks speaker turns in the text
+		text, turn := strings.CutSuffix(strings.TrimSpace(segment.Text), speakerTurnMarker)
+		converted := whisperSegment{Text: text, SpeakerTurnNext: turn}
+		converted.Offsets.From = int64(segment.Start * 1000)
+		converted.Offsets.To = int64(segment.End * 1000)
+		for _, word := range segment.Words {
+			token := whisperToken{Text: " " + strings.TrimSpace(word.Word), P: word.Probability}
+			token.Offsets.From = int64(word.Start * 1000)
+			token.Offsets.To = int64(word.End * 1000)
+			converted.Tokens = append(converted.Tokens, token)
+		}
+		output.Transcription = append(output.Transcription, converted)
+	}
+	output.Result.Language = result.Language
+	if result.DetectedLanguage != "" {
+		output.Result.Language = result.DetectedLanguage
+	}
+	if len(output.Transcription) == 0 && strings.TrimSpace(result.Text) != "" {
+		output.Transcription = []whisperSegment{{Text: result.Text}}
+	}
+	return output, nil
+}
+
+// freePort asks the system for an unused local TCP port
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
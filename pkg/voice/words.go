@@ -0,0 +1,56 @@
+package voice
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Word is a transcribed word and when it was said in the recording
+type Word struct {
+	Text       string
+	Start      time.Duration
+	End        time.Duration
+	Confidence float64 // 0-1
+}
+
+// TranscriptionResult is a transcription with the timing of every word, to
+// align the text with the audio
+type TranscriptionResult struct {
+	Text     string
+	Language string
+	Words    []Word
+}
+
+// WordTranscriber is implemented by transcribers that know when each word
+// was said
+type WordTranscriber interface {
+	TranscribeWords(ctx context.Context, audioFilePath, language string) (*TranscriptionResult, error)
+}
+
+// tokenWords joins whisper's sub-word tokens into words: a token starting
+// with a space starts a new word
+func tokenWords(tokens []whisperToken) []Word {
+	var words []Word
+	var probability float64
+	var count int
+	for _, token := range tokens {
+		// Skip special tokens such as [_BEG_] and [_TT_150]
+		if strings.HasPrefix(token.Text, "[_") || strings.TrimSpace(token.Text) == "" {
+			continue
+		}
+		start := time.Duration(token.Offsets.From) * time.Millisecond
+		end := time.Duration(token.Offsets.To) * time.Millisecond
+		if len(words) == 0 || strings.HasPrefix(token.Text, " ") {
+			words = append(words, Word{Start: start})
+			probability, count = 0, 0
+		}
+		word := &words[len(words)-1]
+		word.Text += strings.TrimSpace(token.Text)
+		word.End = end
+		probability += token.P
+		count++
+		word.Confidence = probability / float64(count)
+	}
+	return words
+}
@@ -0,0 +1,144 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/jparrill/bobo-desk-pet/pkg/config"
+	"github.com/jparrill/bobo-desk-pet/pkg/quota"
+	"github.com/jparrill/bobo-desk-pet/pkg/wyoming"
+)
+
+// WyomingTranscriber implements transcription with a remote Wyoming
+// speech-to-text service such as Home Assistant's faster-whisper add-on
+type WyomingTranscriber struct {
+	client *wyoming.Client
+}
+
+// NewWyomingTranscriber creates a transcriber for the service at cfg.ASRURI
+func NewWyomingTranscriber(cfg *config.WyomingConfig) (*WyomingTranscriber, error) {
+	client, err := wyoming.NewClient(cfg.ASRURI)
+	if err != nil {
+		return nil, err
+	}
+	return &WyomingTranscriber{client: client}, nil
+}
+
+// Transcribe sends a WAV recording to the remote service
+func (w *WyomingTranscriber) Transcribe(ctx context.Context, audioFilePath, language string) (string, error) {
+	data, err := os.ReadFile(audioFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", audioFilePath, err)
+	}
+
+	format, pcm, err := parseWAV(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", audioFilePath, err)
+	}
+
+	text, err := w.client.Transcribe(ctx, wyoming.AudioFormat{
+		Rate:     format.SampleRate,
+		Width:    format.BitsPerSample / 8,
+		Channels: format.Channels,
+	}, pcm, language)
+	if err != nil {
+		return "", fmt.Errorf("wyoming transcription failed: %w", err)
+	}
+	return text, nil
+}
+
+// TranscribeStream sends what has been recorded so far again and again while
+// the recording is made
+func (w *WyomingTranscriber) TranscribeStream(ctx context.Context, audioFilePath, language string, partial func(text string)) error {
+	return followRecording(ctx, audioFilePath, func(ctx context.Context, snapshot string) (string, error) {
+		return w.Transcribe(ctx, snapshot, language)
+	}, partial)
+}
+
+// WyomingTTS implements TTS with a remote Wyoming text-to-speech service such
+// as Home Assistant's piper add-on, playing the audio on the local speakers
+type WyomingTTS struct {
+	client *wyoming.Client
+	voice  string
+	player *Player
+	quota  *quota.Guard
+	logger *slog.Logger
+}
+
+// NewWyomingTTS creates a TTS engine for the service at cfg.TTSURI that
+// plays its speech with player
+func NewWyomingTTS(cfg *config.WyomingConfig, player *Player) (*WyomingTTS, error) {
+	if player == nil {
+		return nil, fmt.Errorf("no audio player to play the speech with")
+	}
+	client, err := wyoming.NewClient(cfg.TTSURI)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WyomingTTS{
+		client: client,
+		voice:  cfg.TTSVoice,
+		player: player,
+		logger: slog.Default(),
+	}, nil
+}
+
+// SetQuota caps the characters sent to the remote service
+func (w *WyomingTTS) SetQuota(guard *quota.Guard) {
+	w.quota = guard
+}
+
+// Speak synthesizes text remotely and plays it
+func (w *WyomingTTS) Speak(ctx context.Context, text string) error {
+	if text == "" {
+		return nil
+	}
+
+	w.logger.Info("🔊 Speaking response...", "service", w.client.Addr())
+
+	file, err := os.CreateTemp("", "bobo-speech-*.wav")
+	if err != nil {
+		return fmt.Errorf("failed to create speech file: %w", err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	if err := w.Synthesize(ctx, text, file.Name()); err != nil {
+		return err
+	}
+
+	if err := w.player.Play(ctx, file.Name()); err != nil {
+		return err
+	}
+
+	w.logger.Info("✅ TTS completed")
+	return nil
+}
+
+// Synthesize renders text to a WAV file at path
+func (w *WyomingTTS) Synthesize(ctx context.Context, text, path string) error {
+	cleanText := cleanTextForSpeech(text)
+	if cleanText == "" {
+		return fmt.Errorf("no speakable text after cleaning")
+	}
+
+	chars := len([]rune(cleanText))
+	if err := w.quota.Allow(quota.TTSCharacters, chars); err != nil {
+		return err
+	}
+
+	format, pcm, err := w.client.Synthesize(ctx, cleanText, w.voice)
+	if err != nil {
+		return fmt.Errorf("wyoming synthesis failed: %w", err)
+	}
+	w.quota.Record(quota.TTSCharacters, chars)
+
+	return writeWAV(path, pcm, wavFormat{
+		SampleRate:    format.Rate,
+		Channels:      format.Channels,
+		BitsPerSample: format.Width * 8,
+	})
+}
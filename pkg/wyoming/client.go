@@ -0,0 +1,122 @@
+package wyoming
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds a whole request to a remote service
+const requestTimeout = 60 * time.Second
+
+// Client talks to a remote Wyoming service, such as Home Assistant's
+// wyoming-faster-whisper (speech-to-text) or wyoming-piper (text-to-speech)
+type Client struct {
+	addr string
+}
+
+// NewClient creates a client for a service URI like tcp://host:10300
+// (the tcp:// scheme is optional)
+func NewClient(uri string) (*Client, error) {
+	addr := strings.TrimPrefix(strings.TrimSpace(uri), "tcp://")
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, fmt.Errorf("invalid Wyoming address %q: %w", uri, err)
+	}
+	return &Client{addr: addr}, nil
+}
+
+// Addr returns the service address
+func (c *Client) Addr() string {
+	return c.addr
+}
+
+// Transcribe sends raw PCM audio to a speech-to-text service and returns the transcript
+func (c *Client) Transcribe(ctx context.Context, format AudioFormat, pcm []byte, language string) (string, error) {
+	conn, reader, err := c.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	request := &Event{Type: TypeTranscribe, Data: map[string]any{}}
+	if language != "" {
+		request.Data["language"] = language
+	}
+	if err := WriteEvent(conn, request); err != nil {
+		return "", fmt.Errorf("failed to send transcribe request: %w", err)
+	}
+	if err := WriteAudio(conn, format, pcm); err != nil {
+		return "", fmt.Errorf("failed to send audio: %w", err)
+	}
+
+	for {
+		event, err := ReadEvent(reader)
+		if err != nil {
+			return "", fmt.Errorf("failed to read transcript: %w", err)
+		}
+		switch event.Type {
+		case TypeTranscript:
+			return event.String("text"), nil
+		case TypeError:
+			return "", fmt.Errorf("service error: %s", event.String("text"))
+		}
+	}
+}
+
+// Synthesize asks a text-to-speech service to speak text (optionally with a
+// named voice) and returns the raw PCM audio
+func (c *Client) Synthesize(ctx context.Context, text, voice string) (AudioFormat, []byte, error) {
+	var format AudioFormat
+
+	conn, reader, err := c.dial(ctx)
+	if err != nil {
+		return format, nil, err
+	}
+	defer conn.Close()
+
+	request := &Event{Type: TypeSynthesize, Data: map[string]any{"text": text}}
+	if voice != "" {
+		request.Data["voice"] = map[string]any{"name": voice}
+	}
+	if err := WriteEvent(conn, request); err != nil {
+		return format, nil, fmt.Errorf("failed to send synthesize request: %w", err)
+	}
+
+	var pcm []byte
+	for {
+		event, err := ReadEvent(reader)
+		if err != nil {
+			return format, nil, fmt.Errorf("failed to read audio: %w", err)
+		}
+		switch event.Type {
+		case TypeAudioStart:
+			format = event.Format()
+		case TypeAudioChunk:
+			pcm = append(pcm, event.Payload...)
+		case TypeAudioStop:
+			return format, pcm, nil
+		case TypeError:
+			return format, nil, fmt.Errorf("service error: %s", event.String("text"))
+		}
+	}
+}
+
+// dial connects to the service, bounding the request by ctx and requestTimeout
+func (c *Client) dial(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", c.addr, err)
+	}
+
+	deadline := time.Now().Add(requestTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	return conn, bufio.NewReader(conn), nil
+}
@@ -0,0 +1,226 @@
+// Package wyoming implements the framing of the Wyoming protocol used by Home
+// Assistant voice services and satellites: a JSON header line, optionally
+// followed by extra JSON data and a binary payload
+package wyoming
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+)
+
+// Version is the protocol version sent in event headers
+const Version = "1.5.2"
+
+// Event types used by Bobo
+const (
+	TypeDescribe   = "describe"
+	TypeInfo       = "info"
+	TypeAudioStart = "audio-start"
+	TypeAudioChunk = "audio-chunk"
+	TypeAudioStop  = "audio-stop"
+	TypeTranscribe = "transcribe"
+	TypeTranscript = "transcript"
+	TypeSynthesize = "synthesize"
+	TypeHandled    = "handled"
+	TypeNotHandled = "not-handled"
+	TypeError      = "error"
+
+	TypeRecognize     = "recognize"
+	TypeIntent        = "intent"
+	TypeNotRecognized = "not-recognized"
+)
+
+// maxSegment guards against corrupt headers announcing huge segments
+const maxSegment = 16 * 1024 * 1024
+
+// maxHeader caps the header line, which is read before its length is known
+const maxHeader = 1024 * 1024
+
+// chunkBytes is the size of the audio chunks written by WriteAudio
+const chunkBytes = 2048
+
+// Event is a single protocol message
+type Event struct {
+	Type    string
+	Data    map[string]any
+	Payload []byte
+}
+
+// header is the first line of every event on the wire
+type header struct {
+	Type          string         `json:"type"`
+	Data          map[string]any `json:"data,omitempty"`
+	DataLength    int            `json:"data_length,omitempty"`
+	PayloadLength int            `json:"payload_length,omitempty"`
+	Version       string         `json:"version,omitempty"`
+}
+
+// Limits caps the parts of an event; longer ones are rejected before they
+// are read
+type Limits struct {
+	Header  int
+	Data    int
+	Payload int
+}
+
+// DefaultLimits are the limits of ReadEvent
+var DefaultLimits = Limits{Header: maxHeader, Data: maxSegment, Payload: maxSegment}
+
+// ReadEvent reads the next event from r
+func ReadEvent(r *bufio.Reader) (*Event, error) {
+	return ReadEventLimits(r, DefaultLimits)
+}
+
+// ReadEventLimits reads the next event from r within limits, for peers that
+// aren't trusted yet
+func ReadEventLimits(r *bufio.Reader, limits Limits) (*Event, error) {
+	line, err := readLine(r, limits.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	var h header
+	if err := json.Unmarshal(line, &h); err != nil {
+		return nil, fmt.Errorf("invalid event header: %w", err)
+	}
+	if h.Type == "" {
+		return nil, fmt.Errorf("event header without type")
+	}
+	if h.DataLength < 0 || h.DataLength > limits.Data || h.PayloadLength < 0 || h.PayloadLength > limits.Payload {
+		return nil, fmt.Errorf("event %s has an invalid segment length", h.Type)
+	}
+
+	event := &Event{Type: h.Type, Data: h.Data}
+	if event.Data == nil {
+		event.Data = make(map[string]any)
+	}
+
+	if h.DataLength > 0 {
+		raw := make([]byte, h.DataLength)
+		if _, err := io.ReadFull(r, raw); err != nil {
+			return nil, fmt.Errorf("failed to read event data: %w", err)
+		}
+		var extra map[string]any
+		if err := json.Unmarshal(raw, &extra); err != nil {
+			return nil, fmt.Errorf("invalid event data: %w", err)
+		}
+		maps.Copy(event.Data, extra)
+	}
+
+	if h.PayloadLength > 0 {
+		event.Payload = make([]byte, h.PayloadLength)
+		if _, err := io.ReadFull(r, event.Payload); err != nil {
+			return nil, fmt.Errorf("failed to read event payload: %w", err)
+		}
+	}
+
+	return event, nil
+}
+
+// readLine reads up to and including the next newline, failing once the line
+// grows past limit bytes
+func readLine(r *bufio.Reader, limit int) ([]byte, error) {
+	var line []byte
+	for {
+		fragment, err := r.ReadSlice('\n')
+		if len(line)+len(fragment) > limit {
+			return nil, fmt.Errorf("event header longer than %d bytes", limit)
+		}
+		line = append(line, fragment...)
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
+
+// WriteEvent writes an event to w, sending its data as a separate segment
+func WriteEvent(w io.Writer, event *Event) error {
+	h := header{Type: event.Type, PayloadLength: len(event.Payload), Version: Version}
+
+	var data []byte
+	if len(event.Data) > 0 {
+		var err error
+		if data, err = json.Marshal(event.Data); err != nil {
+			return fmt.Errorf("failed to encode event data: %w", err)
+		}
+		h.DataLength = len(data)
+	}
+
+	line, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("failed to encode event header: %w", err)
+	}
+
+	buf := make([]byte, 0, len(line)+1+len(data)+len(event.Payload))
+	buf = append(buf, line...)
+	buf = append(buf, '\n')
+	buf = append(buf, data...)
+	buf = append(buf, event.Payload...)
+
+	_, err = w.Write(buf)
+	return err
+}
+
+// String returns a data field as a string ("" when missing)
+func (e *Event) String(key string) string {
+	value, _ := e.Data[key].(string)
+	return value
+}
+
+// Int returns a numeric data field as an int, or fallback when missing
+func (e *Event) Int(key string, fallback int) int {
+	if value, ok := e.Data[key].(float64); ok {
+		return int(value)
+	}
+	if value, ok := e.Data[key].(int); ok {
+		return value
+	}
+	return fallback
+}
+
+// AudioFormat describes raw PCM audio carried in audio events
+type AudioFormat struct {
+	Rate     int
+	Width    int
+	Channels int
+}
+
+// Format reads the audio format from an audio-start or audio-chunk event,
+// defaulting to 16kHz 16-bit mono
+func (e *Event) Format() AudioFormat {
+	return AudioFormat{
+		Rate:     e.Int("rate", 16000),
+		Width:    e.Int("width", 2),
+		Channels: e.Int("channels", 1),
+	}
+}
+
+// AudioEvent builds an audio-start/audio-chunk/audio-stop event
+func AudioEvent(eventType string, format AudioFormat, payload []byte) *Event {
+	return &Event{
+		Type: eventType,
+		Data: map[string]any{
+			"rate":     format.Rate,
+			"width":    format.Width,
+			"channels": format.Channels,
+		},
+		Payload: payload,
+	}
+}
+
+// WriteAudio streams PCM as audio-start, audio-chunk and audio-stop events
+func WriteAudio(w io.Writer, format AudioFormat, pcm []byte) error {
+	if err := WriteEvent(w, AudioEvent(TypeAudioStart, format, nil)); err != nil {
+		return err
+	}
+	for offset := 0; offset < len(pcm); offset += chunkBytes {
+		end := min(offset+chunkBytes, len(pcm))
+		if err := WriteEvent(w, AudioEvent(TypeAudioChunk, format, pcm[offset:end])); err != nil {
+			return err
+		}
+	}
+	return WriteEvent(w, AudioEvent(TypeAudioStop, format, nil))
+}
@@ -178,6 +178,24 @@ detect_hardware_flags() {
         CMAKE_FLAGS="-DCMAKE_BUILD_TYPE=Release"
     fi
 
+    # GPU acceleration asked for with WHISPER_ACCEL (WHISPER_GPU picks it at runtime)
+    case "${WHISPER_ACCEL:-}" in
+        metal)
+            CMAKE_FLAGS="$CMAKE_FLAGS -DGGML_METAL=ON"
+            ;;
+        coreml)
+            CMAKE_FLAGS="$CMAKE_FLAGS -DGGML_METAL=ON -DWHISPER_COREML=ON"
+            ;;
+        cuda)
+            CMAKE_FLAGS="$CMAKE_FLAGS -DGGML_CUDA=ON"
+            ;;
+        "")
+            ;;
+        *)
+            echo "⚠️  Unknown WHISPER_ACCEL '$WHISPER_ACCEL' (metal, coreml or cuda), building for the CPU" >&2
+            ;;
+    esac
+
     echo "$CMAKE_FLAGS"
 }
 
@@ -197,6 +215,9 @@ show_hardware_detection() {
         else
             echo "🔧 Using standard build configuration for $ARCH"
         fi
+        if [ -n "${WHISPER_ACCEL:-}" ]; then
+            echo "🚀 Building with $WHISPER_ACCEL acceleration"
+        fi
     fi
 }
 